@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// clipboardAccess coordinates backend access between concurrent tool calls
+// and the monitor. Concurrent reads are collapsed into a single backend call
+// whose result is shared (single flight), since every read spawns a process
+// or crosses into PowerShell on most platforms. Writes are exclusive: they
+// wait for an in-flight read, and reads issued during a write start a fresh
+// backend call once it completes instead of sharing a stale result.
+type clipboardAccess struct {
+	backend func() clipboardBackend
+
+	rw       sync.RWMutex // held shared by a backend read, exclusively by a write
+	mu       sync.Mutex   // protects inflight
+	inflight *readCall
+
+	readOnly atomic.Bool // set by --read-only; every write fails with errReadOnly
+}
+
+// clipboardRead is the outcome of a backend read.
+type clipboardRead struct {
+	content  string // converted to UTF-8
+	encoding string // detected source encoding, see convertToUTF8
+	utility  string // utility that served the read, if the backend reports it
+}
+
+// readCall is one backend read shared by every caller that joined it.
+type readCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc // kills the backend read once every waiter left
+	waiters int                // callers still waiting, protected by clipboardAccess.mu
+	data    clipboardRead
+	err     error
+}
+
+func newClipboardAccess(backend func() clipboardBackend) *clipboardAccess {
+	return &clipboardAccess{backend: backend}
+}
+
+// clipboardAccessor is the coordinator used by readClipboard and writeClipboard.
+var clipboardAccessor = newClipboardAccess(selectBackend)
+
+// read returns the clipboard converted to UTF-8, joining a read already in
+// flight if there is one. The backend read is bounded by MCP_CLIP_READ_TIMEOUT
+// rather than by any one caller's ctx, since it is shared; a caller whose ctx
+// ends stops waiting, and the read is abandoned once nobody waits for it any
+// more.
+func (a *clipboardAccess) read(ctx context.Context) (clipboardRead, error) {
+	a.mu.Lock()
+	call := a.inflight
+	if call == nil {
+		// Detached from the caller so others can share the read, but traced
+		// as part of the call that started it
+		readCtx, cancel := context.WithCancel(trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx)))
+		call = &readCall{done: make(chan struct{}), cancel: cancel}
+		a.inflight = call
+		go a.run(readCtx, call)
+	}
+	call.waiters++
+	a.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.data, call.err
+	case <-ctx.Done():
+		a.mu.Lock()
+		if call.waiters--; call.waiters == 0 {
+			call.cancel()
+			a.retire(call)
+		}
+		a.mu.Unlock()
+		return clipboardRead{}, ctx.Err()
+	}
+}
+
+// run performs the backend read for call and wakes its waiters.
+func (a *clipboardAccess) run(ctx context.Context, call *readCall) {
+	a.rw.RLock()
+	defer func() {
+		// Retire the call before releasing the read lock, so a write that
+		// completes afterwards can never be followed by a reader joining it.
+		a.mu.Lock()
+		a.retire(call)
+		a.mu.Unlock()
+		a.rw.RUnlock()
+		call.cancel()
+		close(call.done)
+	}()
+
+	// The timeout starts once a pending write has finished
+	ctx, cancel := withReadTimeout(ctx)
+	defer cancel()
+	var err error
+	backend, limit := a.backend(), getMaxClipboardBytes()
+	ctx, end := startChildSpan(ctx, "clipboard.read", attribute.String("clipboard.backend", backend.Name()))
+	defer func() { end(call.err) }()
+	if ur, ok := backend.(utilityReader); ok {
+		call.data.content, call.data.utility, err = ur.ReadUtility(ctx, limit)
+	} else {
+		call.data.content, err = readBackendLimited(ctx, backend, limit)
+	}
+	if err == nil {
+		call.data.content, call.data.encoding = convertToUTF8(call.data.content)
+	}
+	call.err = timeoutError(ctx, err)
+	metrics.backendCall(false, backend.Name(), call.err)
+}
+
+// retire stops new readers from joining call. a.mu must be held.
+func (a *clipboardAccess) retire(call *readCall) {
+	if a.inflight == call {
+		a.inflight = nil
+	}
+}
+
+// write replaces the clipboard content, excluding concurrent backend reads.
+func (a *clipboardAccess) write(ctx context.Context, content string) error {
+	return a.exclusive(ctx, func(ctx context.Context, backend clipboardBackend) error {
+		return backend.Write(ctx, content)
+	})
+}
+
+// writeImage places an image on the clipboard, like write.
+func (a *clipboardAccess) writeImage(ctx context.Context, data []byte, mimeType string) error {
+	return a.exclusive(ctx, func(ctx context.Context, backend clipboardBackend) error {
+		writer, ok := backend.(imageWriter)
+		if !ok {
+			return fmt.Errorf("the %s backend cannot write images to the clipboard", backend.Name())
+		}
+		return writer.WriteImage(ctx, data, mimeType)
+	})
+}
+
+// exclusive runs a backend write while no backend read is in flight.
+func (a *clipboardAccess) exclusive(ctx context.Context, write func(context.Context, clipboardBackend) error) error {
+	if a.readOnly.Load() {
+		return errReadOnly
+	}
+	a.rw.Lock()
+	defer a.rw.Unlock()
+	ctx, cancel := withReadTimeout(ctx)
+	defer cancel()
+	backend := a.backend()
+	ctx, end := startChildSpan(ctx, "clipboard.write", attribute.String("clipboard.backend", backend.Name()))
+	err := timeoutError(ctx, write(ctx, backend))
+	end(err)
+	metrics.backendCall(true, backend.Name(), err)
+	return err
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeBackend is an in-memory clipboard that counts backend calls.
+type fakeBackend struct {
+	mu      sync.Mutex
+	content string
+	reads   atomic.Int32
+	delay   time.Duration
+}
+
+func (b *fakeBackend) Name() string { return "fake" }
+
+func (b *fakeBackend) Read(ctx context.Context) (string, error) {
+	b.reads.Add(1)
+	select {
+	case <-time.After(b.delay):
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.content, nil
+}
+
+func (b *fakeBackend) Write(ctx context.Context, content string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.content = content
+	return nil
+}
+
+// Test that concurrent reads share a single backend call
+func TestClipboardAccessSingleFlight(t *testing.T) {
+	backend := &fakeBackend{content: "shared", delay: 50 * time.Millisecond}
+	access := newClipboardAccess(func() clipboardBackend { return backend })
+
+	var wg sync.WaitGroup
+	results := make([]string, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data, _ := access.read(context.Background())
+			results[i] = data.content
+		}(i)
+	}
+	wg.Wait()
+
+	for i, got := range results {
+		if got != "shared" {
+			t.Errorf("Reader %d: expected shared content, got %q", i, got)
+		}
+	}
+	if reads := backend.reads.Load(); reads >= int32(len(results)) {
+		t.Errorf("Expected concurrent reads to be collapsed, got %d backend reads", reads)
+	}
+}
+
+// Test that a read issued after a write completes observes the write
+func TestClipboardAccessReadAfterWrite(t *testing.T) {
+	backend := &fakeBackend{content: "old", delay: time.Millisecond}
+	access := newClipboardAccess(func() clipboardBackend { return backend })
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					access.read(context.Background())
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		want := time.Now().String()
+		if err := access.write(context.Background(), want); err != nil {
+			t.Fatalf("Unexpected write error: %v", err)
+		}
+		if got, _ := access.read(context.Background()); got.content != want {
+			t.Errorf("Expected %q after write, got %q", want, got.content)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+// Test that a hung backend read is killed after MCP_CLIP_READ_TIMEOUT
+func TestClipboardAccessReadTimeout(t *testing.T) {
+	t.Setenv("MCP_CLIP_READ_TIMEOUT", "20ms")
+	backend := &fakeBackend{content: "slow", delay: time.Minute}
+	access := newClipboardAccess(func() clipboardBackend { return backend })
+
+	start := time.Now()
+	_, err := access.read(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected read to give up quickly, took %v", elapsed)
+	}
+	if hint := remediationHint(err); !strings.Contains(hint, "MCP_CLIP_READ_TIMEOUT") {
+		t.Errorf("Expected hint to mention MCP_CLIP_READ_TIMEOUT, got %q", hint)
+	}
+}
+
+// Test that a cancelled caller stops waiting without failing the others
+func TestClipboardAccessCallerCancellation(t *testing.T) {
+	backend := &fakeBackend{content: "shared", delay: 100 * time.Millisecond}
+	access := newClipboardAccess(func() clipboardBackend { return backend })
+
+	done := make(chan string)
+	go func() {
+		data, _ := access.read(context.Background())
+		done <- data.content
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := access.read(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the cancelled caller to get its context error, got %v", err)
+	}
+	if content := <-done; content != "shared" {
+		t.Errorf("Expected the remaining caller to get the shared read, got %q", content)
+	}
+	if reads := backend.reads.Load(); reads != 1 {
+		t.Errorf("Expected 1 backend read, got %d", reads)
+	}
+}
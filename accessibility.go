@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// macSelectedTextScript asks System Events for the focused element's AXSelectedText.
+const macSelectedTextScript = `tell application "System Events"
+	set frontApp to first application process whose frontmost is true
+	set focusedElement to value of attribute "AXFocusedUIElement" of frontApp
+	return value of attribute "AXSelectedText" of focusedElement
+end tell`
+
+// uiaSelectedTextScript reads the selection of the focused element through UI Automation.
+const uiaSelectedTextScript = `
+	Add-Type -AssemblyName UIAutomationClient
+	Add-Type -AssemblyName UIAutomationTypes
+	$element = [System.Windows.Automation.AutomationElement]::FocusedElement
+	$pattern = $null
+	if ($element -and $element.TryGetCurrentPattern([System.Windows.Automation.TextPattern]::Pattern, [ref]$pattern)) {
+		$ranges = $pattern.GetSelection()
+		if ($ranges.Length -gt 0) { $ranges[0].GetText(-1) }
+	}
+`
+
+// accessibilityEnabled reports whether the user opted into selected-text
+// capture. Reading another application's selection is sensitive, so it is
+// never done without explicit configuration.
+func accessibilityEnabled() bool {
+	return os.Getenv("MCP_CLIP_ACCESSIBILITY") == "1"
+}
+
+// readSelectedText returns the text currently selected in the focused
+// application via the platform accessibility API: AX on macOS, UI Automation
+// on Windows and WSL2, and the PRIMARY selection on X11/Wayland (which is
+// where Linux desktops publish selected text).
+func readSelectedText(ctx context.Context) (string, error) {
+	ctx, cancel := withReadTimeout(ctx)
+	defer cancel()
+	var cmd *exec.Cmd
+	switch {
+	case isWSL2() || runtime.GOOS == "windows":
+		powershellPath := findPowerShell()
+		if powershellPath == "" {
+			return "", fmt.Errorf("PowerShell not found - required for UI Automation access")
+		}
+		cmd = powershellCommand(ctx, powershellPath, "-NoProfile", "-Command", uiaSelectedTextScript)
+	case runtime.GOOS == "darwin":
+		cmd = exec.CommandContext(ctx, "osascript", "-e", macSelectedTextScript)
+	default:
+		return readPrimarySelection(ctx)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("accessibility query failed (is accessibility access granted?): %v", err)
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+func readPrimarySelection(ctx context.Context) (string, error) {
+	candidates := [][]string{
+		withWaylandSeat([]string{"wl-paste", "--primary", "--no-newline"}),
+		{"xclip", "-o", "-selection", "primary"},
+		{"xsel", "--output", "--primary"},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		candidates = candidates[1:]
+	}
+
+	for _, args := range candidates {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		output, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("%s failed to read the primary selection: %v", args[0], err)
+		}
+		return string(output), nil
+	}
+	return "", fmt.Errorf("no selection utility found (install wl-clipboard, xclip or xsel)")
+}
+
+// selectionFallbackResult serves read_clipboard's selection_fallback option
+// when the clipboard is empty.
+func (cs *ClipboardServer) selectionFallbackResult(ctx context.Context, format string) (*mcp.CallToolResult, error) {
+	if !accessibilityEnabled() {
+		return mcp.NewToolResultText("Clipboard is empty (selected-text fallback is disabled; set MCP_CLIP_ACCESSIBILITY=1 to enable it)"), nil
+	}
+
+	selected, err := readSelectedText(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Clipboard is empty and reading the selected text failed: %v", err)), nil
+	}
+	if selected == "" {
+		return mcp.NewToolResultText("Clipboard is empty and no text is selected"), nil
+	}
+
+	result, err := cs.contentResult(ctx, selected, format)
+	if err != nil || result.IsError {
+		return result, err
+	}
+	header := mcp.NewTextContent("Clipboard is empty; returning the text selected in the focused application")
+	result.Content = append([]mcp.Content{header}, result.Content...)
+	return result, nil
+}
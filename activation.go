@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenFDsStart is the first file descriptor systemd passes to an activated
+// service.
+const listenFDsStart = 3
+
+// activatedListener returns the listening socket systemd passed to this
+// process (LISTEN_PID and LISTEN_FDS), or nil when it wasn't socket activated.
+// The variables are cleared so child processes don't claim the socket too.
+func activatedListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, nil
+	}
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	file := os.NewFile(listenFDsStart, "systemd-socket")
+	defer file.Close()
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use the socket passed by systemd: %v", err)
+	}
+	if fds > 1 && os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Ignoring %d extra sockets passed by systemd\n", fds-1)
+	}
+	return listener, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const appendRetries = 3
+
+// joinClipboard appends text to current, inserting separator unless current
+// is empty or already ends with it.
+func joinClipboard(current, text, separator string) string {
+	if current == "" || strings.HasSuffix(current, separator) {
+		return current + text
+	}
+	return current + separator + text
+}
+
+// appendClipboard appends text to the clipboard. Appends from this server are
+// serialized, and the clipboard is re-read right before writing so that a copy
+// the user makes in the meantime isn't overwritten with stale content.
+func (cs *ClipboardServer) appendClipboard(ctx context.Context, text, separator, expectedHash string) (string, error) {
+	cs.appendMutex.Lock()
+	defer cs.appendMutex.Unlock()
+
+	for attempt := 0; attempt < appendRetries; attempt++ {
+		current, err := readClipboard(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to read clipboard: %v", err)
+		}
+		if hint := cs.concealedHint(ctx, current); hint != "" {
+			return "", &concealedError{hint: hint}
+		}
+		if current != "" && !isProbablyText(current) {
+			return "", fmt.Errorf("clipboard holds binary content; only text can be appended to")
+		}
+		if expectedHash != "" && contentHash(current) != expectedHash {
+			return "", fmt.Errorf("clipboard changed since expected_hash was read (now %s)", contentHash(current))
+		}
+
+		combined := joinClipboard(current, text, separator)
+		if latest, err := readClipboard(ctx); err != nil || latest != current {
+			continue
+		}
+		if err := writeClipboard(ctx, combined); err != nil {
+			return "", fmt.Errorf("failed to write clipboard: %v", err)
+		}
+		cs.recordChange(combined)
+		return combined, nil
+	}
+	return "", fmt.Errorf("clipboard kept changing while appending; try again")
+}
+
+func (cs *ClipboardServer) appendToClipboardHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	text, err := request.RequireString("text")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if text == "" {
+		return mcp.NewToolResultError("text must not be empty"), nil
+	}
+	separator := request.GetString("separator", "\n")
+
+	combined, err := cs.appendClipboard(ctx, text, separator, request.GetString("expected_hash", ""))
+	var concealed *concealedError
+	if errors.As(err, &concealed) {
+		return concealedResult(concealed.hint), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to append to clipboard: %v", err)), nil
+	}
+
+	result := mcp.NewToolResultText(fmt.Sprintf("Appended %d bytes; the clipboard now holds %d bytes", len(text), len(combined)))
+	result.Meta = mcp.NewMetaFromMap(map[string]any{"sha256": contentHash(combined)})
+	return result, nil
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+// Test separator handling when appending to clipboard text
+func TestJoinClipboard(t *testing.T) {
+	tests := []struct {
+		current, text, separator, want string
+	}{
+		{"", "first", "\n", "first"},
+		{"first", "second", "\n", "first\nsecond"},
+		{"first\n", "second", "\n", "first\nsecond"},
+		{"a", "b", ", ", "a, b"},
+		{"a", "b", "", "ab"},
+	}
+	for _, tt := range tests {
+		if got := joinClipboard(tt.current, tt.text, tt.separator); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+}
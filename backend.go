@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// clipboardBackend abstracts the platform mechanism used to access the
+// clipboard. Utilities the backend runs are killed once ctx is done.
+type clipboardBackend interface {
+	Name() string
+	Read(ctx context.Context) (string, error)
+	Write(ctx context.Context, content string) error
+}
+
+// utilityReader is implemented by backends that read through one of several
+// utilities and can report which one served the read.
+type utilityReader interface {
+	ReadUtility(ctx context.Context, limit int) (content, utility string, err error)
+}
+
+// changeWatcher is implemented by backends that are told about clipboard
+// changes as they happen, which replaces polling.
+type changeWatcher interface {
+	WatchChanges(ctx context.Context, changed func()) error
+}
+
+// historyProvider is implemented by backends backed by a clipboard manager
+// that keeps its own history.
+type historyProvider interface {
+	History(ctx context.Context, n int) ([]string, error)
+}
+
+// formatReader is implemented by backends that can list the formats on the
+// clipboard and read a specific one, such as text/html.
+type formatReader interface {
+	Formats(ctx context.Context) ([]string, error)
+	ReadFormat(ctx context.Context, mimeType string) (string, error)
+}
+
+// imageWriter is implemented by backends that can place an image, rather
+// than text, on the clipboard. mimeType is image/png or image/jpeg.
+type imageWriter interface {
+	WriteImage(ctx context.Context, data []byte, mimeType string) error
+}
+
+// textMIMETypes are the text formats, in order of preference. They are also
+// what backends that own the selection offer when writing text.
+var textMIMETypes = []string{"text/plain;charset=utf-8", "UTF8_STRING", "text/plain", "STRING"}
+
+// preferredMIMEType chooses which offered format to read: text first, then
+// any image, then whatever is offered first.
+func preferredMIMEType(offered []string) string {
+	for _, preferred := range textMIMETypes {
+		for _, mimeType := range offered {
+			if strings.EqualFold(mimeType, preferred) {
+				return mimeType
+			}
+		}
+	}
+	for _, mimeType := range offered {
+		if strings.HasPrefix(mimeType, "image/") {
+			return mimeType
+		}
+	}
+	if len(offered) > 0 {
+		return offered[0]
+	}
+	return ""
+}
+
+// backendOverrides are the backends MCP_CLIP_BACKEND can force instead of
+// detecting one.
+var backendOverrides = map[string]clipboardBackend{
+	"native":  nativeBackend{},
+	"wsl2":    wsl2Backend{},
+	"termux":  termuxBackend{},
+	"klipper": klipperBackend{},
+	"portal":  portalBackend{},
+	"copyq":   copyqBackend{},
+	"bridge":  bridgeBackend{},
+}
+
+// selectBackend picks the backend for the current environment. Detection is
+// cheap and repeated on every call so that environment changes are honored.
+func selectBackend() clipboardBackend {
+	if backend, ok := backendOverrides[strings.ToLower(os.Getenv("MCP_CLIP_BACKEND"))]; ok {
+		return backend
+	}
+	switch {
+	case os.Getenv("MCP_CLIP_BRIDGE") != "":
+		return bridgeBackend{}
+	case isTermux():
+		return termuxBackend{}
+	case isFlatpak():
+		return portalBackend{}
+	case isWSL2():
+		return wsl2Backend{}
+	default:
+		return nativeBackend{}
+	}
+}
+
+// warnBackend reports an MCP_CLIP_BACKEND value that names no backend.
+func warnBackend() {
+	name := strings.ToLower(os.Getenv("MCP_CLIP_BACKEND"))
+	if _, ok := backendOverrides[name]; !ok && name != "" && name != "auto" {
+		fmt.Fprintf(os.Stderr, "Unknown backend '%s' in MCP_CLIP_BACKEND, detecting one instead\n", name)
+	}
+}
+
+// nativeBackend uses pbpaste on macOS, the configurable utility chain
+// (wl-clipboard, xclip, xsel) on Linux and atotto/clipboard's Win32 calls on
+// Windows.
+type nativeBackend struct{}
+
+func (nativeBackend) Name() string { return "native" }
+
+func (b nativeBackend) Read(ctx context.Context) (string, error) {
+	return b.ReadLimited(ctx, 0)
+}
+
+func (nativeBackend) Write(ctx context.Context, content string) error {
+	if usesLinuxUtilities() {
+		return writeLinuxClipboard(ctx, content)
+	}
+	_, err := runWithContext(ctx, func() (struct{}, error) {
+		return struct{}{}, clipboard.WriteAll(content)
+	})
+	return err
+}
+
+// WriteImage uses osascript on macOS, PowerShell on Windows and wl-copy or
+// xclip on Linux.
+func (nativeBackend) WriteImage(ctx context.Context, data []byte, mimeType string) error {
+	switch {
+	case runtime.GOOS == "darwin":
+		return writeMacImage(ctx, data, mimeType)
+	case runtime.GOOS == "windows":
+		return writeWindowsImage(ctx, data)
+	case usesLinuxUtilities():
+		return writeLinuxImage(ctx, data, mimeType)
+	}
+	return fmt.Errorf("writing images to the clipboard is not supported on %s", runtime.GOOS)
+}
+
+func (b nativeBackend) ReadLimited(ctx context.Context, limit int) (string, error) {
+	content, _, err := b.ReadUtility(ctx, limit)
+	return content, err
+}
+
+// ReadUtility streams the output of pbpaste or the Linux utility chain, so
+// reading can stop at limit. Windows reads through the Win32 API and has no
+// such utility; there the size of the clipboard text is checked before it is
+// copied out.
+func (nativeBackend) ReadUtility(ctx context.Context, limit int) (string, string, error) {
+	switch {
+	case runtime.GOOS == "darwin":
+		output, err := runLimited(ctx, exec.CommandContext(ctx, "pbpaste"), limit)
+		return string(output), "pbpaste", timeoutError(ctx, err)
+	case usesLinuxUtilities():
+		return readLinuxClipboard(ctx, limit)
+	}
+
+	if limit > 0 {
+		if size, err := clipboardTextSize(); err == nil {
+			if oversize := utf16Oversize(size, limit); oversize != nil {
+				return "", "", oversize
+			}
+		}
+	}
+	content, err := runWithContext(ctx, clipboard.ReadAll)
+	if err == nil && limit > 0 && len(content) > limit {
+		return "", "", &oversizeError{size: len(content), limit: limit, exact: true}
+	}
+	return content, "", err
+}
+
+// WatchChanges reports clipboard changes where the platform offers a cheap
+// change signal: NSPasteboard's changeCount on macOS, a clipboard format
+// listener window on Windows and XFixes selection events on X11. Elsewhere
+// it fails immediately, and the clipboard is polled instead.
+func (nativeBackend) WatchChanges(ctx context.Context, changed func()) error {
+	switch {
+	case runtime.GOOS == "darwin":
+		return watchPasteboard(ctx, changed)
+	case runtime.GOOS == "windows":
+		return watchClipboardListener(ctx, changed)
+	case usesLinuxUtilities() && os.Getenv("WAYLAND_DISPLAY") == "":
+		return watchXFixes(ctx, changed)
+	}
+	return fmt.Errorf("no clipboard change notifications on %s", runtime.GOOS)
+}
+
+// usesLinuxUtilities reports whether the clipboard is reached through
+// command-line utilities rather than an OS API.
+func usesLinuxUtilities() bool {
+	switch runtime.GOOS {
+	case "darwin", "windows", "plan9":
+		return false
+	}
+	return true
+}
+
+// wsl2Backend bridges to the Windows clipboard through PowerShell.
+type wsl2Backend struct{}
+
+func (wsl2Backend) Name() string { return "wsl2" }
+
+func (b wsl2Backend) Read(ctx context.Context) (string, error) {
+	return b.ReadLimited(ctx, 0)
+}
+
+func (wsl2Backend) ReadLimited(ctx context.Context, limit int) (string, error) {
+	data, err := readClipboardDataWSL2(ctx, limit)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (wsl2Backend) Write(ctx context.Context, content string) error {
+	return writeWindowsText(ctx, content)
+}
+
+func (wsl2Backend) WriteImage(ctx context.Context, data []byte, mimeType string) error {
+	return writeWindowsImage(ctx, data)
+}
+
+// termuxBackend uses the Termux:API utilities on Android.
+type termuxBackend struct{}
+
+func (termuxBackend) Name() string { return "termux" }
+
+func (b termuxBackend) Read(ctx context.Context) (string, error) {
+	return b.ReadLimited(ctx, 0)
+}
+
+func (termuxBackend) ReadLimited(ctx context.Context, limit int) (string, error) {
+	output, err := runLimited(ctx, exec.CommandContext(ctx, "termux-clipboard-get"), limit)
+	if _, ok := asOversize(err); ok {
+		return "", err
+	}
+	if ctx.Err() != nil {
+		return "", timeoutError(ctx, ctx.Err())
+	}
+	if err != nil {
+		return "", fmt.Errorf("termux-clipboard-get failed (is the Termux:API app installed?): %v", err)
+	}
+	return string(output), nil
+}
+
+func (termuxBackend) Write(ctx context.Context, content string) error {
+	cmd := exec.CommandContext(ctx, "termux-clipboard-set")
+	cmd.Stdin = strings.NewReader(content)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("termux-clipboard-set failed: %v (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func isTermux() bool {
+	if runtime.GOOS != "android" && os.Getenv("TERMUX_VERSION") == "" &&
+		!strings.Contains(os.Getenv("PREFIX"), "com.termux") {
+		return false
+	}
+	_, err := exec.LookPath("termux-clipboard-get")
+	return err == nil
+}
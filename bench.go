@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"time"
+)
+
+const (
+	DefaultBenchIterations = 20
+
+	// benchPollInterval is how often the background monitor reads the
+	// clipboard when the backend has no change notifications.
+	benchPollInterval = 500 * time.Millisecond
+)
+
+// benchResult summarizes the reads of one backend or utility. Failed reads
+// are counted but excluded from the timings.
+type benchResult struct {
+	Name       string  `json:"name"`
+	Iterations int     `json:"iterations"`
+	Failures   int     `json:"failures"`
+	Error      string  `json:"error,omitempty"` // the last failure
+	Bytes      int     `json:"bytes"`           // size of the last successful read
+	MinMs      float64 `json:"minMs"`
+	MedianMs   float64 `json:"medianMs"`
+	P95Ms      float64 `json:"p95Ms"`
+	MaxMs      float64 `json:"maxMs"`
+	MBPerSec   float64 `json:"mbPerSec"`
+}
+
+// benchReads times n calls of read, which returns the number of bytes read.
+func benchReads(ctx context.Context, name string, n int, read func(context.Context) (int, error)) benchResult {
+	result := benchResult{Name: name, Iterations: n}
+	var durations []time.Duration
+	var total time.Duration
+	totalBytes := 0
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		readCtx, cancel := withReadTimeout(ctx)
+		start := time.Now()
+		size, err := read(readCtx)
+		elapsed := time.Since(start)
+		cancel()
+		if err != nil {
+			result.Failures++
+			result.Error = err.Error()
+			continue
+		}
+		durations = append(durations, elapsed)
+		total += elapsed
+		totalBytes += size
+		result.Bytes = size
+	}
+	if len(durations) == 0 {
+		return result
+	}
+
+	slices.Sort(durations)
+	result.MinMs = milliseconds(durations[0])
+	result.MedianMs = milliseconds(durations[len(durations)/2])
+	result.P95Ms = milliseconds(durations[(len(durations)*95-1)/100])
+	result.MaxMs = milliseconds(durations[len(durations)-1])
+	if total > 0 {
+		result.MBPerSec = float64(totalBytes) / (1 << 20) / total.Seconds()
+	}
+	return result
+}
+
+// runBench benchmarks reads through the active backend and, where the
+// clipboard is reached through command-line utilities, each installed
+// utility on its own, so they can be compared.
+func runBench(ctx context.Context, n int) []benchResult {
+	results := []benchResult{benchReads(ctx, "backend "+selectBackend().Name(), n, func(ctx context.Context) (int, error) {
+		content, err := readClipboard(ctx)
+		return len(content), err
+	})}
+
+	if _, ok := selectBackend().(nativeBackend); !ok || !usesLinuxUtilities() {
+		return results
+	}
+	for _, utility := range linuxUtilities {
+		read := withWaylandSeat(utility.read)
+		if _, err := exec.LookPath(read[0]); err != nil {
+			continue
+		}
+		results = append(results, benchReads(ctx, utility.name, n, func(ctx context.Context) (int, error) {
+			output, err := runLimited(ctx, exec.CommandContext(ctx, read[0], read[1:]...), getMaxClipboardBytes())
+			return len(output), timeoutError(ctx, err)
+		}))
+	}
+	return results
+}
+
+// handleBenchCommand runs `mcp-clip bench [-n N] [--json]`.
+func handleBenchCommand(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	n := fs.Int("n", DefaultBenchIterations, "reads per backend or utility")
+	asJSON := fs.Bool("json", false, "print the results as JSON")
+	if err := fs.Parse(args); err != nil || *n < 1 {
+		fmt.Printf("Usage: %s bench [-n N] [--json]\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	if !*asJSON {
+		fmt.Printf("Benchmarking clipboard reads (%d per candidate)...\n\n", *n)
+	}
+	results := runBench(context.Background(), *n)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(results)
+		return
+	}
+	printBenchResults(os.Stdout, results)
+}
+
+func printBenchResults(w io.Writer, results []benchResult) {
+	fmt.Fprintf(w, "%-16s %10s %10s %10s %10s %12s %10s\n", "candidate", "median", "p95", "max", "bytes", "MB/s", "failures")
+	for _, result := range results {
+		if result.Failures == result.Iterations {
+			fmt.Fprintf(w, "%-16s failed: %s\n", result.Name, result.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%-16s %8.1fms %8.1fms %8.1fms %10d %12.2f %10d\n",
+			result.Name, result.MedianMs, result.P95Ms, result.MaxMs, result.Bytes, result.MBPerSec, result.Failures)
+	}
+
+	backend := results[0]
+	if backend.Failures < backend.Iterations && backend.P95Ms > milliseconds(benchPollInterval)/2 {
+		fmt.Fprintf(w, "\n⚠️  Reads take up to %.0fms, a large share of the %v polling interval; consider --no-monitor or a faster utility (MCP_CLIP_LINUX_UTILITIES)\n", backend.P95Ms, benchPollInterval)
+	}
+}
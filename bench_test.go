@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Test that the backend and each installed utility are benchmarked
+func TestRunBench(t *testing.T) {
+	fakeClipboard(t, "hello")
+	results := runBench(context.Background(), 3)
+	if len(results) != 2 || results[0].Name != "backend native" || results[1].Name != "xsel" {
+		t.Fatalf("Expected the backend and xsel to be benchmarked, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Failures != 0 || result.Bytes != 5 || result.MinMs > result.MedianMs || result.MedianMs > result.MaxMs {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	}
+}
+
+// Test that failed reads are counted and reported
+func TestBenchReadsFailures(t *testing.T) {
+	calls := 0
+	result := benchReads(context.Background(), "flaky", 4, func(context.Context) (int, error) {
+		calls++
+		if calls%2 == 0 {
+			return 0, errors.New("busy")
+		}
+		return 10, nil
+	})
+	if result.Failures != 2 || result.Error != "busy" || result.Bytes != 10 {
+		t.Errorf("Expected two failures, got %+v", result)
+	}
+
+	var out bytes.Buffer
+	printBenchResults(&out, []benchResult{{Name: "backend native", Iterations: 2, Failures: 2, Error: "no display"}})
+	if !strings.Contains(out.String(), "backend native   failed: no display") {
+		t.Errorf("Expected the failure to be printed, got %q", out.String())
+	}
+}
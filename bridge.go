@@ -0,0 +1,466 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// A clipboard bridge lets an mcp-clip that cannot reach a clipboard itself,
+// such as one in a devcontainer, use the clipboard of the machine running
+// `mcp-clip bridge`. The bridge speaks a minimal HTTP API, over a Unix socket
+// mounted into the container or over TCP. A bridge started with a token
+// requires it as a bearer token on every request, which lets it listen on
+// network addresses for headless servers using a desktop's clipboard; given
+// a certificate it serves HTTPS there, so neither crosses the network in the
+// clear:
+//
+//	GET /v1/clipboard?limit=N   the content as is (413 above N bytes)
+//	PUT /v1/clipboard           set text, or a PNG or JPEG image by Content-Type
+//	GET /v1/formats             the formats on the clipboard, as a JSON array
+//	GET /v1/flavor?type=MIME    one flavor such as text/html (204 if absent)
+const (
+	bridgeClipboardPath = "/v1/clipboard"
+	bridgeFormatsPath   = "/v1/formats"
+	bridgeFlavorPath    = "/v1/flavor"
+
+	// bridgeSizeHeader carries the size of content refused as too large.
+	bridgeSizeHeader = "X-Clipboard-Size"
+
+	// maxBridgeWriteBytes bounds writes to the bridge when MCP_CLIP_MAX_BYTES
+	// disables the cap, since the body is held in memory.
+	maxBridgeWriteBytes = 1 << 30 // 1GB
+)
+
+// bridgeHandler serves the clipboard reached through access to bridge clients.
+func bridgeHandler(access *clipboardAccess) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(bridgeClipboardPath, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			serveBridgeRead(w, r, access)
+		case http.MethodPut:
+			serveBridgeWrite(w, r, access)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc(bridgeFormatsPath, func(w http.ResponseWriter, r *http.Request) {
+		formats, err := bridgeClipboardFormats(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(bridgeFormats(formats))
+	})
+	mux.HandleFunc(bridgeFlavorPath, func(w http.ResponseWriter, r *http.Request) {
+		content, err := readBridgeFlavor(r.Context(), r.URL.Query().Get("type"))
+		switch {
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		case content == "":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			io.WriteString(w, content)
+		}
+	})
+	return mux
+}
+
+func serveBridgeRead(w http.ResponseWriter, r *http.Request, access *clipboardAccess) {
+	data, err := readWithRetry(r.Context(), access.read)
+	content := data.content
+	if oversize, ok := asOversize(err); ok {
+		w.Header().Set(bridgeSizeHeader, strconv.Itoa(oversize.size))
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if limit, _ := strconv.Atoi(r.URL.Query().Get("limit")); limit > 0 && len(content) > limit {
+		w.Header().Set(bridgeSizeHeader, strconv.Itoa(len(content)))
+		http.Error(w, (&oversizeError{size: len(content), limit: limit, exact: true}).Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	w.Header().Set("Content-Type", contentMIMEType(content))
+	io.WriteString(w, content)
+}
+
+func serveBridgeWrite(w http.ResponseWriter, r *http.Request, access *clipboardAccess) {
+	r.Body = http.MaxBytesReader(w, r.Body, int64(bridgeWriteLimit()))
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "image/png", "image/jpeg":
+		err = access.writeImage(r.Context(), data, mediaType)
+	case "", "text/plain":
+		err = access.write(r.Context(), string(data))
+	default:
+		http.Error(w, fmt.Sprintf("unsupported content type %s", mediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bridgeWriteLimit returns the largest write the bridge accepts:
+// MCP_CLIP_MAX_BYTES, or maxBridgeWriteBytes when that is disabled.
+func bridgeWriteLimit() int {
+	if limit := getMaxClipboardBytes(); limit > 0 {
+		return limit
+	}
+	return maxBridgeWriteBytes
+}
+
+// bridgeClipboardFormats lists the formats for a bridge client, whose
+// concealed check relies on them. On macOS the format names come from
+// AppleScript, which never shows the nspasteboard.org markers, so a marker
+// found among the pasteboard types is added.
+func bridgeClipboardFormats(ctx context.Context) ([]string, error) {
+	formats, err := listClipboardFormats(ctx)
+	if err != nil || runtime.GOOS != "darwin" || concealedFormat(formats) != "" {
+		return formats, err
+	}
+	if types, err := clipboardTypes(ctx); err == nil {
+		if marker := concealedFormat(types); marker != "" {
+			formats = append(formats, marker)
+		}
+	}
+	return formats, nil
+}
+
+// bridgeFormats adds text/html and text/rtf to the platform's format names
+// (HTML Format, «class HTML», public.rtf, ...) when it offers those flavors,
+// so clients can ask for them by MIME type.
+func bridgeFormats(formats []string) []string {
+	result := append([]string{}, formats...)
+	for _, flavor := range []struct {
+		markers  []string
+		mimeType string
+	}{{[]string{"html"}, "text/html"}, {[]string{"rtf", "rich text"}, "text/rtf"}} {
+		found, named := false, false
+		for _, format := range formats {
+			lower := strings.ToLower(format)
+			for _, marker := range flavor.markers {
+				found = found || strings.Contains(lower, marker)
+			}
+			named = named || lower == flavor.mimeType
+		}
+		if found && !named {
+			result = append(result, flavor.mimeType)
+		}
+	}
+	return result
+}
+
+// readBridgeFlavor reads one flavor for a bridge client: HTML and RTF the
+// way read_clipboard_flavors does on this platform, other types from a
+// backend that reads formats directly.
+func readBridgeFlavor(ctx context.Context, mimeType string) (string, error) {
+	switch strings.ToLower(mimeType) {
+	case "text/html":
+		return readClipboardHTML(ctx)
+	case "text/rtf", "application/rtf", "text/richtext":
+		return readClipboardRTF(ctx)
+	}
+	if reader, ok := selectBackend().(formatReader); ok {
+		return reader.ReadFormat(ctx, mimeType)
+	}
+	return "", fmt.Errorf("the %s backend cannot read %s", selectBackend().Name(), mimeType)
+}
+
+// handleBridgeCommand runs `mcp-clip bridge --listen ADDR` until interrupted.
+func handleBridgeCommand(args []string) {
+	fs := flag.NewFlagSet("bridge", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	listen := fs.String("listen", "", "unix:/path or host:port to serve the clipboard on")
+	token := fs.String("token", os.Getenv("MCP_CLIP_BRIDGE_TOKEN"), "bearer token clients must send")
+	certFile := fs.String("tls-cert", os.Getenv("MCP_CLIP_BRIDGE_TLS_CERT"), "PEM certificate to serve HTTPS with")
+	keyFile := fs.String("tls-key", os.Getenv("MCP_CLIP_BRIDGE_TLS_KEY"), "PEM private key of the certificate")
+	if err := fs.Parse(args); err != nil || *listen == "" || (*certFile == "") != (*keyFile == "") {
+		fmt.Printf("Usage: %s bridge --listen unix:/path/mcp-clip-bridge.sock|host:port [--token TOKEN] [--tls-cert FILE --tls-key FILE]\n", os.Args[0])
+		os.Exit(1)
+	}
+	if err := checkBridgeListen(*listen, *token); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if warning := bridgeListenWarning(*listen, *certFile != ""); warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if _, ok := selectBackend().(bridgeBackend); ok {
+		fmt.Fprintf(os.Stderr, "The bridge would serve its own bridge: unset MCP_CLIP_BRIDGE on the host\n")
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := serveBridge(ctx, *listen, *certFile, *keyFile, requireBearer(*token, bridgeHandler(clipboardAccessor))); err != nil {
+		fmt.Fprintf(os.Stderr, "Bridge error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// checkBridgeListen only allows Unix sockets and loopback addresses without
+// a token, since the bridge hands the clipboard to anyone who can connect.
+func checkBridgeListen(addr, token string) error {
+	local, err := isLocalListenAddr(addr)
+	if err != nil {
+		return fmt.Errorf("invalid bridge address %s: %v", addr, err)
+	}
+	if local || token != "" {
+		return nil
+	}
+	return fmt.Errorf("the bridge only listens on %s with a token: set --token or MCP_CLIP_BRIDGE_TOKEN", addr)
+}
+
+// bridgeListenWarning explains the risk of serving the bridge on a network
+// address without TLS, or returns "" when there is none. The token keeps
+// others from using the bridge but, like the clipboard, is sent in the clear.
+func bridgeListenWarning(addr string, tls bool) string {
+	if local, err := isLocalListenAddr(addr); err != nil || local || tls {
+		return ""
+	}
+	return fmt.Sprintf("the bridge serves plain HTTP on %s, so its token and the clipboard cross the network unencrypted; use --tls-cert and --tls-key, or listen on 127.0.0.1 and forward the port over SSH", addr)
+}
+
+// serveBridge serves handler at addr until ctx is cancelled, over HTTPS when
+// certFile and keyFile are given.
+func serveBridge(ctx context.Context, addr, certFile, keyFile string, handler http.Handler) error {
+	listener, err := listenHTTP(addr)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: handler}
+	errChan := make(chan error, 1)
+	go func() {
+		if certFile != "" {
+			errChan <- server.ServeTLS(listener, certFile, keyFile)
+			return
+		}
+		errChan <- server.Serve(listener)
+	}()
+	fmt.Fprintf(os.Stderr, "Serving the %s clipboard at %s\n", selectBackend().Name(), addr)
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// bridgeBackend uses the clipboard of another machine through the bridge at
+// MCP_CLIP_BRIDGE. It is selected whenever MCP_CLIP_BRIDGE is set.
+type bridgeBackend struct{}
+
+func (bridgeBackend) Name() string { return "bridge" }
+
+func (b bridgeBackend) Read(ctx context.Context) (string, error) {
+	return b.ReadLimited(ctx, 0)
+}
+
+func (bridgeBackend) ReadLimited(ctx context.Context, limit int) (string, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	response, err := bridgeRequest(ctx, http.MethodGet, bridgeClipboardPath, query, "", nil)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusRequestEntityTooLarge {
+		size, _ := strconv.Atoi(response.Header.Get(bridgeSizeHeader))
+		return "", &oversizeError{size: size, limit: limit, exact: size > 0}
+	}
+	if err := bridgeError(response); err != nil {
+		return "", err
+	}
+	body := io.Reader(response.Body)
+	if limit > 0 {
+		body = io.LimitReader(body, int64(limit)+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("clipboard bridge read failed: %v", err)
+	}
+	if limit > 0 && len(data) > limit {
+		return "", &oversizeError{size: len(data), limit: limit}
+	}
+	return string(data), nil
+}
+
+func (bridgeBackend) Write(ctx context.Context, content string) error {
+	return bridgeWrite(ctx, "text/plain; charset=utf-8", []byte(content))
+}
+
+func (bridgeBackend) WriteImage(ctx context.Context, data []byte, mimeType string) error {
+	return bridgeWrite(ctx, mimeType, data)
+}
+
+func (bridgeBackend) Formats(ctx context.Context) ([]string, error) {
+	response, err := bridgeRequest(ctx, http.MethodGet, bridgeFormatsPath, nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if err := bridgeError(response); err != nil {
+		return nil, err
+	}
+	var formats []string
+	if err := json.NewDecoder(response.Body).Decode(&formats); err != nil {
+		return nil, fmt.Errorf("invalid format list from the clipboard bridge: %v", err)
+	}
+	return formats, nil
+}
+
+func (bridgeBackend) ReadFormat(ctx context.Context, mimeType string) (string, error) {
+	response, err := bridgeRequest(ctx, http.MethodGet, bridgeFlavorPath, url.Values{"type": {mimeType}}, "", nil)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if err := bridgeError(response); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(response.Body)
+	return string(data), err
+}
+
+func bridgeWrite(ctx context.Context, contentType string, data []byte) error {
+	response, err := bridgeRequest(ctx, http.MethodPut, bridgeClipboardPath, nil, contentType, data)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	return bridgeError(response)
+}
+
+// bridgeRequest sends a request to the bridge named by MCP_CLIP_BRIDGE:
+// unix:/path for a mounted socket, host:port, or https://host:port for a
+// bridge serving TLS. MCP_CLIP_BRIDGE_CA names the CA certificate to trust
+// for it, such as the bridge's own self-signed certificate.
+func bridgeRequest(ctx context.Context, method, path string, query url.Values, contentType string, body []byte) (*http.Response, error) {
+	addr := os.Getenv("MCP_CLIP_BRIDGE")
+	if addr == "" {
+		return nil, fmt.Errorf("MCP_CLIP_BRIDGE is not set")
+	}
+	client, baseURL := http.DefaultClient, "http://"+strings.TrimPrefix(addr, "http://")
+	if strings.HasPrefix(addr, "https://") {
+		baseURL = strings.TrimSuffix(addr, "/")
+		if caFile := os.Getenv("MCP_CLIP_BRIDGE_CA"); caFile != "" {
+			roots, err := loadCertPool(caFile)
+			if err != nil {
+				return nil, err
+			}
+			client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+		}
+	}
+	if socketPath, ok := strings.CutPrefix(addr, unixAddrPrefix); ok {
+		client = &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+			},
+		}}
+		baseURL = "http://bridge"
+	}
+
+	target := baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	if token := os.Getenv("MCP_CLIP_BRIDGE_TOKEN"); token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, timeoutError(ctx, ctx.Err())
+		}
+		return nil, fmt.Errorf("clipboard bridge at %s not reachable: %v", addr, err)
+	}
+	return response, nil
+}
+
+// loadCertPool returns a pool of the PEM certificates in file.
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP_CLIP_BRIDGE_CA: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("MCP_CLIP_BRIDGE_CA holds no PEM certificates: %s", file)
+	}
+	return pool, nil
+}
+
+// bridgeError turns an error response into an error carrying the bridge's message.
+func bridgeError(response *http.Response) error {
+	if response.StatusCode < 300 {
+		return nil
+	}
+	if response.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("clipboard bridge rejected the request: set MCP_CLIP_BRIDGE_TOKEN to the bridge's token")
+	}
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 4<<10))
+	return fmt.Errorf("clipboard bridge: %s", strings.TrimSpace(string(message)))
+}
+
+// containerMarkerPaths are created by Docker and Podman inside containers.
+var containerMarkerPaths = []string{"/.dockerenv", "/run/.containerenv"}
+
+// isContainer reports whether mcp-clip runs in a Docker or Podman container,
+// where there is usually no clipboard to reach without a bridge.
+func isContainer() bool {
+	for _, marker := range containerMarkerPaths {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	return os.Getenv("container") != ""
+}
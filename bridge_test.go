@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startBridge serves backend over a Unix socket and points MCP_CLIP_BRIDGE at it.
+func startBridge(t *testing.T, backend clipboardBackend) {
+	startBridgeWithToken(t, backend, "")
+}
+
+func startBridgeWithToken(t *testing.T, backend clipboardBackend, token string) {
+	socketPath := filepath.Join(t.TempDir(), "bridge.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	access := newClipboardAccess(func() clipboardBackend { return backend })
+	go func() {
+		done <- serveBridge(ctx, unixAddrPrefix+socketPath, "", "", requireBearer(token, bridgeHandler(access)))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	t.Setenv("MCP_CLIP_BRIDGE", unixAddrPrefix+socketPath)
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(socketPath); err == nil {
+			return
+		}
+	}
+	t.Fatal("Bridge did not start listening")
+}
+
+// Test reading and writing text through a bridge over a Unix socket
+func TestBridgeReadWrite(t *testing.T) {
+	host := &fakeBackend{content: "from the host"}
+	startBridge(t, host)
+	if _, ok := selectBackend().(bridgeBackend); !ok {
+		t.Fatalf("Expected MCP_CLIP_BRIDGE to select the bridge backend, got %s", selectBackend().Name())
+	}
+
+	content, err := (bridgeBackend{}).Read(context.Background())
+	if err != nil || content != "from the host" {
+		t.Fatalf("Expected the host clipboard, got %q: %v", content, err)
+	}
+
+	if err := (bridgeBackend{}).Write(context.Background(), "from the container ✓"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if host.content != "from the container ✓" {
+		t.Errorf("Expected the host clipboard to be set, got %q", host.content)
+	}
+
+	_, err = (bridgeBackend{}).ReadLimited(context.Background(), 5)
+	if oversize, ok := asOversize(err); !ok || oversize.size != len("from the container ✓") {
+		t.Errorf("Expected an exact oversize error, got %v", err)
+	}
+
+	if err := (bridgeBackend{}).WriteImage(context.Background(), []byte("\x89PNG"), "image/png"); err == nil || !strings.Contains(err.Error(), "cannot write images") {
+		t.Errorf("Expected the host backend's image error, got %v", err)
+	}
+}
+
+// Test that a bridge with a token rejects clients that don't send it
+func TestBridgeToken(t *testing.T) {
+	startBridgeWithToken(t, &fakeBackend{content: "secret"}, "s3cret")
+
+	if _, err := (bridgeBackend{}).Read(context.Background()); err == nil || !strings.Contains(err.Error(), "MCP_CLIP_BRIDGE_TOKEN") {
+		t.Errorf("Expected a missing token error, got %v", err)
+	}
+
+	t.Setenv("MCP_CLIP_BRIDGE_TOKEN", "wrong")
+	if _, err := (bridgeBackend{}).Read(context.Background()); err == nil {
+		t.Error("Expected a wrong token to be rejected")
+	}
+
+	t.Setenv("MCP_CLIP_BRIDGE_TOKEN", "s3cret")
+	if content, err := (bridgeBackend{}).Read(context.Background()); err != nil || content != "secret" {
+		t.Errorf("Expected the content with the right token, got %q: %v", content, err)
+	}
+}
+
+// Test a bridge serving HTTPS with a certificate the client is told to trust
+func TestBridgeTLS(t *testing.T) {
+	// Borrow httptest's certificate for 127.0.0.1
+	server := httptest.NewTLSServer(nil)
+	cert := server.TLS.Certificates[0]
+	server.Close()
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0600)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	access := newClipboardAccess(func() clipboardBackend { return &fakeBackend{content: "over tls"} })
+	go func() {
+		done <- serveBridge(ctx, addr, certFile, keyFile, requireBearer("s3cret", bridgeHandler(access)))
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+	}
+	t.Setenv("MCP_CLIP_BRIDGE_TOKEN", "s3cret")
+
+	t.Setenv("MCP_CLIP_BRIDGE", addr)
+	if _, err := (bridgeBackend{}).Read(context.Background()); err == nil {
+		t.Error("Expected plain HTTP to a TLS bridge to fail")
+	}
+	t.Setenv("MCP_CLIP_BRIDGE", "https://"+addr)
+	if _, err := (bridgeBackend{}).Read(context.Background()); err == nil {
+		t.Error("Expected an untrusted certificate to be rejected")
+	}
+	t.Setenv("MCP_CLIP_BRIDGE_CA", certFile)
+	if content, err := (bridgeBackend{}).Read(context.Background()); err != nil || content != "over tls" {
+		t.Errorf("Expected the content over TLS, got %q: %v", content, err)
+	}
+}
+
+// Test that bridge writes are bounded even when MCP_CLIP_MAX_BYTES is disabled
+func TestBridgeWriteLimit(t *testing.T) {
+	t.Setenv("MCP_CLIP_MAX_BYTES", "1024")
+	if limit := bridgeWriteLimit(); limit != 1024 {
+		t.Errorf("Expected MCP_CLIP_MAX_BYTES, got %d", limit)
+	}
+	t.Setenv("MCP_CLIP_MAX_BYTES", "0")
+	if limit := bridgeWriteLimit(); limit != maxBridgeWriteBytes {
+		t.Errorf("Expected the fixed bound, got %d", limit)
+	}
+}
+
+// Test the warning about serving the bridge unencrypted on the network
+func TestBridgeListenWarning(t *testing.T) {
+	if warning := bridgeListenWarning(":9123", false); !strings.Contains(warning, "unencrypted") {
+		t.Errorf("Expected a warning for a network address, got %q", warning)
+	}
+	for _, warning := range []string{
+		bridgeListenWarning(":9123", true),
+		bridgeListenWarning("127.0.0.1:9123", false),
+		bridgeListenWarning("unix:/run/bridge.sock", false),
+	} {
+		if warning != "" {
+			t.Errorf("Expected no warning, got %q", warning)
+		}
+	}
+}
+
+// Test that an unreachable bridge is reported with its address
+func TestBridgeUnreachable(t *testing.T) {
+	t.Setenv("MCP_CLIP_BRIDGE", unixAddrPrefix+filepath.Join(t.TempDir(), "missing.sock"))
+	if _, err := (bridgeBackend{}).Read(context.Background()); err == nil || !strings.Contains(err.Error(), "not reachable") {
+		t.Errorf("Expected an unreachable bridge error, got %v", err)
+	}
+}
+
+// Test that rich flavors are offered under their MIME types
+func TestBridgeFormats(t *testing.T) {
+	formats := bridgeFormats([]string{"Text", "HTML Format", "Rich Text Format"})
+	if strings.Join(formats, ",") != "Text,HTML Format,Rich Text Format,text/html,text/rtf" {
+		t.Errorf("Unexpected formats: %v", formats)
+	}
+	if formats := bridgeFormats([]string{"text/html", "text/plain"}); len(formats) != 2 {
+		t.Errorf("Expected MIME types not to be repeated, got %v", formats)
+	}
+}
+
+// Test that the bridge only listens on network addresses with a token
+func TestCheckBridgeListen(t *testing.T) {
+	for addr, ok := range map[string]bool{
+		"unix:/run/bridge.sock": true,
+		"127.0.0.1:9123":        true,
+		"localhost:9123":        true,
+		"[::1]:9123":            true,
+		":9123":                 false,
+		"0.0.0.0:9123":          false,
+		"192.168.1.5:9123":      false,
+	} {
+		if err := checkBridgeListen(addr, ""); (err == nil) != ok {
+			t.Errorf("checkBridgeListen(%s) = %v", addr, err)
+		}
+		if err := checkBridgeListen(addr, "s3cret"); err != nil {
+			t.Errorf("checkBridgeListen(%s) with a token = %v", addr, err)
+		}
+	}
+	if err := checkBridgeListen("9123", "s3cret"); err == nil {
+		t.Error("Expected an address without a port to be rejected")
+	}
+}
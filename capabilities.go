@@ -0,0 +1,32 @@
+package main
+
+import "fmt"
+
+// backendCapabilities describes what the clipboard backend can do beyond
+// reading and writing text. Tools that need a missing capability are left
+// out or described as limited when they are registered, so clients don't
+// find out from failed calls.
+type backendCapabilities struct {
+	name        string
+	readImages  bool // images and other binary content can be read
+	writeImages bool // images can be placed on the clipboard
+	flavors     bool // HTML and RTF flavors can be read
+}
+
+// capabilitiesOf reports what backend can do.
+func capabilitiesOf(backend clipboardBackend) backendCapabilities {
+	_, writeImages := backend.(imageWriter)
+	caps := backendCapabilities{name: backend.Name(), readImages: true, writeImages: writeImages, flavors: true}
+	switch backend.(type) {
+	case termuxBackend:
+		caps.readImages, caps.flavors = false, false
+	case klipperBackend:
+		caps.readImages = false
+	}
+	return caps
+}
+
+// limitation describes a missing capability for tool descriptions.
+func (c backendCapabilities) limitation(what string) string {
+	return fmt.Sprintf(" (the %s clipboard backend %s)", c.name, what)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Test that a text-only backend gets no image or flavor tools and says so
+func TestRegisterToolsTextOnlyBackend(t *testing.T) {
+	t.Setenv("MCP_CLIP_BACKEND", "termux")
+	s := server.NewMCPServer("test", "1.0.0")
+	NewClipboardServer().registerTools(s)
+
+	for _, name := range []string{"read_clipboard_pair", "read_clipboard_flavors", "decode_qr"} {
+		if s.GetTool(name) != nil {
+			t.Errorf("Expected %s not to be registered for termux", name)
+		}
+	}
+	write := s.GetTool("write_clipboard")
+	if write == nil {
+		t.Fatal("Expected write_clipboard to be registered")
+	}
+	if _, ok := write.Tool.InputSchema.Properties["image"]; ok {
+		t.Error("Expected no image parameter when the backend cannot write images")
+	}
+	if !strings.Contains(write.Tool.Description, "termux clipboard backend cannot write images") {
+		t.Errorf("Expected the limitation in the description, got %q", write.Tool.Description)
+	}
+	if read := s.GetTool("read_clipboard"); !strings.Contains(read.Tool.Description, "can only read text") {
+		t.Errorf("Expected the limitation in the description, got %q", read.Tool.Description)
+	}
+}
+
+// Test that a full-featured backend gets every tool
+func TestRegisterToolsCopyQBackend(t *testing.T) {
+	t.Setenv("MCP_CLIP_BACKEND", "copyq")
+	s := server.NewMCPServer("test", "1.0.0")
+	NewClipboardServer().registerTools(s)
+
+	for _, name := range []string{"read_clipboard_pair", "read_clipboard_flavors", "decode_qr"} {
+		if s.GetTool(name) == nil {
+			t.Errorf("Expected %s to be registered for copyq", name)
+		}
+	}
+	if _, ok := s.GetTool("write_clipboard").Tool.InputSchema.Properties["image"]; !ok {
+		t.Error("Expected the image parameter when the backend can write images")
+	}
+}
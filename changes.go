@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	DefaultChangesLimit = 20
+	changesInlineRunes  = 2000
+)
+
+func (cs *ClipboardServer) getClipboardChangesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	since := request.GetInt("since_sequence", 0)
+	if since < 0 {
+		return mcp.NewToolResultError("since_sequence must not be negative"), nil
+	}
+	limit := request.GetInt("limit", DefaultChangesLimit)
+	if limit <= 0 {
+		limit = DefaultChangesLimit
+	}
+
+	latest := cs.history.latestID()
+	entries := cs.history.since(uint64(since))
+	return mcp.NewToolResultText(renderChanges(entries, uint64(since), latest, limit)), nil
+}
+
+// renderChanges lists changes newer than since, oldest first, noting any
+// sequences that were evicted from history and any entries beyond limit.
+func renderChanges(entries []historyEntry, since, latest uint64, limit int) string {
+	var b strings.Builder
+	if latest <= since {
+		fmt.Fprintf(&b, "No clipboard changes since sequence %d (latest sequence: %d)", since, latest)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "%d clipboard changes since sequence %d (latest sequence: %d)\n", latest-since, since, latest)
+	if len(entries) == 0 || entries[0].ID > since+1 {
+		firstKept := latest + 1
+		if len(entries) > 0 {
+			firstKept = entries[0].ID
+		}
+		fmt.Fprintf(&b, "Note: sequences %d-%d were evicted from history (see MCP_CLIP_HISTORY_SIZE) or copied again later\n", since+1, firstKept-1)
+	}
+
+	shown := entries
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+	for _, entry := range shown {
+		fmt.Fprintf(&b, "\n## #%d · %s · %s · %d bytes · sha256: %s", entry.ID, entry.Time.Format("2006-01-02 15:04:05"), entryLabel(entry), entry.Size, entry.Hash)
+		if entry.Seen > 1 {
+			fmt.Fprintf(&b, " · seen %d times since %s", entry.Seen, entry.FirstSeen.Format("2006-01-02 15:04:05"))
+		}
+		b.WriteString("\n")
+		if entry.redacted() {
+			b.WriteString("Content not retained (privacy mode)\n")
+			continue
+		}
+		if entry.Kind != "text" {
+			fmt.Fprintf(&b, "Content available as resource %s%d\n", historyURIPrefix, entry.ID)
+			continue
+		}
+		if text, cut := truncateRunes(entry.Content, changesInlineRunes); cut {
+			fmt.Fprintf(&b, "%s\n… truncated; full content at %s%d\n", text, historyURIPrefix, entry.ID)
+		} else {
+			fmt.Fprintf(&b, "%s\n", entry.Content)
+		}
+	}
+	if len(entries) > len(shown) {
+		fmt.Fprintf(&b, "\n%d more changes not shown; call again with since_sequence: %d\n", len(entries)-len(shown), shown[len(shown)-1].ID)
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test change listing, eviction notes and paging
+func TestRenderChanges(t *testing.T) {
+	h := newClipboardHistory(3)
+	for _, content := range []string{"one", "two", "three", "four", "five"} {
+		h.add(content, time.Now())
+	}
+
+	out := renderChanges(h.since(1), 1, h.latestID(), 2)
+	for _, want := range []string{"4 clipboard changes since sequence 1", "sequences 2-2 were evicted", "## #3", "three", "## #4", "1 more changes not shown; call again with since_sequence: 4"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "five") {
+		t.Error("Expected entries beyond limit to be omitted")
+	}
+
+	if out := renderChanges(nil, 5, 5, 10); !strings.HasPrefix(out, "No clipboard changes since sequence 5") {
+		t.Errorf("Unexpected output for no changes: %s", out)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// cleanupTempFilesHandler removes saved clipboard files on demand: those
+// past MCP_CLEANUP_TTL, or with all set every one of them, to reclaim disk
+// space or get rid of sensitive content right away. With all, files still
+// being written are left alone.
+func (cs *ClipboardServer) cleanupTempFilesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	all := request.GetBool("all", false)
+	var match func(filePath string) bool
+	if all {
+		match = func(filePath string) bool { return !strings.HasSuffix(filePath, PartialSuffix) }
+	} else {
+		cutoffTime := time.Now().Add(-getCleanupTTL())
+		match = func(filePath string) bool { return shouldRemoveFile(filePath, cutoffTime) }
+	}
+
+	cleanup, err := removeTempFiles(match)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to clean up temp files: %v", err)), nil
+	}
+
+	scope := fmt.Sprintf("older than %v", getCleanupTTL())
+	if all {
+		scope = "of any age"
+	}
+	message := fmt.Sprintf("Removed %d temp file(s) %s, freeing %d bytes", cleanup.removed, scope, cleanup.bytes)
+	if cleanup.errors > 0 {
+		message += fmt.Sprintf("; %d could not be removed", cleanup.errors)
+	}
+	result := mcp.NewToolResultText(message)
+	result.Meta = mcp.NewMetaFromMap(map[string]any{"removed": cleanup.removed, "bytes": cleanup.bytes, "errors": cleanup.errors})
+	return result, nil
+}
+
+// purgeSessionFilesHandler deletes every temp file created during this
+// session, e.g. at the end of a workflow that handled sensitive content, and
+// reports each file removed.
+func (cs *ClipboardServer) purgeSessionFilesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	removed, failed := cs.removeSessionFiles()
+	if len(removed) == 0 && len(failed) == 0 {
+		result := mcp.NewToolResultText("No temp files were created during this session")
+		result.Meta = mcp.NewMetaFromMap(map[string]any{"removed": []string{}})
+		return result, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Removed %d session file(s)", len(removed))
+	for _, filePath := range removed {
+		fmt.Fprintf(&b, "\n- %s", filePath)
+	}
+	failures := make(map[string]string, len(failed))
+	if len(failed) > 0 {
+		fmt.Fprintf(&b, "\nCould not remove %d file(s):", len(failed))
+		for _, filePath := range slices.Sorted(maps.Keys(failed)) {
+			fmt.Fprintf(&b, "\n- %s: %v", filePath, failed[filePath])
+			failures[filePath] = failed[filePath].Error()
+		}
+	}
+	result := mcp.NewToolResultText(b.String())
+	result.Meta = mcp.NewMetaFromMap(map[string]any{"removed": removed, "failed": failures})
+	return result, nil
+}
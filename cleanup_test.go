@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test that the background timer removes expired temp files
+func TestRunPeriodicCleanup(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	t.Setenv("MCP_CLEANUP_TTL", "1h")
+
+	expired := filepath.Join(dir, fmt.Sprintf("%s%d-a.txt", FilenamePrefix, time.Now().Add(-2*time.Hour).Unix()))
+	fresh := filepath.Join(dir, fmt.Sprintf("%s%d-b.txt", FilenamePrefix, time.Now().Unix()))
+	for _, path := range []string{expired, fresh} {
+		if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runPeriodicCleanup(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(expired); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the expired file to be removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("Expected the fresh file to be kept: %v", err)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Expected cleanup to stop with its context")
+	}
+}
+
+// Test the cleanup interval setting
+func TestGetCleanupInterval(t *testing.T) {
+	if got := getCleanupInterval(); got != DefaultCleanupInterval {
+		t.Errorf("Expected %v by default, got %v", DefaultCleanupInterval, got)
+	}
+	t.Setenv("MCP_CLEANUP_INTERVAL", "0")
+	if got := getCleanupInterval(); got != 0 {
+		t.Errorf("Expected 0 to disable the timer, got %v", got)
+	}
+	t.Setenv("MCP_CLEANUP_INTERVAL", "bogus")
+	if got := getCleanupInterval(); got != DefaultCleanupInterval {
+		t.Errorf("Expected the default for an invalid value, got %v", got)
+	}
+}
+
+// Test that cleanup_temp_files honors the TTL unless all is set
+func TestCleanupTempFilesHandler(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	t.Setenv("MCP_CLEANUP_TTL", "1h")
+
+	expired := filepath.Join(dir, fmt.Sprintf("%s%d-a.txt", FilenamePrefix, time.Now().Add(-2*time.Hour).Unix()))
+	fresh := filepath.Join(dir, fmt.Sprintf("%s%d-b.txt", FilenamePrefix, time.Now().Unix()))
+	partial := fresh + ".1" + PartialSuffix
+	for _, path := range []string{expired, fresh, partial} {
+		if err := os.WriteFile(path, []byte("12345"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cs := NewClipboardServer()
+
+	result, _ := cs.cleanupTempFilesHandler(context.Background(), mcp.CallToolRequest{})
+	if result.Meta.AdditionalFields["removed"] != 1 || result.Meta.AdditionalFields["bytes"] != int64(5) {
+		t.Errorf("Expected only the expired file removed, got %v", result.Meta)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("Expected the fresh file to be kept: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"all": true}
+	result, _ = cs.cleanupTempFilesHandler(context.Background(), request)
+	if result.Meta.AdditionalFields["removed"] != 1 {
+		t.Errorf("Expected the fresh file removed with all, got %v", result.Meta)
+	}
+	if _, err := os.Stat(partial); err != nil {
+		t.Errorf("Expected the file being written to be kept: %v", err)
+	}
+}
+
+// Test that purge_session_files removes and lists only this session's files
+func TestPurgeSessionFilesHandler(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	cs := NewClipboardServer()
+	atomic.StoreInt32(&cs.running, 1)
+
+	other := filepath.Join(dir, fmt.Sprintf("%s%d-other.txt", FilenamePrefix, time.Now().Unix()))
+	os.WriteFile(other, []byte("x"), 0600)
+	saved, _, err := saveToTempFile([]byte("secret"), "txt", cs, func(int) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, _ := cs.purgeSessionFilesHandler(context.Background(), mcp.CallToolRequest{})
+	if removed := result.Meta.AdditionalFields["removed"].([]string); len(removed) != 1 || removed[0] != saved {
+		t.Errorf("Expected %s to be reported removed, got %v", saved, removed)
+	}
+	if _, err := os.Stat(saved); !os.IsNotExist(err) {
+		t.Error("Expected the session file to be deleted")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("Expected files from other sessions to be kept: %v", err)
+	}
+
+	result, _ = cs.purgeSessionFilesHandler(context.Background(), mcp.CallToolRequest{})
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "No temp files") {
+		t.Errorf("Expected nothing left to purge, got %q", text)
+	}
+}
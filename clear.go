@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const MaxClearDelay = 24 * time.Hour
+
+// pendingClear is a scheduled clipboard wipe. Only one is pending at a
+// time; scheduling another replaces it.
+type pendingClear struct {
+	mu    sync.Mutex
+	timer *time.Timer
+	hash  string // only clear while the clipboard still has this sha256; "" clears anything
+	at    time.Time
+}
+
+// scheduleClear wipes the clipboard after delay, like password managers do
+// for copied secrets. With a hash, the clipboard is only wiped if it still
+// holds that content, so a copy the user made in the meantime survives.
+func (cs *ClipboardServer) scheduleClear(delay time.Duration, hash string) time.Time {
+	p := &cs.clear
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.hash, p.at = hash, time.Now().Add(delay)
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		p.mu.Lock()
+		if p.timer != timer {
+			p.mu.Unlock()
+			return // replaced or cancelled meanwhile
+		}
+		p.timer = nil
+		p.mu.Unlock()
+		cs.clearClipboard(context.Background(), hash)
+	})
+	p.timer = timer
+	return p.at
+}
+
+// cancelClear cancels a pending clear and reports whether there was one.
+func (cs *ClipboardServer) cancelClear() bool {
+	p := &cs.clear
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timer == nil {
+		return false
+	}
+	p.timer.Stop()
+	p.timer = nil
+	return true
+}
+
+// runPendingClear clears the clipboard right away if a clear is pending, so
+// secrets don't outlive the server when it shuts down first.
+func (cs *ClipboardServer) runPendingClear() {
+	p := &cs.clear
+	p.mu.Lock()
+	hash := p.hash
+	pending := p.timer != nil
+	if pending {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	p.mu.Unlock()
+	if pending {
+		cs.clearClipboard(context.Background(), hash)
+	}
+}
+
+// clearClipboard empties the clipboard, if it still holds content with the
+// given hash (any content when hash is "").
+func (cs *ClipboardServer) clearClipboard(ctx context.Context, hash string) {
+	if hash != "" {
+		current, err := readClipboard(ctx)
+		if err != nil || current == "" || contentHash(current) != hash {
+			if os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "Scheduled clipboard clear skipped: content changed or unreadable (%v)\n", err)
+			}
+			return
+		}
+	}
+	if err := writeClipboard(ctx, ""); err != nil {
+		fmt.Fprintf(os.Stderr, "Scheduled clipboard clear failed: %v\n", err)
+		return
+	}
+	if os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Cleared the clipboard as scheduled\n")
+	}
+}
+
+// clearDelay reads a delay in seconds from the named argument; 0 means none.
+func clearDelay(request mcp.CallToolRequest, name string) (time.Duration, error) {
+	seconds := request.GetFloat(name, 0)
+	if seconds < 0 {
+		return 0, fmt.Errorf("%s must not be negative", name)
+	}
+	delay := time.Duration(seconds * float64(time.Second))
+	if delay > MaxClearDelay {
+		return 0, fmt.Errorf("%s must be at most %d", name, int(MaxClearDelay.Seconds()))
+	}
+	return delay, nil
+}
+
+// withScheduledClear schedules clearing the content a write just placed on
+// the clipboard, if the write asked for it with clear_after_seconds.
+func (cs *ClipboardServer) withScheduledClear(result *mcp.CallToolResult, delay time.Duration, content string) *mcp.CallToolResult {
+	if delay == 0 {
+		return result
+	}
+	at := cs.scheduleClear(delay, contentHash(content))
+	if text, ok := result.Content[0].(mcp.TextContent); ok {
+		text.Text += fmt.Sprintf("; it will be cleared in %v unless it changes first", delay)
+		result.Content[0] = text
+	}
+	resultMeta(result)["clearAt"] = at.UTC().Format(time.RFC3339)
+	return result
+}
+
+// scheduleClipboardClearHandler schedules or cancels a clipboard wipe.
+func (cs *ClipboardServer) scheduleClipboardClearHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	delay, err := clearDelay(request, "seconds")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if delay == 0 {
+		if cs.cancelClear() {
+			return mcp.NewToolResultText("Cancelled the pending clipboard clear"), nil
+		}
+		return mcp.NewToolResultText("No clipboard clear was pending"), nil
+	}
+
+	hash := ""
+	if request.GetBool("only_if_unchanged", true) {
+		current, err := readClipboard(ctx)
+		if err != nil {
+			return backendErrorResult(ctx, err), nil
+		}
+		if current == "" {
+			return mcp.NewToolResultText("Clipboard is already empty; nothing to clear"), nil
+		}
+		hash = contentHash(current)
+	}
+	at := cs.scheduleClear(delay, hash)
+
+	message := fmt.Sprintf("Clipboard will be cleared in %v", delay)
+	if hash != "" {
+		message += " unless its content changes first"
+	}
+	result := mcp.NewToolResultText(message)
+	meta := resultMeta(result)
+	meta["clearAt"] = at.UTC().Format(time.RFC3339)
+	if hash != "" {
+		meta["sha256"] = hash
+	}
+	return result, nil
+}
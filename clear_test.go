@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fakeClipboard puts an xsel that keeps the clipboard in a file on PATH.
+func fakeClipboard(t *testing.T, content string) string {
+	if !usesLinuxUtilities() || runtime.GOOS == "android" {
+		t.Skip("xsel is only used on Linux")
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "clipboard")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ncase \"$*\" in\n*--output*) cat \"" + file + "\" ;;\n*) cat > \"" + file + "\" ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(dir, "xsel"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("MCP_CLIP_BACKEND", "native")
+	t.Setenv("MCP_CLIP_LINUX_UTILITIES", "xsel")
+	return file
+}
+
+// Test that a scheduled clear wipes the clipboard only while it is unchanged
+func TestScheduleClear(t *testing.T) {
+	file := fakeClipboard(t, "secret")
+	cs := NewClipboardServer()
+
+	cs.clearClipboard(context.Background(), contentHash("other"))
+	if data, _ := os.ReadFile(file); string(data) != "secret" {
+		t.Errorf("Expected changed content to be kept, got %q", data)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"seconds": 0.05}
+	result, err := cs.scheduleClipboardClearHandler(context.Background(), request)
+	if err != nil || result.IsError || result.Meta.AdditionalFields["sha256"] != contentHash("secret") {
+		t.Fatalf("Expected the clear to be scheduled, got %v (%v)", result, err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, _ := os.ReadFile(file); len(data) == 0 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Error("Expected the clipboard to be cleared")
+}
+
+// Test cancelling a pending clear and flushing one at shutdown
+func TestCancelAndRunPendingClear(t *testing.T) {
+	file := fakeClipboard(t, "secret")
+	cs := NewClipboardServer()
+
+	cs.scheduleClear(time.Hour, "")
+	if !cs.cancelClear() || cs.cancelClear() {
+		t.Error("Expected exactly one pending clear to be cancelled")
+	}
+
+	cs.scheduleClear(time.Hour, contentHash("secret"))
+	cs.runPendingClear()
+	if data, _ := os.ReadFile(file); len(data) != 0 {
+		t.Errorf("Expected the pending clear to run at shutdown, got %q", data)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clientServerName is the key mcp-clip is configured under, as in the README.
+const clientServerName = "clipboard"
+
+// mcpClients maps the clients install --client supports to their config file.
+// All of them keep servers in an "mcpServers" object.
+var mcpClients = map[string]func() (string, error){
+	"claude": func() (string, error) {
+		// ~/Library/Application Support on macOS, %AppData% on Windows, ~/.config on Linux
+		dir, err := os.UserConfigDir()
+		return filepath.Join(dir, "Claude", "claude_desktop_config.json"), err
+	},
+	"cursor": func() (string, error) {
+		home, err := os.UserHomeDir()
+		return filepath.Join(home, ".cursor", "mcp.json"), err
+	},
+	"windsurf": func() (string, error) {
+		home, err := os.UserHomeDir()
+		return filepath.Join(home, ".codeium", "windsurf", "mcp_config.json"), err
+	},
+}
+
+func mcpClientNames() []string {
+	var names []string
+	for name := range mcpClients {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// installClientConfig adds or updates the clipboard server in the client's
+// config file, backing up an existing file first.
+func installClientConfig(opts installOptions, out io.Writer) error {
+	exe, err := installedExecutable()
+	if err != nil {
+		return err
+	}
+	configPath, err := mcpClients[opts.client]()
+	if err != nil {
+		return fmt.Errorf("failed to locate the %s config: %v", opts.client, err)
+	}
+
+	original, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %v", configPath, err)
+	}
+	updated, err := patchClientConfig(original, exe, opts.serverArgs)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %v", configPath, err)
+	}
+
+	if original != nil {
+		backupPath := configPath + "." + time.Now().Format("20060102-150405") + ".bak"
+		if err := os.WriteFile(backupPath, original, 0600); err != nil {
+			return fmt.Errorf("failed to back up %s: %v", configPath, err)
+		}
+		fmt.Fprintf(out, "Backed up %s to %s\n", configPath, backupPath)
+	} else if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(configPath), err)
+	}
+
+	// Replace the config in one step so the client never reads half a file
+	perm := os.FileMode(0600)
+	if info, err := os.Stat(configPath); err == nil {
+		perm = info.Mode().Perm()
+	}
+	partial := configPath + PartialSuffix
+	if err := os.WriteFile(partial, updated, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %v", partial, err)
+	}
+	if err := os.Rename(partial, configPath); err != nil {
+		os.Remove(partial)
+		return fmt.Errorf("failed to replace %s: %v", configPath, err)
+	}
+	fmt.Fprintf(out, "Configured %q in %s; restart %s to use it\n", clientServerName, configPath, opts.client)
+	return nil
+}
+
+// patchClientConfig sets the clipboard server entry of a client config to
+// run exe with args. Other settings, other servers and other fields of an
+// existing entry (such as env) are kept as they were written, in their order:
+// only the objects leading to the entry are re-encoded.
+func patchClientConfig(config []byte, exe string, args []string) ([]byte, error) {
+	var settings []jsonMember
+	if len(bytes.TrimSpace(config)) > 0 {
+		var err error
+		if settings, err = decodeJSONObject(config); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+	}
+	serversJSON, found := lookupJSONMember(settings, "mcpServers")
+	if !found || string(serversJSON) == "null" {
+		serversJSON = json.RawMessage("{}")
+	}
+	servers, err := decodeJSONObject(serversJSON)
+	if err != nil {
+		return nil, fmt.Errorf("mcpServers is not an object")
+	}
+	var entry []jsonMember
+	if entryJSON, found := lookupJSONMember(servers, clientServerName); found && string(entryJSON) != "null" {
+		if entry, err = decodeJSONObject(entryJSON); err != nil {
+			return nil, fmt.Errorf("mcpServers.%s is not an object", clientServerName)
+		}
+	}
+	if args == nil {
+		args = []string{}
+	}
+	command, _ := json.Marshal(exe)
+	argsJSON, _ := json.Marshal(args)
+	entry = setJSONMember(entry, "command", command)
+	entry = setJSONMember(entry, "args", argsJSON)
+
+	servers = setJSONMember(servers, clientServerName, encodeJSONObject(entry, 2))
+	settings = setJSONMember(settings, "mcpServers", encodeJSONObject(servers, 1))
+	return append(encodeJSONObject(settings, 0), '\n'), nil
+}
+
+// jsonMember is a member of a JSON object, its value left undecoded so that
+// it can be written back byte for byte, numbers and key order included.
+type jsonMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// decodeJSONObject splits a JSON object into its members, in order.
+func decodeJSONObject(data []byte) ([]jsonMember, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+	var members []jsonMember
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		members = append(members, jsonMember{key: token.(string), value: value})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON object")
+	}
+	return members, nil
+}
+
+// lookupJSONMember returns the value of key, the last one if it is repeated,
+// as encoding/json would decode it.
+func lookupJSONMember(members []jsonMember, key string) (json.RawMessage, bool) {
+	for i := len(members) - 1; i >= 0; i-- {
+		if members[i].key == key {
+			return members[i].value, true
+		}
+	}
+	return nil, false
+}
+
+// setJSONMember replaces the value of key in place, or appends it.
+func setJSONMember(members []jsonMember, key string, value json.RawMessage) []jsonMember {
+	for i := len(members) - 1; i >= 0; i-- {
+		if members[i].key == key {
+			members[i].value = value
+			return members
+		}
+	}
+	return append(members, jsonMember{key: key, value: value})
+}
+
+// encodeJSONObject writes members as an object indented by two spaces per
+// level, for an object nested depth levels deep. Values are copied as is.
+func encodeJSONObject(members []jsonMember, depth int) json.RawMessage {
+	if len(members) == 0 {
+		return json.RawMessage("{}")
+	}
+	indent := strings.Repeat("  ", depth)
+	var b bytes.Buffer
+	b.WriteString("{")
+	for i, member := range members {
+		key, _ := json.Marshal(member.key)
+		b.WriteString("\n" + indent + "  ")
+		b.Write(key)
+		b.WriteString(": ")
+		b.Write(member.value)
+		if i < len(members)-1 {
+			b.WriteString(",")
+		}
+	}
+	b.WriteString("\n" + indent + "}")
+	return b.Bytes()
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test that the clipboard entry is added or updated without losing other settings
+func TestPatchClientConfig(t *testing.T) {
+	original := `{
+  "theme": "dark",
+  "mcpServers": {
+    "other": {"command": "other-server"},
+    "clipboard": {"command": "old-mcp-clip", "args": ["--privacy"], "env": {"MCP_DEBUG": "1"}}
+  }
+}`
+	patched, err := patchClientConfig([]byte(original), "/usr/local/bin/mcp-clip", []string{"--read-only"})
+	if err != nil {
+		t.Fatalf("Failed to patch config: %v", err)
+	}
+	var config struct {
+		Theme      string `json:"theme"`
+		MCPServers map[string]struct {
+			Command string            `json:"command"`
+			Args    []string          `json:"args"`
+			Env     map[string]string `json:"env"`
+		} `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(patched, &config); err != nil {
+		t.Fatalf("Invalid patched config: %v", err)
+	}
+	entry := config.MCPServers["clipboard"]
+	if entry.Command != "/usr/local/bin/mcp-clip" || len(entry.Args) != 1 || entry.Args[0] != "--read-only" || entry.Env["MCP_DEBUG"] != "1" {
+		t.Errorf("Unexpected clipboard entry: %+v", entry)
+	}
+	if config.Theme != "dark" || config.MCPServers["other"].Command != "other-server" {
+		t.Errorf("Expected other settings to be kept, got %s", patched)
+	}
+
+	patched, err = patchClientConfig(nil, "mcp-clip", nil)
+	if err != nil || !strings.Contains(string(patched), `"args": []`) {
+		t.Errorf("Expected a new config with empty args, got %s: %v", patched, err)
+	}
+	if _, err := patchClientConfig([]byte(`{"mcpServers": []}`), "mcp-clip", nil); err == nil {
+		t.Error("Expected an error for a malformed mcpServers")
+	}
+	if _, err := patchClientConfig([]byte(`{"mcpServers": {}} {}`), "mcp-clip", nil); err == nil {
+		t.Error("Expected an error for trailing data")
+	}
+}
+
+// Test that settings and servers other than the clipboard entry come back
+// byte for byte, in their original order
+func TestPatchClientConfigKeepsOthers(t *testing.T) {
+	original := `{
+  "zoom": 1.2500,
+  "maxTokens": 12345678901234567890,
+  "mcpServers": {
+    "search": {"command": "search-server",   "args": ["--port", "8080"]},
+    "clipboard": {
+      "env": {"MCP_DEBUG": "1"},
+      "command": "old-mcp-clip",
+      "timeout": 30.0
+    },
+    "files": {
+      "command": "files-server"
+    }
+  },
+  "analytics": false
+}`
+	patched, err := patchClientConfig([]byte(original), "/usr/local/bin/mcp-clip", []string{"--privacy"})
+	if err != nil {
+		t.Fatalf("Failed to patch config: %v", err)
+	}
+	want := `{
+  "zoom": 1.2500,
+  "maxTokens": 12345678901234567890,
+  "mcpServers": {
+    "search": {"command": "search-server",   "args": ["--port", "8080"]},
+    "clipboard": {
+      "env": {"MCP_DEBUG": "1"},
+      "command": "/usr/local/bin/mcp-clip",
+      "timeout": 30.0,
+      "args": ["--privacy"]
+    },
+    "files": {
+      "command": "files-server"
+    }
+  },
+  "analytics": false
+}
+`
+	if string(patched) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, patched)
+	}
+}
+
+// Test that installing into an existing config backs it up first
+func TestInstallClientConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, ".cursor", "mcp.json")
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(`{"mcpServers": {}}`), 0644)
+
+	var out bytes.Buffer
+	if err := installClientConfig(installOptions{client: "cursor", serverArgs: []string{"--no-monitor"}}, &out); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	config, _ := os.ReadFile(configPath)
+	if !strings.Contains(string(config), `"--no-monitor"`) {
+		t.Errorf("Expected the entry to be written, got %s", config)
+	}
+	backups, _ := filepath.Glob(configPath + ".*.bak")
+	if len(backups) != 1 {
+		t.Fatalf("Expected one backup, got %v", backups)
+	}
+	if backup, _ := os.ReadFile(backups[0]); string(backup) != `{"mcpServers": {}}` {
+		t.Errorf("Expected the backup to hold the original, got %s", backup)
+	}
+	if info, _ := os.Stat(configPath); info.Mode().Perm() != 0644 {
+		t.Errorf("Expected the config permissions to be kept, got %v", info.Mode())
+	}
+}
+
+// Test --client option validation
+func TestParseInstallClientOptions(t *testing.T) {
+	opts, err := parseInstallOptions([]string{"--client", "claude", "--", "--read-only", "--privacy"})
+	if err != nil || opts.client != "claude" || len(opts.serverArgs) != 2 {
+		t.Errorf("Unexpected options %+v: %v", opts, err)
+	}
+	for _, args := range [][]string{
+		{"--client", "vim"},
+		{"--client", "claude", "--", "--bogus"},
+		{"--client", "claude", "--http", "127.0.0.1:1"},
+	} {
+		if _, err := parseInstallOptions(args); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
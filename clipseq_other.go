@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "fmt"
+
+// clipboardSequence is only available on Windows.
+func clipboardSequence() (uint32, error) {
+	return 0, fmt.Errorf("the clipboard sequence number is only available on Windows")
+}
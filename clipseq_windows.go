@@ -0,0 +1,18 @@
+//go:build windows
+
+package main
+
+import "fmt"
+
+var procGetClipboardSequenceNumber = user32.NewProc("GetClipboardSequenceNumber")
+
+// clipboardSequence returns the clipboard sequence number, which Windows
+// increments on every change, so polling can tell whether the content changed
+// without reading it.
+func clipboardSequence() (uint32, error) {
+	seq, _, _ := procGetClipboardSequenceNumber.Call()
+	if seq == 0 {
+		return 0, fmt.Errorf("GetClipboardSequenceNumber is unavailable without access to the window station")
+	}
+	return uint32(seq), nil
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "fmt"
+
+// clipboardTextSize is only available on Windows.
+func clipboardTextSize() (int, error) {
+	return 0, fmt.Errorf("clipboard text size is only available on Windows")
+}
@@ -0,0 +1,37 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32             = windows.NewLazySystemDLL("kernel32.dll")
+	procGlobalSize       = kernel32.NewProc("GlobalSize")
+	procOpenClipboard    = user32.NewProc("OpenClipboard")
+	procCloseClipboard   = user32.NewProc("CloseClipboard")
+	procGetClipboardData = user32.NewProc("GetClipboardData")
+)
+
+const cfUnicodeText = 13
+
+// clipboardTextSize returns the size in bytes of the clipboard's
+// CF_UNICODETEXT data, including its terminating NUL, without reading it.
+func clipboardTextSize() (int, error) {
+	if ok, _, err := procOpenClipboard.Call(0); ok == 0 {
+		return 0, fmt.Errorf("OpenClipboard failed: %v", err)
+	}
+	defer procCloseClipboard.Call()
+	handle, _, err := procGetClipboardData.Call(cfUnicodeText)
+	if handle == 0 {
+		return 0, fmt.Errorf("GetClipboardData failed: %v", err)
+	}
+	size, _, err := procGlobalSize.Call(handle)
+	if size == 0 {
+		return 0, fmt.Errorf("GlobalSize failed: %v", err)
+	}
+	return int(size), nil
+}
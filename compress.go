@@ -0,0 +1,64 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// getCompressThreshold returns the size from which spilled text is saved
+// gzip-compressed, or 0 when MCP_CLIP_COMPRESS_SPILLS_ABOVE is unset.
+func getCompressThreshold() int {
+	return getSizeEnv("MCP_CLIP_COMPRESS_SPILLS_ABOVE", 0)
+}
+
+// compressSpill reports whether a spill of size bytes saved with extension
+// should be gzipped. Images and the recognized binary formats are mostly
+// compressed already, so only text and base64 spills are.
+func compressSpill(extension string, size int) bool {
+	threshold := getCompressThreshold()
+	if threshold == 0 || size < threshold {
+		return false
+	}
+	if _, binary := binaryMIMETypes[extension]; binary {
+		return false
+	}
+	return !strings.HasPrefix(extensionMIMEType(extension), "image/")
+}
+
+// isCompressedSpill reports whether a saved file was gzipped by the server,
+// which names it with a second ".gz" extension, as in
+// mcp-clip-<time>-<sha256>.txt.gz. Clipboard content that already was gzip
+// data is saved with the single extension ".gz" and left alone.
+func isCompressedSpill(filePath string) bool {
+	name := strings.TrimSuffix(filepath.Base(filePath), ".gz")
+	return name != filepath.Base(filePath) && filepath.Ext(name) != ""
+}
+
+// openSpill opens a saved file for reading, decompressing it if the server
+// compressed it.
+func openSpill(filePath string) (io.ReadCloser, error) {
+	file, err := os.Open(filePath)
+	if err != nil || !isCompressedSpill(filePath) {
+		return file, err
+	}
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return gzipFile{reader, file}, nil
+}
+
+// gzipFile closes both the decompressor and the file beneath it.
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g gzipFile) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
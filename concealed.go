@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const StatusConcealed = "concealed"
+
+// concealedFormats are the clipboard formats password managers add to mark
+// content that clipboard managers must not record: the nspasteboard.org
+// types on macOS, the Windows clipboard history opt-out, and KDE's hint,
+// which KeePassXC sets on Linux.
+var concealedFormats = []string{
+	"org.nspasteboard.ConcealedType",
+	"org.nspasteboard.TransientType",
+	"ExcludeClipboardContentFromMonitorProcessing",
+	"x-kde-passwordManagerHint",
+}
+
+// jxaPasteboardTypesScript prints the pasteboard types as UTIs. AppleScript's
+// "clipboard info" only reports the types it knows by name, so the
+// nspasteboard.org markers never show up in it.
+const jxaPasteboardTypesScript = `ObjC.import('AppKit'); ObjC.deepUnwrap($.NSPasteboard.generalPasteboard.types).join('\n')`
+
+// concealCheck caches the outcome of the last concealed-format check, so
+// the monitor lists formats once per change rather than on every poll.
+type concealCheck struct {
+	hash string
+	hint string
+}
+
+// concealedFormat returns the first concealed marker among formats, or "".
+func concealedFormat(formats []string) string {
+	for _, format := range formats {
+		for _, marker := range concealedFormats {
+			if strings.EqualFold(format, marker) {
+				return marker
+			}
+		}
+	}
+	return ""
+}
+
+// clipboardTypes lists the clipboard formats for the concealed check. On
+// macOS the pasteboard types are read through JXA, see
+// jxaPasteboardTypesScript.
+func clipboardTypes(ctx context.Context) ([]string, error) {
+	if _, ok := selectBackend().(formatReader); ok || runtime.GOOS != "darwin" {
+		return listClipboardFormats(ctx)
+	}
+	output, err := exec.CommandContext(ctx, "osascript", "-l", "JavaScript", "-e", jxaPasteboardTypesScript).Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSpace(string(output)), "\n"), nil
+}
+
+// concealedHint reports the marker a password manager put next to content,
+// or "" when it is an ordinary copy. Formats that can't be listed count as
+// not concealed, since most clipboards then don't carry the markers either.
+func (cs *ClipboardServer) concealedHint(ctx context.Context, content string) string {
+	if content == "" || os.Getenv("MCP_CLIP_IGNORE_CONCEALED") == "1" {
+		return ""
+	}
+	hash := contentHash(content)
+	if last, ok := cs.lastConceal.Load().(concealCheck); ok && last.hash == hash {
+		return last.hint
+	}
+
+	ctx, cancel := withReadTimeout(ctx)
+	defer cancel()
+	formats, err := clipboardTypes(ctx)
+	if err != nil {
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Failed to check for concealed clipboard content: %v\n", err)
+		}
+		return ""
+	}
+	hint := concealedFormat(formats)
+	cs.lastConceal.Store(concealCheck{hash: hash, hint: hint})
+	if hint != "" && os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Clipboard content marked %s; not recorded\n", hint)
+	}
+	return hint
+}
+
+// concealedError refuses an operation that would copy content a password
+// manager marked as concealed without its marker, such as writing it back.
+type concealedError struct {
+	hint string
+}
+
+func (e *concealedError) Error() string {
+	return fmt.Sprintf("the clipboard holds content a password manager marked as concealed (%s), which would be written back without its marker", e.hint)
+}
+
+// concealedResult withholds content a password manager marked as concealed.
+func concealedResult(hint string) *mcp.CallToolResult {
+	message := fmt.Sprintf("The clipboard holds content a password manager marked as concealed (%s); it is not returned or recorded. Pass allow_concealed: true only if the user explicitly asked for it.", hint)
+	result := mcp.NewToolResultError(message)
+	meta := resultMeta(result)
+	meta["status"] = StatusConcealed
+	meta["format"] = hint
+	return result
+}
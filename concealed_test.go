@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test recognizing the markers password managers put on the clipboard
+func TestConcealedFormat(t *testing.T) {
+	if hint := concealedFormat([]string{"public.utf8-plain-text", "org.nspasteboard.ConcealedType"}); hint != "org.nspasteboard.ConcealedType" {
+		t.Errorf("Expected the nspasteboard marker, got %q", hint)
+	}
+	if hint := concealedFormat([]string{"TARGETS", "UTF8_STRING", "x-kde-passwordManagerHint"}); hint != "x-kde-passwordManagerHint" {
+		t.Errorf("Expected the KDE marker, got %q", hint)
+	}
+	if hint := concealedFormat([]string{"text/plain", "text/html"}); hint != "" {
+		t.Errorf("Expected no marker, got %q", hint)
+	}
+}
+
+// fakeConcealedCopyQ installs a fake copyq whose clipboard holds a password
+// marked with KDE's password manager hint, alongside an HTML flavor.
+func fakeConcealedCopyQ(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts not available")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$1 $2" in
+"clipboard ?") printf 'text/plain\ntext/html\nx-kde-passwordManagerHint\n' ;;
+"clipboard text/plain") printf 'hunter2' ;;
+"clipboard text/html") printf '<b>hunter2</b>' ;;
+*) echo "unexpected: $*" >&2; exit 1 ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "copyq"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("MCP_CLIP_BACKEND", "copyq")
+}
+
+// Test that concealed content is withheld unless explicitly allowed, and never recorded
+func TestReadClipboardConcealed(t *testing.T) {
+	fakeConcealedCopyQ(t)
+
+	cs := NewClipboardServer()
+	cs.onDemand = true
+	request := mcp.CallToolRequest{}
+	result, err := cs.readClipboardHandler(context.Background(), request)
+	if err != nil || !result.IsError || result.Meta.AdditionalFields["status"] != StatusConcealed {
+		t.Fatalf("Expected concealed content to be withheld, got %v (%v)", result, err)
+	}
+
+	request.Params.Arguments = map[string]any{"format": "markdown"}
+	result, err = cs.readClipboardHandler(context.Background(), request)
+	if err != nil || !result.IsError || result.Meta.AdditionalFields["status"] != StatusConcealed {
+		t.Errorf("Expected concealed content to be withheld as Markdown, got %v (%v)", result, err)
+	}
+
+	request.Params.Arguments = map[string]any{"allow_concealed": true}
+	result, err = cs.readClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "hunter2") {
+		t.Errorf("Expected allow_concealed to return the content, got %v (%v)", result, err)
+	}
+	if len(cs.history.recent(0)) != 0 {
+		t.Error("Expected concealed content not to be recorded in history")
+	}
+}
+
+// Test that the other tools reading the live clipboard withhold concealed content
+func TestConcealedOtherReaders(t *testing.T) {
+	fakeConcealedCopyQ(t)
+	cs := NewClipboardServer()
+	entry := cs.history.add("old text", time.Now())
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"from_sequence": float64(entry.ID)}
+	result, err := cs.diffClipboardHandler(context.Background(), request)
+	if err != nil || !result.IsError || result.Meta.AdditionalFields["status"] != StatusConcealed {
+		t.Errorf("Expected diff_clipboard to withhold concealed content, got %v (%v)", result, err)
+	}
+
+	request.Params.Arguments = map[string]any{"name": "secret"}
+	result, err = cs.saveSnippetHandler(context.Background(), request)
+	if err != nil || !result.IsError || result.Meta.AdditionalFields["status"] != StatusConcealed {
+		t.Errorf("Expected save_snippet to withhold concealed content, got %v (%v)", result, err)
+	}
+
+	result, err = cs.decodeQRHandler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || !result.IsError || result.Meta.AdditionalFields["status"] != StatusConcealed {
+		t.Errorf("Expected decode_qr to withhold concealed content, got %v (%v)", result, err)
+	}
+
+	handler := cs.promptHandler(func(map[string]string) string { return "Explain this" })
+	prompt, err := handler(context.Background(), mcp.GetPromptRequest{})
+	if err == nil || !strings.Contains(err.Error(), "concealed") {
+		t.Errorf("Expected the prompt to withhold concealed content, got %v (%v)", prompt, err)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Write outcomes when MCP_CLIP_CONFIRM_OVERWRITE asks the user first.
+const (
+	StatusOverwriteDeclined       = "overwrite_declined"
+	StatusConfirmationUnavailable = "confirmation_unavailable"
+)
+
+const confirmOverwriteTimeout = 2 * time.Minute
+
+// elicitor sends MCP elicitation requests; *server.MCPServer implements it.
+type elicitor interface {
+	RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error)
+}
+
+// confirmOverwriteEnabled reports whether tools must ask the user, via MCP
+// elicitation, before replacing what is on the clipboard. The setting is
+// server-side so an agent cannot opt out of it.
+func confirmOverwriteEnabled() bool {
+	return os.Getenv("MCP_CLIP_CONFIRM_OVERWRITE") == "1"
+}
+
+// confirmOverwrite asks the user before a tool replaces non-empty clipboard
+// content with incoming. It returns nil when the write may go ahead, or the
+// result to return instead. When the user cannot be asked, the write is
+// refused, since silently clobbering the copy buffer is what the setting
+// exists to prevent.
+func confirmOverwrite(ctx context.Context, action string, incoming string) *mcp.CallToolResult {
+	if !confirmOverwriteEnabled() {
+		return nil
+	}
+	current, err := readClipboard(ctx)
+	if err == nil && (current == "" || current == incoming) {
+		return nil
+	}
+
+	if session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo); ok && session.GetClientCapabilities().Elicitation == nil {
+		return confirmationUnavailableResult(fmt.Errorf("the client does not support elicitation"))
+	}
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return confirmationUnavailableResult(fmt.Errorf("no MCP session to ask the user through"))
+	}
+	return askOverwrite(ctx, srv, action, current)
+}
+
+// askOverwrite sends the confirmation request and interprets the answer.
+func askOverwrite(ctx context.Context, e elicitor, action string, current string) *mcp.CallToolResult {
+	message := fmt.Sprintf("An agent wants to %s, replacing what you copied", action)
+	if current != "" {
+		kind, _ := classifyContent(current)
+		message += fmt.Sprintf(" (%s, %d bytes)", kind, len(current))
+	}
+	message += ". Allow it?"
+
+	ctx, cancel := context.WithTimeout(ctx, confirmOverwriteTimeout)
+	defer cancel()
+	answer, err := e.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: message,
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"overwrite": map[string]any{
+						"type":        "boolean",
+						"title":       "Replace clipboard content",
+						"description": "The current clipboard content will be lost",
+					},
+				},
+				"required": []string{"overwrite"},
+			},
+		},
+	})
+	if err != nil {
+		return confirmationUnavailableResult(err)
+	}
+
+	if answer.Action == mcp.ElicitationResponseActionAccept {
+		if content, ok := answer.Content.(map[string]any); ok && content["overwrite"] == true {
+			return nil
+		}
+	}
+	if os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Clipboard overwrite not confirmed (%s)\n", answer.Action)
+	}
+	result := mcp.NewToolResultText("The user did not allow replacing the clipboard content; clipboard not written")
+	return withStatus(result, StatusOverwriteDeclined, "Don't retry the write unless the user asks for it.")
+}
+
+func confirmationUnavailableResult(err error) *mcp.CallToolResult {
+	hint := "Use a client that supports MCP elicitation, or unset MCP_CLIP_CONFIRM_OVERWRITE to write without asking."
+	result := mcp.NewToolResultError(fmt.Sprintf("Clipboard not written: could not ask the user to confirm the overwrite (%v). %s", err, hint))
+	return withStatus(result, StatusConfirmationUnavailable, hint)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type fakeElicitor struct {
+	response mcp.ElicitationResponse
+	err      error
+	request  mcp.ElicitationRequest
+}
+
+func (f *fakeElicitor) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	f.request = request
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &mcp.ElicitationResult{ElicitationResponse: f.response}, nil
+}
+
+// Test that only an accepted overwrite=true answer lets the write go ahead
+func TestAskOverwrite(t *testing.T) {
+	accept := &fakeElicitor{response: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionAccept, Content: map[string]any{"overwrite": true}}}
+	if result := askOverwrite(context.Background(), accept, "write 3 bytes of text to the clipboard", "hello"); result != nil {
+		t.Errorf("Expected the write to be allowed, got %v", result)
+	}
+	if accept.request.Params.Message != "An agent wants to write 3 bytes of text to the clipboard, replacing what you copied (text, 5 bytes). Allow it?" {
+		t.Errorf("Unexpected message: %q", accept.request.Params.Message)
+	}
+
+	for _, e := range []*fakeElicitor{
+		{response: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionAccept, Content: map[string]any{"overwrite": false}}},
+		{response: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline}},
+		{response: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionCancel}},
+	} {
+		result := askOverwrite(context.Background(), e, "write", "hello")
+		if result == nil || result.IsError || result.Meta.AdditionalFields["status"] != StatusOverwriteDeclined {
+			t.Errorf("Expected %s to decline the write, got %v", e.response.Action, result)
+		}
+	}
+
+	result := askOverwrite(context.Background(), &fakeElicitor{err: errors.New("method not found")}, "write", "hello")
+	if result == nil || !result.IsError || result.Meta.AdditionalFields["status"] != StatusConfirmationUnavailable {
+		t.Errorf("Expected the write to be refused when the client can't be asked, got %v", result)
+	}
+}
+
+// Test that write_clipboard refuses to overwrite without a session to ask through
+func TestWriteClipboardConfirmOverwrite(t *testing.T) {
+	fakeCopyQ(t)
+	t.Setenv("MCP_CLIP_BACKEND", "copyq")
+	cs := NewClipboardServer()
+
+	write := func(content string) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"content": content}
+		result, err := cs.writeClipboardHandler(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if result := write("new"); result.IsError {
+		t.Errorf("Expected the write to go ahead without MCP_CLIP_CONFIRM_OVERWRITE, got %v", result)
+	}
+
+	t.Setenv("MCP_CLIP_CONFIRM_OVERWRITE", "1")
+	if result := write("new"); !result.IsError || result.Meta.AdditionalFields["status"] != StatusConfirmationUnavailable {
+		t.Errorf("Expected the overwrite to be refused, got %v", result)
+	}
+	if result := write("hello"); result.IsError {
+		t.Errorf("Expected identical content not to need confirmation, got %v", result)
+	}
+}
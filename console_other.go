@@ -0,0 +1,6 @@
+//go:build !windows
+
+package main
+
+// detachConsole is only needed on Windows, where console programs get a window.
+func detachConsole() {}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package main
+
+var procFreeConsole = kernel32.NewProc("FreeConsole")
+
+// detachConsole closes the console window a background server was started
+// with, such as the one Task Scheduler opens for the service task.
+func detachConsole() {
+	procFreeConsole.Call()
+}
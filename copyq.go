@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// copyqBackend uses the CopyQ clipboard manager's command-line interface.
+// Unlike plain text scraping it sees every format on the clipboard, so rich
+// content (HTML, images) can be read, and it exposes CopyQ's own history.
+type copyqBackend struct{}
+
+func (copyqBackend) Name() string { return "copyq" }
+
+func (b copyqBackend) Read(ctx context.Context) (string, error) {
+	return b.ReadLimited(ctx, 0)
+}
+
+// ReadLimited reads the clipboard's preferred format: text if offered, else
+// an image, else the first other format.
+func (b copyqBackend) ReadLimited(ctx context.Context, limit int) (string, error) {
+	formats, err := b.Formats(ctx)
+	if err != nil {
+		return "", err
+	}
+	mimeType := preferredMIMEType(formats)
+	if mimeType == "" {
+		return "", nil
+	}
+	output, err := copyqRun(ctx, limit, "clipboard", mimeType)
+	return string(output), err
+}
+
+func (copyqBackend) Write(ctx context.Context, content string) error {
+	cmd := exec.CommandContext(ctx, "copyq", "copy", "-")
+	cmd.Stdin = strings.NewReader(content)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return copyqError(ctx, err, output)
+	}
+	return nil
+}
+
+func (copyqBackend) WriteImage(ctx context.Context, data []byte, mimeType string) error {
+	cmd := exec.CommandContext(ctx, "copyq", "copy", mimeType, "-")
+	cmd.Stdin = bytes.NewReader(data)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return copyqError(ctx, err, output)
+	}
+	return nil
+}
+
+// Formats lists the clipboard's formats, leaving out CopyQ's internal ones.
+func (copyqBackend) Formats(ctx context.Context) ([]string, error) {
+	output, err := copyqRun(ctx, 0, "clipboard", "?")
+	if err != nil {
+		return nil, err
+	}
+	return parseCopyQFormats(string(output)), nil
+}
+
+func (copyqBackend) ReadFormat(ctx context.Context, mimeType string) (string, error) {
+	output, err := copyqRun(ctx, 0, "clipboard", mimeType)
+	return string(output), err
+}
+
+// History returns the text of up to n items of CopyQ's clipboard tab, newest
+// first. Items without text, such as images, are skipped.
+func (copyqBackend) History(ctx context.Context, n int) ([]string, error) {
+	output, err := copyqRun(ctx, 0, "count")
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return nil, fmt.Errorf("unexpected copyq count output %q", strings.TrimSpace(string(output)))
+	}
+
+	var items []string
+	for row := 0; row < min(n, count); row++ {
+		formats, err := copyqRun(ctx, 0, "read", "?", strconv.Itoa(row))
+		if err != nil {
+			return items, err
+		}
+		mimeType := preferredMIMEType(parseCopyQFormats(string(formats)))
+		isText := func(text string) bool { return strings.EqualFold(text, mimeType) }
+		if !slices.ContainsFunc(textMIMETypes, isText) {
+			continue
+		}
+		item, err := copyqRun(ctx, 0, "read", mimeType, strconv.Itoa(row))
+		if err != nil {
+			return items, err
+		}
+		items = append(items, string(item))
+	}
+	return items, nil
+}
+
+// parseCopyQFormats splits "?" output into formats, dropping CopyQ's own
+// bookkeeping formats such as application/x-copyq-owner-window-title.
+func parseCopyQFormats(output string) []string {
+	var formats []string
+	for _, line := range strings.Split(output, "\n") {
+		format := strings.TrimSpace(line)
+		if format != "" && !strings.HasPrefix(format, "application/x-copyq-") {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// copyqRun runs a copyq command, stopping its output at limit bytes (if positive).
+func copyqRun(ctx context.Context, limit int, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath("copyq"); err != nil {
+		return nil, fmt.Errorf("copyq not found - required for the CopyQ backend")
+	}
+	output, err := runLimited(ctx, exec.CommandContext(ctx, "copyq", args...), limit)
+	if _, ok := asOversize(err); ok {
+		return nil, err
+	}
+	if err != nil {
+		return nil, copyqError(ctx, err, nil)
+	}
+	return output, nil
+}
+
+func copyqError(ctx context.Context, err error, output []byte) error {
+	if ctx.Err() != nil {
+		return timeoutError(ctx, ctx.Err())
+	}
+	if msg := strings.TrimSpace(string(output)); msg != "" {
+		err = fmt.Errorf("%v: %s", err, msg)
+	}
+	return fmt.Errorf("copyq failed (is the CopyQ server running?): %v", err)
+}
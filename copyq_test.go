@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeCopyQ installs a copyq script with HTML on the clipboard and a history
+// holding text and an image.
+func fakeCopyQ(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts not available")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$1 $2 $3" in
+"clipboard ? ") printf 'application/x-copyq-owner-window-title\ntext/html\ntext/plain\n' ;;
+"clipboard text/plain ") printf 'hello' ;;
+"clipboard text/html ") printf '<b>hello</b>' ;;
+"count  ") echo 3 ;;
+"read ? 0") printf 'text/plain\n' ;;
+"read text/plain 0") printf 'hello' ;;
+"read ? 1") printf 'image/png\n' ;;
+"read ? 2") printf 'text/plain\n' ;;
+"read text/plain 2") printf 'older' ;;
+"copy - ") while read -r line; do :; done ;;
+*) echo "unexpected: $*" >&2; exit 1 ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "copyq"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+// Test reading, format listing and history through the copyq command
+func TestCopyQBackend(t *testing.T) {
+	fakeCopyQ(t)
+	backend := copyqBackend{}
+	ctx := context.Background()
+
+	if content, err := backend.Read(ctx); err != nil || content != "hello" {
+		t.Errorf("Expected 'hello', got %q (%v)", content, err)
+	}
+	formats, err := backend.Formats(ctx)
+	if err != nil || len(formats) != 2 || formats[0] != "text/html" {
+		t.Errorf("Expected text/html and text/plain without CopyQ's own formats, got %q (%v)", formats, err)
+	}
+	if html, err := readFormatHTML(ctx, backend); err != nil || html != "<b>hello</b>" {
+		t.Errorf("Expected the HTML flavor, got %q (%v)", html, err)
+	}
+	items, err := backend.History(ctx, 10)
+	if err != nil || len(items) != 2 || items[1] != "older" {
+		t.Errorf("Expected 2 text history items with the image skipped, got %q (%v)", items, err)
+	}
+	if err := backend.Write(ctx, "x"); err != nil {
+		t.Errorf("Expected write to succeed, got %v", err)
+	}
+}
+
+// Test that a missing copyq command is reported
+func TestCopyQBackendMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if _, err := (copyqBackend{}).Read(context.Background()); err == nil {
+		t.Error("Expected an error without copyq installed")
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// dataDir returns the directory for persistent server data such as saved
+// snippets: MCP_CLIP_DATA_DIR if set, otherwise the platform's per-user data
+// location. It returns "" when no home directory can be determined.
+func dataDir() string {
+	if dir := os.Getenv("MCP_CLIP_DATA_DIR"); dir != "" {
+		return dir
+	}
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+			return filepath.Join(xdg, "mcp-clip")
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, ".local", "share", "mcp-clip")
+		}
+		return ""
+	}
+	// %AppData% on Windows, ~/Library/Application Support on macOS
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "mcp-clip")
+	}
+	return ""
+}
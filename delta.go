@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// contentHash returns the hex-encoded SHA-256 of content. It is the hash
+// clients echo back as since_hash when requesting delta reads.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// computeDelta reports whether content extends a previously read prefix of
+// sinceLength bytes hashing to sinceHash, and if so returns the appended tail.
+func computeDelta(content string, sinceLength int, sinceHash string) (string, bool) {
+	if sinceLength < 0 || sinceLength > len(content) {
+		return "", false
+	}
+	if contentHash(content[:sinceLength]) != sinceHash {
+		return "", false
+	}
+	return content[sinceLength:], true
+}
+
+// handleDeltaRead serves a read_clipboard call carrying since_length/since_hash.
+// Only the bytes appended since the previous read are returned when the
+// clipboard still starts with the previously read text; otherwise the full
+// content is returned along with a note so the client can resynchronize.
+func handleDeltaRead(ctx context.Context, content string, sinceLength int, sinceHash string, cs *ClipboardServer) (*mcp.CallToolResult, error) {
+	if !isProbablyText(content) {
+		return mcp.NewToolResultError("Delta reads are only supported for text clipboard content"), nil
+	}
+	if sinceLength > 0 && sinceHash == "" {
+		return mcp.NewToolResultError("since_hash is required when since_length is greater than 0"), nil
+	}
+	if sinceHash == "" {
+		sinceHash = contentHash("")
+	}
+
+	maxDirectOutput := getInlineThresholds().text
+	hash := contentHash(content)
+
+	delta, ok := computeDelta(content, sinceLength, sinceHash)
+	if !ok {
+		if len(content) > maxDirectOutput {
+			spill, err := cs.spillToFile(ctx, textSource(content), "txt")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to save large text content to temp file: %v", err)), nil
+			}
+			return withSpill(mcp.NewToolResultText(fmt.Sprintf("Clipboard content does not extend the previous read; full text too large (%d bytes, sha256: %s). Saved to: %s", len(content), hash, spill)), spill), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Clipboard content does not extend the previous read; full text (%d bytes, sha256: %s):\n%s", len(content), hash, content)), nil
+	}
+
+	if delta == "" {
+		return mcp.NewToolResultText(fmt.Sprintf("Clipboard unchanged since previous read (%d bytes, sha256: %s)", len(content), hash)), nil
+	}
+
+	if len(delta) > maxDirectOutput {
+		spill, err := cs.spillToFile(ctx, textSource(delta), "txt")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to save large delta to temp file: %v", err)), nil
+		}
+		return withSpill(mcp.NewToolResultText(fmt.Sprintf("Clipboard text delta too large (%d bytes appended after offset %d; total %d bytes, sha256: %s). Saved to: %s", len(delta), sinceLength, len(content), hash, spill)), spill), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Clipboard text delta (%d bytes appended after offset %d; total %d bytes, sha256: %s):\n%s", len(delta), sinceLength, len(content), hash, delta)), nil
+}
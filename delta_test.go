@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+// Test delta computation for appended, unchanged and replaced content
+func TestComputeDelta(t *testing.T) {
+	previous := "first line\n"
+	hash := contentHash(previous)
+
+	delta, ok := computeDelta(previous+"second line\n", len(previous), hash)
+	if !ok || delta != "second line\n" {
+		t.Errorf("Expected appended delta, got %q (ok=%v)", delta, ok)
+	}
+
+	delta, ok = computeDelta(previous, len(previous), hash)
+	if !ok || delta != "" {
+		t.Errorf("Expected empty delta for unchanged content, got %q (ok=%v)", delta, ok)
+	}
+
+	if _, ok := computeDelta("other text entirely", len(previous), hash); ok {
+		t.Error("Expected mismatch when content no longer starts with previous text")
+	}
+
+	if _, ok := computeDelta("short", 100, hash); ok {
+		t.Error("Expected mismatch when since_length exceeds content length")
+	}
+
+	delta, ok = computeDelta("anything", 0, contentHash(""))
+	if !ok || delta != "anything" {
+		t.Errorf("Expected full content for zero offset, got %q (ok=%v)", delta, ok)
+	}
+}
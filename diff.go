@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	diffContextLines = 3
+	diffMaxLines     = 10000
+	diffMaxEdits     = 1000
+)
+
+type diffOpKind byte
+
+const (
+	diffEqual  diffOpKind = ' '
+	diffDelete diffOpKind = '-'
+	diffInsert diffOpKind = '+'
+)
+
+type diffOp struct {
+	kind diffOpKind
+	line string
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes a shortest line edit script using Myers' algorithm. It
+// gives up (returning false) once more than maxEdits edits would be needed,
+// bounding memory for inputs that are essentially unrelated.
+func diffLines(a, b []string, maxEdits int) ([]diffOp, bool) {
+	n, m := len(a), len(b)
+	limit := n + m
+	if limit > maxEdits {
+		limit = maxEdits
+	}
+	off := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int // trace[d] holds v[off-d-1 : off+d+2] before step d
+
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		trace = append(trace, append([]int(nil), v[off-d-1:off+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return nil, false
+	}
+
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		snap := trace[d]
+		at := func(k int) int { return snap[k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{diffEqual, a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{diffInsert, b[y-1]})
+				y--
+			} else {
+				ops = append(ops, diffOp{diffDelete, a[x-1]})
+				x--
+			}
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops, true
+}
+
+// unifiedDiff renders ops as a unified diff with the given context size.
+// It returns an empty string when there are no changes.
+func unifiedDiff(fromName, toName string, ops []diffOp, context int) string {
+	var changes []int
+	for i, op := range ops {
+		if op.kind != diffEqual {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+
+	// Line numbers (0-based) reached before each op index
+	aLine := make([]int, len(ops)+1)
+	bLine := make([]int, len(ops)+1)
+	for i, op := range ops {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if op.kind != diffInsert {
+			aLine[i+1]++
+		}
+		if op.kind != diffDelete {
+			bLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(changes); {
+		start := max(changes[i]-context, 0)
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context {
+			j++
+		}
+		end := min(changes[j]+context+1, len(ops))
+
+		aCount, bCount := aLine[end]-aLine[start], bLine[end]-bLine[start]
+		aStart, bStart := aLine[start], bLine[start]
+		if aCount > 0 {
+			aStart++
+		}
+		if bCount > 0 {
+			bStart++
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, op := range ops[start:end] {
+			fmt.Fprintf(&b, "%c%s\n", op.kind, op.line)
+		}
+		i = j + 1
+	}
+	return b.String()
+}
+
+func (cs *ClipboardServer) diffClipboardHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	fromID := request.GetInt("from_sequence", 0)
+	if fromID <= 0 {
+		return mcp.NewToolResultError("from_sequence is required and must be a history sequence number"), nil
+	}
+	from, ok := cs.history.get(uint64(fromID))
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("History entry %d not found (it may have been evicted)", fromID)), nil
+	}
+	if from.redacted() {
+		return mcp.NewToolResultError(fmt.Sprintf("History entry %d has no content: privacy mode keeps only hashes", fromID)), nil
+	}
+	fromName := fmt.Sprintf("%s%d", historyURIPrefix, from.ID)
+
+	var toContent, toName string
+	if toID := request.GetInt("to_sequence", 0); toID > 0 {
+		to, ok := cs.history.get(uint64(toID))
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("History entry %d not found (it may have been evicted)", toID)), nil
+		}
+		if to.redacted() {
+			return mcp.NewToolResultError(fmt.Sprintf("History entry %d has no content: privacy mode keeps only hashes", toID)), nil
+		}
+		toContent, toName = to.Content, fmt.Sprintf("%s%d", historyURIPrefix, to.ID)
+	} else {
+		content, err := readClipboard(ctx)
+		if err != nil {
+			return backendErrorResult(ctx, err), nil
+		}
+		if hint := cs.concealedHint(ctx, content); hint != "" {
+			return concealedResult(hint), nil
+		}
+		if denied := cs.policyResult(content); denied != nil {
+			return denied, nil
+		}
+		toContent, toName = content, "clipboard (current)"
+	}
+
+	if from.Kind != "text" || !isProbablyText(toContent) {
+		return mcp.NewToolResultError("Only text content can be diffed"), nil
+	}
+
+	a, b := splitLines(from.Content), splitLines(toContent)
+	if len(a) > diffMaxLines || len(b) > diffMaxLines {
+		return mcp.NewToolResultError(fmt.Sprintf("Content too large to diff (limit %d lines per side)", diffMaxLines)), nil
+	}
+	ops, ok := diffLines(a, b, diffMaxEdits)
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("Entries differ by more than %d lines; a diff would not be readable", diffMaxEdits)), nil
+	}
+
+	diff := unifiedDiff(fromName, toName, ops, diffContextLines)
+	if diff == "" {
+		return mcp.NewToolResultText(fmt.Sprintf("No differences between %s and %s", fromName, toName)), nil
+	}
+	return mcp.NewToolResultText("```diff\n" + diff + "```"), nil
+}
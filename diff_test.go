@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test unified diff output for a small edit
+func TestUnifiedDiff(t *testing.T) {
+	a := splitLines("one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n")
+	b := splitLines("one\ntwo\nthree\nFOUR\nfive\nsix\nseven\neight\nnine\n")
+
+	ops, ok := diffLines(a, b, diffMaxEdits)
+	if !ok {
+		t.Fatal("Expected diff to succeed")
+	}
+	got := unifiedDiff("a", "b", ops, 3)
+	want := `--- a
++++ b
+@@ -1,8 +1,9 @@
+ one
+ two
+ three
+-four
++FOUR
+ five
+ six
+ seven
+ eight
++nine
+`
+	if got != want {
+		t.Errorf("Unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// Test separate hunks, identical input and edit limits
+func TestDiffHunksAndLimits(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 30; i++ {
+		line := strings.Repeat("x", i+1)
+		a = append(a, line)
+		b = append(b, line)
+	}
+	b[2], b[25] = "changed", "also changed"
+
+	ops, _ := diffLines(a, b, diffMaxEdits)
+	if got := strings.Count(unifiedDiff("a", "b", ops, 3), "@@ -"); got != 2 {
+		t.Errorf("Expected 2 hunks, got %d", got)
+	}
+
+	ops, _ = diffLines(a, a, diffMaxEdits)
+	if unifiedDiff("a", "a", ops, 3) != "" {
+		t.Error("Expected empty diff for identical input")
+	}
+
+	if _, ok := diffLines(a, []string{"unrelated"}, 5); ok {
+		t.Error("Expected diff to give up beyond the edit limit")
+	}
+
+	ops, _ = diffLines(nil, []string{"new"}, diffMaxEdits)
+	if got := unifiedDiff("a", "b", ops, 3); !strings.Contains(got, "@@ -0,0 +1,1 @@\n+new") {
+		t.Errorf("Unexpected diff against empty input:\n%s", got)
+	}
+}
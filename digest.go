@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	DefaultDigestPeriod  = 7 * 24 * time.Hour
+	digestTopDomains     = 10
+	digestNotableSnippet = 5
+	digestSamplingTokens = 800
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+// codeHints are substrings that commonly appear in copied source code.
+var codeHints = []string{
+	"func ", "def ", "class ", "import ", "return ", "const ", "let ", "var ",
+	"#include", "SELECT ", "=>", "();", "};", "fn ", "package ", "#!/",
+}
+
+// clipboardDigest summarizes clipboard activity recorded in history for a period.
+type clipboardDigest struct {
+	Since    time.Time
+	Until    time.Time
+	Total    int
+	Bytes    int
+	ByClass  map[string]int
+	Domains  []domainCount
+	Snippets []historyEntry
+	ByDay    map[string]int
+}
+
+type domainCount struct {
+	Domain string
+	Count  int
+}
+
+// parseDigestPeriod accepts Go durations plus a "d" suffix for days (e.g. "7d").
+func parseDigestPeriod(period string) (time.Duration, error) {
+	if period == "" {
+		return DefaultDigestPeriod, nil
+	}
+	if days, ok := strings.CutSuffix(period, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid period %q", period)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q", period)
+	}
+	return d, nil
+}
+
+func buildDigest(entries []historyEntry, since, until time.Time) clipboardDigest {
+	digest := clipboardDigest{
+		Since:   since,
+		Until:   until,
+		ByClass: make(map[string]int),
+		ByDay:   make(map[string]int),
+	}
+	domains := make(map[string]int)
+
+	for _, entry := range entries {
+		if entry.Time.Before(since) || entry.Time.After(until) {
+			continue
+		}
+		digest.Total++
+		digest.Bytes += entry.Size
+		digest.ByDay[entry.Time.Format("2006-01-02 (Mon)")]++
+
+		class := digestClass(entry)
+		digest.ByClass[class]++
+
+		if entry.Kind == "text" {
+			for _, raw := range urlPattern.FindAllString(entry.Content, -1) {
+				if u, err := url.Parse(raw); err == nil && u.Host != "" {
+					domains[strings.ToLower(u.Hostname())]++
+				}
+			}
+			if class == "code" && len(digest.Snippets) < digestNotableSnippet {
+				digest.Snippets = append(digest.Snippets, entry)
+			}
+		}
+	}
+
+	for domain, count := range domains {
+		digest.Domains = append(digest.Domains, domainCount{Domain: domain, Count: count})
+	}
+	sort.Slice(digest.Domains, func(i, j int) bool {
+		if digest.Domains[i].Count != digest.Domains[j].Count {
+			return digest.Domains[i].Count > digest.Domains[j].Count
+		}
+		return digest.Domains[i].Domain < digest.Domains[j].Domain
+	})
+	if len(digest.Domains) > digestTopDomains {
+		digest.Domains = digest.Domains[:digestTopDomains]
+	}
+	return digest
+}
+
+// digestClass refines the history kind of text entries into url, code or text.
+func digestClass(entry historyEntry) string {
+	if entry.Kind != "text" {
+		return entry.Kind
+	}
+	trimmed := strings.TrimSpace(entry.Content)
+	if urlPattern.MatchString(trimmed) && !strings.ContainsAny(trimmed, " \n\t") {
+		return "url"
+	}
+	if looksLikeCode(trimmed) {
+		return "code"
+	}
+	return "text"
+}
+
+func looksLikeCode(content string) bool {
+	hits := 0
+	for _, hint := range codeHints {
+		if strings.Contains(content, hint) {
+			hits++
+		}
+	}
+	if hits >= 2 {
+		return true
+	}
+	lines := strings.Split(content, "\n")
+	if len(lines) < 3 {
+		return false
+	}
+	structured := 0
+	for _, line := range lines {
+		if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ") ||
+			strings.HasSuffix(line, "{") || strings.HasSuffix(line, ";") || strings.HasSuffix(line, ":") {
+			structured++
+		}
+	}
+	return hits >= 1 && structured*2 >= len(lines)
+}
+
+func renderDigest(d clipboardDigest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Clipboard Digest\n\n%s → %s\n\n", d.Since.Format("2006-01-02 15:04"), d.Until.Format("2006-01-02 15:04"))
+	if d.Total == 0 {
+		b.WriteString("_No clipboard activity recorded in this period._\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "**%d** clipboard changes, %d bytes total.\n\n## By class\n\n", d.Total, d.Bytes)
+	for _, class := range sortedKeys(d.ByClass) {
+		fmt.Fprintf(&b, "- %s: %d\n", class, d.ByClass[class])
+	}
+
+	b.WriteString("\n## By day\n\n")
+	for _, day := range sortedKeys(d.ByDay) {
+		fmt.Fprintf(&b, "- %s: %d\n", day, d.ByDay[day])
+	}
+
+	if len(d.Domains) > 0 {
+		b.WriteString("\n## Top domains\n\n")
+		for _, dc := range d.Domains {
+			fmt.Fprintf(&b, "- %s: %d\n", dc.Domain, dc.Count)
+		}
+	}
+
+	if len(d.Snippets) > 0 {
+		b.WriteString("\n## Notable code snippets\n\n")
+		for _, entry := range d.Snippets {
+			fmt.Fprintf(&b, "- #%d at %s (%d bytes): `%s`\n", entry.ID, entry.Time.Format("2006-01-02 15:04"), entry.Size,
+				strings.ReplaceAll(previewText(entry.Content, timelinePreviewSz), "`", "'"))
+		}
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (cs *ClipboardServer) clipboardDigestHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	period, err := parseDigestPeriod(request.GetString("period", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%v. Use a duration like '24h' or a day count like '7d'", err)), nil
+	}
+
+	now := time.Now()
+	report := renderDigest(buildDigest(cs.history.recent(0), now.Add(-period), now))
+
+	if request.GetBool("summarize", false) {
+		summary, err := summarizeDigest(ctx, report)
+		if err != nil {
+			report += fmt.Sprintf("\n_Summary unavailable: %v_\n", err)
+		} else {
+			report += "\n## Summary\n\n" + summary + "\n"
+		}
+	}
+	return mcp.NewToolResultText(report), nil
+}
+
+// summarizeDigest asks the client's model, via MCP sampling, for a short
+// natural-language summary of the digest.
+func summarizeDigest(ctx context.Context, report string) (string, error) {
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return "", fmt.Errorf("no MCP server in context")
+	}
+
+	samplingCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	result, err := srv.RequestSampling(samplingCtx, mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			Messages: []mcp.SamplingMessage{{
+				Role:    mcp.RoleUser,
+				Content: mcp.NewTextContent("Summarize this clipboard activity digest in a few sentences for a personal review or timesheet. Mention the main topics and sites worked on.\n\n" + report),
+			}},
+			SystemPrompt: "You summarize clipboard activity reports concisely and factually.",
+			MaxTokens:    digestSamplingTokens,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("client sampling failed: %v", err)
+	}
+
+	switch content := result.Content.(type) {
+	case mcp.TextContent:
+		return content.Text, nil
+	case map[string]any:
+		if text, ok := content["text"].(string); ok {
+			return text, nil
+		}
+	}
+	return "", fmt.Errorf("client returned non-text summary")
+}
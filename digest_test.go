@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test digest counts, domains and snippets for a period
+func TestBuildDigest(t *testing.T) {
+	now := time.Now()
+	h := newClipboardHistory(10)
+	h.add("https://github.com/standardbeagle/mcp-clip", now.Add(-2*time.Hour))
+	h.add("see https://github.com/x and https://go.dev/doc", now.Add(-time.Hour))
+	h.add("func main() {\n\tfmt.Println(\"hi\")\n\treturn\n}", now.Add(-30*time.Minute))
+	h.add("too old", now.Add(-10*24*time.Hour))
+
+	d := buildDigest(h.recent(0), now.Add(-DefaultDigestPeriod), now)
+	if d.Total != 3 {
+		t.Errorf("Expected 3 entries in period, got %d", d.Total)
+	}
+	if d.ByClass["url"] != 1 || d.ByClass["code"] != 1 || d.ByClass["text"] != 1 {
+		t.Errorf("Unexpected class counts: %v", d.ByClass)
+	}
+	if len(d.Domains) == 0 || d.Domains[0].Domain != "github.com" || d.Domains[0].Count != 2 {
+		t.Errorf("Expected github.com as top domain with 2 hits, got %v", d.Domains)
+	}
+	if len(d.Snippets) != 1 {
+		t.Errorf("Expected 1 notable snippet, got %d", len(d.Snippets))
+	}
+
+	out := renderDigest(d)
+	if !strings.Contains(out, "## Top domains") || !strings.Contains(out, "go.dev: 1") {
+		t.Errorf("Unexpected digest output:\n%s", out)
+	}
+}
+
+// Test period parsing with day suffix
+func TestParseDigestPeriod(t *testing.T) {
+	if d, err := parseDigestPeriod("7d"); err != nil || d != 7*24*time.Hour {
+		t.Errorf("Expected 7 days, got %v (%v)", d, err)
+	}
+	if d, err := parseDigestPeriod("90m"); err != nil || d != 90*time.Minute {
+		t.Errorf("Expected 90m, got %v (%v)", d, err)
+	}
+	if _, err := parseDigestPeriod("soon"); err == nil {
+		t.Error("Expected error for invalid period")
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+)
+
+// applyDisplayConfig points the server, and every clipboard utility it
+// spawns, at the display named by MCP_CLIP_DISPLAY, MCP_CLIP_WAYLAND_DISPLAY
+// and MCP_CLIP_XAUTHORITY. MCP clients often launch servers with a stripped
+// environment that lacks DISPLAY and WAYLAND_DISPLAY, which leaves xclip and
+// wl-paste unable to reach the desktop session.
+func applyDisplayConfig() {
+	if runtime.GOOS != "linux" {
+		return
+	}
+	for _, override := range []struct{ config, env string }{
+		{"MCP_CLIP_DISPLAY", "DISPLAY"},
+		{"MCP_CLIP_WAYLAND_DISPLAY", "WAYLAND_DISPLAY"},
+		{"MCP_CLIP_XAUTHORITY", "XAUTHORITY"},
+	} {
+		if value := os.Getenv(override.config); value != "" {
+			os.Setenv(override.env, value)
+			if os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "Using %s=%s from %s\n", override.env, value, override.config)
+			}
+		}
+	}
+
+	// A relative Wayland socket name is resolved against XDG_RUNTIME_DIR,
+	// which a stripped environment lacks as well
+	if wayland := os.Getenv("WAYLAND_DISPLAY"); wayland != "" && !filepath.IsAbs(wayland) && os.Getenv("XDG_RUNTIME_DIR") == "" {
+		runtimeDir := fmt.Sprintf("/run/user/%d", os.Getuid())
+		if _, err := os.Stat(filepath.Join(runtimeDir, wayland)); err == nil {
+			os.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+		}
+	}
+}
+
+// withWaylandSeat adds the seat named by MCP_CLIP_WAYLAND_SEAT to a wl-paste
+// or wl-copy command line, for compositors with several seats.
+func withWaylandSeat(args []string) []string {
+	seat := os.Getenv("MCP_CLIP_WAYLAND_SEAT")
+	if seat == "" || len(args) == 0 || (args[0] != "wl-paste" && args[0] != "wl-copy") {
+		return args
+	}
+	return slices.Concat(args[:1], []string{"--seat", seat}, args[1:])
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// Test that MCP_CLIP_DISPLAY and MCP_CLIP_WAYLAND_DISPLAY override the session variables
+func TestApplyDisplayConfig(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("display overrides only apply on Linux")
+	}
+	t.Setenv("DISPLAY", ":0")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("XDG_RUNTIME_DIR", "/tmp/runtime")
+	t.Setenv("MCP_CLIP_DISPLAY", ":1")
+	t.Setenv("MCP_CLIP_WAYLAND_DISPLAY", "wayland-1")
+
+	applyDisplayConfig()
+	if os.Getenv("DISPLAY") != ":1" || os.Getenv("WAYLAND_DISPLAY") != "wayland-1" {
+		t.Errorf("Expected DISPLAY=:1 and WAYLAND_DISPLAY=wayland-1, got %q and %q", os.Getenv("DISPLAY"), os.Getenv("WAYLAND_DISPLAY"))
+	}
+	if os.Getenv("XDG_RUNTIME_DIR") != "/tmp/runtime" {
+		t.Errorf("Expected XDG_RUNTIME_DIR to be kept, got %q", os.Getenv("XDG_RUNTIME_DIR"))
+	}
+}
+
+// Test that MCP_CLIP_WAYLAND_SEAT is only passed to wl-clipboard
+func TestWithWaylandSeat(t *testing.T) {
+	args := []string{"wl-paste", "--no-newline"}
+	if got := withWaylandSeat(args); !slices.Equal(got, args) {
+		t.Errorf("Expected no seat by default, got %v", got)
+	}
+
+	t.Setenv("MCP_CLIP_WAYLAND_SEAT", "seat1")
+	if got := withWaylandSeat(args); !slices.Equal(got, []string{"wl-paste", "--seat", "seat1", "--no-newline"}) {
+		t.Errorf("Expected the seat after wl-paste, got %v", got)
+	}
+	if !slices.Equal(args, []string{"wl-paste", "--no-newline"}) {
+		t.Errorf("Expected the original arguments to be unchanged, got %v", args)
+	}
+	if got := withWaylandSeat([]string{"xclip", "-o"}); len(got) != 2 {
+		t.Errorf("Expected xclip to be left alone, got %v", got)
+	}
+}
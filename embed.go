@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// spillURIPrefix names saved clipboard files as MCP resources, so clients
+// that cannot see the server's temp directory can still fetch them.
+const spillURIPrefix = "clipboard://files/"
+
+// spillResource saves binary content that is too large to inline, like
+// spillToFile, and also returns it as MCP content: an embedded blob resource
+// when its base64 fits MCP_CLIP_MAX_EMBEDDED, otherwise a link to the
+// clipboard://files/ resource serving it. With the "b64" extension the file
+// holds the content base64-encoded.
+func (cs *ClipboardServer) spillResource(ctx context.Context, data []byte, ext, mimeType string) (spillInfo, mcp.Content, error) {
+	src := bytesSource(data)
+	if ext == "b64" {
+		src = base64Source(src)
+	}
+	spill, filePath, err := cs.saveSpill(ctx, src, ext)
+	if err != nil {
+		return spillInfo{}, nil, err
+	}
+
+	uri := spillURIPrefix + filepath.Base(filePath)
+	if base64.StdEncoding.EncodedLen(len(data)) > getInlineThresholds().embedded {
+		description := fmt.Sprintf("Clipboard content (%s, %d bytes)", mimeType, len(data))
+		return spill, mcp.NewResourceLink(uri, filepath.Base(filePath), description, mimeType), nil
+	}
+	blob := encodeBase64(ctx, data)
+	return spill, mcp.NewEmbeddedResource(mcp.BlobResourceContents{URI: uri, MIMEType: mimeType, Blob: blob}), nil
+}
+
+// spilledFileResourceHandler serves files saved by spillResource. Only
+// complete mcp-clip files directly inside the temp directory can be read.
+// Files the server gzipped are served decompressed.
+func (cs *ClipboardServer) spilledFileResourceHandler(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uri := request.Params.URI
+	name := strings.TrimPrefix(uri, spillURIPrefix)
+	if !strings.HasPrefix(name, FilenamePrefix) || strings.HasSuffix(name, PartialSuffix) || name != filepath.Base(name) {
+		return nil, fmt.Errorf("invalid clipboard file URI %s", uri)
+	}
+	file, err := openSpill(filepath.Join(os.TempDir(), name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("clipboard file %s not found (it may have expired)", name)
+		}
+		return nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if isCompressedSpill(name) {
+		name = strings.TrimSuffix(name, ".gz")
+	}
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	if ext == "b64" {
+		return []mcp.ResourceContents{
+			mcp.BlobResourceContents{URI: uri, MIMEType: "application/octet-stream", Blob: strings.TrimSpace(string(data))},
+		}, nil
+	}
+	return []mcp.ResourceContents{
+		mcp.BlobResourceContents{URI: uri, MIMEType: extensionMIMEType(ext), Blob: encodeBase64(ctx, data)},
+	}, nil
+}
+
+// withResource appends spilled content to a tool result.
+func withResource(result *mcp.CallToolResult, resource mcp.Content) *mcp.CallToolResult {
+	result.Content = append(result.Content, resource)
+	return result
+}
+
+// extensionMIMEType maps a saved file's extension back to its MIME type.
+func extensionMIMEType(ext string) string {
+	switch ext {
+	case "png", "jpg", "gif", "webp", "bmp":
+		return imageMIMEType(ext)
+	case "txt":
+		return "text/plain"
+	}
+	if mimeType, ok := binaryMIMETypes[ext]; ok {
+		return mimeType
+	}
+	return "application/octet-stream"
+}
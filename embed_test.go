@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test that a spilled image is embedded as a blob resource that can also be
+// read back through the clipboard://files/ template
+func TestSpilledImageEmbedded(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("MCP_CLIP_MAX_INLINE_IMAGE", "10")
+	cs := NewClipboardServer()
+	data := append([]byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}, make([]byte, 100)...)
+
+	result, _ := handleBinaryContent(context.Background(), data, cs)
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected text and resource content, got %d items", len(result.Content))
+	}
+	embedded, ok := result.Content[1].(mcp.EmbeddedResource)
+	if !ok {
+		t.Fatalf("Expected an embedded resource, got %T", result.Content[1])
+	}
+	blob := embedded.Resource.(mcp.BlobResourceContents)
+	if blob.MIMEType != "image/png" || blob.Blob != base64.StdEncoding.EncodeToString(data) {
+		t.Errorf("Expected the PNG as a blob, got %s with %d base64 bytes", blob.MIMEType, len(blob.Blob))
+	}
+
+	var request mcp.ReadResourceRequest
+	request.Params.URI = blob.URI
+	contents, err := cs.spilledFileResourceHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", blob.URI, err)
+	}
+	if read := contents[0].(mcp.BlobResourceContents); read.Blob != blob.Blob || read.MIMEType != "image/png" {
+		t.Errorf("Expected the resource to serve the same PNG, got %s", read.MIMEType)
+	}
+}
+
+// Test that files over MCP_CLIP_MAX_EMBEDDED are linked instead of embedded
+func TestSpilledBinaryLinked(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("MCP_CLIP_MAX_INLINE_BASE64", "10")
+	t.Setenv("MCP_CLIP_MAX_EMBEDDED", "0")
+	cs := NewClipboardServer()
+
+	result, _ := handleBinaryContent(context.Background(), []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, cs)
+	link, ok := result.Content[len(result.Content)-1].(mcp.ResourceLink)
+	if !ok {
+		t.Fatalf("Expected a resource link, got %T", result.Content[len(result.Content)-1])
+	}
+
+	var request mcp.ReadResourceRequest
+	request.Params.URI = link.URI
+	contents, err := cs.spilledFileResourceHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", link.URI, err)
+	}
+	decoded, _ := base64.StdEncoding.DecodeString(contents[0].(mcp.BlobResourceContents).Blob)
+	if len(decoded) != 13 {
+		t.Errorf("Expected the 13 original bytes, got %d", len(decoded))
+	}
+}
+
+// Test that only mcp-clip files in the temp directory are served
+func TestSpilledFileResourceRejectsOtherPaths(t *testing.T) {
+	cs := NewClipboardServer()
+	for _, uri := range []string{spillURIPrefix + "../etc/passwd", spillURIPrefix + "passwd", spillURIPrefix + "mcp-clip-1/../../x"} {
+		var request mcp.ReadResourceRequest
+		request.Params.URI = uri
+		if _, err := cs.spilledFileResourceHandler(context.Background(), request); err == nil {
+			t.Errorf("Expected %s to be rejected", uri)
+		}
+	}
+}
+
+// Test that spilled content reports the size and sha256 of the saved file
+func TestSpilledTextChecksum(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("MCP_CLIP_MAX_INLINE_TEXT", "5")
+	content := "more than five bytes"
+
+	result, err := NewClipboardServer().contentResult(context.Background(), content, "text")
+	if err != nil || result.IsError {
+		t.Fatalf("Expected a spilled result, got %v (%v)", result, err)
+	}
+	spill, ok := result.Meta.AdditionalFields["spill"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected spill metadata, got %v", result.Meta)
+	}
+	saved, err := os.ReadFile(spill["location"].(string))
+	if err != nil || string(saved) != content {
+		t.Fatalf("Expected the content in %v, got %q (%v)", spill["location"], saved, err)
+	}
+	if spill["sha256"] != contentHash(content) || spill["size"] != len(content) {
+		t.Errorf("Expected size %d and sha256 %s, got %v", len(content), contentHash(content), spill)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "sha256: "+contentHash(content)) {
+		t.Errorf("Expected the checksum in the message, got %q", text)
+	}
+}
+
+// Test that a temp file name holding other content is not reused, while one
+// holding the same content is
+func TestSaveToTempFileCollision(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	data := []byte("clipboard content")
+
+	// Occupy the names for this second and the next with other content
+	hash := contentHash(string(data))
+	now := time.Now().Unix()
+	for _, ts := range []int64{now, now + 1} {
+		name := filepath.Join(dir, fmt.Sprintf("%s%d-%s.txt", FilenamePrefix, ts, hash))
+		if err := os.WriteFile(name, []byte("something else"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filePath, sum, err := saveToTempFile(data, "txt", nil, func(int) {})
+	if err != nil || sum != hash {
+		t.Fatalf("Expected a saved file with sha256 %s, got %s (%v)", hash, sum, err)
+	}
+	if !strings.HasSuffix(filePath, "-1.txt") {
+		t.Errorf("Expected a numbered name after the collision, got %s", filePath)
+	}
+	if saved, _ := os.ReadFile(filePath); string(saved) != string(data) {
+		t.Errorf("Expected the new content in %s, got %q", filePath, saved)
+	}
+
+	again, _, err := saveToTempFile(data, "txt", nil, func(int) {})
+	if err != nil || again != filePath {
+		t.Errorf("Expected identical content to reuse %s, got %s (%v)", filePath, again, err)
+	}
+}
+
+// Test that temp files are written through a .partial file that is renamed
+// into place, and that partial files are never served
+func TestSaveToTempFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+
+	filePath, _, err := saveToTempFile([]byte("complete content"), "txt", nil, func(int) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ := os.ReadFile(filePath); string(saved) != "complete content" {
+		t.Errorf("Expected the full content in %s, got %q", filePath, saved)
+	}
+	if partials, _ := filepath.Glob(filepath.Join(dir, "*"+PartialSuffix)); len(partials) != 0 {
+		t.Errorf("Expected no partial files left behind, got %v", partials)
+	}
+
+	partial := filepath.Base(filePath) + ".123" + PartialSuffix
+	os.WriteFile(filepath.Join(dir, partial), []byte("trunc"), 0600)
+	var request mcp.ReadResourceRequest
+	request.Params.URI = spillURIPrefix + partial
+	if _, err := NewClipboardServer().spilledFileResourceHandler(context.Background(), request); err == nil {
+		t.Error("Expected partial files to be rejected")
+	}
+}
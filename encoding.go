@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	xunicode "golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+	EncodingShiftJIS    = "shift_jis"
+	EncodingWindows1252 = "windows-1252"
+)
+
+// detectEncoding sniffs the character encoding of raw clipboard data and
+// returns it along with the data converted to UTF-8. Data in a recognized
+// binary format, or that doesn't decode to text in any supported encoding,
+// is returned unchanged with encoding "".
+func detectEncoding(data []byte) (string, string) {
+	if len(data) == 0 {
+		return "", EncodingUTF8
+	}
+	// A charset guess can make PDF or WAV bytes look like Shift-JIS or
+	// UTF-16, so formats known by their magic number are never decoded.
+	// Text that happens to start like one ("MZ is a band", "BM25 ranking")
+	// is valid UTF-8 without control bytes and is still treated as text.
+	if !utf8.Valid(data) || controlByteRatio(data) >= 0.05 {
+		if isImage, _ := detectImageType(data); isImage {
+			return string(data), ""
+		}
+		if ext, _ := sniffBinaryType(data); ext != "" {
+			return string(data), ""
+		}
+	}
+
+	var candidates []string
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		candidates = append(candidates, EncodingUTF16LE)
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		candidates = append(candidates, EncodingUTF16BE)
+	}
+	if enc := sniffUTF16(data); enc != "" {
+		candidates = append(candidates, enc)
+	}
+	if utf8.Valid(data) {
+		candidates = append(candidates, EncodingUTF8)
+	}
+	if looksLikeShiftJIS(data) {
+		candidates = append(candidates, EncodingShiftJIS)
+	}
+	if controlByteRatio(data) < 0.05 {
+		candidates = append(candidates, EncodingWindows1252)
+	}
+
+	// A candidate is only accepted when what it decodes to reads as text
+	for _, enc := range candidates {
+		text := string(data)
+		if dec := decoderFor(enc); dec != nil {
+			converted, err := dec.NewDecoder().String(text)
+			if err != nil {
+				if os.Getenv("MCP_DEBUG") == "1" {
+					fmt.Fprintf(os.Stderr, "Failed to convert clipboard text from %s: %v\n", enc, err)
+				}
+				continue
+			}
+			// Windows clipboard text is NUL-terminated
+			text = strings.TrimRight(converted, "\x00")
+		}
+		if isProbablyText(text) {
+			return text, enc
+		}
+	}
+	return string(data), ""
+}
+
+// sniffUTF16 detects BOM-less UTF-16 by the zero bytes that ASCII-range
+// characters leave in every other position.
+func sniffUTF16(data []byte) string {
+	if len(data) < 4 || len(data)%2 != 0 {
+		return ""
+	}
+	var evenZeros, oddZeros int
+	for i := 0; i < len(data); i += 2 {
+		if data[i] == 0 {
+			evenZeros++
+		}
+		if data[i+1] == 0 {
+			oddZeros++
+		}
+	}
+	pairs := len(data) / 2
+	switch {
+	case oddZeros*10 >= pairs*4 && evenZeros*10 < pairs:
+		return EncodingUTF16LE
+	case evenZeros*10 >= pairs*4 && oddZeros*10 < pairs:
+		return EncodingUTF16BE
+	}
+	return ""
+}
+
+func looksLikeShiftJIS(data []byte) bool {
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(data)
+	if err != nil || bytes.ContainsRune(decoded, utf8.RuneError) {
+		return false
+	}
+	for _, r := range string(decoded) {
+		if unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Han) {
+			return true
+		}
+	}
+	return false
+}
+
+// controlByteRatio is the share of bytes that are C0 controls other than common whitespace.
+func controlByteRatio(data []byte) float64 {
+	controls := 0
+	for _, b := range data {
+		if b < 0x20 && b != '\n' && b != '\r' && b != '\t' {
+			controls++
+		}
+	}
+	return float64(controls) / float64(len(data))
+}
+
+func decoderFor(name string) encoding.Encoding {
+	switch name {
+	case EncodingUTF16LE:
+		return xunicode.UTF16(xunicode.LittleEndian, xunicode.UseBOM)
+	case EncodingUTF16BE:
+		return xunicode.UTF16(xunicode.BigEndian, xunicode.UseBOM)
+	case EncodingShiftJIS:
+		return japanese.ShiftJIS
+	case EncodingWindows1252:
+		return charmap.Windows1252
+	}
+	return nil
+}
+
+// convertToUTF8 transparently converts clipboard text in a foreign encoding to
+// UTF-8. It returns the (possibly converted) content and the detected
+// encoding, which is "" for binary data; binary data is returned unchanged.
+func convertToUTF8(content string) (string, string) {
+	return detectEncoding([]byte(content))
+}
+
+// normalizeText strips a leading byte order mark and applies Unicode NFC
+// normalization so that visually identical strings compare equal.
+func normalizeText(content string) string {
+	content = strings.TrimPrefix(content, "\uFEFF")
+	return norm.NFC.String(content)
+}
+
+// annotateEncoding records the detected text encoding in the result metadata
+// and notes any conversion that took place.
+func annotateEncoding(result *mcp.CallToolResult, enc string) {
+	meta := resultMeta(result)
+	meta["encoding"] = enc
+	if enc != EncodingUTF8 {
+		note := mcp.NewTextContent(fmt.Sprintf("Converted clipboard text from %s to UTF-8", enc))
+		result.Content = append([]mcp.Content{note}, result.Content...)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+// Test charset sniffing and conversion to UTF-8
+func TestConvertToUTF8(t *testing.T) {
+	utf16le, _ := xunicode.UTF16(xunicode.LittleEndian, xunicode.IgnoreBOM).NewEncoder().String("Hello, clipboard\x00")
+	utf16bom, _ := xunicode.UTF16(xunicode.LittleEndian, xunicode.UseBOM).NewEncoder().String("Grüße")
+	sjis, _ := japanese.ShiftJIS.NewEncoder().String("こんにちは世界")
+	latin1, _ := charmap.Windows1252.NewEncoder().String("café crème")
+
+	tests := []struct {
+		name, input, want, encoding string
+	}{
+		{"utf-8", "plain text", "plain text", EncodingUTF8},
+		{"utf-16le", utf16le, "Hello, clipboard", EncodingUTF16LE},
+		{"utf-16 bom", utf16bom, "Grüße", EncodingUTF16LE},
+		{"shift_jis", sjis, "こんにちは世界", EncodingShiftJIS},
+		{"windows-1252", latin1, "café crème", EncodingWindows1252},
+	}
+	for _, tt := range tests {
+		got, enc := convertToUTF8(tt.input)
+		if got != tt.want || enc != tt.encoding {
+			t.Errorf("%s: expected %q (%s), got %q (%s)", tt.name, tt.want, tt.encoding, got, enc)
+		}
+	}
+
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"
+	if got, enc := convertToUTF8(png); got != png || enc != "" {
+		t.Errorf("Expected binary image data to pass through unchanged, got encoding %q", enc)
+	}
+}
+
+// Test that binary formats are never decoded as text, which once made PDF
+// bytes look like Shift-JIS and WAV bytes like UTF-16
+func TestConvertToUTF8Binary(t *testing.T) {
+	tests := map[string]string{
+		"pdf": "%PDF-1.4\n%\x82\xa0\x82\xa2\x93\xfa\x96\x7b\n1 0 obj\n<< /Type /Catalog >>\nendobj\n",
+		"wav": "RIFF\x24\x08\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x02\x00\x44\xac\x00\x00\x10\xb1\x02\x00\x04\x00\x10\x00data\x00\x08\x00\x00",
+		// No magic number, and UTF-16 that decodes to control characters
+		"utf-16 lookalike": "\x01\x00\x02\x00\x03\x00\x04\x00\x05\x00\x06\x00",
+	}
+	for name, data := range tests {
+		if got, enc := convertToUTF8(data); got != data || enc != "" {
+			t.Errorf("%s: expected the bytes unchanged without an encoding, got encoding %q", name, enc)
+		}
+	}
+}
+
+// Test that text starting like a magic number is still recognized as UTF-8,
+// so it goes on to be normalized
+func TestConvertToUTF8MagicLookalike(t *testing.T) {
+	for _, text := range []string{"MZ is a great band name, café", "BM25 ranking: café", "%PDF-1.4 is the version we target"} {
+		if got, enc := convertToUTF8(text); got != text || enc != EncodingUTF8 {
+			t.Errorf("Expected %q as UTF-8, got %q (%s)", text, got, enc)
+		}
+	}
+}
+
+// Test BOM stripping and NFC normalization
+func TestNormalizeText(t *testing.T) {
+	decomposed := "Cafe\u0301"
+	if got := normalizeText("\uFEFF" + decomposed); got != "Café" {
+		t.Errorf("Expected BOM stripped and NFC composed 'Café', got %q", got)
+	}
+	if got := normalizeText("plain"); got != "plain" {
+		t.Errorf("Expected unchanged text, got %q", got)
+	}
+}
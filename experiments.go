@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// experiment is an opt-in feature that ships disabled by default. Large new
+// subsystems start here and graduate once they have proven themselves.
+type experiment struct {
+	name        string
+	description string
+}
+
+// knownExperiments lists the experiments this build understands.
+var knownExperiments = []experiment{
+	{"sync", "Cross-machine clipboard sync (configured with MCP_CLIP_SYNC_*)"},
+}
+
+// experimentEnabled reports whether name is listed in MCP_CLIP_EXPERIMENTS.
+// Builds tagged noexperiments never enable any experiment.
+func experimentEnabled(name string) bool {
+	if !experimentsAvailable {
+		return false
+	}
+	for _, enabled := range requestedExperiments() {
+		if enabled == name || enabled == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// requestedExperiments parses the comma-separated MCP_CLIP_EXPERIMENTS list.
+func requestedExperiments() []string {
+	var names []string
+	for _, name := range strings.Split(os.Getenv("MCP_CLIP_EXPERIMENTS"), ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// enabledExperiments returns the known experiments that are switched on.
+func enabledExperiments() []string {
+	var names []string
+	for _, exp := range knownExperiments {
+		if experimentEnabled(exp.name) {
+			names = append(names, exp.name)
+		}
+	}
+	return names
+}
+
+// warnExperiments reports experiment names that this build doesn't know and
+// explains when experiments were compiled out.
+func warnExperiments() {
+	requested := requestedExperiments()
+	if len(requested) == 0 {
+		return
+	}
+	if !experimentsAvailable {
+		fmt.Fprintf(os.Stderr, "MCP_CLIP_EXPERIMENTS ignored: this build was compiled without experimental features\n")
+		return
+	}
+	for _, name := range requested {
+		if name == "all" {
+			continue
+		}
+		known := false
+		for _, exp := range knownExperiments {
+			known = known || exp.name == name
+		}
+		if !known {
+			fmt.Fprintf(os.Stderr, "Unknown experiment '%s' in MCP_CLIP_EXPERIMENTS\n", name)
+		}
+	}
+}
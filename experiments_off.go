@@ -0,0 +1,5 @@
+//go:build noexperiments
+
+package main
+
+const experimentsAvailable = false
@@ -0,0 +1,7 @@
+//go:build !noexperiments
+
+package main
+
+// experimentsAvailable is false in builds tagged noexperiments, for
+// distributions that must not expose experimental code paths at all.
+const experimentsAvailable = true
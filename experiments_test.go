@@ -0,0 +1,33 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test parsing of MCP_CLIP_EXPERIMENTS
+func TestExperimentEnabled(t *testing.T) {
+	if !experimentsAvailable {
+		t.Skip("experiments are compiled out")
+	}
+	t.Setenv("MCP_CLIP_EXPERIMENTS", " Sync , ocr,,")
+	if !experimentEnabled("sync") {
+		t.Errorf("Expected sync to be enabled")
+	}
+	if experimentEnabled("paste") {
+		t.Errorf("Expected paste to be disabled")
+	}
+	if got := enabledExperiments(); !reflect.DeepEqual(got, []string{"sync"}) {
+		t.Errorf("Expected only known experiments, got %v", got)
+	}
+
+	t.Setenv("MCP_CLIP_EXPERIMENTS", "all")
+	if !experimentEnabled("sync") {
+		t.Errorf("Expected all to enable sync")
+	}
+
+	t.Setenv("MCP_CLIP_EXPERIMENTS", "")
+	if experimentEnabled("sync") || len(enabledExperiments()) != 0 {
+		t.Errorf("Expected no experiments by default")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxExtractMatches bounds how many matches read_clipboard's extract returns,
+// so a pattern like "." on a large paste can't defeat the point of extracting.
+const maxExtractMatches = 1000
+
+// textExtraction is read_clipboard's extract/extract_group request.
+type textExtraction struct {
+	pattern *regexp.Regexp
+	group   int
+}
+
+// requestedExtraction compiles the extract pattern of a read, returning nil
+// when none was given.
+func requestedExtraction(request mcp.CallToolRequest) (*textExtraction, error) {
+	pattern := request.GetString("extract", "")
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extract pattern: %v", err)
+	}
+	group := request.GetInt("extract_group", 0)
+	if group < 0 || group > re.NumSubexp() {
+		return nil, fmt.Errorf("extract_group %d does not exist: the pattern has %d capture group(s)", group, re.NumSubexp())
+	}
+	return &textExtraction{pattern: re, group: group}, nil
+}
+
+// extract returns the matches of the pattern in text, or the chosen capture
+// group of each match, in order. A group that did not participate in a
+// match is skipped. It also reports whether matches were left out.
+func (e *textExtraction) extract(text string) ([]string, bool) {
+	var matches []string
+	for _, match := range e.pattern.FindAllStringSubmatchIndex(text, maxExtractMatches+1) {
+		if start, end := match[2*e.group], match[2*e.group+1]; start >= 0 {
+			matches = append(matches, text[start:end])
+		}
+	}
+	if len(matches) > maxExtractMatches {
+		return matches[:maxExtractMatches], true
+	}
+	return matches, false
+}
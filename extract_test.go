@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test extracting matches and capture groups
+func TestTextExtraction(t *testing.T) {
+	text := "see https://example.com/a and http://example.org/b?q=1\nuser=alice id=7\nuser=bob"
+	tests := []struct {
+		pattern string
+		group   int
+		want    []string
+	}{
+		{`https?://\S+`, 0, []string{"https://example.com/a", "http://example.org/b?q=1"}},
+		{`user=(\w+)`, 1, []string{"alice", "bob"}},
+		{`user=(\w+)(?: id=(\d+))?`, 2, []string{"7"}},
+		{`nothing`, 0, nil},
+	}
+	for _, tt := range tests {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"extract": tt.pattern, "extract_group": float64(tt.group)}
+		extraction, err := requestedExtraction(request)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.pattern, err)
+		}
+		if got, capped := extraction.extract(text); strings.Join(got, "|") != strings.Join(tt.want, "|") || capped {
+			t.Errorf("%s group %d: expected %q, got %q", tt.pattern, tt.group, tt.want, got)
+		}
+	}
+
+	for _, args := range []map[string]any{
+		{"extract": "("},
+		{"extract": `a(b)`, "extract_group": float64(2)},
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		if _, err := requestedExtraction(request); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+// Test that the number of matches is capped
+func TestTextExtractionCap(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"extract": "."}
+	extraction, _ := requestedExtraction(request)
+	if matches, capped := extraction.extract(strings.Repeat("x", maxExtractMatches+5)); len(matches) != maxExtractMatches || !capped {
+		t.Errorf("Expected %d matches and capped, got %d (%v)", maxExtractMatches, len(matches), capped)
+	}
+}
+
+// Test read_clipboard's extract parameter
+func TestReadClipboardExtract(t *testing.T) {
+	fakeClipboard(t, "panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/src/main.go:12\nexit status 2")
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"extract": `/\S+\.go:\d+`, "format": "text"}
+	result, err := NewClipboardServer().readClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "/src/main.go:12" {
+		t.Errorf("Expected the source location, got %q", text)
+	}
+	if info := result.Meta.AdditionalFields["extract"].(map[string]any); info["matches"] != 1 {
+		t.Errorf("Unexpected extract metadata: %v", info)
+	}
+
+	request.Params.Arguments = map[string]any{"extract": `https?://\S+`}
+	result, _ = NewClipboardServer().readClipboardHandler(context.Background(), request)
+	if result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "No text on the clipboard matches") {
+		t.Errorf("Expected a no-match result, got %v", result.Content)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	DefaultMaxFileContent      = 16 << 10 // 16KB per file
+	DefaultMaxFileContentTotal = 64 << 10 // 64KB across all files
+	maxFileContentFiles        = 20
+)
+
+// parseFileList recognizes clipboard text that is a list of copied files:
+// one absolute path or file:// URI per line, as file managers (and the WSL2
+// reader, for Windows file drops) put on the clipboard. The "copy" or "cut"
+// line of GNOME's x-special/gnome-copied-files is skipped. It returns nil
+// when any line is not a file path.
+func parseFileList(content string) []string {
+	var paths []string
+	for i, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue // text/uri-list allows comments
+		}
+		if i == 0 && (line == "copy" || line == "cut") {
+			continue
+		}
+		if strings.HasPrefix(line, "file://") {
+			u, err := url.Parse(line)
+			if err != nil || (u.Host != "" && u.Host != "localhost") {
+				return nil
+			}
+			line = u.Path
+		}
+		if !filepath.IsAbs(line) {
+			return nil
+		}
+		paths = append(paths, filepath.Clean(line))
+	}
+	return paths
+}
+
+// appendFileContents adds the content of the small text files among paths
+// to a read_clipboard result. Each file is capped at
+// MCP_CLIP_MAX_FILE_CONTENT bytes and all files together at
+// MCP_CLIP_MAX_FILE_CONTENT_TOTAL; files that are larger, binary, missing or
+// withheld by the content policy are listed with the reason in _meta.files.
+func (cs *ClipboardServer) appendFileContents(result *mcp.CallToolResult, paths []string) {
+	perFile := getSizeEnv("MCP_CLIP_MAX_FILE_CONTENT", DefaultMaxFileContent)
+	budget := getSizeEnv("MCP_CLIP_MAX_FILE_CONTENT_TOTAL", DefaultMaxFileContentTotal)
+
+	files := make([]map[string]any, 0, len(paths))
+	for i, path := range paths {
+		info := map[string]any{"path": path}
+		files = append(files, info)
+		if i >= maxFileContentFiles {
+			info["skipped"] = fmt.Sprintf("only the first %d files are read", maxFileContentFiles)
+			continue
+		}
+		content, reason := readSmallTextFile(path, min(perFile, budget))
+		if reason == "" {
+			if denied := cs.policyResult(content); denied != nil {
+				reason = "withheld by content policy"
+			}
+		}
+		if reason != "" {
+			info["skipped"] = reason
+			continue
+		}
+		budget -= len(content)
+		info["size"] = len(content)
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf("File %s (%d bytes):\n%s", path, len(content), content)))
+	}
+
+	meta := resultMeta(result)
+	meta["files"] = files
+}
+
+// readSmallTextFile reads a regular text file of at most limit bytes. When
+// the file can't be included, it returns the reason instead.
+func readSmallTextFile(path string, limit int) (string, string) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "file not found"
+		}
+		return "", fmt.Sprintf("cannot open file: %v", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return "", fmt.Sprintf("cannot open file: %v", err)
+	}
+	if !stat.Mode().IsRegular() {
+		return "", "not a regular file"
+	}
+	if stat.Size() > int64(limit) {
+		return "", fmt.Sprintf("file is %d bytes, above the %d byte limit", stat.Size(), limit)
+	}
+
+	// The file may have grown since Stat
+	data, err := io.ReadAll(io.LimitReader(file, int64(limit)+1))
+	if err != nil {
+		return "", fmt.Sprintf("failed to read file: %v", err)
+	}
+	if len(data) > limit {
+		return "", fmt.Sprintf("file is above the %d byte limit", limit)
+	}
+	if !utf8.Valid(data) || !isProbablyText(string(data)) {
+		return "", "binary file"
+	}
+	return string(data), ""
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test recognition of copied file lists in their common clipboard forms
+func TestParseFileList(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b c.txt")
+
+	paths := parseFileList(a + "\n" + b + "\n")
+	if len(paths) != 2 || paths[1] != b {
+		t.Errorf("Expected 2 paths, got %q", paths)
+	}
+	paths = parseFileList("copy\nfile://" + filepath.ToSlash(strings.ReplaceAll(b, " ", "%20")))
+	if len(paths) != 1 || paths[0] != b {
+		t.Errorf("Expected the decoded URI path, got %q", paths)
+	}
+	if paths := parseFileList(a + "\nnot a path"); paths != nil {
+		t.Errorf("Expected no file list for mixed text, got %q", paths)
+	}
+	if paths := parseFileList("file://server/share/a.txt"); paths != nil {
+		t.Errorf("Expected remote file URIs to be rejected, got %q", paths)
+	}
+}
+
+// Test that small text files are inlined and others skipped with a reason
+func TestAppendFileContents(t *testing.T) {
+	t.Setenv("MCP_CLIP_MAX_FILE_CONTENT", "100")
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.txt")
+	large := filepath.Join(dir, "large.txt")
+	binary := filepath.Join(dir, "image.bin")
+	os.WriteFile(small, []byte("hello world"), 0644)
+	os.WriteFile(large, []byte(strings.Repeat("x", 200)), 0644)
+	os.WriteFile(binary, []byte{0x89, 'P', 'N', 'G', 0, 0, 0, 0}, 0644)
+
+	result := mcp.NewToolResultText("Clipboard text content")
+	NewClipboardServer().appendFileContents(result, []string{small, large, binary, filepath.Join(dir, "missing.txt")})
+
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected only the small file inlined, got %d content items", len(result.Content))
+	}
+	if text := result.Content[1].(mcp.TextContent).Text; !strings.Contains(text, "hello world") {
+		t.Errorf("Expected the small file content, got %q", text)
+	}
+	files := result.Meta.AdditionalFields["files"].([]map[string]any)
+	for i, want := range []string{"", "above the 100 byte limit", "binary file", "file not found"} {
+		skipped, _ := files[i]["skipped"].(string)
+		if want == "" && skipped != "" || !strings.Contains(skipped, want) {
+			t.Errorf("Expected file %d skipped for %q, got %q", i, want, skipped)
+		}
+	}
+}
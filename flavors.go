@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// psRTFClipboardScript prints the RTF clipboard flavor, which Word, Outlook
+// and WordPad publish next to plain text and HTML.
+const psRTFClipboardScript = `[Console]::OutputEncoding = [System.Text.Encoding]::UTF8; Add-Type -AssemblyName System.Windows.Forms; [System.Windows.Forms.Clipboard]::GetText([System.Windows.Forms.TextDataFormat]::Rtf)`
+
+// clipboardFlavor is one representation of the copied content.
+type clipboardFlavor struct {
+	mimeType string
+	ext      string // extension used when the flavor is saved to a temp file
+	content  string
+}
+
+// readClipboardRTF returns the RTF flavor of the clipboard, or "" when the
+// clipboard holds no RTF.
+func readClipboardRTF(ctx context.Context) (string, error) {
+	ctx, cancel := withReadTimeout(ctx)
+	defer cancel()
+
+	if reader, ok := selectBackend().(formatReader); ok {
+		return readFormatFlavor(ctx, reader, "text/rtf", "application/rtf", "text/richtext")
+	}
+
+	switch {
+	case isTermux():
+		return "", fmt.Errorf("the Termux clipboard has no RTF flavor")
+	case isWSL2() || runtime.GOOS == "windows":
+		powershellPath := findPowerShell()
+		if powershellPath == "" {
+			return "", fmt.Errorf("PowerShell not found - required for Windows clipboard access")
+		}
+		output, err := powershellCommand(ctx, powershellPath, "-NoProfile", "-STA", "-Command", psRTFClipboardScript).Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to read RTF clipboard: %v", err)
+		}
+		return string(output), nil
+	case runtime.GOOS == "darwin":
+		output, err := exec.CommandContext(ctx, "osascript", "-e", "the clipboard as «class RTF »").Output()
+		if err != nil {
+			// osascript fails when the clipboard has no RTF flavor
+			return "", nil
+		}
+		return decodeAppleScriptData(string(output))
+	default:
+		for _, mimeType := range []string{"text/rtf", "application/rtf", "text/richtext"} {
+			output, err := readLinuxFlavor(ctx, mimeType)
+			if err != nil || output != "" {
+				return output, err
+			}
+		}
+		return "", nil
+	}
+}
+
+// readClipboardFlavors reads the plain text, HTML and RTF flavors of the
+// clipboard, as office suites and mail clients publish them together. Flavors
+// that are absent or cannot be read on this platform are left out; an error
+// is returned only when none could be read.
+func readClipboardFlavors(ctx context.Context) ([]clipboardFlavor, error) {
+	var flavors []clipboardFlavor
+	text, textErr := readClipboard(ctx)
+	if textErr == nil && text != "" && isProbablyText(text) {
+		flavors = append(flavors, clipboardFlavor{mimeType: "text/plain", ext: "txt", content: text})
+	}
+	for _, rich := range []struct {
+		mimeType, ext string
+		read          func(context.Context) (string, error)
+	}{
+		{"text/html", "html", readClipboardHTML},
+		{"text/rtf", "rtf", readClipboardRTF},
+	} {
+		content, err := rich.read(ctx)
+		if err != nil {
+			if os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "Clipboard %s flavor unavailable: %v\n", rich.mimeType, err)
+			}
+			continue
+		}
+		if strings.TrimSpace(strings.TrimRight(content, "\x00")) != "" {
+			flavors = append(flavors, clipboardFlavor{mimeType: rich.mimeType, ext: rich.ext, content: strings.TrimRight(content, "\x00")})
+		}
+	}
+	if len(flavors) == 0 && textErr != nil {
+		return nil, textErr
+	}
+	return flavors, nil
+}
+
+// richestFlavor picks the flavor that preserves the most structure: HTML
+// keeps tables and links in a form models read well, RTF keeps formatting,
+// and plain text is the fallback.
+func richestFlavor(flavors []clipboardFlavor) string {
+	for _, mimeType := range []string{"text/html", "text/rtf", "text/plain"} {
+		for _, flavor := range flavors {
+			if flavor.mimeType == mimeType {
+				return mimeType
+			}
+		}
+	}
+	return ""
+}
+
+// readClipboardFlavorsHandler returns every text flavor of the clipboard in
+// one result, one content item per flavor, so content copied from Word, Excel
+// or Outlook can be used in whichever form suits the task.
+func (cs *ClipboardServer) readClipboardFlavorsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	flavors, err := readClipboardFlavors(ctx)
+	if err != nil {
+		return backendErrorResult(ctx, err), nil
+	}
+	if len(flavors) == 0 {
+		return emptyClipboardResult(ctx), nil
+	}
+	if hint := cs.concealedHint(ctx, flavors[0].content); hint != "" {
+		return concealedResult(hint), nil
+	}
+
+	limits := getInlineThresholds()
+	names := make([]string, 0, len(flavors))
+	summary := make([]map[string]any, 0, len(flavors))
+	var contents []mcp.Content
+	for _, flavor := range flavors {
+		if denied := cs.policyResult(flavor.content); denied != nil {
+			return denied, nil
+		}
+		names = append(names, flavor.mimeType)
+		info := map[string]any{"mimeType": flavor.mimeType, "size": len(flavor.content)}
+		if len(flavor.content) > limits.text {
+			spill, err := cs.spillToFile(ctx, textSource(flavor.content), flavor.ext)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to save large %s content to temp file: %v", flavor.mimeType, err)), nil
+			}
+			info["path"], info["sha256"] = spill.location, spill.sha256
+			contents = append(contents, mcp.NewTextContent(fmt.Sprintf("%s too large. Saved to: %s", flavor.mimeType, spill)))
+		} else {
+			contents = append(contents, mcp.NewTextContent(fmt.Sprintf("%s (%d bytes):\n%s", flavor.mimeType, len(flavor.content), flavor.content)))
+		}
+		summary = append(summary, info)
+	}
+
+	richest := richestFlavor(flavors)
+	header := mcp.NewTextContent(fmt.Sprintf("Clipboard offers %d flavor(s): %s. Richest: %s", len(flavors), strings.Join(names, ", "), richest))
+	result := &mcp.CallToolResult{Content: append([]mcp.Content{header}, contents...)}
+	result.Meta = mcp.NewMetaFromMap(map[string]any{"flavors": summary, "richest": richest})
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test that HTML is preferred over RTF and RTF over plain text
+func TestRichestFlavor(t *testing.T) {
+	flavors := []clipboardFlavor{{mimeType: "text/plain"}, {mimeType: "text/rtf"}}
+	if got := richestFlavor(flavors); got != "text/rtf" {
+		t.Errorf("Expected text/rtf, got %q", got)
+	}
+	flavors = append(flavors, clipboardFlavor{mimeType: "text/html"})
+	if got := richestFlavor(flavors); got != "text/html" {
+		t.Errorf("Expected text/html, got %q", got)
+	}
+	if got := richestFlavor(nil); got != "" {
+		t.Errorf("Expected no flavor, got %q", got)
+	}
+}
+
+// Test that text and HTML flavors come back together, without the absent RTF
+func TestReadClipboardFlavorsHandler(t *testing.T) {
+	fakeCopyQ(t)
+	t.Setenv("MCP_CLIP_BACKEND", "copyq")
+	cs := NewClipboardServer()
+
+	result, err := cs.readClipboardFlavorsHandler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("Expected a result, got %v (%v)", result, err)
+	}
+	if len(result.Content) != 3 {
+		t.Fatalf("Expected a header and 2 flavors, got %d content items", len(result.Content))
+	}
+	if text := result.Content[2].(mcp.TextContent).Text; !strings.HasPrefix(text, "text/html") || !strings.Contains(text, "<b>hello</b>") {
+		t.Errorf("Expected the HTML flavor, got %q", text)
+	}
+	if result.Meta.AdditionalFields["richest"] != "text/html" {
+		t.Errorf("Expected text/html as richest flavor, got %v", result.Meta.AdditionalFields["richest"])
+	}
+	if flavors := result.Meta.AdditionalFields["flavors"].([]map[string]any); len(flavors) != 2 || flavors[0]["mimeType"] != "text/plain" {
+		t.Errorf("Expected text/plain and text/html in _meta, got %v", flavors)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultHistorySize = 50
+
+// historyEntry is one recorded clipboard change. Content that reappears on
+// the clipboard keeps a single entry: Time is when it was last seen, and
+// FirstSeen and Seen record its earlier appearances.
+type historyEntry struct {
+	ID        uint64
+	Time      time.Time
+	Content   string
+	Kind      string // "text", "image" or "binary"
+	Format    string // image type or sniffed binary extension, e.g. "png" or "pdf"
+	Size      int
+	Hash      string
+	FirstSeen time.Time
+	Seen      int
+}
+
+// clipboardHistory is a bounded, in-memory log of clipboard changes, oldest first.
+type clipboardHistory struct {
+	mu      sync.RWMutex
+	entries []historyEntry
+	limit   int
+	nextID  uint64
+	redact  bool // keep only hash, size and type (privacy mode)
+}
+
+func newClipboardHistory(limit int) *clipboardHistory {
+	return &clipboardHistory{limit: limit, nextID: 1}
+}
+
+func getHistorySize() int {
+	if sizeStr := os.Getenv("MCP_CLIP_HISTORY_SIZE"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size >= 0 {
+			return size
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Invalid MCP_CLIP_HISTORY_SIZE '%s', using default: %d\n", sizeStr, DefaultHistorySize)
+		}
+	}
+	return DefaultHistorySize
+}
+
+// recordHistory adds content to the history unless the content policy
+// withholds it, so denied content can't surface through history tools and
+// resources later.
+func (cs *ClipboardServer) recordHistory(content string, at time.Time) {
+	if denied, _ := cs.policy.check(content); denied {
+		return
+	}
+	cs.history.add(content, at)
+}
+
+// add records content and returns the new entry. A zero limit disables history.
+// Content already in the history (compared by hash, as when a clipboard
+// manager rewrites the selection) is not stored twice: its entry moves to the
+// newest position with a new ID, so the change is still sequenced, and its
+// last-seen time and count are bumped.
+func (h *clipboardHistory) add(content string, at time.Time) historyEntry {
+	hash := contentHash(content)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entry := historyEntry{Content: content, Size: len(content), Hash: hash, FirstSeen: at}
+	if i := slices.IndexFunc(h.entries, func(e historyEntry) bool { return e.Hash == hash }); i >= 0 {
+		entry = h.entries[i]
+		h.entries = slices.Delete(h.entries, i, i+1)
+	} else {
+		entry.Kind, entry.Format = classifyContent(content)
+		if h.redact {
+			entry.Content = ""
+		}
+	}
+	entry.ID = h.nextID
+	entry.Time = at
+	entry.Seen++
+	h.nextID++
+
+	if h.limit <= 0 {
+		return entry
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.limit {
+		h.entries = append([]historyEntry(nil), h.entries[len(h.entries)-h.limit:]...)
+	}
+	return entry
+}
+
+// recent returns up to n entries, newest first. n <= 0 returns all entries.
+func (h *clipboardHistory) recent(n int) []historyEntry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if n <= 0 || n > len(h.entries) {
+		n = len(h.entries)
+	}
+	result := make([]historyEntry, 0, n)
+	for i := len(h.entries) - 1; i >= 0 && len(result) < n; i-- {
+		result = append(result, h.entries[i])
+	}
+	return result
+}
+
+// latestID returns the ID of the most recent change, or 0 if none was recorded.
+func (h *clipboardHistory) latestID() uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.nextID - 1
+}
+
+// since returns retained entries with an ID greater than seq, oldest first.
+func (h *clipboardHistory) since(seq uint64) []historyEntry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var result []historyEntry
+	for _, entry := range h.entries {
+		if entry.ID > seq {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func (h *clipboardHistory) get(id uint64) (historyEntry, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, entry := range h.entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+	return historyEntry{}, false
+}
+
+// findHash returns the retained entry whose content has the given sha256.
+func (h *clipboardHistory) findHash(hash string) (historyEntry, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if i := slices.IndexFunc(h.entries, func(e historyEntry) bool { return e.Hash == hash }); i >= 0 {
+		return h.entries[i], true
+	}
+	return historyEntry{}, false
+}
+
+// classifyContent reports whether content is text, a recognized image, or other binary data.
+func classifyContent(content string) (string, string) {
+	if isProbablyText(content) {
+		return "text", ""
+	}
+	if isImage, imageType := detectImageType([]byte(content)); isImage {
+		return "image", imageType
+	}
+	ext, _ := sniffBinaryType([]byte(content))
+	return "binary", ext
+}
+
+// previewText returns a single-line preview of at most maxRunes runes.
+func previewText(content string, maxRunes int) string {
+	flat := strings.Join(strings.Fields(content), " ")
+	if preview, cut := truncateRunes(flat, maxRunes); cut {
+		return preview + "…"
+	}
+	return flat
+}
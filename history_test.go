@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test that history is bounded and returned newest first
+func TestHistoryBoundedNewestFirst(t *testing.T) {
+	h := newClipboardHistory(3)
+	for i := 1; i <= 5; i++ {
+		h.add(strings.Repeat("x", i), time.Now())
+	}
+
+	entries := h.recent(0)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].ID != 5 || entries[2].ID != 3 {
+		t.Errorf("Expected IDs 5..3, got %d..%d", entries[0].ID, entries[2].ID)
+	}
+	if _, ok := h.get(1); ok {
+		t.Error("Expected evicted entry 1 to be gone")
+	}
+}
+
+// Test that clipboard updates are recorded in history
+func TestUpdateClipboardRecordsHistory(t *testing.T) {
+	cs := NewClipboardServer()
+	cs.updateClipboard("first")
+	cs.updateClipboard("first")
+	cs.updateClipboard("second")
+
+	entries := cs.history.recent(0)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 history entries, got %d", len(entries))
+	}
+	if entries[0].Content != "second" || entries[0].Kind != "text" {
+		t.Errorf("Unexpected newest entry: %+v", entries[0])
+	}
+}
+
+// Test timeline rendering includes previews and entry links
+func TestRenderTimeline(t *testing.T) {
+	now := time.Now()
+	h := newClipboardHistory(10)
+	h.add("go test ./...", now.Add(-5*time.Minute))
+	h.add("\x89PNG\r\n\x1a\n\x00\x00\x00\x00", now)
+
+	out := renderTimeline(h.recent(0), now)
+	for _, want := range []string{"# Clipboard Timeline", "**#1**", "5m ago", "`go test ./...`", "clipboard://history/2", "image/png"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected timeline to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+// Test that reappearing content moves its entry instead of duplicating it
+func TestHistoryDeduplicates(t *testing.T) {
+	h := newClipboardHistory(10)
+	start := time.Now()
+	h.add("a", start)
+	h.add("b", start.Add(time.Second))
+	h.add("a", start.Add(2*time.Second))
+
+	entries := h.recent(0)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	newest := entries[0]
+	if newest.Content != "a" || newest.ID != 3 || newest.Seen != 2 {
+		t.Errorf("Expected 'a' as entry 3 seen twice, got %q as entry %d seen %d times", newest.Content, newest.ID, newest.Seen)
+	}
+	if !newest.Time.Equal(start.Add(2*time.Second)) || !newest.FirstSeen.Equal(start) {
+		t.Errorf("Expected last seen %v and first seen %v, got %v and %v", start.Add(2*time.Second), start, newest.Time, newest.FirstSeen)
+	}
+	if h.latestID() != 3 {
+		t.Errorf("Expected the reappearance to advance the sequence to 3, got %d", h.latestID())
+	}
+}
+
+// Test that text in any script is recognized and binary data is not
+func TestIsProbablyText(t *testing.T) {
+	for _, text := range []string{
+		"plain ASCII text\n",
+		"中文文本，用于测试剪贴板。",
+		"日本語のテキストです",
+		"Привет, мир! Это тест.",
+		"مرحبا بالعالم",
+		"emoji 👍🏽 and accents: café",
+		"\x1b[31mred\x1b[0m terminal output with colors and enough text around it",
+	} {
+		if !isProbablyText(text) {
+			t.Errorf("Expected %q to be text", text)
+		}
+	}
+	for _, binary := range []string{
+		"\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x01\x00",
+		"\xff\xd8\xff\xe0\x00\x10JFIF\x00\x01\x01\x00\x00\x01",
+		"abc\x00\x01\x02\x03def",
+	} {
+		if isProbablyText(binary) {
+			t.Errorf("Expected %q to be binary", binary)
+		}
+	}
+}
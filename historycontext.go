@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	historyContextEntries = 3
+	historyContextPreview = 60
+)
+
+// historyContext returns up to n entries copied before the current content,
+// newest first. The newest entry is skipped when it is the current content.
+func historyContext(entries []historyEntry, current string, n int) []historyEntry {
+	if len(entries) > 0 && entries[0].Hash == contentHash(current) {
+		entries = entries[1:]
+	}
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// renderHistoryContext describes previous entries in one line each, e.g.
+// "#12 · 2m ago · url · https://example.com".
+func renderHistoryContext(entries []historyEntry, now time.Time) string {
+	var b strings.Builder
+	b.WriteString("Previously copied (newest first):")
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "\n- #%d · %s · %s", entry.ID, formatAge(now.Sub(entry.Time)), digestClass(entry))
+		if entry.Kind == "text" && !entry.redacted() {
+			fmt.Fprintf(&b, " · %s", previewText(entry.Content, historyContextPreview))
+		} else {
+			fmt.Fprintf(&b, " · %s, %d bytes", entryLabel(entry), entry.Size)
+		}
+	}
+	return b.String()
+}
+
+// appendHistoryContext adds brief metadata about the previous clipboard
+// entries to result, as a trailing text block and as "history" in _meta.
+func (cs *ClipboardServer) appendHistoryContext(result *mcp.CallToolResult, current string) {
+	entries := historyContext(cs.history.recent(historyContextEntries+1), current, historyContextEntries)
+	if len(entries) == 0 {
+		return
+	}
+
+	now := time.Now()
+	summaries := make([]map[string]any, len(entries))
+	for i, entry := range entries {
+		summaries[i] = map[string]any{
+			"sequence":   entry.ID,
+			"type":       digestClass(entry),
+			"ageSeconds": int(now.Sub(entry.Time).Seconds()),
+		}
+		if entry.Kind == "text" && !entry.redacted() {
+			summaries[i]["preview"] = previewText(entry.Content, historyContextPreview)
+		}
+	}
+
+	meta := resultMeta(result)
+	meta["history"] = summaries
+	result.Content = append(result.Content, mcp.NewTextContent(renderHistoryContext(entries, now)))
+}
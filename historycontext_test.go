@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test that history context skips the current content and is capped
+func TestHistoryContext(t *testing.T) {
+	h := newClipboardHistory(10)
+	start := time.Now().Add(-10 * time.Minute)
+	h.add("panic: runtime error", start)
+	h.add("/home/user/project/main.go", start.Add(time.Minute))
+	h.add("https://example.com/docs", start.Add(2*time.Minute))
+	h.add("current", start.Add(3*time.Minute))
+	h.add("latest", start.Add(4*time.Minute))
+
+	entries := historyContext(h.recent(4), "latest", 3)
+	if len(entries) != 3 || entries[0].Content != "current" || entries[2].Content != "/home/user/project/main.go" {
+		t.Errorf("Expected the 3 entries before the current one, got %+v", entries)
+	}
+
+	// Current content not yet recorded: nothing is skipped
+	entries = historyContext(h.recent(4), "something new", 3)
+	if len(entries) != 3 || entries[0].Content != "latest" {
+		t.Errorf("Expected the 3 newest entries, got %+v", entries)
+	}
+
+	rendered := renderHistoryContext(h.recent(2), start.Add(5*time.Minute))
+	if !strings.Contains(rendered, "#5 · 1m ago · text · latest") || !strings.Contains(rendered, "#4 · 2m ago") {
+		t.Errorf("Unexpected rendering: %s", rendered)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// psHTMLClipboardScript prints the CF_HTML clipboard flavor as UTF-8. It uses
+// Windows Forms because PowerShell 7's Get-Clipboard only reads plain text.
+const psHTMLClipboardScript = `[Console]::OutputEncoding = [System.Text.Encoding]::UTF8; Add-Type -AssemblyName System.Windows.Forms; [System.Windows.Forms.Clipboard]::GetText([System.Windows.Forms.TextDataFormat]::Html)`
+
+// readClipboardHTML returns the HTML flavor of the clipboard, which browsers
+// and office suites publish alongside plain text. It returns "" when the
+// clipboard holds no HTML.
+func readClipboardHTML(ctx context.Context) (string, error) {
+	ctx, cancel := withReadTimeout(ctx)
+	defer cancel()
+
+	if reader, ok := selectBackend().(formatReader); ok {
+		return readFormatHTML(ctx, reader)
+	}
+
+	switch {
+	case isTermux():
+		return "", fmt.Errorf("the Termux clipboard has no HTML flavor")
+	case isWSL2() || runtime.GOOS == "windows":
+		powershellPath := findPowerShell()
+		if powershellPath == "" {
+			return "", fmt.Errorf("PowerShell not found - required for Windows clipboard access")
+		}
+		return readWindowsHTML(powershellCommand(ctx, powershellPath, "-NoProfile", "-STA", "-Command", psHTMLClipboardScript))
+	case runtime.GOOS == "darwin":
+		output, err := exec.CommandContext(ctx, "osascript", "-e", "the clipboard as «class HTML»").Output()
+		if err != nil {
+			// osascript fails when the clipboard has no HTML flavor
+			return "", nil
+		}
+		return decodeAppleScriptData(string(output))
+	default:
+		return readLinuxHTML(ctx)
+	}
+}
+
+// readFormatHTML reads text/html from a backend that lists formats, if offered.
+func readFormatHTML(ctx context.Context, reader formatReader) (string, error) {
+	return readFormatFlavor(ctx, reader, "text/html")
+}
+
+// readFormatFlavor reads the first of mimeTypes a backend that lists formats
+// offers, or returns "" when it offers none of them.
+func readFormatFlavor(ctx context.Context, reader formatReader, mimeTypes ...string) (string, error) {
+	formats, err := reader.Formats(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, mimeType := range mimeTypes {
+		for _, format := range formats {
+			if strings.EqualFold(format, mimeType) {
+				return reader.ReadFormat(ctx, format)
+			}
+		}
+	}
+	return "", nil
+}
+
+func readWindowsHTML(cmd *exec.Cmd) (string, error) {
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read HTML clipboard: %v", err)
+	}
+	return extractCFHTMLFragment(string(output)), nil
+}
+
+func readLinuxHTML(ctx context.Context) (string, error) {
+	output, err := readLinuxFlavor(ctx, "text/html")
+	if err != nil {
+		return "", fmt.Errorf("no utility that can read the HTML clipboard found (install wl-clipboard or xclip)")
+	}
+	return output, nil
+}
+
+// readLinuxFlavor reads one MIME type from the Wayland or X11 clipboard,
+// returning "" when it is not offered.
+func readLinuxFlavor(ctx context.Context, mimeType string) (string, error) {
+	candidates := [][]string{
+		withWaylandSeat([]string{"wl-paste", "--no-newline", "--type", mimeType}),
+		{"xclip", "-o", "-selection", "clipboard", "-t", mimeType},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		candidates = candidates[1:]
+	}
+
+	for _, args := range candidates {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		output, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+		if err != nil {
+			// Both tools exit non-zero when the requested type is not offered
+			return "", nil
+		}
+		return string(output), nil
+	}
+	return "", fmt.Errorf("no clipboard utility found (install wl-clipboard or xclip)")
+}
+
+// extractCFHTMLFragment strips the Windows CF_HTML header ("Version:0.9
+// StartHTML:..."), keeping only the copied fragment when it is marked.
+func extractCFHTMLFragment(cfHTML string) string {
+	if start := strings.Index(cfHTML, "<!--StartFragment-->"); start >= 0 {
+		fragment := cfHTML[start+len("<!--StartFragment-->"):]
+		if end := strings.Index(fragment, "<!--EndFragment-->"); end >= 0 {
+			fragment = fragment[:end]
+		}
+		return fragment
+	}
+	if start := strings.Index(strings.ToLower(cfHTML), "<html"); start >= 0 {
+		return cfHTML[start:]
+	}
+	return cfHTML
+}
+
+// decodeAppleScriptData decodes osascript's «data HTML3C68746D6C3E...» output,
+// as returned for HTML and RTF flavors.
+func decodeAppleScriptData(output string) (string, error) {
+	output = strings.TrimSpace(output)
+	if !strings.HasPrefix(output, "«data ") {
+		return output, nil
+	}
+	hexData := strings.TrimSuffix(strings.TrimPrefix(output, "«data "), "»")
+	if len(hexData) < 4 {
+		return "", nil
+	}
+	data, err := hex.DecodeString(hexData[4:]) // skip the four-character type code
+	if err != nil {
+		return "", fmt.Errorf("failed to decode clipboard data: %v", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+// Test stripping of the Windows CF_HTML header
+func TestExtractCFHTMLFragment(t *testing.T) {
+	cfHTML := "Version:0.9\r\nStartHTML:00000097\r\nEndHTML:00000170\r\nStartFragment:00000131\r\nEndFragment:00000134\r\n<html><body>\r\n<!--StartFragment--><b>Hi</b><!--EndFragment-->\r\n</body>\r\n</html>"
+	if got := extractCFHTMLFragment(cfHTML); got != "<b>Hi</b>" {
+		t.Errorf("Expected fragment, got %q", got)
+	}
+
+	if got := extractCFHTMLFragment("Version:0.9\r\n<html><p>x</p></html>"); got != "<html><p>x</p></html>" {
+		t.Errorf("Expected document without header, got %q", got)
+	}
+}
+
+// Test decoding of osascript's hex data literal
+func TestDecodeAppleScriptData(t *testing.T) {
+	got, err := decodeAppleScriptData("«data HTML3C623E48693C2F623E»\n")
+	if err != nil || got != "<b>Hi</b>" {
+		t.Errorf("Expected <b>Hi</b>, got %q (%v)", got, err)
+	}
+}
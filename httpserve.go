@@ -0,0 +1,403 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	DefaultFileURLTTL    = 15 * time.Minute
+	filesPathPrefix      = "/files/"
+	maxFileSweepInterval = time.Minute
+	unixAddrPrefix       = "unix:"
+)
+
+// isLocalListenAddr reports whether only this machine can connect to addr: a
+// Unix socket, or a loopback host.
+func isLocalListenAddr(addr string) (bool, error) {
+	if strings.HasPrefix(addr, unixAddrPrefix) {
+		return true, nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false, err
+	}
+	ip := net.ParseIP(host)
+	return host == "localhost" || (ip != nil && ip.IsLoopback()), nil
+}
+
+// checkHTTPListen only allows Unix sockets and loopback addresses without a
+// token, since /mcp reads and writes the clipboard for anyone who connects.
+func checkHTTPListen(addr, token string) error {
+	local, err := isLocalListenAddr(addr)
+	if err != nil {
+		return fmt.Errorf("invalid HTTP address %s: %v", addr, err)
+	}
+	if local || token != "" {
+		return nil
+	}
+	return fmt.Errorf("MCP over HTTP only listens on %s with a token: set MCP_CLIP_HTTP_TOKEN", addr)
+}
+
+// serveHTTP serves MCP over streamable HTTP at /mcp until ctx is cancelled.
+// An address of the form unix:/path listens on a Unix domain socket. When
+// MCP_CLIP_SERVE_FILES=1, overflow files are also served at /files/{token},
+// and MCP_CLIP_METRICS=1 exposes Prometheus metrics at /metrics.
+func serveHTTP(ctx context.Context, s *server.MCPServer, cs *ClipboardServer, addr string) error {
+	token := os.Getenv("MCP_CLIP_HTTP_TOKEN")
+	if err := checkHTTPListen(addr, token); err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", requireBearer(token, cs.subscriptions.subscriptionMiddleware(server.NewStreamableHTTPServer(s))))
+	if os.Getenv("MCP_CLIP_METRICS") == "1" {
+		mux.Handle("/metrics", requireBearer(token, metrics))
+	}
+
+	if os.Getenv("MCP_CLIP_SERVE_FILES") == "1" {
+		baseURL, err := publicBaseURL(addr)
+		if err != nil {
+			return err
+		}
+		cs.files = newFileServer(baseURL, getFileURLTTL())
+		cs.files.singleUse = os.Getenv("MCP_CLIP_FILE_URL_SINGLE_USE") == "1"
+		if auditPath := os.Getenv("MCP_CLIP_FILE_AUDIT_LOG"); auditPath != "" {
+			auditFile, err := os.OpenFile(auditPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to open file audit log: %v", err)
+			}
+			defer auditFile.Close()
+			cs.files.audit = auditFile
+		}
+		mux.Handle(filesPathPrefix, cs.files)
+		go cs.files.collect(ctx)
+	}
+
+	listener, err := listenHTTP(addr)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{Handler: mux}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- httpServer.Serve(listener)
+	}()
+
+	if os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Serving MCP over HTTP at http://%s/mcp\n", addr)
+	}
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// serveStdioAndHTTP serves the launching client over stdio and auxiliary
+// clients over HTTP at addr, sharing the monitor, history and subscriptions.
+// The process lives as long as the stdio client: when it disconnects the HTTP
+// listener is shut down too, and an HTTP failure ends both.
+func (cs *ClipboardServer) serveStdioAndHTTP(ctx context.Context, s *server.MCPServer, addr string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	httpErr := make(chan error, 1)
+	go func() {
+		httpErr <- serveHTTP(ctx, s, cs, addr)
+		cancel()
+	}()
+
+	stdioErr := cs.serveStdio(ctx, s)
+	cancel()
+	if err := <-httpErr; err != nil {
+		return err
+	}
+	if errors.Is(stdioErr, context.Canceled) {
+		return nil
+	}
+	return stdioErr
+}
+
+// listenHTTP listens on a TCP address, or on the Unix domain socket named by
+// a unix:/path address. A socket file left behind by an earlier instance is
+// replaced; the listener removes it again when closed. A socket passed by
+// systemd socket activation is used instead of either.
+func listenHTTP(addr string) (net.Listener, error) {
+	if listener, err := activatedListener(); listener != nil || err != nil {
+		return listener, err
+	}
+	socketPath, ok := strings.CutPrefix(addr, unixAddrPrefix)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	if info, err := os.Lstat(socketPath); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(socketPath)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %v", err)
+	}
+	return listener, nil
+}
+
+// requireBearer rejects requests without the expected bearer token. An empty
+// token disables the check.
+func requireBearer(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// publicBaseURL is the base of the file URLs handed to clients. A Unix socket
+// has no URL a client could fetch from, so it needs MCP_CLIP_PUBLIC_URL.
+func publicBaseURL(addr string) (string, error) {
+	if base := os.Getenv("MCP_CLIP_PUBLIC_URL"); base != "" {
+		return strings.TrimSuffix(base, "/"), nil
+	}
+	if strings.HasPrefix(addr, unixAddrPrefix) {
+		return "", fmt.Errorf("MCP_CLIP_SERVE_FILES=1 on a Unix socket needs MCP_CLIP_PUBLIC_URL set to the URL the socket is proxied at")
+	}
+	if strings.HasPrefix(addr, ":") {
+		addr = "localhost" + addr
+	}
+	return "http://" + addr, nil
+}
+
+func getFileURLTTL() time.Duration {
+	if ttlStr := os.Getenv("MCP_CLIP_FILE_URL_TTL"); ttlStr != "" {
+		if ttl, err := time.ParseDuration(ttlStr); err == nil && ttl > 0 {
+			return ttl
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Invalid MCP_CLIP_FILE_URL_TTL format '%s', using default: %v\n", ttlStr, DefaultFileURLTTL)
+		}
+	}
+	return DefaultFileURLTTL
+}
+
+// fileServer hands out unguessable, expiring URLs for overflow files so remote
+// clients that cannot read the server's temp directory can still download them.
+type fileServer struct {
+	baseURL   string
+	ttl       time.Duration
+	singleUse bool      // revoke a URL after its first download
+	audit     io.Writer // receives one JSON line per download attempt, may be nil
+
+	mu     sync.Mutex
+	tokens map[string]servedFile
+}
+
+type servedFile struct {
+	path      string
+	expires   time.Time
+	downloads int
+}
+
+// downloadRecord is a single entry of the download audit log.
+type downloadRecord struct {
+	Time   time.Time `json:"time"`
+	Token  string    `json:"token"`
+	File   string    `json:"file,omitempty"`
+	Remote string    `json:"remote"`
+	Method string    `json:"method"`
+	Status int       `json:"status"`
+	Bytes  int64     `json:"bytes"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+func newFileServer(baseURL string, ttl time.Duration) *fileServer {
+	return &fileServer{
+		baseURL: baseURL,
+		ttl:     ttl,
+		tokens:  make(map[string]servedFile),
+	}
+}
+
+// publish registers filePath and returns the URL it can be fetched from.
+func (fs *fileServer) publish(filePath string) (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate file token: %v", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	fs.mu.Lock()
+	fs.tokens[token] = servedFile{path: filePath, expires: time.Now().Add(fs.ttl)}
+	fs.mu.Unlock()
+
+	return fs.baseURL + filesPathPrefix + token, nil
+}
+
+// claim looks up token for a download. Single-use tokens are revoked by the
+// first GET so that concurrent requests cannot both be served.
+func (fs *fileServer) claim(token string, consume bool) (servedFile, string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	file, ok := fs.tokens[token]
+	switch {
+	case !ok:
+		return servedFile{}, "unknown or expired token"
+	case time.Now().After(file.expires):
+		delete(fs.tokens, token)
+		return servedFile{}, "expired token"
+	}
+
+	if consume {
+		file.downloads++
+		if fs.singleUse {
+			delete(fs.tokens, token)
+		} else {
+			fs.tokens[token] = file
+		}
+	}
+	return file, ""
+}
+
+// sweep forgets expired tokens and returns how many were removed.
+func (fs *fileServer) sweep(now time.Time) int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	removed := 0
+	for token, file := range fs.tokens {
+		if now.After(file.expires) {
+			delete(fs.tokens, token)
+			removed++
+		}
+	}
+	return removed
+}
+
+// collect periodically sweeps expired tokens until ctx is cancelled, so URLs
+// that are never fetched don't accumulate for the life of the server.
+func (fs *fileServer) collect(ctx context.Context) {
+	ticker := time.NewTicker(min(fs.ttl, maxFileSweepInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if removed := fs.sweep(now); removed > 0 && os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "Expired %d served file URL(s)\n", removed)
+			}
+		}
+	}
+}
+
+func (fs *fileServer) record(rec downloadRecord) {
+	if os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "File download %s %s from %s: %d (%d bytes) %s\n", rec.Method, rec.Token, rec.Remote, rec.Status, rec.Bytes, rec.Reason)
+	}
+	if fs.audit == nil {
+		return
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.audit.Write(append(line, '\n'))
+}
+
+// countingWriter tracks the status and body size of a response for auditing.
+type countingWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *countingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (fs *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, filesPathPrefix)
+	cw := &countingWriter{ResponseWriter: w, status: http.StatusOK}
+	rec := downloadRecord{Time: time.Now(), Token: previewToken(token), Remote: r.RemoteAddr, Method: r.Method}
+	defer func() {
+		rec.Status, rec.Bytes = cw.status, cw.bytes
+		fs.record(rec)
+	}()
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		rec.Reason = "method not allowed"
+		http.Error(cw, rec.Reason, http.StatusMethodNotAllowed)
+		return
+	}
+
+	file, reason := fs.claim(token, r.Method == http.MethodGet)
+	if reason != "" {
+		rec.Reason = reason
+		http.NotFound(cw, r)
+		return
+	}
+	rec.File = filepath.Base(file.path)
+
+	f, err := os.Open(file.path)
+	if err != nil {
+		rec.Reason = "file no longer exists"
+		http.NotFound(cw, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		rec.Reason = "failed to stat file"
+		http.Error(cw, rec.Reason, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", rec.File))
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeContent(cw, r, rec.File, info.ModTime(), f)
+}
+
+// previewToken shortens a token for logs so the audit trail can't be replayed.
+func previewToken(token string) string {
+	if len(token) > 8 {
+		return token[:8] + "…"
+	}
+	return token
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Test that published files are downloadable until their URL expires
+func TestFileServerPublishAndExpire(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "mcp-clip-test.txt")
+	if err := os.WriteFile(filePath, []byte("large clipboard text"), 0600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	fs := newFileServer("http://example.test", time.Hour)
+	url, err := fs.publish(filePath)
+	if err != nil {
+		t.Fatalf("Failed to publish file: %v", err)
+	}
+	if !strings.HasPrefix(url, "http://example.test/files/") {
+		t.Errorf("Unexpected URL: %s", url)
+	}
+	path := strings.TrimPrefix(url, "http://example.test")
+
+	rec := httptest.NewRecorder()
+	fs.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	body, _ := io.ReadAll(rec.Result().Body)
+	if rec.Code != http.StatusOK || string(body) != "large clipboard text" {
+		t.Errorf("Expected file content, got %d %q", rec.Code, body)
+	}
+
+	rec = httptest.NewRecorder()
+	fs.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files/not-a-token", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown token, got %d", rec.Code)
+	}
+
+	fs.ttl = -time.Second
+	url, _ = fs.publish(filePath)
+	rec = httptest.NewRecorder()
+	fs.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, strings.TrimPrefix(url, "http://example.test"), nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for expired token, got %d", rec.Code)
+	}
+}
+
+// Test bearer token enforcement
+func TestRequireBearer(t *testing.T) {
+	handler := requireBearer("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", rec.Code)
+	}
+
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 with token, got %d", rec.Code)
+	}
+}
+
+// Test that MCP over HTTP refuses network addresses without a token
+func TestCheckHTTPListen(t *testing.T) {
+	for addr, ok := range map[string]bool{
+		"127.0.0.1:8765":       true,
+		"[::1]:8765":           true,
+		"localhost:8765":       true,
+		"unix:/tmp/mcp.sock":   true,
+		":8765":                false,
+		"0.0.0.0:8765":         false,
+		"192.168.1.10:8765":    false,
+		"devbox.internal:8765": false,
+	} {
+		if err := checkHTTPListen(addr, ""); (err == nil) != ok {
+			t.Errorf("checkHTTPListen(%s) = %v", addr, err)
+		}
+		if err := checkHTTPListen(addr, "secret"); err != nil {
+			t.Errorf("checkHTTPListen(%s) with a token = %v", addr, err)
+		}
+	}
+
+	t.Setenv("MCP_CLIP_HTTP_TOKEN", "")
+	err := serveHTTP(context.Background(), server.NewMCPServer("test", "1.0.0"), NewClipboardServer(), ":0")
+	if err == nil || !strings.Contains(err.Error(), "MCP_CLIP_HTTP_TOKEN") {
+		t.Errorf("Expected serveHTTP to refuse :0 without a token, got %v", err)
+	}
+}
+
+// Test file URL bases, which a Unix socket can't provide on its own
+func TestPublicBaseURL(t *testing.T) {
+	t.Setenv("MCP_CLIP_PUBLIC_URL", "")
+	for addr, expected := range map[string]string{
+		":8765":          "http://localhost:8765",
+		"127.0.0.1:8765": "http://127.0.0.1:8765",
+	} {
+		if got, err := publicBaseURL(addr); err != nil || got != expected {
+			t.Errorf("Expected %s for %s, got %s (%v)", expected, addr, got, err)
+		}
+	}
+	if got, err := publicBaseURL("unix:/tmp/mcp.sock"); err == nil {
+		t.Errorf("Expected an error for a Unix socket without MCP_CLIP_PUBLIC_URL, got %s", got)
+	}
+
+	t.Setenv("MCP_CLIP_PUBLIC_URL", "https://clip.example.com/")
+	if got, err := publicBaseURL("unix:/tmp/mcp.sock"); err != nil || got != "https://clip.example.com" {
+		t.Errorf("Expected the public URL for a Unix socket, got %s (%v)", got, err)
+	}
+}
+
+// Test that single-use URLs are revoked after the first download but not by HEAD
+func TestFileServerSingleUse(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "mcp-clip-test.txt")
+	if err := os.WriteFile(filePath, []byte("once"), 0600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	fs := newFileServer("", time.Hour)
+	fs.singleUse = true
+	url, _ := fs.publish(filePath)
+
+	codes := []int{}
+	for _, method := range []string{http.MethodHead, http.MethodGet, http.MethodGet} {
+		rec := httptest.NewRecorder()
+		fs.ServeHTTP(rec, httptest.NewRequest(method, url, nil))
+		codes = append(codes, rec.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusNotFound {
+		t.Errorf("Expected HEAD 200, GET 200, GET 404, got %v", codes)
+	}
+}
+
+// Test that sweeping removes only expired tokens
+func TestFileServerSweep(t *testing.T) {
+	fs := newFileServer("", time.Hour)
+	fs.publish("/tmp/a")
+	fs.publish("/tmp/b")
+
+	if removed := fs.sweep(time.Now()); removed != 0 {
+		t.Errorf("Expected 0 tokens removed, got %d", removed)
+	}
+	if removed := fs.sweep(time.Now().Add(2 * time.Hour)); removed != 2 {
+		t.Errorf("Expected 2 tokens removed, got %d", removed)
+	}
+	if len(fs.tokens) != 0 {
+		t.Errorf("Expected no tokens left, got %d", len(fs.tokens))
+	}
+}
+
+// Test that downloads and rejected requests are written to the audit log
+func TestFileServerAudit(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "mcp-clip-test.txt")
+	if err := os.WriteFile(filePath, []byte("audited"), 0600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	var audit bytes.Buffer
+	fs := newFileServer("", time.Hour)
+	fs.audit = &audit
+	url, _ := fs.publish(filePath)
+
+	fs.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, url, nil))
+	fs.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/files/bogus", nil))
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 audit records, got %d", len(lines))
+	}
+
+	var served, rejected downloadRecord
+	json.Unmarshal([]byte(lines[0]), &served)
+	json.Unmarshal([]byte(lines[1]), &rejected)
+	if served.Status != http.StatusOK || served.Bytes != int64(len("audited")) || served.File != "mcp-clip-test.txt" {
+		t.Errorf("Unexpected download record: %+v", served)
+	}
+	if strings.Contains(lines[0], strings.TrimPrefix(url, filesPathPrefix)) {
+		t.Errorf("Expected audit log not to contain the full token")
+	}
+	if rejected.Status != http.StatusNotFound || rejected.Reason == "" {
+		t.Errorf("Unexpected rejection record: %+v", rejected)
+	}
+}
+
+// Test that unix: addresses listen on a private socket, replacing a stale one
+func TestListenHTTPUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "mcp-clip.sock")
+	for i := 0; i < 2; i++ {
+		listener, err := listenHTTP(unixAddrPrefix + socketPath)
+		if err != nil {
+			t.Fatalf("Failed to listen on socket: %v", err)
+		}
+		info, err := os.Stat(socketPath)
+		if err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Expected a 0600 socket, got %v %v", info, err)
+		}
+		// Leave the first socket file behind like a crashed instance would
+		if l, ok := listener.(*net.UnixListener); ok && i == 0 {
+			l.SetUnlinkOnClose(false)
+		}
+		listener.Close()
+	}
+}
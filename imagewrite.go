@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// psWriteImageScript places the base64 image read from stdin on the Windows
+// clipboard. Set-Clipboard only takes text in PowerShell 7, so this goes
+// through Windows Forms, whose SetImage also adds the DIB formats most
+// applications paste from.
+const psWriteImageScript = `Add-Type -AssemblyName System.Windows.Forms
+Add-Type -AssemblyName System.Drawing
+$bytes = [Convert]::FromBase64String([Console]::In.ReadToEnd())
+$stream = New-Object System.IO.MemoryStream(,$bytes)
+[System.Windows.Forms.Clipboard]::SetImage([System.Drawing.Image]::FromStream($stream))
+`
+
+// writeWindowsImage sets the Windows clipboard to an image through
+// PowerShell, from Windows or from WSL2.
+func writeWindowsImage(ctx context.Context, data []byte) error {
+	powershellPath := findPowerShell()
+	if powershellPath == "" {
+		return fmt.Errorf("PowerShell not found - required for Windows clipboard access")
+	}
+	cmd := powershellCommand(ctx, powershellPath, "-NoProfile", "-STA", "-Command", psWriteImageScript)
+	cmd.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString(data))
+	end := traceCommand(ctx, cmd)
+	output, err := cmd.CombinedOutput()
+	end(err)
+	if err != nil {
+		if ctx.Err() != nil {
+			return timeoutError(ctx, ctx.Err())
+		}
+		return fmt.Errorf("PowerShell clipboard image write failed: %v (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// writeMacImage sets the pasteboard to a PNG or JPEG image. AppleScript can
+// only read the image data from a file, so it is staged in a temp file.
+func writeMacImage(ctx context.Context, data []byte, mimeType string) error {
+	class := "«class PNGf»"
+	if mimeType == "image/jpeg" {
+		class = "JPEG picture"
+	}
+	file, err := os.CreateTemp("", FilenamePrefix+"write-*")
+	if err != nil {
+		return fmt.Errorf("failed to stage image: %v", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stage image: %v", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "osascript",
+		"-e", "on run argv",
+		"-e", "set the clipboard to (read (POSIX file (item 1 of argv)) as "+class+")",
+		"-e", "end run",
+		file.Name())
+	end := traceCommand(ctx, cmd)
+	output, err := cmd.CombinedOutput()
+	end(err)
+	if err != nil {
+		return fmt.Errorf("osascript clipboard image write failed: %v (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// writeImageHandler serves write_clipboard calls that pass an image, as
+// base64 (optionally a data: URL) or as the path of an image file.
+func (cs *ClipboardServer) writeImageHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	encoded, imagePath := request.GetString("image", ""), request.GetString("image_path", "")
+	if request.GetString("content", "") != "" || (encoded != "" && imagePath != "") {
+		return mcp.NewToolResultError("Pass only one of content, image or image_path"), nil
+	}
+	clearAfter, err := clearDelay(request, "clear_after_seconds")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	scratchAfter, err := scratchDelay(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	limit := getMaxClipboardBytes()
+
+	var data []byte
+	if imagePath != "" {
+		info, err := os.Stat(imagePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read image file: %v", err)), nil
+		}
+		if limit > 0 && info.Size() > int64(limit) {
+			return tooLargeResult(&oversizeError{size: int(info.Size()), limit: limit, exact: true}), nil
+		}
+		if data, err = os.ReadFile(imagePath); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read image file: %v", err)), nil
+		}
+	} else {
+		if _, payload, ok := strings.Cut(encoded, ";base64,"); ok && strings.HasPrefix(encoded, "data:") {
+			encoded = payload
+		}
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(strings.TrimSpace(encoded)); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("image is not valid base64: %v", err)), nil
+		}
+	}
+	if limit > 0 && len(data) > limit {
+		return tooLargeResult(&oversizeError{size: len(data), limit: limit, exact: true}), nil
+	}
+	mimeType, err := writableImageType(data)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if request.GetBool("skip_if_present", true) {
+		if current, err := readClipboard(ctx); err == nil && current == string(data) {
+			return cs.withScheduledClear(mcp.NewToolResultText(fmt.Sprintf("Clipboard already contains this image (%d bytes); not rewritten", len(data))), clearAfter, string(data)), nil
+		}
+	}
+
+	if denied := confirmOverwrite(ctx, fmt.Sprintf("write a %s image (%d bytes) to the clipboard", mimeType, len(data)), string(data)); denied != nil {
+		return denied, nil
+	}
+	var original string
+	if scratchAfter > 0 {
+		if original, err = cs.snapshotForScratch(ctx); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Scratch write refused: %v", err)), nil
+		}
+	}
+	if err := clipboardAccessor.writeImage(ctx, data, mimeType); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to write image to clipboard: %v", err)), nil
+	}
+	cs.recordChange(string(data))
+
+	result := cs.withScheduledClear(mcp.NewToolResultText(fmt.Sprintf("Wrote %s image (%d bytes) to the clipboard", mimeType, len(data))), clearAfter, string(data))
+	return cs.withScratchRestore(result, scratchAfter, original, string(data)), nil
+}
+
+// writableImageType returns the MIME type of PNG or JPEG data, the image
+// formats every platform can place on the clipboard.
+func writableImageType(data []byte) (string, error) {
+	switch _, imageType := detectImageType(data); imageType {
+	case "png", "jpg":
+		return imageMIMEType(imageType), nil
+	case "":
+		return "", fmt.Errorf("data is not a PNG or JPEG image")
+	default:
+		return "", fmt.Errorf("%s images cannot be written to the clipboard; convert to PNG or JPEG", imageType)
+	}
+}
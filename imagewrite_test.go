@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var testPNG = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0}
+
+// Test that only PNG and JPEG data can be written as images
+func TestWritableImageType(t *testing.T) {
+	if mimeType, err := writableImageType(testPNG); err != nil || mimeType != "image/png" {
+		t.Errorf("Expected image/png, got %q (%v)", mimeType, err)
+	}
+	if mimeType, err := writableImageType([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0, 0, 0}); err != nil || mimeType != "image/jpeg" {
+		t.Errorf("Expected image/jpeg, got %q (%v)", mimeType, err)
+	}
+	if _, err := writableImageType([]byte("GIF89a\x00\x00")); err == nil || !strings.Contains(err.Error(), "gif") {
+		t.Errorf("Expected GIF to be rejected, got %v", err)
+	}
+	if _, err := writableImageType([]byte("plain text")); err == nil {
+		t.Error("Expected text to be rejected")
+	}
+}
+
+// Test writing a base64 image through xclip with its MIME type
+func TestWriteImageHandler(t *testing.T) {
+	if !usesLinuxUtilities() || runtime.GOOS == "android" {
+		t.Skip("xclip is only used on Linux")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" > \"$0.args\"\ncat > \"$0.data\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("MCP_CLIP_BACKEND", "native")
+	t.Setenv("MCP_CLIP_LINUX_UTILITIES", "xclip")
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{
+		"image":           "data:image/png;base64," + base64.StdEncoding.EncodeToString(testPNG),
+		"skip_if_present": false,
+	}
+	result, err := NewClipboardServer().writeClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected the image to be written, got %v (%v)", result, err)
+	}
+	if args, _ := os.ReadFile(filepath.Join(dir, "xclip.args")); !strings.Contains(string(args), "-t image/png") {
+		t.Errorf("Expected xclip to offer image/png, got %q", args)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "xclip.data")); string(data) != string(testPNG) {
+		t.Errorf("Expected the decoded PNG on stdin, got %q", data)
+	}
+}
+
+// Test that text and an image can't be written together
+func TestWriteImageHandlerConflict(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"content": "text", "image_path": "/tmp/a.png"}
+	result, _ := NewClipboardServer().writeClipboardHandler(context.Background(), request)
+	if !result.IsError {
+		t.Error("Expected an error when both content and image_path are given")
+	}
+}
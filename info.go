@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// contentMIMEType describes clipboard content by MIME type, using the same
+// classification as the history.
+func contentMIMEType(content string) string {
+	kind, format := classifyContent(content)
+	switch {
+	case kind == "text" && isJSON(content):
+		return "application/json"
+	case kind == "text":
+		return "text/plain"
+	case kind == "image":
+		return imageMIMEType(format)
+	}
+	if mimeType, ok := binaryMIMETypes[format]; ok {
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
+// annotateMetadata adds read_clipboard's include_metadata fields to a
+// result: the MIME type, size and sha256 of the clipboard content (before
+// normalization, so they match clipboard_info and the history) and the
+// current change sequence. Encoding and language are annotated separately.
+func (cs *ClipboardServer) annotateMetadata(result *mcp.CallToolResult, content string) {
+	meta := resultMeta(result)
+	if _, ok := meta["mimeType"]; !ok {
+		meta["mimeType"] = contentMIMEType(content)
+	}
+	meta["size"] = len(content)
+	meta["sha256"] = contentHash(content)
+	meta["sequence"] = cs.history.latestID()
+}
+
+// clipboardInfoHandler describes the clipboard without returning its
+// content, so agents can decide whether a full read is worth the tokens.
+// With the monitor running it answers from the last observed state without
+// touching the clipboard; on demand it reads the clipboard once.
+func (cs *ClipboardServer) clipboardInfoHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	source := "monitor"
+	if content, _ := cs.getLastClipboard(); cs.onDemand || content == "" {
+		source = "read"
+		data, err := readClipboardData(ctx)
+		if err != nil {
+			return backendErrorResult(ctx, err), nil
+		}
+		if hint := cs.concealedHint(ctx, data.content); hint != "" {
+			return concealedResult(hint), nil
+		}
+		cs.recordChange(data.content)
+		if data.content == "" {
+			return emptyClipboardResult(ctx), nil
+		}
+	}
+
+	content, changedAt := cs.getLastClipboard()
+	kind, _ := classifyContent(content)
+	mimeType := contentMIMEType(content)
+	meta := map[string]any{
+		"kind":        kind,
+		"mimeType":    mimeType,
+		"size":        len(content),
+		"sha256":      contentHash(content),
+		"sequence":    cs.history.latestID(),
+		"lastChanged": changedAt.UTC().Format(time.RFC3339),
+		"source":      source,
+		"environment": environmentMeta(),
+	}
+	summary := fmt.Sprintf("Clipboard holds %s (%s), %d bytes", kind, mimeType, len(content))
+	if kind == "text" {
+		lines := len(splitLines(content))
+		meta["lines"] = lines
+		summary += fmt.Sprintf(", %d lines", lines)
+	}
+	summary += fmt.Sprintf(", last changed %s (sequence: %d, sha256: %s)", changedAt.Format("2006-01-02 15:04:05"), meta["sequence"], meta["sha256"])
+
+	result := mcp.NewToolResultText(summary)
+	result.Meta = mcp.NewMetaFromMap(meta)
+	return result, nil
+}
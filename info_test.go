@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test MIME types derived from clipboard content
+func TestContentMIMEType(t *testing.T) {
+	cases := map[string]string{
+		"hello":                    "text/plain",
+		`{"a": 1}`:                 "application/json",
+		string(testPNG):            "image/png",
+		"%PDF-1.7\x00\x01\x02\x03": "application/pdf",
+	}
+	for content, want := range cases {
+		if got := contentMIMEType(content); got != want {
+			t.Errorf("Expected %s for %q, got %s", want, content, got)
+		}
+	}
+}
+
+// Test that clipboard_info reports the monitored state without its content
+func TestClipboardInfoHandler(t *testing.T) {
+	cs := NewClipboardServer()
+	cs.updateClipboard("line one\nline two")
+
+	result, err := cs.clipboardInfoHandler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("Expected a result, got %v (%v)", result, err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; strings.Contains(text, "line one") {
+		t.Errorf("Expected no content in the result, got %q", text)
+	}
+	if result.Meta.AdditionalFields["size"] != 17 || result.Meta.AdditionalFields["lines"] != 2 || result.Meta.AdditionalFields["mimeType"] != "text/plain" {
+		t.Errorf("Expected 17 bytes of text/plain in 2 lines, got %v", result.Meta)
+	}
+	if result.Meta.AdditionalFields["sha256"] != contentHash("line one\nline two") || result.Meta.AdditionalFields["source"] != "monitor" {
+		t.Errorf("Expected the hash of the monitored content, got %v", result.Meta)
+	}
+	if result.Meta.AdditionalFields["sequence"] != uint64(1) {
+		t.Errorf("Expected sequence 1, got %v", result.Meta.AdditionalFields["sequence"])
+	}
+}
+
+// Test the fields include_metadata adds to a read result
+func TestAnnotateMetadata(t *testing.T) {
+	cs := NewClipboardServer()
+	cs.updateClipboard("first")
+	cs.updateClipboard("second")
+
+	result := mcp.NewToolResultText("Clipboard text content:\nsecond")
+	cs.annotateMetadata(result, "second")
+	if result.Meta.AdditionalFields["mimeType"] != "text/plain" || result.Meta.AdditionalFields["size"] != 6 || result.Meta.AdditionalFields["sequence"] != uint64(2) {
+		t.Errorf("Expected text/plain, 6 bytes and sequence 2, got %v", result.Meta)
+	}
+	if result.Meta.AdditionalFields["sha256"] != contentHash("second") {
+		t.Errorf("Expected the content hash, got %v", result.Meta.AdditionalFields["sha256"])
+	}
+
+	// A MIME type set by the read, e.g. for JSON, is kept
+	result.Meta.AdditionalFields["mimeType"] = "application/json"
+	cs.annotateMetadata(result, "second")
+	if result.Meta.AdditionalFields["mimeType"] != "application/json" {
+		t.Errorf("Expected the existing mimeType to be kept, got %v", result.Meta.AdditionalFields["mimeType"])
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// installOptions are the command-line options of the install subcommand.
+type installOptions struct {
+	systemdUser bool     // install a systemd user unit
+	launchd     bool     // install a macOS LaunchAgent
+	client      string   // add mcp-clip to this MCP client's configuration
+	serverArgs  []string // with client, the flags the client starts mcp-clip with
+	socket      bool     // with systemdUser, start the server by socket activation
+	httpAddr    string   // address the installed server listens on
+	noEnable    bool     // write the service files without enabling them
+}
+
+func parseInstallOptions(args []string) (installOptions, error) {
+	var opts installOptions
+	fs := flag.NewFlagSet("install", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&opts.systemdUser, "systemd-user", false, "install a systemd user unit")
+	fs.BoolVar(&opts.launchd, "launchd", false, "install a macOS LaunchAgent")
+	fs.StringVar(&opts.client, "client", "", "add mcp-clip to this MCP client's configuration")
+	fs.BoolVar(&opts.socket, "socket", false, "start the server when its socket is first used")
+	fs.StringVar(&opts.httpAddr, "http", "", "address the installed server listens on")
+	fs.BoolVar(&opts.noEnable, "no-enable", false, "write the service files without enabling them")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, fmt.Errorf("Invalid arguments: %v", err)
+	}
+	opts.serverArgs = fs.Args()
+	targets := 0
+	for _, chosen := range []bool{opts.systemdUser, opts.launchd, opts.client != ""} {
+		if chosen {
+			targets++
+		}
+	}
+	switch {
+	case targets != 1:
+		return opts, fmt.Errorf("Choose one of --systemd-user, --launchd or --client")
+	case opts.socket && !opts.systemdUser:
+		return opts, fmt.Errorf("--socket requires --systemd-user")
+	case opts.client != "" && (opts.httpAddr != "" || opts.noEnable):
+		return opts, fmt.Errorf("--http and --no-enable apply to services; pass server flags for --client after --")
+	case opts.client == "" && len(opts.serverArgs) > 0:
+		return opts, fmt.Errorf("Unexpected arguments: %v", opts.serverArgs)
+	}
+	if opts.client != "" {
+		if _, ok := mcpClients[opts.client]; !ok {
+			return opts, fmt.Errorf("Unknown client %q; choose %s", opts.client, strings.Join(mcpClientNames(), ", "))
+		}
+		if _, err := parseServerOptions(opts.serverArgs); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// handleInstallCommand installs mcp-clip as a background service or into an
+// MCP client's configuration.
+func handleInstallCommand(args []string) {
+	opts, err := parseInstallOptions(args)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		printUsage()
+		os.Exit(1)
+	}
+	install := installSystemdUser
+	if opts.launchd {
+		install = installLaunchAgent
+	} else if opts.client != "" {
+		install = installClientConfig
+	}
+	if err := install(opts, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Install failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// installedExecutable returns the resolved path of the running binary, which
+// service definitions start.
+func installedExecutable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the mcp-clip executable: %v", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return exe, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const jsonIndent = "  "
+
+// isJSON reports whether text is a JSON object or array. Bare scalars such as
+// "42" or "true" are valid JSON too, but reporting them as such is just noise.
+func isJSON(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return false
+	}
+	return json.Valid([]byte(trimmed))
+}
+
+// prettyJSON re-indents JSON text, preserving key order and number formatting.
+func prettyJSON(text string) (string, error) {
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(strings.TrimSpace(text)), "", jsonIndent); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// annotateJSON marks a result as carrying JSON in its metadata.
+func annotateJSON(result *mcp.CallToolResult, pretty bool) {
+	meta := resultMeta(result)
+	meta["mimeType"] = "application/json"
+	meta["prettyPrinted"] = pretty
+}
@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+// Test JSON detection
+func TestIsJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{`{"a": 1}`, true},
+		{"  [1, 2, 3]\n", true},
+		{`{"a": }`, false},
+		{"42", false},
+		{`"just a string"`, false},
+		{"plain text", false},
+	}
+	for _, tt := range tests {
+		if got := isJSON(tt.input); got != tt.want {
+			t.Errorf("Expected isJSON(%q) = %v, got %v", tt.input, tt.want, got)
+		}
+	}
+}
+
+// Test that pretty-printing indents without reordering keys
+func TestPrettyJSON(t *testing.T) {
+	got, err := prettyJSON(`{"z":1,"a":[true,null],"n":1.50}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "{\n  \"z\": 1,\n  \"a\": [\n    true,\n    null\n  ],\n  \"n\": 1.50\n}"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
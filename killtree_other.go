@@ -0,0 +1,19 @@
+//go:build !unix
+
+package main
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// killTreeOnCancel kills cmd and its descendants with taskkill when cmd's
+// context ends.
+func killTreeOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+}
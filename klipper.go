@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	klipperService   = "org.kde.klipper"
+	klipperPath      = "/klipper"
+	klipperInterface = "org.kde.klipper.klipper"
+)
+
+// klipperBackend talks to KDE's clipboard manager over a D-Bus session bus
+// connection. Besides the current contents it exposes Klipper's own history
+// and change signal, so history is available immediately and changes are
+// seen without polling. Klipper's D-Bus API is text-only.
+type klipperBackend struct{}
+
+func (klipperBackend) Name() string { return "klipper" }
+
+func (klipperBackend) Read(ctx context.Context) (string, error) {
+	conn, err := klipperConnect()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return klipperCall(ctx, conn, "getClipboardContents")
+}
+
+// Write sets the clipboard over the bus rather than through a tool like
+// qdbus, which would take the content as a command-line argument, visible to
+// other users in the process list and limited in size.
+func (klipperBackend) Write(ctx context.Context, content string) error {
+	conn, err := klipperConnect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = klipperCall(ctx, conn, "setClipboardContents", content)
+	return err
+}
+
+// History returns up to n of Klipper's history items, newest first.
+func (klipperBackend) History(ctx context.Context, n int) ([]string, error) {
+	conn, err := klipperConnect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var items []string
+	for i := 0; i < n; i++ {
+		item, err := klipperCall(ctx, conn, "getClipboardHistoryItem", int32(i))
+		if err != nil {
+			return items, err
+		}
+		if item == "" {
+			break // past the end of the history
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// WatchChanges calls changed whenever Klipper announces a history update,
+// until ctx is done or the bus connection is lost.
+func (klipperBackend) WatchChanges(ctx context.Context, changed func()) error {
+	conn, err := klipperConnect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.AddMatchSignal(dbus.WithMatchInterface(klipperInterface), dbus.WithMatchMember("clipboardHistoryUpdated")); err != nil {
+		return fmt.Errorf("failed to subscribe to Klipper's change signal: %v", err)
+	}
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case signal, ok := <-signals:
+			if !ok {
+				return fmt.Errorf("D-Bus session bus connection closed")
+			}
+			// The bus also delivers its own NameAcquired signal
+			if signal.Name == klipperInterface+".clipboardHistoryUpdated" {
+				changed()
+			}
+		}
+	}
+}
+
+func klipperConnect() (*dbus.Conn, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the D-Bus session bus: %v", err)
+	}
+	return conn, nil
+}
+
+// klipperCall invokes a Klipper D-Bus method and returns its string result,
+// or "" for methods without one.
+func klipperCall(ctx context.Context, conn *dbus.Conn, method string, args ...any) (string, error) {
+	call := conn.Object(klipperService, klipperPath).CallWithContext(ctx, klipperInterface+"."+method, 0, args...)
+	if call.Err != nil {
+		if ctx.Err() != nil {
+			return "", timeoutError(ctx, ctx.Err())
+		}
+		return "", fmt.Errorf("klipper %s failed (is Klipper running?): %v", method, call.Err)
+	}
+	var result string
+	if len(call.Body) > 0 {
+		if err := call.Store(&result); err != nil {
+			return "", fmt.Errorf("klipper %s returned an unexpected reply: %v", method, err)
+		}
+	}
+	return result, nil
+}
+
+// importHistory seeds the history from the backend's clipboard manager, so
+// items copied before the server started are available right away.
+func (cs *ClipboardServer) importHistory(ctx context.Context, provider historyProvider) {
+	ctx, cancel := withReadTimeout(ctx)
+	defer cancel()
+	items, err := provider.History(ctx, getHistorySize())
+	if err != nil && os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Clipboard manager history import failed: %v\n", err)
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for i := len(items) - 1; i > 0; i-- {
+		cs.recordHistory(items[i], now)
+	}
+	// The newest item is the current clipboard
+	cs.updateClipboard(items[0])
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeKlipper answers Klipper's D-Bus methods from a fixed history and
+// records the content set through them.
+type fakeKlipper struct {
+	conn    *dbus.Conn
+	history []string
+	written chan string
+}
+
+func (k *fakeKlipper) GetClipboardContents() (string, *dbus.Error) {
+	return k.history[0], nil
+}
+
+func (k *fakeKlipper) GetClipboardHistoryItem(i int32) (string, *dbus.Error) {
+	if int(i) >= len(k.history) {
+		return "", nil
+	}
+	return k.history[i], nil
+}
+
+func (k *fakeKlipper) SetClipboardContents(content string) *dbus.Error {
+	k.written <- content
+	return nil
+}
+
+// fakeKlipperBus starts a private session bus and serves a fake Klipper on it.
+func fakeKlipperBus(t *testing.T) *fakeKlipper {
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not available")
+	}
+	cmd := exec.Command(daemon, "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("Failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read the bus address: %v", err)
+	}
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", strings.TrimSpace(address))
+
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	klipper := &fakeKlipper{
+		conn:    conn,
+		history: []string{"newest", "older\nline two\n", "oldest"},
+		written: make(chan string, 1),
+	}
+	methods := map[string]any{
+		"getClipboardContents":    klipper.GetClipboardContents,
+		"getClipboardHistoryItem": klipper.GetClipboardHistoryItem,
+		"setClipboardContents":    klipper.SetClipboardContents,
+	}
+	if err := conn.ExportMethodTable(methods, klipperPath, klipperInterface); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err := conn.RequestName(klipperService, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("Failed to own %s: %v", klipperService, err)
+	}
+	return klipper
+}
+
+// Test reading the current contents and history over the bus
+func TestKlipperBackend(t *testing.T) {
+	fakeKlipperBus(t)
+	backend := klipperBackend{}
+
+	if content, err := backend.Read(context.Background()); err != nil || content != "newest" {
+		t.Errorf("Expected 'newest', got %q (%v)", content, err)
+	}
+	items, err := backend.History(context.Background(), 10)
+	if err != nil || len(items) != 3 || items[1] != "older\nline two\n" {
+		t.Errorf("Expected 3 history items with a multi-line one, got %q (%v)", items, err)
+	}
+	if items, _ := backend.History(context.Background(), 2); len(items) != 2 {
+		t.Errorf("Expected history to be capped at 2, got %d", len(items))
+	}
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path="+filepath.Join(t.TempDir(), "missing"))
+	if err := backend.Write(context.Background(), "x"); err == nil {
+		t.Error("Expected the failing D-Bus call to be reported")
+	}
+	if _, err := backend.Read(context.Background()); err == nil {
+		t.Error("Expected the failing D-Bus read to be reported")
+	}
+}
+
+// Test that writes go over the session bus, not a command line
+func TestKlipperWrite(t *testing.T) {
+	klipper := fakeKlipperBus(t)
+	t.Setenv("PATH", t.TempDir())
+	content := "line one\n" + strings.Repeat("x", 200000)
+	if err := (klipperBackend{}).Write(context.Background(), content); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := <-klipper.written; got != content {
+		t.Errorf("Expected %d bytes to be written, got %d", len(content), len(got))
+	}
+}
+
+// Test that Klipper's history seeds ours, oldest first
+func TestImportHistory(t *testing.T) {
+	fakeKlipperBus(t)
+	cs := NewClipboardServer()
+	cs.importHistory(context.Background(), klipperBackend{})
+
+	entries := cs.history.recent(10)
+	if len(entries) != 3 || entries[0].Content != "newest" || entries[2].Content != "oldest" {
+		t.Errorf("Expected imported history newest first, got %v", entries)
+	}
+	if content, _ := cs.getLastClipboard(); content != "newest" {
+		t.Errorf("Expected the newest item to be the current clipboard, got %q", content)
+	}
+}
+
+// Test that Klipper's change signal is reported until the context ends
+func TestKlipperWatchChanges(t *testing.T) {
+	klipper := fakeKlipperBus(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{}, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- (klipperBackend{}).WatchChanges(ctx, func() { changes <- struct{}{} })
+	}()
+
+	// The subscription is in place once a signal gets through
+	deadline := time.After(5 * time.Second)
+	for received := false; !received; {
+		if err := klipper.conn.Emit(klipperPath, klipperInterface+".clipboardHistoryUpdated"); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-changes:
+			received = true
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("Expected Klipper's change signal to be reported")
+		}
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected the watch to end with the context, got %v", err)
+	}
+}
+
+// Test that MCP_CLIP_BACKEND forces a backend
+func TestSelectBackendOverride(t *testing.T) {
+	t.Setenv("MCP_CLIP_BACKEND", "Klipper")
+	if name := selectBackend().Name(); name != "klipper" {
+		t.Errorf("Expected klipper backend, got %s", name)
+	}
+	t.Setenv("MCP_CLIP_BACKEND", "bogus")
+	if name := selectBackend().Name(); name == "klipper" {
+		t.Errorf("Expected detection for an unknown backend, got %s", name)
+	}
+}
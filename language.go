@@ -0,0 +1,174 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	languageScanLimit = 16 * 1024 // only the head of large content is inspected
+	languageMinScore  = 2
+)
+
+// languageRule scores a language by how many of its signal patterns occur in
+// the text. Names are the identifiers Markdown code fences use.
+type languageRule struct {
+	name     string
+	patterns []*regexp.Regexp
+}
+
+var languageRules = []languageRule{
+	{"go", compileAll(
+		`(?m)^package \w+\s*$`,
+		`\bfunc (\([^)]*\) )?\w+\(`,
+		`\w+ := `,
+		`\bif err != nil\b`,
+		`(?m)^import \($`,
+		`\bfmt\.\w+\(`,
+	)},
+	{"python", compileAll(
+		`(?m)^\s*def \w+\(.*\)( -> .+)?:\s*$`,
+		`(?m)^\s*(from [\w.]+ )?import [\w., ]+$`,
+		`\bself\.\w+`,
+		`(?m)^\s*class \w+(\(.*\))?:\s*$`,
+		`(?m)^\s*(elif .+|else|try|except.*|finally):\s*$`,
+		`__name__ == ['"]__main__['"]`,
+		`\bprint\(`,
+	)},
+	{"javascript", compileAll(
+		`\b(const|let|var) \w+ = `,
+		`\bfunction\s*\w*\s*\(`,
+		`\) => `,
+		`\bconsole\.\w+\(`,
+		`\brequire\(['"]`,
+		`(?m)^(export|import) .+['"];?$`,
+		` === `,
+	)},
+	{"typescript", compileAll(
+		`\b(interface|type) \w+(<.+>)? (=|\{)`,
+		`\w+\??: (string|number|boolean|any|unknown|void)\b`,
+		`\): (string|number|boolean|void|Promise<.+>) \{`,
+		`\b(public|private|readonly) \w+: `,
+	)},
+	{"rust", compileAll(
+		`\bfn \w+(<.+>)?\(`,
+		`\blet mut \w+`,
+		`(?m)^\s*(pub )?(struct|enum|trait|impl)\b`,
+		`\b(println|vec|format)!\(`,
+		`(?m)^use \w+(::\w+)+`,
+		`&mut \w+`,
+	)},
+	{"java", compileAll(
+		`\bpublic (static )?(final )?(class|void|interface)\b`,
+		`System\.out\.print`,
+		`@Override\b`,
+		`(?m)^import java\.`,
+		`\b(private|protected) (static )?\w+(<.+>)? \w+( =|;)`,
+	)},
+	{"c", compileAll(
+		`(?m)^#include\s*[<"]`,
+		`\bint main\(`,
+		`\bprintf\(`,
+		`\b(malloc|free|sizeof)\(`,
+		`(?m)^#define \w+`,
+	)},
+	{"cpp", compileAll(
+		`\bstd::\w+`,
+		`\b(cout|cin|endl)\b`,
+		`\btemplate\s*<`,
+		`\bnullptr\b`,
+		`(?m)^#include <\w+>$`,
+	)},
+	{"sql", compileAll(
+		`(?i)\bselect\b[\s\S]+?\bfrom\b`,
+		`(?i)\b(insert into|delete from|update \w+ set)\b`,
+		`(?i)\b(create|alter|drop) (table|index|view|database)\b`,
+		`(?i)\b(where|inner join|left join|group by|order by)\b`,
+	)},
+	{"bash", compileAll(
+		`^#!/(usr/)?bin/(env )?(ba|z)?sh`,
+		`(?m)^\s*(sudo|apt(-get)?|brew|cd|echo|export|chmod|mkdir|curl|grep|git|npm|go|docker) `,
+		`\$\{?\w+\}?`,
+		`(?m)^\s*(fi|done|esac)\s*$`,
+		` (&&|\|\|) `,
+		`(?m)^\$ \S`,
+	)},
+	{"html", compileAll(
+		`(?i)<!DOCTYPE html`,
+		`(?i)<(html|head|body|div|span|p|a|ul|li|table|script)[\s>]`,
+		`</\w+>`,
+	)},
+	{"css", compileAll(
+		`(?m)^\s*([.#]?[\w-]+(:\w+)?[\s,]*)+\{\s*$`,
+		`(?m)^\s*(color|margin|padding|display|font-[\w-]+|background(-color)?|border|width|height): [^;]+;`,
+		`@media\b`,
+	)},
+	{"yaml", compileAll(
+		`(?m)^[\w-]+:\s*$`,
+		`(?m)^\s+- [\w"']`,
+		`(?m)^(\s+)?[\w-]+: [\w"'./-]+\s*$`,
+		`(?m)^---\s*$`,
+	)},
+}
+
+func compileAll(patterns ...string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		compiled[i] = regexp.MustCompile(p)
+	}
+	return compiled
+}
+
+// detectLanguage guesses the programming language of text, returning a
+// code-fence identifier or "" when no language is clearly indicated.
+func detectLanguage(text string) string {
+	if isJSON(text) {
+		return "json"
+	}
+	text = truncateBytes(text, languageScanLimit)
+	text = strings.TrimSpace(text)
+
+	scores := make(map[string]int, len(languageRules))
+	best, bestScore, tied := "", 0, false
+	for _, rule := range languageRules {
+		score := 0
+		for _, re := range rule.patterns {
+			if re.MatchString(text) {
+				score++
+			}
+		}
+		scores[rule.name] = score
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = rule.name, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+
+	// TypeScript and C++ are refinements of JavaScript and C
+	switch {
+	case best == "javascript" && scores["typescript"] > 0:
+		best, tied = "typescript", false
+	case best == "c" && scores["cpp"] > 0:
+		best, tied = "cpp", false
+	}
+
+	if bestScore < languageMinScore || tied {
+		return ""
+	}
+	return best
+}
+
+// annotateLanguage records the detected programming language, if any, in the
+// result metadata so clients can pick the right code fence.
+func annotateLanguage(result *mcp.CallToolResult, text string) {
+	language := detectLanguage(text)
+	if language == "" {
+		return
+	}
+	meta := resultMeta(result)
+	meta["language"] = language
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+// Test programming language detection on typical snippets
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"go", "package main\n\nfunc main() {\n\tx := 1\n\tif err != nil {\n\t\treturn\n\t}\n}", "go"},
+		{"python", "import os\n\ndef main(args):\n    self.value = 1\n    print(args)\n", "python"},
+		{"javascript", "const fs = require('fs');\nconst read = (p) => fs.readFileSync(p);\nconsole.log(read('x'));", "javascript"},
+		{"typescript", "interface User {\n  name: string;\n  age: number;\n}\nconst u: User = { name: 'a', age: 1 };", "typescript"},
+		{"rust", "use std::io;\n\nfn main() {\n    let mut x = 5;\n    println!(\"{}\", x);\n}", "rust"},
+		{"java", "import java.util.List;\n\npublic class Main {\n    @Override\n    public String toString() { return \"\"; }\n}", "java"},
+		{"c", "#include <stdio.h>\n\nint main() {\n    printf(\"hi\\n\");\n    return 0;\n}", "c"},
+		{"cpp", "#include <iostream>\n\nint main() {\n    std::cout << \"hi\" << std::endl;\n}", "cpp"},
+		{"sql", "SELECT id, name FROM users WHERE active = 1 ORDER BY name;", "sql"},
+		{"bash", "#!/bin/bash\nif [ -z \"$HOME\" ]; then\n  echo missing\nfi\n", "bash"},
+		{"html", "<!DOCTYPE html>\n<html><body><div>Hi</div></body></html>", "html"},
+		{"css", ".button {\n  color: red;\n  padding: 4px;\n}\n", "css"},
+		{"json", `{"name": "mcp-clip"}`, "json"},
+		{"prose", "Remember to buy milk and call the plumber tomorrow morning.", ""},
+		{"url", "https://example.com/path?q=1", ""},
+	}
+	for _, tt := range tests {
+		if got := detectLanguage(tt.input); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
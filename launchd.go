@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const launchAgentLabel = "com.standardbeagle.mcp-clip"
+
+const launchAgentTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Label</key>
+  <string>%[1]s</string>
+  <key>ProgramArguments</key>
+  <array>
+    <string>%[2]s</string>
+    <string>--http</string>
+    <string>%[3]s</string>
+  </array>
+  <key>RunAtLoad</key>
+  <true/>
+  <key>KeepAlive</key>
+  <true/>
+  <key>ThrottleInterval</key>
+  <integer>10</integer>
+  <key>StandardOutPath</key>
+  <string>%[4]s</string>
+  <key>StandardErrorPath</key>
+  <string>%[4]s</string>
+</dict>
+</plist>
+`
+
+// launchAgentPlist runs exe over HTTP at addr for as long as the user is
+// logged in, relaunching it whenever it exits and logging to logPath.
+func launchAgentPlist(exe, addr, logPath string) string {
+	return fmt.Sprintf(launchAgentTemplate, launchAgentLabel, xmlEscape(exe), xmlEscape(addr), xmlEscape(logPath))
+}
+
+// installLaunchAgent writes the LaunchAgent and, unless disabled, loads it
+// into the user's GUI session, replacing an agent loaded earlier.
+func installLaunchAgent(opts installOptions, out io.Writer) error {
+	exe, err := installedExecutable()
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to find the home directory: %v", err)
+	}
+	addr := opts.httpAddr
+	if addr == "" {
+		addr = DefaultServiceAddr
+	}
+
+	agentDir := filepath.Join(home, "Library", "LaunchAgents")
+	logDir := filepath.Join(home, "Library", "Logs")
+	for _, dir := range []string{agentDir, logDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %v", dir, err)
+		}
+	}
+	plistPath := filepath.Join(agentDir, launchAgentLabel+".plist")
+	logPath := filepath.Join(logDir, "mcp-clip.log")
+	if err := os.WriteFile(plistPath, []byte(launchAgentPlist(exe, addr, logPath)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", plistPath, err)
+	}
+	fmt.Fprintf(out, "Wrote %s (logging to %s)\n", plistPath, logPath)
+
+	domain := fmt.Sprintf("gui/%d", os.Getuid())
+	if opts.noEnable {
+		fmt.Fprintf(out, "Load it with: launchctl bootstrap %s %s\n", domain, plistPath)
+		return nil
+	}
+	// An agent that isn't loaded yet makes bootout fail, which is fine
+	exec.Command("launchctl", "bootout", domain+"/"+launchAgentLabel).Run()
+	if output, err := exec.Command("launchctl", "bootstrap", domain, plistPath).CombinedOutput(); err != nil {
+		return fmt.Errorf("launchctl bootstrap failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	fmt.Fprintf(out, "Loaded %s; MCP is served at http://%s/mcp\n", launchAgentLabel, addr)
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test the generated LaunchAgent
+func TestLaunchAgentPlist(t *testing.T) {
+	plist := launchAgentPlist("/Applications/Tools & Co/mcp-clip", "127.0.0.1:8765", "/Users/a/Library/Logs/mcp-clip.log")
+	for _, want := range []string{
+		"<string>/Applications/Tools &amp; Co/mcp-clip</string>",
+		"<string>--http</string>\n    <string>127.0.0.1:8765</string>",
+		"<key>KeepAlive</key>\n  <true/>",
+		"<key>StandardErrorPath</key>\n  <string>/Users/a/Library/Logs/mcp-clip.log</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("Expected the plist to contain %q, got:\n%s", want, plist)
+		}
+	}
+}
+
+// Test that --no-enable only writes the LaunchAgent
+func TestInstallLaunchAgentNoEnable(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	var out bytes.Buffer
+	if err := installLaunchAgent(installOptions{launchd: true, httpAddr: "127.0.0.1:9000", noEnable: true}, &out); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	plist, err := os.ReadFile(filepath.Join(home, "Library", "LaunchAgents", launchAgentLabel+".plist"))
+	if err != nil || !strings.Contains(string(plist), "<string>127.0.0.1:9000</string>") {
+		t.Errorf("Expected the agent to be written, got %q: %v", plist, err)
+	}
+	if !strings.Contains(out.String(), "launchctl bootstrap gui/") {
+		t.Errorf("Expected instructions to load the agent, got %q", out.String())
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// lineRange is read_clipboard's start_line/end_line selection: 1-based and
+// inclusive, with end 0 meaning the last line.
+type lineRange struct {
+	start, end int
+}
+
+// requestedLineRange returns the line range of a read, and whether one was
+// requested at all.
+func requestedLineRange(request mcp.CallToolRequest) (lineRange, bool, error) {
+	args := request.GetArguments()
+	_, hasStart := args["start_line"]
+	_, hasEnd := args["end_line"]
+	if !hasStart && !hasEnd {
+		return lineRange{}, false, nil
+	}
+	lines := lineRange{start: request.GetInt("start_line", 1), end: request.GetInt("end_line", 0)}
+	switch {
+	case lines.start < 1:
+		return lines, true, fmt.Errorf("start_line must be at least 1")
+	case hasEnd && lines.end < lines.start:
+		return lines, true, fmt.Errorf("end_line must not be before start_line")
+	}
+	return lines, true, nil
+}
+
+// selectLines cuts the requested lines out of text, keeping their line
+// endings. Lines are counted as clipboard_info counts them. The range's end
+// is clamped to the last line; a start past it is an error that reports how
+// many lines there are.
+func selectLines(text string, lines lineRange) (string, map[string]any, error) {
+	all := strings.SplitAfter(text, "\n")
+	if len(all) > 1 && all[len(all)-1] == "" {
+		// A trailing line break ends the last line rather than starting one
+		all = all[:len(all)-1]
+	}
+	total := len(all)
+	if lines.start > total {
+		return "", nil, fmt.Errorf("start_line %d is past the end of the clipboard text (%d lines)", lines.start, total)
+	}
+	end := total
+	if lines.end > 0 {
+		end = min(lines.end, total)
+	}
+	info := map[string]any{"start": lines.start, "end": end, "total": total}
+	return strings.Join(all[lines.start-1:end], ""), info, nil
+}
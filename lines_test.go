@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test selecting line ranges from text
+func TestSelectLines(t *testing.T) {
+	text := "one\r\ntwo\nthree\nfour"
+	tests := []struct {
+		lines lineRange
+		want  string
+	}{
+		{lineRange{1, 1}, "one\r\n"},
+		{lineRange{2, 3}, "two\nthree\n"},
+		{lineRange{3, 0}, "three\nfour"},
+		{lineRange{4, 100}, "four"},
+	}
+	for _, tt := range tests {
+		got, info, err := selectLines(text, tt.lines)
+		if err != nil || got != tt.want {
+			t.Errorf("%+v: expected %q, got %q (%v)", tt.lines, tt.want, got, err)
+		}
+		if info["total"] != 4 {
+			t.Errorf("%+v: expected 4 lines in total, got %v", tt.lines, info)
+		}
+	}
+	if _, _, err := selectLines(text, lineRange{5, 0}); err == nil || !strings.Contains(err.Error(), "4 lines") {
+		t.Errorf("Expected a start past the end to be rejected, got %v", err)
+	}
+
+	got, info, err := selectLines("line1\nline2\nline3\n", lineRange{3, 0})
+	if err != nil || got != "line3\n" || info["total"] != 3 || info["end"] != 3 {
+		t.Errorf("Expected a trailing newline not to count as a line, got %q %v (%v)", got, info, err)
+	}
+	if _, _, err := selectLines("line1\nline2\nline3\n", lineRange{4, 0}); err == nil {
+		t.Error("Expected the empty line after a trailing newline not to be selectable")
+	}
+}
+
+// Test read_clipboard's start_line and end_line
+func TestReadClipboardLineRange(t *testing.T) {
+	log := strings.Repeat("line "+strings.Repeat("x", 40)+"\n", 999) + "last line"
+	fakeClipboard(t, "first\nsecond\nthird\n"+log)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"start_line": float64(2), "end_line": float64(3), "format": "text"}
+	result, err := NewClipboardServer().readClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "second\nthird\n" {
+		t.Errorf("Expected lines 2-3, got %q", text)
+	}
+	if lines := result.Meta.AdditionalFields["lines"].(map[string]any); lines["end"] != 3 || lines["total"] != 1003 {
+		t.Errorf("Unexpected line metadata: %v", lines)
+	}
+
+	for _, args := range []map[string]any{
+		{"start_line": float64(0)},
+		{"start_line": float64(3), "end_line": float64(2)},
+		{"start_line": float64(2000)},
+		{"start_line": float64(1), "since_length": float64(0)},
+	} {
+		request.Params.Arguments = args
+		if result, _ := NewClipboardServer().readClipboardHandler(context.Background(), request); !result.IsError {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// linuxUtility is a command-line clipboard tool for Linux desktops.
+type linuxUtility struct {
+	name  string
+	read  []string
+	write []string
+}
+
+// linuxUtilities are the utilities that can be named in MCP_CLIP_LINUX_UTILITIES.
+// The arguments match those atotto/clipboard uses.
+var linuxUtilities = []linuxUtility{
+	{"wl-paste", []string{"wl-paste", "--no-newline"}, []string{"wl-copy"}},
+	{"xclip", []string{"xclip", "-out", "-selection", "clipboard"}, []string{"xclip", "-in", "-selection", "clipboard"}},
+	{"xsel", []string{"xsel", "--output", "--clipboard"}, []string{"xsel", "--input", "--clipboard"}},
+	{"termux", []string{"termux-clipboard-get"}, []string{"termux-clipboard-set"}},
+}
+
+func findLinuxUtility(name string) (linuxUtility, bool) {
+	for _, utility := range linuxUtilities {
+		if utility.name == name {
+			return utility, true
+		}
+	}
+	return linuxUtility{}, false
+}
+
+// getLinuxUtilityChain returns the utilities to try, in order. The default
+// is wl-paste (on Wayland), xclip, xsel, then Termux; MCP_CLIP_LINUX_UTILITIES
+// overrides it with a comma-separated list such as "xsel,xclip".
+func getLinuxUtilityChain() []linuxUtility {
+	names := []string{"wl-paste", "xclip", "xsel", "termux"}
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		names = names[1:]
+	}
+	if configured := os.Getenv("MCP_CLIP_LINUX_UTILITIES"); configured != "" {
+		names = strings.Split(configured, ",")
+	}
+
+	var chain []linuxUtility
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if utility, ok := findLinuxUtility(name); ok {
+			utility.read, utility.write = withWaylandSeat(utility.read), withWaylandSeat(utility.write)
+			chain = append(chain, utility)
+		}
+	}
+	return chain
+}
+
+// utilityNames renders a chain as "xclip, xsel".
+func utilityNames(chain []linuxUtility) string {
+	var names []string
+	for _, utility := range chain {
+		names = append(names, utility.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// warnLinuxUtilities reports MCP_CLIP_LINUX_UTILITIES entries that can't be used.
+func warnLinuxUtilities() {
+	configured := os.Getenv("MCP_CLIP_LINUX_UTILITIES")
+	if configured == "" {
+		return
+	}
+	for _, name := range strings.Split(configured, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch _, ok := findLinuxUtility(name); {
+		case ok || name == "":
+		case name == "xdotool":
+			fmt.Fprintf(os.Stderr, "Ignoring xdotool in MCP_CLIP_LINUX_UTILITIES: it can type text but cannot read or set the clipboard\n")
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown clipboard utility '%s' in MCP_CLIP_LINUX_UTILITIES (known: wl-paste, xclip, xsel, termux)\n", name)
+		}
+	}
+}
+
+// readLinuxClipboard tries each installed utility in the chain until one
+// succeeds, returning its output and name. Oversized content and timeouts
+// end the chain, since another utility would fare no better.
+func readLinuxClipboard(ctx context.Context, limit int) (string, string, error) {
+	var failures []string
+	for _, utility := range getLinuxUtilityChain() {
+		if _, err := exec.LookPath(utility.read[0]); err != nil {
+			continue
+		}
+		output, err := runLimited(ctx, exec.CommandContext(ctx, utility.read[0], utility.read[1:]...), limit)
+		if err == nil {
+			return string(output), utility.name, nil
+		}
+		if _, ok := asOversize(err); ok {
+			return "", utility.name, err
+		}
+		if ctx.Err() != nil {
+			return "", utility.name, timeoutError(ctx, ctx.Err())
+		}
+		message := err.Error()
+		if exitErr := (*exec.ExitError)(nil); errors.As(err, &exitErr) {
+			message += " " + string(exitErr.Stderr)
+		}
+		if isEmptySelectionMessage(message) {
+			// Every utility reads the same selection
+			return "", utility.name, &emptySelectionError{utility: utility.name, err: err}
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", utility.name, err))
+	}
+	return "", "", linuxChainError(failures)
+}
+
+// emptySelectionMessages are what the utilities print when the clipboard
+// holds no text: xclip when the owner offers no STRING target, wl-paste when
+// nothing (or nothing textual) is copied.
+var emptySelectionMessages = []string{
+	"target string not available",
+	"target utf8_string not available",
+	"nothing is copied",
+	"no selection",
+	"no suitable type of content copied",
+}
+
+// isEmptySelectionMessage reports whether a utility's error says the
+// clipboard holds no text.
+func isEmptySelectionMessage(message string) bool {
+	message = strings.ToLower(message)
+	for _, empty := range emptySelectionMessages {
+		if strings.Contains(message, empty) {
+			return true
+		}
+	}
+	return false
+}
+
+// emptySelectionError is a read that failed because the clipboard holds no
+// text. It is final: retrying returns the same until something is copied.
+type emptySelectionError struct {
+	utility string
+	err     error
+}
+
+func (e *emptySelectionError) Error() string {
+	return fmt.Sprintf("%s: the clipboard holds no text: %v", e.utility, e.err)
+}
+
+func (e *emptySelectionError) Unwrap() error { return e.err }
+
+// writeLinuxClipboard sets the clipboard with the first utility in the chain
+// that succeeds.
+func writeLinuxClipboard(ctx context.Context, content string) error {
+	var failures []string
+	for _, utility := range getLinuxUtilityChain() {
+		if _, err := exec.LookPath(utility.write[0]); err != nil {
+			continue
+		}
+		// xclip and wl-copy fork a process that serves the selection and
+		// keeps any output pipes open, so output is not captured
+		cmd := exec.CommandContext(ctx, utility.write[0], utility.write[1:]...)
+		cmd.Stdin = strings.NewReader(content)
+		end := traceCommand(ctx, cmd)
+		err := cmd.Run()
+		end(err)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return timeoutError(ctx, ctx.Err())
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", utility.name, err))
+	}
+	return linuxChainError(failures)
+}
+
+// writeLinuxImage sets the clipboard to an image with the first utility in
+// the chain that can offer a MIME type: wl-copy or xclip. xsel only handles
+// text.
+func writeLinuxImage(ctx context.Context, data []byte, mimeType string) error {
+	var failures []string
+	for _, utility := range getLinuxUtilityChain() {
+		var args []string
+		switch utility.name {
+		case "wl-paste":
+			args = withWaylandSeat([]string{"wl-copy", "--type", mimeType})
+		case "xclip":
+			args = []string{"xclip", "-in", "-selection", "clipboard", "-t", mimeType}
+		default:
+			continue
+		}
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Stdin = bytes.NewReader(data)
+		end := traceCommand(ctx, cmd)
+		err := cmd.Run()
+		end(err)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return timeoutError(ctx, ctx.Err())
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", utility.name, err))
+	}
+	if len(failures) == 0 {
+		return fmt.Errorf("no clipboard utility that can write images available: install wl-clipboard or xclip")
+	}
+	return linuxChainError(failures)
+}
+
+func linuxChainError(failures []string) error {
+	if len(failures) == 0 {
+		return fmt.Errorf("no clipboard utilities available: install wl-clipboard, xclip or xsel (or adjust MCP_CLIP_LINUX_UTILITIES)")
+	}
+	return fmt.Errorf("every clipboard utility failed: %s", strings.Join(failures, "; "))
+}
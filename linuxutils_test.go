@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Test the default utility order and MCP_CLIP_LINUX_UTILITIES overrides
+func TestLinuxUtilityChain(t *testing.T) {
+	t.Setenv("MCP_CLIP_LINUX_UTILITIES", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	if got := utilityNames(getLinuxUtilityChain()); got != "xclip, xsel, termux" {
+		t.Errorf("Expected X11 default chain, got %s", got)
+	}
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	if got := utilityNames(getLinuxUtilityChain()); got != "wl-paste, xclip, xsel, termux" {
+		t.Errorf("Expected Wayland default chain, got %s", got)
+	}
+
+	t.Setenv("MCP_CLIP_LINUX_UTILITIES", " XSel, xdotool,,bogus,xclip ")
+	if got := utilityNames(getLinuxUtilityChain()); got != "xsel, xclip" {
+		t.Errorf("Expected configured chain without unusable entries, got %s", got)
+	}
+}
+
+// Test that a failing utility falls through to the next one in the chain
+func TestReadLinuxClipboardFallsBack(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts not available")
+	}
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"xclip": "#!/bin/sh\necho \"Error: Can't open display\" >&2\nexit 1\n",
+		"xsel":  "#!/bin/sh\nprintf 'from xsel'\n",
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("MCP_CLIP_LINUX_UTILITIES", "wl-paste,xclip,xsel")
+
+	content, utility, err := readLinuxClipboard(context.Background(), 0)
+	if err != nil || content != "from xsel" || utility != "xsel" {
+		t.Errorf("Expected content from xsel, got %q from %q (%v)", content, utility, err)
+	}
+
+	t.Setenv("MCP_CLIP_LINUX_UTILITIES", "xclip")
+	if _, _, err := readLinuxClipboard(context.Background(), 0); err == nil || !strings.Contains(err.Error(), "xclip") {
+		t.Errorf("Expected the xclip failure to be reported, got %v", err)
+	}
+	t.Setenv("MCP_CLIP_LINUX_UTILITIES", "wl-paste")
+	if _, _, err := readLinuxClipboard(context.Background(), 0); err == nil || !strings.Contains(err.Error(), "no clipboard utilities") {
+		t.Errorf("Expected a missing-utility error, got %v", err)
+	}
+}
+
+// Test that an empty clipboard ends the chain with a final error
+func TestReadLinuxClipboardEmptySelection(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts not available")
+	}
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"xclip": "#!/bin/sh\necho \"Error: target STRING not available\" >&2\nexit 1\n",
+		"xsel":  "#!/bin/sh\nprintf 'stale'\n",
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("MCP_CLIP_LINUX_UTILITIES", "xclip,xsel")
+
+	for _, limit := range []int{0, 1024} {
+		content, utility, err := readLinuxClipboard(context.Background(), limit)
+		var empty *emptySelectionError
+		if !errors.As(err, &empty) || content != "" || utility != "xclip" {
+			t.Errorf("limit %d: expected an empty selection from xclip, got %q from %q (%v)", limit, content, utility, err)
+		}
+		if isTransientReadError(err) {
+			t.Errorf("limit %d: expected an empty clipboard not to be retried", limit)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// watchClipboardListener is only available on Windows.
+func watchClipboardListener(ctx context.Context, changed func()) error {
+	return fmt.Errorf("clipboard listener windows are only available on Windows")
+}
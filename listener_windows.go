@@ -0,0 +1,172 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32                            = windows.NewLazySystemDLL("user32.dll")
+	procRegisterClassExW              = user32.NewProc("RegisterClassExW")
+	procCreateWindowExW               = user32.NewProc("CreateWindowExW")
+	procDestroyWindow                 = user32.NewProc("DestroyWindow")
+	procDefWindowProcW                = user32.NewProc("DefWindowProcW")
+	procGetMessageW                   = user32.NewProc("GetMessageW")
+	procDispatchMessageW              = user32.NewProc("DispatchMessageW")
+	procPostMessageW                  = user32.NewProc("PostMessageW")
+	procPostQuitMessage               = user32.NewProc("PostQuitMessage")
+	procAddClipboardFormatListener    = user32.NewProc("AddClipboardFormatListener")
+	procRemoveClipboardFormatListener = user32.NewProc("RemoveClipboardFormatListener")
+)
+
+const (
+	wmDestroy         = 0x0002
+	wmClose           = 0x0010
+	wmClipboardUpdate = 0x031D
+	hwndMessage       = ^uintptr(2) // HWND_MESSAGE, (HWND)-3
+)
+
+type wndClassEx struct {
+	size       uint32
+	style      uint32
+	wndProc    uintptr
+	clsExtra   int32
+	wndExtra   int32
+	instance   windows.Handle
+	icon       windows.Handle
+	cursor     windows.Handle
+	background windows.Handle
+	menuName   *uint16
+	className  *uint16
+	iconSm     windows.Handle
+}
+
+type winMsg struct {
+	hwnd    uintptr
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	pt      struct{ x, y int32 }
+	private uint32
+}
+
+var (
+	listenerClassOnce sync.Once
+	listenerClassName *uint16
+	listenerClassErr  error
+
+	// listenerSignals maps each listener window to the channel its
+	// WM_CLIPBOARDUPDATE messages are forwarded to.
+	listenerSignals sync.Map
+)
+
+// registerListenerClass registers the window class shared by listener
+// windows. Callbacks can't be freed, so this happens once per process.
+func registerListenerClass() error {
+	listenerClassOnce.Do(func() {
+		listenerClassName, listenerClassErr = windows.UTF16PtrFromString("McpClipClipboardListener")
+		if listenerClassErr != nil {
+			return
+		}
+		class := wndClassEx{
+			wndProc:   windows.NewCallback(listenerWndProc),
+			className: listenerClassName,
+		}
+		class.size = uint32(unsafe.Sizeof(class))
+		if atom, _, err := procRegisterClassExW.Call(uintptr(unsafe.Pointer(&class))); atom == 0 {
+			listenerClassErr = fmt.Errorf("RegisterClassExW failed: %v", err)
+		}
+	})
+	return listenerClassErr
+}
+
+func listenerWndProc(hwnd, msg, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case wmClipboardUpdate:
+		if signal, ok := listenerSignals.Load(hwnd); ok {
+			// Coalesce bursts: one pending signal covers any number of updates
+			select {
+			case signal.(chan struct{}) <- struct{}{}:
+			default:
+			}
+		}
+		return 0
+	case wmClose:
+		procRemoveClipboardFormatListener.Call(hwnd)
+		procDestroyWindow.Call(hwnd)
+		return 0
+	case wmDestroy:
+		procPostQuitMessage.Call(0)
+		return 0
+	}
+	ret, _, _ := procDefWindowProcW.Call(hwnd, msg, wParam, lParam)
+	return ret
+}
+
+// watchClipboardListener creates a hidden message-only window registered
+// with AddClipboardFormatListener, so Windows tells us about every clipboard
+// change and nothing is polled. changed is called once at start, so the
+// current contents are recorded, and then after each change; it runs outside
+// the window's message loop, so slow reads don't hold up Windows.
+func watchClipboardListener(ctx context.Context, changed func()) error {
+	// A window's messages are delivered to the thread that created it
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := registerListenerClass(); err != nil {
+		return err
+	}
+	hwnd, _, err := procCreateWindowExW.Call(0, uintptr(unsafe.Pointer(listenerClassName)), 0, 0, 0, 0, 0, 0, hwndMessage, 0, 0, 0)
+	if hwnd == 0 {
+		return fmt.Errorf("CreateWindowExW failed: %v", err)
+	}
+	signal := make(chan struct{}, 1)
+	listenerSignals.Store(hwnd, signal)
+	defer listenerSignals.Delete(hwnd)
+
+	if ok, _, err := procAddClipboardFormatListener.Call(hwnd); ok == 0 {
+		procDestroyWindow.Call(hwnd)
+		return fmt.Errorf("AddClipboardFormatListener failed: %v", err)
+	}
+	stop := context.AfterFunc(ctx, func() { procPostMessageW.Call(hwnd, wmClose, 0, 0) })
+	defer stop()
+
+	signal <- struct{}{}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-signal:
+				changed()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var msg winMsg
+	for {
+		ret, _, err := procGetMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+		switch int32(ret) {
+		case 0: // WM_QUIT, after the window was closed
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("clipboard listener window closed")
+		case -1:
+			procRemoveClipboardFormatListener.Call(hwnd)
+			procDestroyWindow.Call(hwnd)
+			return fmt.Errorf("GetMessageW failed: %v", err)
+		}
+		procDispatchMessageW.Call(uintptr(unsafe.Pointer(&msg)))
+	}
+}
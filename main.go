@@ -1,13 +1,16 @@
 package main
 
 import (
+	"compress/gzip"
 	"context"
-	"crypto/md5"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"runtime"
@@ -17,16 +20,21 @@ import (
 	"sync/atomic"
 	"syscall"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
-	"github.com/atotto/clipboard"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 const (
-	DefaultCleanupTTL = 1 * time.Hour
-	FilenamePrefix    = "mcp-clip-"
-	MaxCASRetries     = 1000 // Maximum retries for compare-and-swap operations
+	DefaultCleanupTTL      = 1 * time.Hour
+	DefaultCleanupInterval = 10 * time.Minute
+	FilenamePrefix         = "mcp-clip-"
+	PartialSuffix          = ".partial" // files still being written, renamed once complete
+	MaxCASRetries          = 1000       // Maximum retries for compare-and-swap operations
+
+	MaxTempFileAttempts = 100 // Numbered names tried when a temp file name holds other content
 )
 
 type clipboardState struct {
@@ -40,11 +48,35 @@ type ClipboardServer struct {
 	cancel        atomic.Pointer[context.CancelFunc] // FIXED: Now uses atomic pointer
 	sessionFiles  []string                           // track files created during this session
 	filesMutex    sync.Mutex                         // protect sessionFiles slice
+	syncer        *syncManager                       // cross-machine sync, nil unless configured
+	history       *clipboardHistory                  // recent clipboard changes
+	changed       atomic.Pointer[chan struct{}]      // closed and replaced on every change
+	files         *fileServer                        // serves overflow files over HTTP, nil unless enabled
+	appendMutex   sync.Mutex                         // serializes append_to_clipboard read-modify-writes
+	snippets      *snippetStore                      // named snippets in the data dir
+	policy        *contentPolicy                     // withholds denied content, nil unless configured
+	pipelines     transformPipelines                 // named read_clipboard pipelines; see pipeline.go
+	onDemand      bool                               // no background monitor; see ondemand.go
+	readOnly      bool                               // only read/inspect tools; see readonly.go
+	privacy       bool                               // content only on explicit reads; see privacy.go
+	allowPaste    bool                               // offer paste_into_active_app; see paste.go
+	lastConceal   atomic.Value                       // stores concealCheck; see concealed.go
+	clear         pendingClear                       // scheduled clipboard wipe; see clear.go
+	scratch       scratchWrite                       // content to restore after a scratch write; see restore.go
+	subscriptions resourceSubscriptions              // resources/subscribe state; see subscriptions.go
+	watchMutex    sync.Mutex                         // protects watchers and stopWatch
+	watchers      int                                // callers waiting on an on-demand watch
+	stopWatch     context.CancelFunc                 // stops the on-demand watch poller
 }
 
 func NewClipboardServer() *ClipboardServer {
-	cs := &ClipboardServer{}
+	cs := &ClipboardServer{
+		history:  newClipboardHistory(getHistorySize()),
+		snippets: newSnippetStore(dataDir()),
+	}
 	cs.lastClipboard.Store(clipboardState{})
+	changed := make(chan struct{})
+	cs.changed.Store(&changed)
 	return cs
 }
 
@@ -56,7 +88,9 @@ func (cs *ClipboardServer) updateClipboard(content string) bool {
 	}
 
 	// Atomic compare-and-swap loop with retry limit for memory safety
-	for retries := 0; retries < MaxCASRetries; retries++ {
+	newState := clipboardState{content: content}
+	stored := false
+	for retries := 0; retries < MaxCASRetries && !stored; retries++ {
 		current := cs.lastClipboard.Load()
 		currentState, ok := current.(clipboardState)
 		if !ok {
@@ -69,26 +103,23 @@ func (cs *ClipboardServer) updateClipboard(content string) bool {
 			return false
 		}
 
-		newState := clipboardState{
-			content: content,
-			time:    time.Now(),
-		}
-
-		// Atomic compare-and-swap ensures no race condition
-		if cs.lastClipboard.CompareAndSwap(current, newState) {
-			return true
-		}
-		// If CAS failed, another goroutine updated the state, retry
+		// Atomic compare-and-swap ensures no race condition; if it fails,
+		// another goroutine updated the state, retry
+		newState.time = time.Now()
+		stored = cs.lastClipboard.CompareAndSwap(current, newState)
 	}
 
-	// Fallback to simple store if max retries exceeded (extremely unlikely)
-	if os.Getenv("MCP_DEBUG") == "1" {
-		fmt.Fprintf(os.Stderr, "Warning: CAS retry limit exceeded in updateClipboard, using fallback\n")
+	if !stored {
+		// Fallback to simple store if max retries exceeded (extremely unlikely)
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Warning: CAS retry limit exceeded in updateClipboard, using fallback\n")
+		}
+		newState.time = time.Now()
+		cs.lastClipboard.Store(newState)
 	}
-	cs.lastClipboard.Store(clipboardState{
-		content: content,
-		time:    time.Now(),
-	})
+	cs.recordHistory(content, newState.time)
+	cs.signalChange()
+	cs.subscriptions.notify()
 	return true
 }
 
@@ -110,30 +141,39 @@ func (cs *ClipboardServer) addSessionFile(filePath string) {
 }
 
 func (cs *ClipboardServer) cleanupSessionFiles() {
+	cs.removeSessionFiles()
+}
+
+// removeSessionFiles deletes every temp file this session created and stops
+// tracking them. It returns the removed paths and the error for each file
+// that could not be removed; files that were already gone count as removed.
+func (cs *ClipboardServer) removeSessionFiles() ([]string, map[string]error) {
 	cs.filesMutex.Lock()
 	files := make([]string, len(cs.sessionFiles))
 	copy(files, cs.sessionFiles)
 	cs.sessionFiles = nil
 	cs.filesMutex.Unlock()
 
-	var removed, errors int
+	var removed []string
+	failed := make(map[string]error)
 	for _, filePath := range files {
-		if err := os.Remove(filePath); err != nil {
-			errors++
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			failed[filePath] = err
 			if os.Getenv("MCP_DEBUG") == "1" {
 				fmt.Fprintf(os.Stderr, "Failed to remove session file %s: %v\n", filePath, err)
 			}
 		} else {
-			removed++
+			removed = append(removed, filePath)
 			if os.Getenv("MCP_DEBUG") == "1" {
 				fmt.Fprintf(os.Stderr, "Removed session file: %s\n", filePath)
 			}
 		}
 	}
 
-	if os.Getenv("MCP_DEBUG") == "1" && (removed > 0 || errors > 0) {
-		fmt.Fprintf(os.Stderr, "Session cleanup: %d removed, %d errors\n", removed, errors)
+	if os.Getenv("MCP_DEBUG") == "1" && (len(removed) > 0 || len(failed) > 0) {
+		fmt.Fprintf(os.Stderr, "Session cleanup: %d removed, %d errors\n", len(removed), len(failed))
 	}
+	return removed, failed
 }
 
 func (cs *ClipboardServer) stop() {
@@ -149,27 +189,48 @@ func (cs *ClipboardServer) stop() {
 }
 
 func main() {
+	applyDisplayConfig()
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "-h", "--help":
 			printUsage()
 			return
 		case "test":
+			if len(os.Args) > 2 && os.Args[2] == "--json" {
+				printTestReport()
+				return
+			}
 			handleTestCommand()
 			return
+		case "bridge":
+			handleBridgeCommand(os.Args[2:])
+			return
+		case "bench":
+			handleBenchCommand(os.Args[2:])
+			return
+		case "install":
+			handleInstallCommand(os.Args[2:])
+			return
+		case "service":
+			handleServiceCommand(os.Args[2:])
+			return
 		case "version":
 			fmt.Println("MCP Clipboard Server v1.0.0")
 			return
-		default:
-			if strings.HasPrefix(os.Args[1], "-") {
-				fmt.Printf("Unknown flag: %s\n", os.Args[1])
-				printUsage()
-				return
-			}
 		}
 	}
 
-	if isRunningFromCLI() {
+	opts, err := parseServerOptions(os.Args[1:])
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		printUsage()
+		return
+	}
+	if opts.detach {
+		detachConsole()
+	}
+
+	if opts.httpAddr == "" && isRunningFromCLI() {
 		fmt.Printf("MCP Clipboard Server v1.0.0\n")
 		fmt.Printf("This is an MCP (Model Context Protocol) server for clipboard access.\n")
 		fmt.Printf("It should be run by an MCP client, not directly from the command line.\n\n")
@@ -178,6 +239,17 @@ func main() {
 	}
 
 	clipboardServer := NewClipboardServer()
+	if clipboardServer.policy, err = loadContentPolicy(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid content policy: %v\n", err)
+		os.Exit(1)
+	}
+	if clipboardServer.pipelines, err = loadPipelines(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid pipelines: %v\n", err)
+		os.Exit(1)
+	}
+	clipboardServer.setReadOnly(opts.readOnly)
+	clipboardServer.setPrivacy(opts.privacy)
+	clipboardServer.allowPaste = opts.paste
 
 	// Cleanup orphaned temp files from previous instances on startup
 	if os.Getenv("MCP_DEBUG") == "1" {
@@ -190,45 +262,107 @@ func main() {
 		}
 	}
 
-	s := server.NewMCPServer(
-		"mcp-clip",
-		"1.0.0",
-		server.WithToolCapabilities(true),
-	)
+	shutdownTracing, err := initTracing(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Tracing disabled: %v\n", err)
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownTracing(ctx)
+	}()
 
-	readClipboardTool := mcp.NewTool("read_clipboard",
-		mcp.WithDescription("Read the current clipboard content, supporting text and images"),
-		mcp.WithString("format",
-			mcp.Description("Format to return clipboard content in: 'text', 'base64', or 'auto' (default)"),
-		),
-	)
+	// Middlewares wrap in order: the first registered sees every result last
+	mcpOptions := []server.ServerOption{
+		server.WithToolCapabilities(true),
+		server.WithPromptCapabilities(false),
+		server.WithResourceCapabilities(true, false),
+		server.WithElicitation(),
+		server.WithToolHandlerMiddleware(schemaVersionMiddleware),
+		server.WithToolHandlerMiddleware(tracingMiddleware),
+	}
+	if limiter := getRateLimiter(); limiter != nil {
+		mcpOptions = append(mcpOptions, server.WithToolHandlerMiddleware(limiter.middleware))
+	}
+	mcpOptions = append(mcpOptions, server.WithToolHandlerMiddleware(progressMiddleware))
+	s := server.NewMCPServer("mcp-clip", "1.0.0", mcpOptions...)
 
-	s.AddTool(readClipboardTool, clipboardServer.readClipboardHandler)
+	clipboardServer.registerTools(s)
+	s.EnableSampling()
+	clipboardServer.registerResources(s)
+	clipboardServer.registerPrompts(s)
 
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	clipboardServer.cancel.Store(&cancel)
 
+	// Expire temp files even when no new ones are being saved
+	go runPeriodicCleanup(ctx, getCleanupInterval())
+
+	warnExperiments()
+	warnBackend()
+	warnLinuxUtilities()
+
+	// Start opt-in cross-machine clipboard sync
+	if syncCfg := loadSyncConfig(); syncCfg.requested() {
+		if !experimentEnabled("sync") {
+			fmt.Fprintf(os.Stderr, "Clipboard sync disabled: it is experimental, add 'sync' to MCP_CLIP_EXPERIMENTS to enable it\n")
+		} else if clipboardServer.privacy {
+			fmt.Fprintf(os.Stderr, "Clipboard sync disabled: it sends clipboard content to peers, which privacy mode forbids\n")
+		} else if syncCfg.token == "" {
+			fmt.Fprintf(os.Stderr, "Clipboard sync disabled: MCP_CLIP_SYNC_TOKEN is required\n")
+		} else {
+			clipboardServer.syncer = newSyncManager(syncCfg, clipboardServer)
+			go func() {
+				if err := clipboardServer.syncer.run(ctx); err != nil {
+					fmt.Fprintf(os.Stderr, "Clipboard sync error: %v\n", err)
+				}
+			}()
+		}
+	}
+
 	// Handle shutdown signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigChan
+		clipboardServer.runPendingRestore()
+		clipboardServer.runPendingClear()
 		clipboardServer.stop()
 		cancel()
 	}()
 
-	// Start clipboard monitoring with context
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				fmt.Fprintf(os.Stderr, "Clipboard monitoring panic: %v\n", r)
+	// Start clipboard monitoring with context, unless running on demand only
+	clipboardServer.onDemand = opts.noMonitor
+	if clipboardServer.onDemand {
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Background clipboard monitoring disabled (--no-monitor)\n")
+		}
+	} else {
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Fprintf(os.Stderr, "Clipboard monitoring panic: %v\n", r)
+				}
+			}()
+			if provider, ok := selectBackend().(historyProvider); ok {
+				clipboardServer.importHistory(ctx, provider)
 			}
+			clipboardServer.startClipboardMonitoring(ctx)
 		}()
-		clipboardServer.startClipboardMonitoring(ctx)
-	}()
+	}
 
-	if err := server.ServeStdio(s); err != nil {
+	if opts.httpAddr != "" && opts.stdio {
+		err = clipboardServer.serveStdioAndHTTP(ctx, s, opts.httpAddr)
+	} else if opts.httpAddr != "" {
+		err = serveHTTP(ctx, s, clipboardServer, opts.httpAddr)
+	} else {
+		err = clipboardServer.serveStdio(ctx, s)
+	}
+	clipboardServer.runPendingRestore()
+	clipboardServer.runPendingClear()
+	if err != nil {
 		clipboardServer.stop()
 		fmt.Fprintf(os.Stderr, "Fatal MCP server error: %v\n", err)
 		os.Exit(1)
@@ -240,50 +374,278 @@ func (cs *ClipboardServer) readClipboardHandler(ctx context.Context, request mcp
 	if f := request.GetString("format", "auto"); f != "" {
 		format = f
 	}
-
-	content, err := readClipboard()
+	prefer := request.GetStringSlice("prefer", nil)
+	lines, hasLines, err := requestedLineRange(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	extraction, err := requestedExtraction(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	var pipeline []transformStep
+	if name := request.GetString("pipeline", ""); name != "" {
+		if pipeline, err = cs.pipelines.lookup(name); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	data, err := readClipboardData(ctx)
+	content, encoding := data.content, data.encoding
+	if cs.syncer != nil {
+		content, err = cs.syncer.fallback(content, err)
+	}
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to read clipboard: %v", err)), nil
+		return backendErrorResult(ctx, err), nil
+	}
+	concealed := cs.concealedHint(ctx, content)
+	if concealed != "" && !request.GetBool("allow_concealed", false) {
+		return concealedResult(concealed), nil
+	}
+	if cs.onDemand && concealed == "" {
+		// Without the monitor, history only learns about content tools read
+		cs.recordChange(content)
+	}
+	if format == "markdown" && len(prefer) == 0 {
+		if result, ok := cs.markdownResult(ctx); ok {
+			return result, nil
+		}
+		// Without an HTML flavor the plain text is returned unchanged
+		format = "text"
+	}
+
+	raw := content
+	if encoding != "" && request.GetBool("normalize", true) {
+		content = normalizeText(content)
 	}
 
 	if content == "" {
-		return mcp.NewToolResultText("Clipboard is empty"), nil
+		if request.GetBool("selection_fallback", false) {
+			return cs.selectionFallbackResult(ctx, format)
+		}
+		return emptyClipboardResult(ctx), nil
+	}
+
+	// Transformed reads return only part of the content, or a changed copy
+	// of it; the policy applies to the whole content all the same.
+	transformed := pipeline != nil || hasLines || extraction != nil
+	if transformed {
+		if denied := cs.policyResult(content); denied != nil {
+			return denied, nil
+		}
+	}
+	// Details of each transformation, reported in the result's _meta
+	transforms := map[string]any{}
+	if pipeline != nil {
+		// Escape sequences would make colored terminal output look binary
+		if !isProbablyText(stripANSI(content)) {
+			return mcp.NewToolResultError("pipeline only applies to text; the clipboard holds " + contentMIMEType(raw)), nil
+		}
+		before := len(content)
+		var steps []string
+		content, steps = runPipeline(content, pipeline)
+		transforms["pipeline"] = map[string]any{"name": request.GetString("pipeline", ""), "steps": steps, "before": before, "after": len(content)}
+		if content == "" {
+			result := mcp.NewToolResultText("The clipboard text is empty after the pipeline")
+			resultMeta(result)["pipeline"] = transforms["pipeline"]
+			return result, nil
+		}
+	}
+	if hasLines {
+		if !isProbablyText(content) {
+			return mcp.NewToolResultError("start_line and end_line only apply to text; the clipboard holds " + contentMIMEType(raw)), nil
+		}
+		var linesInfo map[string]any
+		if content, linesInfo, err = selectLines(content, lines); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		transforms["lines"] = linesInfo
+	}
+	if extraction != nil {
+		if !isProbablyText(content) {
+			return mcp.NewToolResultError("extract only applies to text; the clipboard holds " + contentMIMEType(raw)), nil
+		}
+		matches, capped := extraction.extract(content)
+		transforms["extract"] = map[string]any{"pattern": extraction.pattern.String(), "group": extraction.group, "matches": len(matches), "capped": capped}
+		if len(matches) == 0 {
+			result := mcp.NewToolResultText(fmt.Sprintf("No text on the clipboard matches %s", extraction.pattern))
+			maps.Copy(resultMeta(result), transforms)
+			return result, nil
+		}
+		content = strings.Join(matches, "\n")
+	}
+
+	if request.GetBool("preview", false) {
+		result := cs.previewResult(content, raw, request.GetInt("preview_bytes", DefaultPreviewBytes))
+		if !result.IsError {
+			maps.Copy(resultMeta(result), transforms)
+		}
+		return result, nil
+	}
+
+	if _, ok := request.GetArguments()["since_length"]; ok || request.GetString("since_hash", "") != "" {
+		if transformed {
+			return mcp.NewToolResultError("Delta reads can't be combined with pipeline, start_line, end_line or extract"), nil
+		}
+		if denied := cs.policyResult(content); denied != nil {
+			return denied, nil
+		}
+		return handleDeltaRead(ctx, content, request.GetInt("since_length", 0), request.GetString("since_hash", ""), cs)
+	}
+
+	var preferred clipboardFlavor
+	var preferMatched bool
+	if len(prefer) > 0 {
+		if preferred, preferMatched = preferredFlavor(ctx, content, prefer); isDerivedFlavor(preferred) {
+			result, err := cs.flavorResult(ctx, preferred)
+			if err == nil && !result.IsError {
+				annotatePreference(result, preferred, true)
+			}
+			return result, err
+		}
+		switch {
+		case preferMatched && strings.HasPrefix(preferred.mimeType, "text/"), preferred.mimeType == "application/json":
+			format = "text"
+		case preferMatched:
+			format = "auto"
+		case format == "markdown":
+			format = "text"
+		}
+	}
+
+	// Text converted from a foreign encoding is text regardless of heuristics
+	if encoding != "" && encoding != EncodingUTF8 && format == "auto" {
+		format = "text"
+	}
+
+	// Without a table the plain text is returned unchanged
+	var table *clipboardTable
+	tableAs := request.GetString("table_as", "markdown")
+	if format == "table" {
+		if tableAs != "markdown" && tableAs != "json" {
+			return mcp.NewToolResultError(fmt.Sprintf("Unknown table_as: %s. Use 'markdown' or 'json'", tableAs)), nil
+		}
+		if denied := cs.policyResult(content); denied != nil {
+			return denied, nil
+		}
+		if table = readClipboardTable(ctx, content); table != nil {
+			content = table.render(tableAs)
+		}
+		format = "text"
+	}
+
+	jsonText := table == nil && format != "base64" && isJSON(content)
+	pretty := jsonText && request.GetBool("pretty", false)
+	if pretty {
+		if indented, err := prettyJSON(content); err == nil {
+			content = indented
+		}
+	}
+
+	var result *mcp.CallToolResult
+	switch returnAs := request.GetString("return_as", "content"); returnAs {
+	case "content", "":
+		result, err = cs.contentResult(ctx, content, format)
+	case "resource":
+		if transformed || pretty || table != nil {
+			// The history entry holds the content as copied, not this result
+			raw = ""
+		}
+		result, err = cs.resourceResult(ctx, content, raw, format)
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Unknown return_as: %s. Use 'content' or 'resource'", returnAs)), nil
+	}
+	if err == nil && !result.IsError {
+		if encoding != "" {
+			annotateEncoding(result, encoding)
+		}
+		if jsonText {
+			annotateJSON(result, pretty)
+		}
+		if data.utility != "" {
+			meta := resultMeta(result)
+			meta["utility"] = data.utility
+		}
+		if format != "base64" && isProbablyText(content) {
+			annotateLanguage(result, content)
+		}
+		if table != nil {
+			annotateTable(result, table, tableAs)
+		} else if format != "base64" && !jsonText && isProbablyText(content) && resultMeta(result)["language"] == nil {
+			if mimeType, ok := detectDelimited(content); ok {
+				resultMeta(result)["delimited"] = mimeType // format "table" renders it
+			}
+		}
+		if table == nil && format != "base64" {
+			if urls := clipboardURLs(content); urls != nil {
+				annotateURLs(ctx, result, urls, request.GetBool("fetch_url_metadata", false))
+			}
+		}
+		if _, _, ok := findPair(cs.history.recent(2), getPairWindow()); ok {
+			meta := resultMeta(result)
+			meta["pairAvailable"] = true // read_clipboard_pair returns both
+		}
+		if request.GetBool("include_history_context", false) {
+			cs.appendHistoryContext(result, raw)
+		}
+		if request.GetBool("include_metadata", false) {
+			cs.annotateMetadata(result, raw)
+		}
+		if request.GetBool("include_file_contents", false) {
+			if paths := parseFileList(raw); len(paths) > 0 {
+				cs.appendFileContents(result, paths)
+			}
+		}
+		if len(prefer) > 0 {
+			annotatePreference(result, preferred, preferMatched)
+		}
+		if len(transforms) > 0 {
+			maps.Copy(resultMeta(result), transforms)
+		}
+		output := outputOf(result)
+		output.MIMEType, output.Size, output.Hash, output.Sequence = contentMIMEType(raw), len(raw), contentHash(raw), cs.history.latestID()
 	}
+	return result, err
+}
 
-	const maxDirectOutput = 25000
+// contentResult renders clipboard content in the requested format, spilling
+// large content to temp files.
+func (cs *ClipboardServer) contentResult(ctx context.Context, content, format string) (*mcp.CallToolResult, error) {
+	if denied := cs.policyResult(content); denied != nil {
+		return denied, nil
+	}
+	limits := getInlineThresholds()
 
 	switch format {
 	case "text":
-		if len(content) > maxDirectOutput {
-			filePath, err := saveToTempFile([]byte(content), "txt", cs)
+		if len(content) > limits.text {
+			spill, err := cs.spillToFile(ctx, textSource(content), "txt")
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Failed to save large content to temp file: %v", err)), nil
 			}
-			return mcp.NewToolResultText(fmt.Sprintf("Clipboard text content too large (%d bytes). Saved to: %s", len(content), filePath)), nil
+			return withSpill(mcp.NewToolResultText(fmt.Sprintf("Clipboard text content too large (%d bytes). Saved to: %s", len(content), spill)), spill), nil
 		}
-		return mcp.NewToolResultText(content), nil
+		return withOutputContent(mcp.NewToolResultText(content), content), nil
 	case "base64":
-		encoded := base64.StdEncoding.EncodeToString([]byte(content))
-		if len(encoded) > maxDirectOutput {
-			filePath, err := saveToTempFile([]byte(encoded), "b64", cs)
+		if encodedLen := base64.StdEncoding.EncodedLen(len(content)); encodedLen > limits.base64 {
+			spill, err := cs.spillToFile(ctx, base64Source(textSource(content)), "b64")
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Failed to save large base64 content to temp file: %v", err)), nil
 			}
-			return mcp.NewToolResultText(fmt.Sprintf("Base64 encoded clipboard content too large (%d bytes). Saved to: %s", len(encoded), filePath)), nil
+			return withSpill(mcp.NewToolResultText(fmt.Sprintf("Base64 encoded clipboard content too large (%d bytes). Saved to: %s", encodedLen, spill)), spill), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Base64 encoded clipboard content:\n%s", encoded)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Base64 encoded clipboard content:\n%s", encodeBase64(ctx, []byte(content)))), nil
 	case "auto":
 		if isProbablyText(content) {
-			if len(content) > maxDirectOutput {
-				filePath, err := saveToTempFile([]byte(content), "txt", cs)
+			if len(content) > limits.text {
+				spill, err := cs.spillToFile(ctx, textSource(content), "txt")
 				if err != nil {
 					return mcp.NewToolResultError(fmt.Sprintf("Failed to save large text content to temp file: %v", err)), nil
 				}
-				return mcp.NewToolResultText(fmt.Sprintf("Clipboard text content too large (%d bytes). Saved to: %s", len(content), filePath)), nil
+				return withSpill(mcp.NewToolResultText(fmt.Sprintf("Clipboard text content too large (%d bytes). Saved to: %s", len(content), spill)), spill), nil
 			}
-			return mcp.NewToolResultText(fmt.Sprintf("Clipboard text content:\n%s", content)), nil
+			return withOutputContent(mcp.NewToolResultText(fmt.Sprintf("Clipboard text content:\n%s", content)), content), nil
 		} else {
-			return handleBinaryContent([]byte(content), cs)
+			return handleBinaryContent(ctx, []byte(content), cs)
 		}
 	default:
 		return mcp.NewToolResultError(fmt.Sprintf("Unknown format: %s. Use 'text', 'base64', or 'auto'", format)), nil
@@ -297,117 +659,94 @@ func (cs *ClipboardServer) startClipboardMonitoring(ctx context.Context) {
 	}
 	defer atomic.StoreInt32(&cs.running, 0)
 
+	cs.watchClipboard(ctx)
+}
+
+// watchClipboard records clipboard changes until ctx is cancelled, using the
+// backend's change notifications where available and polling otherwise.
+func (cs *ClipboardServer) watchClipboard(ctx context.Context) {
+	if watcher, ok := selectBackend().(changeWatcher); ok {
+		err := watcher.WatchChanges(ctx, func() { cs.checkClipboard(ctx) })
+		if ctx.Err() != nil {
+			return // Graceful shutdown
+		}
+		if errors.Is(err, errPortalDenied) {
+			// Polling would only read through the same denied portal
+			if os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "Clipboard monitoring stopped: %v\n", err)
+			}
+			return
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Clipboard change notifications stopped, polling instead: %v\n", err)
+		}
+	}
+	cs.pollClipboard(ctx)
+}
+
+// pollClipboard records clipboard changes every 500ms until ctx is cancelled.
+// On native Windows a tick only reads the clipboard when its sequence number
+// moved, so a large clipboard isn't read in full twice a second.
+func (cs *ClipboardServer) pollClipboard(ctx context.Context) {
 	ticker := time.NewTicker(500 * time.Millisecond)
 	defer ticker.Stop()
 
+	_, native := selectBackend().(nativeBackend)
+	useSequence := native && runtime.GOOS == "windows"
+	var lastSequence uint32
 	for {
 		select {
 		case <-ctx.Done():
 			return // Graceful shutdown
 		case <-ticker.C:
-			content, err := readClipboard()
-			if err != nil {
-				// In debug mode, we could log this error
-				if os.Getenv("MCP_DEBUG") == "1" {
-					fmt.Fprintf(os.Stderr, "Clipboard read error: %v\n", err)
-				}
+			if !useSequence {
+				cs.checkClipboard(ctx)
 				continue
 			}
-
-			// Use lock-free update
-			cs.updateClipboard(content)
-		}
-	}
-}
-
-func readClipboard() (string, error) {
-	if isWSL2() {
-		data, err := readClipboardDataWSL2()
-		if err != nil {
-			return "", err
-		}
-		return string(data), nil
-	}
-	return clipboard.ReadAll()
-}
-
-func readClipboardDataWSL2() ([]byte, error) {
-	powershellPath := findPowerShell()
-	if powershellPath == "" {
-		return nil, fmt.Errorf("PowerShell not found - required for WSL2 clipboard access")
-	}
-
-	textCmd := exec.Command(powershellPath, "-Command", "Get-Clipboard -Raw")
-	textOutput, textErr := textCmd.Output()
-
-	if textErr == nil && len(textOutput) > 0 {
-		content := strings.TrimSpace(string(textOutput))
-		if content != "" {
-			return []byte(content), nil
-		}
-	}
-
-	imageCmd := exec.Command(powershellPath, "-Command", `
-		$image = Get-Clipboard -Format Image
-		if ($image -ne $null) {
-			$ms = New-Object System.IO.MemoryStream
-			$image.Save($ms, [System.Drawing.Imaging.ImageFormat]::Png)
-			[Convert]::ToBase64String($ms.ToArray())
-		}
-	`)
-	imageOutput, imageErr := imageCmd.Output()
-
-	if imageErr == nil && len(imageOutput) > 0 {
-		content := strings.TrimSpace(string(imageOutput))
-		if content != "" {
-			data, err := base64.StdEncoding.DecodeString(content)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode base64 image data: %v", err)
+			sequence, err := clipboardSequence()
+			if err == nil && sequence == lastSequence {
+				continue
+			}
+			// Remember the number only once its content was read, so a read
+			// that failed (another app holding the clipboard) is retried
+			if cs.checkClipboard(ctx) && err == nil {
+				lastSequence = sequence
 			}
-			return data, nil
 		}
 	}
-
-	return []byte{}, nil
 }
 
-func isWSL2() bool {
-	if runtime.GOOS != "linux" {
-		return false
-	}
-
-	if _, err := os.Stat("/proc/version"); err != nil {
-		return false
-	}
-
-	content, err := os.ReadFile("/proc/version")
+// checkClipboard reads the clipboard and records it if it changed. It
+// reports whether the clipboard could be read.
+func (cs *ClipboardServer) checkClipboard(ctx context.Context) bool {
+	defer func(start time.Time) { metrics.observePoll(time.Since(start)) }(time.Now())
+	content, err := readClipboard(ctx)
 	if err != nil {
+		// In debug mode, we could log this error
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Clipboard read error: %v\n", err)
+		}
 		return false
 	}
-
-	return strings.Contains(strings.ToLower(string(content)), "microsoft") ||
-		strings.Contains(strings.ToLower(string(content)), "wsl")
-}
-
-func findPowerShell() string {
-	powershellPaths := []string{
-		"/mnt/c/Windows/System32/WindowsPowerShell/v1.0/powershell.exe",
-		"/mnt/c/WINDOWS/System32/WindowsPowerShell/v1.0/powershell.exe",
-		"/mnt/c/windows/system32/windowspowershell/v1.0/powershell.exe",
+	if cs.concealedHint(ctx, content) != "" {
+		return true
 	}
 
-	for _, path := range powershellPaths {
-		if _, err := os.Stat(path); err == nil {
-			return path
-		}
-	}
+	// Use lock-free update
+	cs.recordChange(content)
+	return true
+}
 
-	cmd := exec.Command("which", "powershell.exe")
-	if output, err := cmd.Output(); err == nil {
-		return strings.TrimSpace(string(output))
-	}
+func readClipboard(ctx context.Context) (string, error) {
+	data, err := readClipboardData(ctx)
+	return data.content, err
+}
 
-	return ""
+// readClipboardData reads the clipboard, converting text in foreign encodings
+// to UTF-8, and reports the detected encoding ("" for binary data) and the
+// utility that served the read. Transient failures are retried.
+func readClipboardData(ctx context.Context) (clipboardRead, error) {
+	return readWithRetry(ctx, clipboardAccessor.read)
 }
 
 func getCleanupTTL() time.Duration {
@@ -426,38 +765,88 @@ func getCleanupTTL() time.Duration {
 	return DefaultCleanupTTL
 }
 
+// getCleanupInterval returns how often expired temp files are removed in
+// the background; 0 disables the timer.
+func getCleanupInterval() time.Duration {
+	if intervalStr := os.Getenv("MCP_CLEANUP_INTERVAL"); intervalStr != "" {
+		if interval, err := time.ParseDuration(intervalStr); err == nil && interval >= 0 {
+			return interval
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Invalid MCP_CLEANUP_INTERVAL format '%s', using default: %v\n", intervalStr, DefaultCleanupInterval)
+		}
+	}
+	return DefaultCleanupInterval
+}
+
+// runPeriodicCleanup removes expired temp files every interval until ctx is
+// done, so files don't outlive their TTL on days when nothing new is saved.
+func runPeriodicCleanup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := cleanupExpiredFiles(); err != nil && os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "Periodic cleanup warning: %v\n", err)
+			}
+		}
+	}
+}
+
 func cleanupExpiredFiles() error {
-	tempDir := os.TempDir()
-	ttl := getCleanupTTL()
-	cutoffTime := time.Now().Add(-ttl)
+	cutoffTime := time.Now().Add(-getCleanupTTL())
+	_, err := removeTempFiles(func(filePath string) bool {
+		return shouldRemoveFile(filePath, cutoffTime)
+	})
+	return err
+}
 
-	files, err := filepath.Glob(filepath.Join(tempDir, FilenamePrefix+"*"))
+// tempCleanup summarizes one removal pass over the temp files.
+type tempCleanup struct {
+	removed int
+	bytes   int64
+	errors  int
+}
+
+// removeTempFiles removes the mcp-clip temp files for which match returns true.
+func removeTempFiles(match func(filePath string) bool) (tempCleanup, error) {
+	var result tempCleanup
+	files, err := filepath.Glob(filepath.Join(os.TempDir(), FilenamePrefix+"*"))
 	if err != nil {
-		return fmt.Errorf("failed to list temp files: %v", err)
+		return result, fmt.Errorf("failed to list temp files: %v", err)
 	}
 
-	var removed, errors int
 	for _, filePath := range files {
-		if shouldRemoveFile(filePath, cutoffTime) {
-			if err := os.Remove(filePath); err != nil {
-				errors++
-				if os.Getenv("MCP_DEBUG") == "1" {
-					fmt.Fprintf(os.Stderr, "Failed to remove expired file %s: %v\n", filePath, err)
-				}
-			} else {
-				removed++
-				if os.Getenv("MCP_DEBUG") == "1" {
-					fmt.Fprintf(os.Stderr, "Removed expired file: %s\n", filePath)
-				}
+		if !match(filePath) {
+			continue
+		}
+		info, statErr := os.Lstat(filePath)
+		if err := os.Remove(filePath); err != nil {
+			result.errors++
+			if os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "Failed to remove temp file %s: %v\n", filePath, err)
 			}
+			continue
+		}
+		result.removed++
+		if statErr == nil {
+			result.bytes += info.Size()
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Removed temp file: %s\n", filePath)
 		}
 	}
 
-	if os.Getenv("MCP_DEBUG") == "1" && (removed > 0 || errors > 0) {
-		fmt.Fprintf(os.Stderr, "Cleanup complete: %d removed, %d errors\n", removed, errors)
+	if os.Getenv("MCP_DEBUG") == "1" && (result.removed > 0 || result.errors > 0) {
+		fmt.Fprintf(os.Stderr, "Cleanup complete: %d removed, %d errors\n", result.removed, result.errors)
 	}
-
-	return nil
+	return result, nil
 }
 
 func shouldRemoveFile(filePath string, cutoffTime time.Time) bool {
@@ -484,7 +873,20 @@ func shouldRemoveFile(filePath string, cutoffTime time.Time) bool {
 	return fileTime.Before(cutoffTime)
 }
 
-func saveToTempFile(data []byte, extension string, cs *ClipboardServer) (string, error) {
+// saveToTempFile writes data to a new temp file named after the time and the
+// sha256 of data, returning its path and the hex sha256.
+func saveToTempFile(data []byte, extension string, cs *ClipboardServer, track func(done int)) (string, string, error) {
+	return saveSourceToTempFile(bytesSource(data), extension, false, cs, track)
+}
+
+// saveSourceToTempFile streams src to a new temp file named after the time
+// and the sha256 of the content, returning its path and the hex sha256. The
+// content is written to a ".partial" file first and hashed on the way, then
+// renamed into place. A file that already has the name is reused only if it
+// holds the same content; otherwise a numbered name is tried. With compress,
+// the file is gzipped and named with a second ".gz" extension; the sha256 is
+// still that of the uncompressed content.
+func saveSourceToTempFile(src spillSource, extension string, compress bool, cs *ClipboardServer, track func(done int)) (string, string, error) {
 	// Clean up expired files before creating new ones
 	if err := cleanupExpiredFiles(); err != nil {
 		// Log error but don't fail - cleanup is best effort
@@ -493,33 +895,56 @@ func saveToTempFile(data []byte, extension string, cs *ClipboardServer) (string,
 		}
 	}
 
-	hash := md5.Sum(data)
+	if compress {
+		extension += ".gz"
+	}
 	timestamp := time.Now().Unix()
-	filename := fmt.Sprintf("mcp-clip-%d-%s.%s", timestamp, hex.EncodeToString(hash[:]), extension)
 	tempDir := os.TempDir()
-	filePath := filepath.Join(tempDir, filename)
-
-	// FIXED: Use atomic file creation with O_CREATE|O_EXCL to prevent TOCTOU race
-	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err := enforceTempQuota(tempDir, src.size, cs); err != nil {
+		return "", "", err
+	}
+	partialPath, hash, err := writePartialFile(tempDir, fmt.Sprintf("%s%d-*.%s", FilenamePrefix, timestamp, extension), src, compress, track)
 	if err != nil {
-		if os.IsExist(err) {
-			// File already exists, return the path
-			return filePath, nil
+		return "", "", fmt.Errorf("failed to write temp file (extension: %s, size: %d bytes, tempDir: %s): %v",
+			extension, src.size, tempDir, err)
+	}
+
+	var filePath string
+	for attempt := 0; ; attempt++ {
+		filename := fmt.Sprintf("%s%d-%s.%s", FilenamePrefix, timestamp, hash, extension)
+		if attempt > 0 {
+			filename = fmt.Sprintf("%s%d-%s-%d.%s", FilenamePrefix, timestamp, hash, attempt, extension)
+		}
+		filePath = filepath.Join(tempDir, filename)
+
+		if _, err := os.Lstat(filePath); os.IsNotExist(err) {
+			break
+		}
+		if fileHasContent(filePath, src.size, hash) {
+			os.Remove(partialPath)
+			return filePath, hash, nil
+		}
+		if attempt >= MaxTempFileAttempts {
+			os.Remove(partialPath)
+			return "", "", fmt.Errorf("failed to create temp file %s (extension: %s, size: %d bytes, tempDir: %s): name in use",
+				filePath, extension, src.size, tempDir)
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Temp file %s exists with different content, trying another name\n", filePath)
 		}
-		return "", fmt.Errorf("failed to create temp file %s (extension: %s, size: %d bytes, tempDir: %s): %v",
-			filePath, extension, len(data), tempDir, err)
 	}
-	defer file.Close()
 
-	if _, err := file.Write(data); err != nil {
-		// Clean up partially created file
-		os.Remove(filePath)
-		return "", fmt.Errorf("failed to write temp file %s (extension: %s, size: %d bytes): %v",
-			filePath, extension, len(data), err)
+	// Concurrent writers of the same name hold the same content, so whichever
+	// rename lands last is harmless.
+	if err := os.Rename(partialPath, filePath); err != nil {
+		os.Remove(partialPath)
+		return "", "", fmt.Errorf("failed to write temp file %s (extension: %s, size: %d bytes): %v",
+			filePath, extension, src.size, err)
 	}
 
+	metrics.tempFileWritten(src.size)
 	if os.Getenv("MCP_DEBUG") == "1" {
-		fmt.Fprintf(os.Stderr, "Created temp file: %s (%d bytes)\n", filePath, len(data))
+		fmt.Fprintf(os.Stderr, "Created temp file: %s (%d bytes)\n", filePath, src.size)
 	}
 
 	// Track file for session cleanup if server instance provided
@@ -527,32 +952,155 @@ func saveToTempFile(data []byte, extension string, cs *ClipboardServer) (string,
 		cs.addSessionFile(filePath)
 	}
 
-	return filePath, nil
+	return filePath, hash, nil
 }
 
-func handleBinaryContent(data []byte, cs *ClipboardServer) (*mcp.CallToolResult, error) {
+// writePartialFile streams src to a new ".partial" file in dir, gzipping it
+// with compress, so a crash or a failed write never leaves a truncated file
+// under a final name for a client to read. It returns the file's path and the
+// hex sha256 of src.
+func writePartialFile(dir, pattern string, src spillSource, compress bool, track func(done int)) (string, string, error) {
+	file, err := os.CreateTemp(dir, pattern+PartialSuffix)
+	if err != nil {
+		return "", "", err
+	}
+	var out io.Writer = file
+	var compressor *gzip.Writer
+	if compress {
+		compressor = gzip.NewWriter(file)
+		out = compressor
+	}
+	hasher := sha256.New()
+	w := &progressWriter{w: io.MultiWriter(out, hasher), track: track}
+	err = src.write(w)
+	if err == nil && w.written != src.size {
+		err = fmt.Errorf("wrote %d bytes, expected %d", w.written, src.size)
+	}
+	if err == nil && compressor != nil {
+		err = compressor.Close()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", "", err
+	}
+	return file.Name(), hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// fileHasContent reports whether the file at filePath holds size bytes with
+// the given hex sha256, once decompressed if the server compressed it.
+func fileHasContent(filePath string, size int, hash string) bool {
+	info, err := os.Stat(filePath)
+	if err != nil || !info.Mode().IsRegular() || (!isCompressedSpill(filePath) && info.Size() != int64(size)) {
+		return false
+	}
+	file, err := openSpill(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	hasher := sha256.New()
+	if n, err := io.Copy(hasher, file); err != nil || n != int64(size) {
+		return false
+	}
+	return hex.EncodeToString(hasher.Sum(nil)) == hash
+}
+
+// spillInfo tells the client where saved content can be fetched, with the
+// size and sha256 of the file so it can check that it read all of it. It
+// prints as "location (N bytes, sha256: ...)". For gzipped files the size
+// and sha256 are those of the decompressed content.
+type spillInfo struct {
+	location   string
+	size       int
+	sha256     string
+	compressed bool
+}
+
+func (s spillInfo) String() string {
+	if s.compressed {
+		return fmt.Sprintf("%s (gzip, %d bytes decompressed, sha256: %s)", s.location, s.size, s.sha256)
+	}
+	return fmt.Sprintf("%s (%d bytes, sha256: %s)", s.location, s.size, s.sha256)
+}
+
+// withSpill records a spill in the result metadata as spill: {location,
+// size, sha256}, plus encoding: "gzip" for compressed files.
+func withSpill(result *mcp.CallToolResult, spill spillInfo) *mcp.CallToolResult {
+	meta := resultMeta(result)
+	info := map[string]any{"location": spill.location, "size": spill.size, "sha256": spill.sha256}
+	if spill.compressed {
+		info["encoding"] = "gzip"
+	}
+	meta["spill"] = info
+	return result
+}
+
+// spillToFile saves overflow content to a temp file and returns where the
+// client can fetch it: an expiring URL when files are served over HTTP,
+// otherwise the local path.
+func (cs *ClipboardServer) spillToFile(ctx context.Context, src spillSource, extension string) (spillInfo, error) {
+	spill, _, err := cs.saveSpill(ctx, src, extension)
+	return spill, err
+}
+
+// saveSpill is spillToFile, also returning the local path of the file.
+func (cs *ClipboardServer) saveSpill(ctx context.Context, src spillSource, extension string) (spill spillInfo, filePath string, err error) {
+	if cs != nil && cs.privacy {
+		return spillInfo{}, "", errPrivacySpill
+	}
+	track := progressFromContext(ctx).stage("Saving clipboard content to "+extension+" file", src.size)
+	compress := compressSpill(extension, src.size)
+	filePath, hash, err := saveSourceToTempFile(src, extension, compress, cs, track)
+	if err != nil {
+		return spillInfo{}, filePath, err
+	}
+	spill = spillInfo{location: filePath, size: src.size, sha256: hash, compressed: compress}
+	if cs == nil || cs.files == nil {
+		return spill, filePath, nil
+	}
+	spill.location, err = cs.files.publish(filePath)
+	return spill, filePath, err
+}
+
+func handleBinaryContent(ctx context.Context, data []byte, cs *ClipboardServer) (*mcp.CallToolResult, error) {
 	isImage, imageType := detectImageType(data)
 
+	limits := getInlineThresholds()
+
 	if isImage {
-		filePath, err := saveToTempFile(data, imageType, cs)
+		if len(data) <= limits.image {
+			return mcp.NewToolResultImage(
+				fmt.Sprintf("Clipboard image content (%s, %d bytes)", imageType, len(data)),
+				encodeBase64(ctx, data),
+				imageMIMEType(imageType),
+			), nil
+		}
+		spill, resource, err := cs.spillResource(ctx, data, imageType, imageMIMEType(imageType))
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to save image to temp file: %v", err)), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Clipboard image content (%s, %d bytes). Saved to: %s", imageType, len(data), filePath)), nil
+		return withSpill(withResource(mcp.NewToolResultText(fmt.Sprintf("Clipboard image content (%s, %d bytes). Saved to: %s", imageType, len(data), spill)), resource), spill), nil
 	}
 
-	encoded := base64.StdEncoding.EncodeToString(data)
-	const maxDirectOutput = 25000
+	if ext, mimeType := sniffBinaryType(data); ext != "" {
+		return knownBinaryResult(ctx, data, ext, mimeType, limits.base64, cs)
+	}
 
-	if len(encoded) > maxDirectOutput {
-		filePath, err := saveToTempFile([]byte(encoded), "b64", cs)
+	if encodedLen := base64.StdEncoding.EncodedLen(len(data)); encodedLen > limits.base64 {
+		spill, resource, err := cs.spillResource(ctx, data, "b64", "application/octet-stream")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to save large binary content to temp file: %v", err)), nil
 		}
-		return mcp.NewToolResultText(fmt.Sprintf("Clipboard binary content too large (%d bytes base64). Saved to: %s", len(encoded), filePath)), nil
+		return withSpill(withResource(mcp.NewToolResultText(fmt.Sprintf("Clipboard binary content too large (%d bytes base64). Saved to: %s", encodedLen, spill)), resource), spill), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Clipboard binary content (base64 encoded):\n%s", encoded)), nil
+	return mcp.NewToolResultText(fmt.Sprintf("Clipboard binary content (base64 encoded):\n%s", encodeBase64(ctx, data))), nil
 }
 
 func detectImageType(data []byte) (bool, string) {
@@ -583,19 +1131,31 @@ func detectImageType(data []byte) (bool, string) {
 	return false, ""
 }
 
+// isProbablyText reports whether content is text in any script: it must be
+// valid UTF-8 with few exceptions, and control characters other than
+// whitespace must be rare. Images and other binary data fail quickly, while
+// Chinese, Japanese or Cyrillic text, whose characters take several bytes
+// each, passes.
 func isProbablyText(content string) bool {
 	if len(content) == 0 {
 		return true
 	}
 
-	textChars := 0
-	for _, r := range content {
-		if r >= 32 && r <= 126 || r == '\n' || r == '\r' || r == '\t' {
-			textChars++
+	runes, suspicious := 0, 0
+	for i := 0; i < len(content); {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		i += size
+		runes++
+		switch {
+		case r == utf8.RuneError && size == 1:
+			suspicious++ // invalid UTF-8
+		case r == '\n' || r == '\r' || r == '\t' || r == '\f' || r == '\v':
+		case unicode.IsControl(r) || r == '\ufffe' || r == '\uffff':
+			suspicious++
 		}
 	}
 
-	return float64(textChars)/float64(len(content)) > 0.8
+	return suspicious*20 <= runes // at most 5% invalid bytes or control characters
 }
 
 func isRunningFromCLI() bool {
@@ -611,7 +1171,21 @@ func printUsage() {
     
     For direct testing:
     %s --help           Show this help message
+    %s --http ADDR      Serve MCP over HTTP at ADDR/mcp instead of stdio (unix:/path for a socket)
+    %s --stdio --http ADDR  Serve stdio and HTTP from one process, sharing its history
+    %s --no-monitor     Only access the clipboard when a tool is called
+    %s --read-only      Never modify the clipboard; only read tools are offered
+    %s --privacy        Keep only hashes, sizes and types; content only on explicit reads
+    %s --allow-paste    Offer paste_into_active_app, which presses Cmd/Ctrl+V in the focused app
     %s test             Test clipboard functionality
+    %s test --json      Same, as a JSON report; exits 1 if a check failed
+    %s bridge --listen unix:/path  Serve this machine's clipboard to mcp-clip in containers
+    %s bridge --listen :9123 --token T [--tls-cert F --tls-key K]  Serve it to remote mcp-clip servers
+    %s bench [-n N] [--json]  Time clipboard reads of the backend and each installed utility
+    %s install --systemd-user [--socket]  Run the HTTP server as a systemd user service
+    %s install --launchd [--http ADDR]  Run the HTTP server as a macOS LaunchAgent
+    %s install --client claude|cursor|windsurf [-- FLAGS]  Add mcp-clip to a client's config
+    %s service install [--http ADDR]  Run the HTTP server at logon on Windows (service uninstall removes it)
     %s version          Show version information
     
     For MCP client usage:
@@ -632,6 +1206,30 @@ func printUsage() {
     
     Available Tools:
     - read_clipboard: Read clipboard content (text/images as base64)
+    - clipboard_info: Type, size, hash and last change time without the content
+    - write_clipboard: Write text or a PNG/JPEG image to the clipboard
+    - append_to_clipboard: Append text to the clipboard content
+    - clipboard_digest: Summarize recorded clipboard activity for a period
+    - wait_for_clipboard_change: Block until the clipboard changes
+    - get_clipboard_changes: List changes since a sequence number
+    - diff_clipboard: Unified diff between history entries
+    - read_clipboard_pair: Screenshot plus the text copied alongside it
+    - read_clipboard_flavors: Plain text, HTML and RTF flavors in one result
+    - save_snippet / list_snippets / copy_snippet_to_clipboard: Named snippet store
+    - cleanup_temp_files: Delete expired (or, with all, every) saved clipboard file
+    - purge_session_files: Delete every file saved during this session
+    - schedule_clipboard_clear: Clear the clipboard after a delay
+    - restore_clipboard: Put back the content saved by a scratch write
+    - paste_into_active_app: Write text and paste it into the focused application
+    - read_clipboard_all: Every format on the clipboard in one result
+    - decode_qr: Decode QR codes in the clipboard image (needs zbarimg)
+    
+    Available Resources:
+    - clipboard://timeline: Recent clipboard history as Markdown
+    - clipboard://history/{id}: Content of a history entry
+    
+    Available Prompts:
+    - summarize_clipboard, explain_clipboard_error, translate_clipboard
     
     Features:
     - Automatic clipboard monitoring with notifications
@@ -641,16 +1239,86 @@ func printUsage() {
     
     Environment Variables:
     - MCP_DEBUG=1: Enable debug logging
+    - MCP_CLIP_MAX_INLINE_TEXT=25000: Largest text returned inline (bytes)
+    - MCP_CLIP_MAX_INLINE_BASE64=25000: Largest base64 payload returned inline
+    - MCP_CLIP_MAX_INLINE_IMAGE=1048576: Largest image returned inline as image content
+    - MCP_CLIP_MAX_EMBEDDED=8388608: Largest saved file embedded in results as a blob resource
+    - MCP_CLEANUP_INTERVAL=10m: How often expired temp files are removed (0 disables)
+    - MCP_CLIP_TEMP_QUOTA=1073741824: Most bytes temp files may use; oldest are evicted (0 disables)
+    - MCP_CLIP_COMPRESS_SPILLS_ABOVE=1048576: Gzip saved text files from this size (default 0, never)
+    - MCP_CLIP_MAX_FILE_CONTENT=16384: Largest copied file read by include_file_contents (bytes)
+    - MCP_CLIP_MAX_FILE_CONTENT_TOTAL=65536: Total bytes of copied files read per call
+    - MCP_CLIP_MAX_BYTES=67108864: Hard cap on clipboard content size (0 disables)
+    - MCP_CLIP_CONFIRM_OVERWRITE=1: Ask the user (MCP elicitation) before replacing clipboard content
+    - MCP_CLIP_BACKEND=klipper: Force a clipboard backend (native, wsl2, termux, klipper, portal, copyq)
+    - MCP_CLIP_LINUX_UTILITIES=xclip,xsel: Order of Linux clipboard utilities to try
+    - MCP_CLIP_POWERSHELL=/path/pwsh.exe: PowerShell used on Windows and WSL2
+    - MCP_CLIP_DISPLAY=:0: X display to use when DISPLAY is missing or wrong
+    - MCP_CLIP_WAYLAND_DISPLAY=wayland-0: Wayland socket to use instead of WAYLAND_DISPLAY
+    - MCP_CLIP_XAUTHORITY=/path/.Xauthority: X authority file for MCP_CLIP_DISPLAY
+    - MCP_CLIP_WAYLAND_SEAT=seat0: Wayland seat passed to wl-paste and wl-copy
+    - MCP_CLIP_READ_TIMEOUT=10s: Kill clipboard utilities that take longer than this
+    - MCP_CLIP_READ_RETRIES=2: Retries of a clipboard read that failed transiently
+    - MCP_CLIP_RETRY_BACKOFF=100ms: Wait before the first retry, doubling after each
+    - MCP_CLIP_HISTORY_SIZE=50: Number of clipboard changes kept in history
+    - MCP_CLIP_PAIR_WINDOW=30s: Maximum gap between paired screenshot and text copies
+    - MCP_CLIP_NO_MONITOR=1: Same as --no-monitor
+    - MCP_CLIP_READ_ONLY=1: Same as --read-only
+    - MCP_CLIP_PRIVACY=1: Same as --privacy
+    - MCP_CLIP_ALLOW_PASTE=1: Same as --allow-paste
+    - MCP_CLIP_IGNORE_CONCEALED=1: Record and return content password managers mark as concealed
+    - MCP_CLIP_BRIDGE=unix:/path: Use the clipboard served by mcp-clip bridge on another machine
+    - MCP_CLIP_BRIDGE_TOKEN=secret: Bearer token the bridge requires and the client sends
+    - MCP_CLIP_BRIDGE_TLS_CERT=cert.pem, MCP_CLIP_BRIDGE_TLS_KEY=key.pem: Serve the bridge over HTTPS
+    - MCP_CLIP_BRIDGE_CA=ca.pem: CA certificate to trust for an https:// MCP_CLIP_BRIDGE
+    - MCP_CLIP_HTTP_ADDR=127.0.0.1:8765: Same as --http
+    - MCP_CLIP_STDIO=1: Same as --stdio
+    - MCP_CLIP_HTTP_TOKEN=secret: Require this bearer token for HTTP requests
+    - MCP_CLIP_SERVE_FILES=1: In HTTP mode, return overflow files as expiring URLs
+    - MCP_CLIP_METRICS=1: In HTTP mode, expose Prometheus metrics at /metrics
+    - OTEL_EXPORTER_OTLP_ENDPOINT=http://localhost:4318: Export tool call traces over OTLP/HTTP
+    - MCP_CLIP_FILE_URL_TTL=15m: Lifetime of served file URLs
+    - MCP_CLIP_FILE_URL_SINGLE_USE=1: Revoke served file URLs after one download
+    - MCP_CLIP_FILE_AUDIT_LOG=path: Append a JSON line per file download attempt
+    - MCP_CLIP_ACCESSIBILITY=1: Allow reading selected text when the clipboard is empty
+    - MCP_CLIP_FETCH_URLS=1: Allow fetching the title and content type of copied URLs
+    - MCP_CLIP_FETCH_PRIVATE_URLS=1: Also fetch URLs on loopback, private and link-local addresses
+    - MCP_CLIP_URL_FETCH_TIMEOUT=3s: How long fetching one URL's metadata may take
+    - MCP_CLIP_DATA_DIR=path: Where snippets are stored (default: per-user data dir)
+    - MCP_CLIP_POLICY_FILE=path: allow/deny regex rules for returned content
+    - MCP_CLIP_POLICY_DENY=regex: Withhold clipboard content matching this pattern
+    - MCP_CLIP_PIPELINES_FILE=path: Named read_clipboard pipelines, one "name: step, step" per line
+    - MCP_CLIP_PIPELINES="name: step, step": Pipelines inline, separated by semicolons
+    - MCP_CLIP_RATE_LIMIT=10: Tool calls per second per client (0 disables)
+    - MCP_CLIP_RATE_BURST=20: Calls allowed in a burst before rate limiting applies
+    - MCP_CLIP_SCHEMA_VERSION=1: Pin the tool result format for older automations
+    - MCP_CLIP_EXPERIMENTS=sync: Comma-separated experimental features to enable ("all" for every one)
+    - MCP_CLIP_SYNC_LISTEN=:9124: Accept clipboard sync peers on this address
+    - MCP_CLIP_SYNC_PEERS=host:9124: Comma-separated sync peers to connect to
+    - MCP_CLIP_SYNC_TOKEN=secret: Shared secret required for sync
     
     For more information about MCP:
     https://modelcontextprotocol.io/
-`, os.Args[0], os.Args[0], os.Args[0])
+`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
 }
 
 func handleTestCommand() {
 	fmt.Println("Testing clipboard functionality...")
+	fmt.Printf("🔌 Backend: %s\n", selectBackend().Name())
+	if wsl := detectWSL(); wsl.detected || os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Printf("🐧 WSL: %v (%s)\n", wsl.detected, wsl.reason)
+	}
+	if _, ok := selectBackend().(nativeBackend); ok && usesLinuxUtilities() {
+		fmt.Printf("🔧 Utilities: %s\n", utilityNames(getLinuxUtilityChain()))
+	}
+	if _, ok := selectBackend().(wsl2Backend); ok {
+		fmt.Printf("🔧 PowerShell: %s\n", findPowerShell())
+	}
+	if experiments := enabledExperiments(); len(experiments) > 0 {
+		fmt.Printf("🧪 Experiments: %s\n", strings.Join(experiments, ", "))
+	}
 
-	content, err := readClipboard()
+	content, err := readClipboard(context.Background())
 	if err != nil {
 		fmt.Printf("❌ Failed to read clipboard: %v\n", err)
 		return
@@ -668,11 +1336,11 @@ func handleTestCommand() {
 		if len(content) <= 100 {
 			fmt.Printf("📄 Content: %s\n", content)
 		} else {
-			fmt.Printf("📄 Content preview: %s...\n", content[:100])
+			fmt.Printf("📄 Content preview: %s...\n", truncateBytes(content, 100))
 		}
 	} else {
 		fmt.Println("🖼️  Content type: Binary (possibly image)")
-		fmt.Printf("📦 Base64 preview: %s...\n", base64.StdEncoding.EncodeToString([]byte(content))[:50])
+		fmt.Printf("📦 Base64 preview: %s...\n", truncateBytes(base64.StdEncoding.EncodeToString([]byte(content)), 50))
 	}
 
 	fmt.Println("✅ Clipboard test completed successfully")
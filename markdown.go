@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+	whitespacePattern = regexp.MustCompile(`[ \t\r\n\f]+`)
+)
+
+// htmlToMarkdown converts an HTML document or fragment to Markdown. It covers
+// the structure that matters for reading: headings, paragraphs, emphasis,
+// links, images, lists, quotes, code and tables. Scripts, styles and unknown
+// tags are dropped, keeping only their text.
+func htmlToMarkdown(source string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return "", err
+	}
+	markdown := renderMarkdownChildren(doc)
+
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	markdown = blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(markdown), nil
+}
+
+func renderMarkdownChildren(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(renderMarkdown(child))
+	}
+	return b.String()
+}
+
+func renderMarkdown(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return whitespacePattern.ReplaceAllString(n.Data, " ")
+	case html.ElementNode:
+	case html.DocumentNode:
+		return renderMarkdownChildren(n)
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Noscript, atom.Template, atom.Button, atom.Select:
+		return ""
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + inlineMarkdown(n) + "\n\n"
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main, atom.Nav, atom.Figure:
+		return "\n\n" + strings.TrimSpace(renderMarkdownChildren(n)) + "\n\n"
+	case atom.Br:
+		return "  \n"
+	case atom.Hr:
+		return "\n\n---\n\n"
+	case atom.Strong, atom.B:
+		return wrapInline(renderMarkdownChildren(n), "**")
+	case atom.Em, atom.I:
+		return wrapInline(renderMarkdownChildren(n), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrapInline(renderMarkdownChildren(n), "~~")
+	case atom.Code, atom.Kbd, atom.Samp:
+		code := textContent(n)
+		fence := "`"
+		if strings.Contains(code, "`") {
+			fence = "``"
+		}
+		return fence + code + fence
+	case atom.Pre:
+		return "\n\n```" + codeLanguage(n) + "\n" + strings.TrimRight(textContent(n), "\n") + "\n```\n\n"
+	case atom.A:
+		text := strings.TrimSpace(renderMarkdownChildren(n))
+		href := attr(n, "href")
+		if href == "" || strings.HasPrefix(href, "javascript:") {
+			return text
+		}
+		if text == "" {
+			text = href
+		}
+		return fmt.Sprintf("[%s](%s)", text, href)
+	case atom.Img:
+		src := attr(n, "src")
+		if src == "" || strings.HasPrefix(src, "data:") {
+			return attr(n, "alt")
+		}
+		return fmt.Sprintf("![%s](%s)", attr(n, "alt"), src)
+	case atom.Ul, atom.Ol:
+		return "\n\n" + renderList(n) + "\n\n"
+	case atom.Blockquote:
+		quoted := strings.TrimSpace(blankLinesPattern.ReplaceAllString(renderMarkdownChildren(n), "\n\n"))
+		return "\n\n> " + strings.ReplaceAll(quoted, "\n", "\n> ") + "\n\n"
+	case atom.Table:
+		return "\n\n" + renderTable(n) + "\n\n"
+	default:
+		return renderMarkdownChildren(n)
+	}
+}
+
+// wrapInline wraps text in a Markdown emphasis marker, keeping surrounding
+// spaces outside the markers where Markdown requires them.
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	leading := text[:len(text)-len(strings.TrimLeft(text, " "))]
+	trailing := text[len(strings.TrimRight(text, " ")):]
+	return leading + marker + trimmed + marker + trailing
+}
+
+// inlineMarkdown renders n's children on a single line.
+func inlineMarkdown(n *html.Node) string {
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(renderMarkdownChildren(n), " "))
+}
+
+func renderList(n *html.Node) string {
+	var items []string
+	number := 1
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+		body := strings.TrimSpace(blankLinesPattern.ReplaceAllString(renderMarkdownChildren(child), "\n\n"))
+		body = strings.ReplaceAll(body, "\n\n", "\n")
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.ReplaceAll(body, "\n", "\n"+indent))
+	}
+	return strings.Join(items, "\n")
+}
+
+func renderTable(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			if child.DataAtom != atom.Tr {
+				walk(child)
+				continue
+			}
+			var row []string
+			for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+					row = append(row, strings.ReplaceAll(inlineMarkdown(cell), "|", `\|`))
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	var b strings.Builder
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	if n.Type == html.ElementNode && n.DataAtom == atom.Br {
+		return "\n"
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+// codeLanguage reads a "language-go" style class from a <pre> or its <code>.
+func codeLanguage(pre *html.Node) string {
+	candidates := []*html.Node{pre}
+	if pre.FirstChild != nil && pre.FirstChild.DataAtom == atom.Code {
+		candidates = append(candidates, pre.FirstChild)
+	}
+	for _, n := range candidates {
+		for _, class := range strings.Fields(attr(n, "class")) {
+			if language, ok := strings.CutPrefix(class, "language-"); ok {
+				return language
+			}
+			if language, ok := strings.CutPrefix(class, "lang-"); ok {
+				return language
+			}
+		}
+	}
+	return ""
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// markdownResult serves read_clipboard's "markdown" format. It reports false
+// when the clipboard has no HTML flavor, so the caller can fall back to the
+// plain text, which needs no conversion.
+func (cs *ClipboardServer) markdownResult(ctx context.Context) (*mcp.CallToolResult, bool) {
+	source, err := readClipboardHTML(ctx)
+	if err != nil || strings.TrimSpace(source) == "" {
+		if err != nil && os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "HTML clipboard unavailable, using plain text: %v\n", err)
+		}
+		return nil, false
+	}
+
+	markdown, err := htmlToMarkdown(source)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to convert HTML clipboard to Markdown: %v", err)), true
+	}
+	result, err := cs.contentResult(ctx, markdown, "text")
+	if err != nil || result.IsError {
+		return result, true
+	}
+	meta := resultMeta(result)
+	meta["mimeType"], meta["sourceMimeType"] = "text/markdown", "text/html"
+	return result, true
+}
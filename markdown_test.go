@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test HTML to Markdown conversion of common web page structure
+func TestHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"heading and paragraph", "<h2>Install</h2>\n<p>Run the <b>installer</b> and <em>wait</em>.</p>", "## Install\n\nRun the **installer** and *wait*."},
+		{"link and image", `<p>See <a href="https://go.dev">the docs</a> <img src="/logo.png" alt="logo"></p>`, "See [the docs](https://go.dev) ![logo](/logo.png)"},
+		{"lists", "<ul><li>one</li><li>two<ol><li>a</li><li>b</li></ol></li></ul>", "- one\n- two\n  1. a\n  2. b"},
+		{"code", `<p>Use <code>go test</code>:</p><pre><code class="language-sh">go test ./...
+go vet ./...</code></pre>`, "Use `go test`:\n\n```sh\ngo test ./...\ngo vet ./...\n```"},
+		{"quote", "<blockquote><p>first</p><p>second</p></blockquote>", "> first\n>\n> second"},
+		{"table", "<table><thead><tr><th>Name</th><th>Size</th></tr></thead><tbody><tr><td>a|b</td><td>1</td></tr><tr><td>c</td></tr></tbody></table>", "| Name | Size |\n| --- | --- |\n| a\\|b | 1 |\n| c |  |"},
+		{"scripts dropped", "<div><script>alert(1)</script><style>p{}</style>Visible</div>", "Visible"},
+	}
+	for _, tt := range tests {
+		got, err := htmlToMarkdown(tt.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+// Test that Markdown too large to inline keeps its spill metadata
+func TestReadClipboardMarkdownSpill(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("MCP_CLIP_MAX_INLINE_TEXT", "5")
+	fakeClipboard(t, "Install now")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncase \"$*\" in\n*text/html*) printf '<h2>Install</h2><p>now</p>' ;;\n*) exit 1 ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"format": "markdown"}
+	result, err := NewClipboardServer().readClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	meta := result.Meta.AdditionalFields
+	spill, ok := meta["spill"].(map[string]any)
+	if !ok || spill["sha256"] != contentHash("## Install\n\nnow") {
+		t.Errorf("Expected the spilled Markdown's sha256, got %v", meta)
+	}
+	if meta["mimeType"] != "text/markdown" || meta["sourceMimeType"] != "text/html" {
+		t.Errorf("Expected Markdown converted from HTML, got %v", meta)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	DefaultMaxClipboardBytes = 64 << 20 // 64MB
+	StatusTooLarge           = "too_large"
+)
+
+// limitedReader is implemented by backends that can stop reading once the
+// clipboard exceeds a size limit, instead of buffering all of it first.
+type limitedReader interface {
+	ReadLimited(ctx context.Context, limit int) (string, error)
+}
+
+// oversizeError reports clipboard content above MCP_CLIP_MAX_BYTES. Reading
+// stops at the limit, so size is a lower bound unless exact is set.
+type oversizeError struct {
+	size  int
+	limit int
+	exact bool
+}
+
+func (e *oversizeError) Error() string {
+	if e.exact {
+		return fmt.Sprintf("clipboard content is %d bytes, above the %d byte limit", e.size, e.limit)
+	}
+	return fmt.Sprintf("clipboard content exceeds the %d byte limit", e.limit)
+}
+
+// getMaxClipboardBytes returns the hard cap on clipboard content size, or 0
+// when MCP_CLIP_MAX_BYTES=0 disables it.
+func getMaxClipboardBytes() int {
+	return getSizeEnv("MCP_CLIP_MAX_BYTES", DefaultMaxClipboardBytes)
+}
+
+// readBackendLimited reads from backend without holding more than limit+1
+// bytes when the backend supports it. Other backends are read in full and
+// checked afterwards, which still keeps oversized content from being
+// returned or written to disk.
+func readBackendLimited(ctx context.Context, backend clipboardBackend, limit int) (string, error) {
+	if limit <= 0 {
+		return backend.Read(ctx)
+	}
+	if lr, ok := backend.(limitedReader); ok {
+		return lr.ReadLimited(ctx, limit)
+	}
+	content, err := backend.Read(ctx)
+	if err == nil && len(content) > limit {
+		return "", &oversizeError{size: len(content), limit: limit, exact: true}
+	}
+	return content, err
+}
+
+// utf16Oversize checks NUL-terminated UTF-16 clipboard text of size bytes
+// against limit before it is converted. Every UTF-16 code unit becomes at
+// least one byte of UTF-8, so the text is known to exceed limit once it has
+// more code units than that.
+func utf16Oversize(size, limit int) *oversizeError {
+	if units := size/2 - 1; units > limit {
+		return &oversizeError{size: units, limit: limit}
+	}
+	return nil
+}
+
+// runLimited runs cmd and returns its output, killing it as soon as the
+// output exceeds limit bytes (limit <= 0 means no limit). The run is traced
+// as part of ctx, which should be the one cmd was created with.
+func runLimited(ctx context.Context, cmd *exec.Cmd, limit int) (output []byte, err error) {
+	end := traceCommand(ctx, cmd)
+	defer func() { end(err) }()
+
+	if limit <= 0 {
+		return cmd.Output()
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	output, readErr := io.ReadAll(io.LimitReader(stdout, int64(limit)+1))
+	if len(output) > limit {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, &oversizeError{size: len(output), limit: limit}
+	}
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%v: %s", err, msg)
+		}
+		return nil, err
+	}
+	return output, readErr
+}
+
+// tooLargeResult explains how to get at content above the size cap.
+func tooLargeResult(e *oversizeError) *mcp.CallToolResult {
+	size := fmt.Sprintf("more than %d bytes", e.limit)
+	if e.exact {
+		size = fmt.Sprintf("%d bytes", e.size)
+	}
+	hint := "Save the content to a file from the source application and share the path instead, or raise MCP_CLIP_MAX_BYTES (0 disables the cap)."
+	result := mcp.NewToolResultError(fmt.Sprintf("Clipboard content is too large to read (%s, limit %d bytes). %s", size, e.limit, hint))
+	meta := resultMeta(result)
+	meta["limit"] = e.limit
+	if e.exact {
+		meta["size"] = e.size
+	} else {
+		meta["sizeAtLeast"] = e.size
+	}
+	return withStatus(result, StatusTooLarge, hint)
+}
+
+func asOversize(err error) (*oversizeError, bool) {
+	var oversize *oversizeError
+	ok := errors.As(err, &oversize)
+	return oversize, ok
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Test that command output above the limit is cut off instead of buffered
+func TestRunLimited(t *testing.T) {
+	if _, err := exec.LookPath("head"); err != nil {
+		t.Skip("head not available")
+	}
+
+	output, err := runLimited(context.Background(), exec.Command("head", "-c", "100", "/dev/zero"), 100)
+	if err != nil || len(output) != 100 {
+		t.Errorf("Expected 100 bytes within the limit, got %d (%v)", len(output), err)
+	}
+
+	// /dev/zero never ends, so this only returns because reading stops at the limit
+	_, err = runLimited(context.Background(), exec.Command("cat", "/dev/zero"), 1000)
+	oversize, ok := asOversize(err)
+	if !ok || oversize.size != 1001 || oversize.exact {
+		t.Errorf("Expected oversize error after 1001 bytes, got %v", err)
+	}
+}
+
+// Test the size check for backends that can't stop early
+func TestReadBackendLimited(t *testing.T) {
+	backend := &fakeBackend{content: strings.Repeat("x", 50)}
+	if content, err := readBackendLimited(context.Background(), backend, 50); err != nil || len(content) != 50 {
+		t.Errorf("Expected content at the limit to be returned, got %d (%v)", len(content), err)
+	}
+	if content, err := readBackendLimited(context.Background(), backend, 0); err != nil || len(content) != 50 {
+		t.Errorf("Expected no limit with 0, got %d (%v)", len(content), err)
+	}
+
+	_, err := readBackendLimited(context.Background(), backend, 49)
+	var oversize *oversizeError
+	if !errors.As(err, &oversize) || oversize.size != 50 || !oversize.exact {
+		t.Errorf("Expected exact oversize error, got %v", err)
+	}
+
+	result := tooLargeResult(oversize)
+	if !result.IsError || result.Meta.AdditionalFields["status"] != StatusTooLarge || result.Meta.AdditionalFields["size"] != 50 {
+		t.Errorf("Unexpected result metadata: %v", result.Meta)
+	}
+}
+
+// Test the Windows size check on UTF-16 clipboard text, which must never
+// reject text that fits once converted to UTF-8
+func TestUTF16Oversize(t *testing.T) {
+	// 100 code units plus the terminating NUL
+	if oversize := utf16Oversize(202, 100); oversize != nil {
+		t.Errorf("Expected 100 code units to fit a 100 byte limit, got %v", oversize)
+	}
+	oversize := utf16Oversize(204, 100)
+	if oversize == nil || oversize.size != 101 || oversize.exact {
+		t.Errorf("Expected a lower-bound oversize error for 101 code units, got %v", oversize)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pollDurationBuckets are the upper bounds, in seconds, of the poll duration
+// histogram. WSL2 reads through PowerShell land in the upper buckets.
+var pollDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// serverMetrics counts clipboard activity for the /metrics endpoint. Counting
+// is always on; it is only exposed when the HTTP server enables it.
+type serverMetrics struct {
+	mu            sync.Mutex
+	reads         map[string]uint64 // backend reads, by backend
+	writes        map[string]uint64 // backend writes, by backend
+	backendErrors map[string]uint64 // failed reads and writes, by backend
+
+	pollBuckets  []atomic.Uint64 // cumulative counts per pollDurationBuckets entry
+	pollCount    atomic.Uint64
+	pollSumNanos atomic.Int64
+
+	tempFiles     atomic.Uint64
+	tempFileBytes atomic.Uint64
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{
+		reads:         map[string]uint64{},
+		writes:        map[string]uint64{},
+		backendErrors: map[string]uint64{},
+		pollBuckets:   make([]atomic.Uint64, len(pollDurationBuckets)),
+	}
+}
+
+// metrics is the process-wide registry.
+var metrics = newServerMetrics()
+
+// backendCall records a backend read or write and whether it failed.
+func (m *serverMetrics) backendCall(write bool, backend string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if write {
+		m.writes[backend]++
+	} else {
+		m.reads[backend]++
+	}
+	if err != nil {
+		if _, ok := asOversize(err); !ok {
+			m.backendErrors[backend]++
+		}
+	}
+}
+
+// observePoll records how long one monitor check of the clipboard took.
+func (m *serverMetrics) observePoll(d time.Duration) {
+	for i, bound := range pollDurationBuckets {
+		if d.Seconds() <= bound {
+			m.pollBuckets[i].Add(1)
+		}
+	}
+	m.pollCount.Add(1)
+	m.pollSumNanos.Add(int64(d))
+}
+
+// tempFileWritten records a temp file saved for overflow content.
+func (m *serverMetrics) tempFileWritten(size int) {
+	m.tempFiles.Add(1)
+	m.tempFileBytes.Add(uint64(size))
+}
+
+// ServeHTTP renders the metrics in the Prometheus text exposition format.
+func (m *serverMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.write(w)
+}
+
+func (m *serverMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	writeCounterVec(w, "mcp_clip_reads_total", "Clipboard reads performed by the backend.", m.reads)
+	writeCounterVec(w, "mcp_clip_writes_total", "Clipboard writes performed by the backend.", m.writes)
+	writeCounterVec(w, "mcp_clip_backend_errors_total", "Failed backend reads and writes.", m.backendErrors)
+	m.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP mcp_clip_poll_duration_seconds Time taken by each monitor check of the clipboard.\n")
+	fmt.Fprintf(w, "# TYPE mcp_clip_poll_duration_seconds histogram\n")
+	for i, bound := range pollDurationBuckets {
+		fmt.Fprintf(w, "mcp_clip_poll_duration_seconds_bucket{le=\"%g\"} %d\n", bound, m.pollBuckets[i].Load())
+	}
+	count := m.pollCount.Load()
+	fmt.Fprintf(w, "mcp_clip_poll_duration_seconds_bucket{le=\"+Inf\"} %d\n", count)
+	fmt.Fprintf(w, "mcp_clip_poll_duration_seconds_sum %g\n", time.Duration(m.pollSumNanos.Load()).Seconds())
+	fmt.Fprintf(w, "mcp_clip_poll_duration_seconds_count %d\n", count)
+
+	fmt.Fprintf(w, "# HELP mcp_clip_temp_files_total Temp files saved for content too large to return inline.\n")
+	fmt.Fprintf(w, "# TYPE mcp_clip_temp_files_total counter\n")
+	fmt.Fprintf(w, "mcp_clip_temp_files_total %d\n", m.tempFiles.Load())
+	fmt.Fprintf(w, "# HELP mcp_clip_temp_file_bytes_total Bytes written to temp files.\n")
+	fmt.Fprintf(w, "# TYPE mcp_clip_temp_file_bytes_total counter\n")
+	fmt.Fprintf(w, "mcp_clip_temp_file_bytes_total %d\n", m.tempFileBytes.Load())
+}
+
+// writeCounterVec writes a counter labelled by backend, in a stable order.
+func writeCounterVec(w io.Writer, name, help string, values map[string]uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	backends := make([]string, 0, len(values))
+	for backend := range values {
+		backends = append(backends, backend)
+	}
+	sort.Strings(backends)
+	for _, backend := range backends {
+		fmt.Fprintf(w, "%s{backend=\"%s\"} %d\n", name, strings.ReplaceAll(backend, `"`, `\"`), values[backend])
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test that counters and the poll histogram are rendered in Prometheus format
+func TestMetricsExposition(t *testing.T) {
+	m := newServerMetrics()
+	m.backendCall(false, "native", nil)
+	m.backendCall(false, "native", errors.New("xclip failed"))
+	m.backendCall(false, "native", &oversizeError{size: 10, limit: 5})
+	m.backendCall(true, "wsl2", nil)
+	m.observePoll(30 * time.Millisecond)
+	m.observePoll(3 * time.Second)
+	m.tempFileWritten(2048)
+
+	recorder := httptest.NewRecorder()
+	m.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	out := recorder.Body.String()
+	for _, want := range []string{
+		`mcp_clip_reads_total{backend="native"} 3`,
+		`mcp_clip_writes_total{backend="wsl2"} 1`,
+		`mcp_clip_backend_errors_total{backend="native"} 1`,
+		`mcp_clip_poll_duration_seconds_bucket{le="0.025"} 0`,
+		`mcp_clip_poll_duration_seconds_bucket{le="0.05"} 1`,
+		`mcp_clip_poll_duration_seconds_bucket{le="5"} 2`,
+		`mcp_clip_poll_duration_seconds_count 2`,
+		`mcp_clip_temp_file_bytes_total 2048`,
+		"# TYPE mcp_clip_poll_duration_seconds histogram",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, out)
+		}
+	}
+	if ct := recorder.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Expected a text/plain content type, got %s", ct)
+	}
+}
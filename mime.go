@@ -0,0 +1,190 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// binaryMIMETypes maps the extensions produced by sniffBinaryType to MIME types.
+var binaryMIMETypes = map[string]string{
+	"pdf":    "application/pdf",
+	"zip":    "application/zip",
+	"gz":     "application/gzip",
+	"bz2":    "application/x-bzip2",
+	"xz":     "application/x-xz",
+	"7z":     "application/x-7z-compressed",
+	"rar":    "application/vnd.rar",
+	"tar":    "application/x-tar",
+	"docx":   "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"pptx":   "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	"odt":    "application/vnd.oasis.opendocument.text",
+	"ods":    "application/vnd.oasis.opendocument.spreadsheet",
+	"odp":    "application/vnd.oasis.opendocument.presentation",
+	"epub":   "application/epub+zip",
+	"ole":    "application/x-ole-storage", // legacy .doc/.xls/.ppt/.msg
+	"mp3":    "audio/mpeg",
+	"wav":    "audio/wav",
+	"ogg":    "audio/ogg",
+	"flac":   "audio/flac",
+	"m4a":    "audio/mp4",
+	"mp4":    "video/mp4",
+	"mov":    "video/quicktime",
+	"webm":   "video/webm",
+	"mkv":    "video/x-matroska",
+	"avi":    "video/x-msvideo",
+	"wasm":   "application/wasm",
+	"sqlite": "application/vnd.sqlite3",
+	"woff":   "font/woff",
+	"woff2":  "font/woff2",
+	"ttf":    "font/ttf",
+	"otf":    "font/otf",
+	"exe":    "application/vnd.microsoft.portable-executable",
+	"elf":    "application/x-elf",
+}
+
+// magicSignatures are checked in order against the start of the data.
+var magicSignatures = []struct {
+	prefix string
+	ext    string
+}{
+	{"%PDF-", "pdf"},
+	{"\x1f\x8b", "gz"},
+	{"BZh", "bz2"},
+	{"\xfd7zXZ\x00", "xz"},
+	{"7z\xbc\xaf\x27\x1c", "7z"},
+	{"Rar!\x1a\x07", "rar"},
+	{"\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1", "ole"},
+	{"ID3", "mp3"},
+	{"OggS", "ogg"},
+	{"fLaC", "flac"},
+	{"\x00asm", "wasm"},
+	{"SQLite format 3\x00", "sqlite"},
+	{"wOFF", "woff"},
+	{"wOF2", "woff2"},
+	{"\x00\x01\x00\x00\x00", "ttf"},
+	{"OTTO", "otf"},
+	{"\x7fELF", "elf"},
+	{"MZ", "exe"},
+}
+
+// sniffBinaryType identifies common non-image binary formats by their magic
+// numbers, returning a file extension and MIME type, or empty strings if the
+// format is unknown.
+func sniffBinaryType(data []byte) (string, string) {
+	ext := sniffBinaryExt(data)
+	return ext, binaryMIMETypes[ext]
+}
+
+func sniffBinaryExt(data []byte) string {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return sniffZipContainer(data)
+	}
+	for _, sig := range magicSignatures {
+		if bytes.HasPrefix(data, []byte(sig.prefix)) {
+			return sig.ext
+		}
+	}
+
+	if len(data) >= 12 && string(data[0:4]) == "RIFF" {
+		switch string(data[8:12]) {
+		case "WAVE":
+			return "wav"
+		case "AVI ":
+			return "avi"
+		}
+	}
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" {
+		switch string(data[8:12]) {
+		case "qt  ":
+			return "mov"
+		case "M4A ", "M4B ":
+			return "m4a"
+		default:
+			return "mp4"
+		}
+	}
+	if bytes.HasPrefix(data, []byte("\x1a\x45\xdf\xa3")) {
+		if bytes.Contains(data[:min(len(data), 64)], []byte("webm")) {
+			return "webm"
+		}
+		return "mkv"
+	}
+	if len(data) >= 262 && string(data[257:262]) == "ustar" {
+		return "tar"
+	}
+
+	// Fall back to the WHATWG sniffing table for MPEG audio frames and the like
+	switch http.DetectContentType(data) {
+	case "audio/mpeg":
+		return "mp3"
+	case "application/zip":
+		return "zip"
+	}
+	return ""
+}
+
+// sniffZipContainer distinguishes Office Open XML, OpenDocument and EPUB files
+// from plain zip archives by the entries they contain.
+func sniffZipContainer(data []byte) string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "zip"
+	}
+
+	for _, file := range reader.File {
+		switch {
+		case strings.HasPrefix(file.Name, "word/"):
+			return "docx"
+		case strings.HasPrefix(file.Name, "xl/"):
+			return "xlsx"
+		case strings.HasPrefix(file.Name, "ppt/"):
+			return "pptx"
+		case file.Name == "mimetype":
+			rc, err := file.Open()
+			if err != nil {
+				continue
+			}
+			buf := make([]byte, 64)
+			n, _ := rc.Read(buf)
+			rc.Close()
+			switch mimeType := string(buf[:n]); {
+			case mimeType == binaryMIMETypes["odt"]:
+				return "odt"
+			case mimeType == binaryMIMETypes["ods"]:
+				return "ods"
+			case mimeType == binaryMIMETypes["odp"]:
+				return "odp"
+			case mimeType == binaryMIMETypes["epub"]:
+				return "epub"
+			}
+		}
+	}
+	return "zip"
+}
+
+// knownBinaryResult returns binary content of a recognized format inline as
+// base64 when small enough, otherwise saves the raw bytes with the proper
+// extension. The MIME type is reported in the result metadata.
+func knownBinaryResult(ctx context.Context, data []byte, ext, mimeType string, maxInline int, cs *ClipboardServer) (*mcp.CallToolResult, error) {
+	var result *mcp.CallToolResult
+	if base64.StdEncoding.EncodedLen(len(data)) <= maxInline {
+		result = mcp.NewToolResultText(fmt.Sprintf("Clipboard %s content (%s, %d bytes, base64 encoded):\n%s", ext, mimeType, len(data), encodeBase64(ctx, data)))
+	} else {
+		spill, resource, err := cs.spillResource(ctx, data, ext, mimeType)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to save %s content to temp file: %v", ext, err)), nil
+		}
+		result = withSpill(withResource(mcp.NewToolResultText(fmt.Sprintf("Clipboard %s content (%s, %d bytes). Saved to: %s", ext, mimeType, len(data), spill)), resource), spill)
+	}
+	meta := resultMeta(result)
+	meta["mimeType"] = mimeType
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func zipWith(t *testing.T, names ...string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range names {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create zip entry: %v", err)
+		}
+		f.Write([]byte("x"))
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+// Test magic number sniffing for common binary formats
+func TestSniffBinaryType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		ext  string
+	}{
+		{"pdf", []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3"), "pdf"},
+		{"gzip", []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00"), "gz"},
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), "wav"},
+		{"mp4", []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00"), "mp4"},
+		{"docx", zipWith(t, "[Content_Types].xml", "word/document.xml"), "docx"},
+		{"xlsx", zipWith(t, "[Content_Types].xml", "xl/workbook.xml"), "xlsx"},
+		{"zip", zipWith(t, "notes.txt"), "zip"},
+		{"unknown", []byte("\x01\x02\x03\x04\x05\x06\x07\x08"), ""},
+	}
+	for _, tt := range tests {
+		ext, mimeType := sniffBinaryType(tt.data)
+		if ext != tt.ext {
+			t.Errorf("%s: expected extension %q, got %q", tt.name, tt.ext, ext)
+		}
+		if ext != "" && mimeType == "" {
+			t.Errorf("%s: expected a MIME type for extension %q", tt.name, ext)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+)
+
+// In on-demand mode (--no-monitor) nothing polls the clipboard in the
+// background: read_clipboard records what it reads, and the clipboard is
+// only watched while a wait_for_clipboard_change call is blocked.
+
+// watchOnDemand watches the clipboard until the returned release func is
+// called. Concurrent callers share one watcher, which stops with the last one.
+// It also records the current content first, so a change is measured against
+// the clipboard as it is now rather than as it was at the last tool call.
+func (cs *ClipboardServer) watchOnDemand(ctx context.Context) (release func()) {
+	cs.checkClipboard(ctx)
+
+	cs.watchMutex.Lock()
+	defer cs.watchMutex.Unlock()
+	if cs.watchers == 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		cs.stopWatch = cancel
+		go cs.watchClipboard(ctx)
+	}
+	cs.watchers++
+
+	return func() {
+		cs.watchMutex.Lock()
+		defer cs.watchMutex.Unlock()
+		cs.watchers--
+		if cs.watchers == 0 {
+			cs.stopWatch()
+			cs.stopWatch = nil
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// Test that --no-monitor and MCP_CLIP_NO_MONITOR enable on-demand mode
+func TestParseNoMonitorOption(t *testing.T) {
+	t.Setenv("MCP_CLIP_NO_MONITOR", "")
+	opts, err := parseServerOptions(nil)
+	if err != nil || opts.noMonitor {
+		t.Errorf("Expected monitoring by default, got noMonitor=%v err=%v", opts.noMonitor, err)
+	}
+	if opts, _ := parseServerOptions([]string{"--no-monitor"}); !opts.noMonitor {
+		t.Error("Expected --no-monitor to disable monitoring")
+	}
+
+	t.Setenv("MCP_CLIP_NO_MONITOR", "1")
+	if opts, _ := parseServerOptions(nil); !opts.noMonitor {
+		t.Error("Expected MCP_CLIP_NO_MONITOR=1 to disable monitoring")
+	}
+	if opts, _ := parseServerOptions([]string{"--no-monitor=false"}); opts.noMonitor {
+		t.Error("Expected the flag to override the environment")
+	}
+}
+
+// Test that concurrent waiters share one on-demand poller, stopped by the last
+func TestWatchOnDemandSharesPoller(t *testing.T) {
+	cs := NewClipboardServer()
+	cs.onDemand = true
+
+	releaseFirst := cs.watchOnDemand(context.Background())
+	releaseSecond := cs.watchOnDemand(context.Background())
+	if cs.watchers != 2 {
+		t.Errorf("Expected 2 watchers, got %d", cs.watchers)
+	}
+
+	releaseFirst()
+	if cs.stopWatch == nil {
+		t.Error("Expected the poller to keep running while a waiter remains")
+	}
+	releaseSecond()
+	if cs.watchers != 0 || cs.stopWatch != nil {
+		t.Errorf("Expected the poller to stop, got %d watchers", cs.watchers)
+	}
+}
+
+// Test that checkClipboard reports whether the clipboard could be read, which
+// the Windows poller uses to retry reads that failed
+func TestCheckClipboardResult(t *testing.T) {
+	fakeClipboard(t, "polled")
+	cs := NewClipboardServer()
+	if !cs.checkClipboard(context.Background()) {
+		t.Error("Expected a successful read to be reported")
+	}
+	if content, _ := cs.getLastClipboard(); content != "polled" {
+		t.Errorf("Expected the content to be recorded, got %q", content)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if cs.checkClipboard(context.Background()) {
+		t.Error("Expected a failed read to be reported")
+	}
+}
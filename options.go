@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// serverOptions are the command-line options for running the MCP server.
+type serverOptions struct {
+	httpAddr  string // serve MCP over streamable HTTP on this address instead of stdio
+	stdio     bool   // with httpAddr, serve stdio as well
+	noMonitor bool   // never poll the clipboard in the background
+	readOnly  bool   // never modify the clipboard
+	privacy   bool   // keep only hashes, sizes and types of clipboard content
+	paste     bool   // offer paste_into_active_app
+	detach    bool   // close the console window on Windows (service task)
+}
+
+func parseServerOptions(args []string) (serverOptions, error) {
+	opts := serverOptions{
+		httpAddr:  os.Getenv("MCP_CLIP_HTTP_ADDR"),
+		stdio:     os.Getenv("MCP_CLIP_STDIO") == "1",
+		noMonitor: os.Getenv("MCP_CLIP_NO_MONITOR") == "1",
+		readOnly:  os.Getenv("MCP_CLIP_READ_ONLY") == "1",
+		privacy:   os.Getenv("MCP_CLIP_PRIVACY") == "1",
+		paste:     os.Getenv("MCP_CLIP_ALLOW_PASTE") == "1",
+	}
+
+	fs := flag.NewFlagSet("mcp-clip", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&opts.httpAddr, "http", opts.httpAddr, "serve MCP over HTTP on this address")
+	fs.BoolVar(&opts.stdio, "stdio", opts.stdio, "with --http, also serve MCP over stdio")
+	fs.BoolVar(&opts.noMonitor, "no-monitor", opts.noMonitor, "only access the clipboard when a tool is called")
+	fs.BoolVar(&opts.readOnly, "read-only", opts.readOnly, "only register tools that read the clipboard")
+	fs.BoolVar(&opts.detach, "background", false, "close the console window on Windows")
+	fs.BoolVar(&opts.privacy, "privacy", opts.privacy, "never retain clipboard content outside explicit reads")
+	fs.BoolVar(&opts.paste, "allow-paste", opts.paste, "offer a tool that pastes into the active application")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, fmt.Errorf("Invalid arguments: %v", err)
+	}
+	return opts, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolOutput is the structured content every tool returns from schema
+// version 3, declared as the tools' output schema, so clients get the facts
+// of a result without parsing its text. Handlers fill in what only they know
+// (the raw clipboard content); the rest is taken from the result's _meta
+// when the result is adapted to the requested version.
+type toolOutput struct {
+	Status    string `json:"status" jsonschema_description:"ok or the _meta status: empty, unsupported_format, error, too_large, rate_limited..."`
+	Message   string `json:"message,omitempty" jsonschema_description:"The text of the result"`
+	Content   string `json:"content,omitempty" jsonschema_description:"Clipboard text returned inline, without any label"`
+	MIMEType  string `json:"mimeType,omitempty" jsonschema_description:"MIME type of the content"`
+	Size      int    `json:"size,omitempty" jsonschema_description:"Size of the clipboard content in bytes"`
+	Hash      string `json:"hash,omitempty" jsonschema_description:"sha256 of the clipboard content"`
+	SpillPath string `json:"spillPath,omitempty" jsonschema_description:"Temp file or URL the content was saved to because it was too large to return inline"`
+	Truncated bool   `json:"truncated,omitempty" jsonschema_description:"The content is not (completely) inline: see spillPath or status"`
+	Sequence  uint64 `json:"sequence,omitempty" jsonschema_description:"Clipboard change sequence the result refers to"`
+}
+
+// outputOf returns the structured output attached to result, attaching an
+// empty one first if there is none.
+func outputOf(result *mcp.CallToolResult) *toolOutput {
+	if output, ok := result.StructuredContent.(*toolOutput); ok {
+		return output
+	}
+	output := &toolOutput{}
+	result.StructuredContent = output
+	return output
+}
+
+// withOutputContent records the clipboard text a result returns inline.
+func withOutputContent(result *mcp.CallToolResult, content string) *mcp.CallToolResult {
+	outputOf(result).Content = content
+	return result
+}
+
+// completeOutput fills in the structured output from the result's text and
+// _meta, keeping what the handler set.
+func completeOutput(result *mcp.CallToolResult) *toolOutput {
+	output := outputOf(result)
+	var meta map[string]any
+	if result.Meta != nil {
+		meta = result.Meta.AdditionalFields
+	}
+
+	if status, ok := meta["status"].(string); ok {
+		output.Status = status
+	} else if result.IsError {
+		output.Status = StatusError
+	} else {
+		output.Status = "ok"
+	}
+	if output.Message == "" {
+		var texts []string
+		for _, content := range result.Content {
+			if text, ok := content.(mcp.TextContent); ok {
+				texts = append(texts, text.Text)
+			}
+		}
+		output.Message = strings.Join(texts, "\n")
+	}
+	if mimeType, ok := meta["mimeType"].(string); ok {
+		output.MIMEType = mimeType
+	}
+	if output.Size == 0 {
+		output.Size = metaInt(meta["size"])
+	}
+	if hash, ok := meta["sha256"].(string); ok && output.Hash == "" {
+		output.Hash = hash
+	}
+	if output.Sequence == 0 {
+		output.Sequence = uint64(metaInt(meta["sequence"]))
+	}
+	if spill, ok := meta["spill"].(map[string]any); ok {
+		output.SpillPath, _ = spill["location"].(string)
+	}
+	output.Truncated = output.Truncated || output.SpillPath != "" || output.Status == StatusTooLarge
+	return output
+}
+
+func metaInt(value any) int {
+	switch n := value.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case uint64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
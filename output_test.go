@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Test that structured output is completed from the result's _meta
+func TestCompleteOutput(t *testing.T) {
+	result := withOutputContent(mcp.NewToolResultText("Clipboard text content:\nhello"), "hello")
+	result.Meta = mcp.NewMetaFromMap(map[string]any{"mimeType": "text/plain", "size": 5, "sha256": contentHash("hello"), "sequence": uint64(7)})
+	output := completeOutput(result)
+	if output.Status != "ok" || output.Content != "hello" || output.MIMEType != "text/plain" || output.Size != 5 || output.Hash != contentHash("hello") || output.Sequence != 7 || output.Truncated {
+		t.Errorf("Unexpected output: %+v", output)
+	}
+
+	spilled := withSpill(mcp.NewToolResultText("Clipboard text content too large"), spillInfo{location: "/tmp/mcp-clip.txt", size: 100, sha256: "abc"})
+	if output := completeOutput(spilled); output.SpillPath != "/tmp/mcp-clip.txt" || !output.Truncated || output.Content != "" {
+		t.Errorf("Expected a spilled output, got %+v", output)
+	}
+
+	if output := completeOutput(tooLargeResult(&oversizeError{size: 100, limit: 10, exact: true})); output.Status != StatusTooLarge || !output.Truncated {
+		t.Errorf("Expected a too_large output, got %+v", output)
+	}
+	if output := completeOutput(mcp.NewToolResultError("boom")); output.Status != StatusError || output.Message != "boom" {
+		t.Errorf("Expected an error output, got %+v", output)
+	}
+}
+
+// Test that read_clipboard returns the raw text and its facts as structured content
+func TestReadClipboardStructuredOutput(t *testing.T) {
+	fakeClipboard(t, "hello world")
+	handler := schemaVersionMiddleware(NewClipboardServer().readClipboardHandler)
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	output := result.StructuredContent.(*toolOutput)
+	if output.Content != "hello world" || output.MIMEType != "text/plain" || output.Size != 11 || output.Hash != contentHash("hello world") {
+		t.Errorf("Unexpected output: %+v", output)
+	}
+}
+
+// Test that tools declare the output schema unless an older format is pinned
+func TestOutputSchemaDeclared(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	NewClipboardServer().registerTools(s)
+	schema := s.GetTool("clipboard_info").Tool.OutputSchema
+	if schema.Type != "object" || schema.Properties["hash"] == nil || schema.Properties["spillPath"] == nil {
+		t.Errorf("Expected the toolOutput schema, got %+v", schema)
+	}
+
+	t.Setenv("MCP_CLIP_SCHEMA_VERSION", "2")
+	s = server.NewMCPServer("test", "1.0.0")
+	NewClipboardServer().registerTools(s)
+	if schema := s.GetTool("clipboard_info").Tool.OutputSchema; schema.Type != "" {
+		t.Errorf("Expected no output schema with version 2 pinned, got %+v", schema)
+	}
+}
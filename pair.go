@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const DefaultPairWindow = 30 * time.Second
+
+// getPairWindow returns how close together an image and a text copy must be
+// to count as a pair.
+func getPairWindow() time.Duration {
+	if windowStr := os.Getenv("MCP_CLIP_PAIR_WINDOW"); windowStr != "" {
+		if window, err := time.ParseDuration(windowStr); err == nil && window > 0 {
+			return window
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Invalid MCP_CLIP_PAIR_WINDOW format '%s', using default: %v\n", windowStr, DefaultPairWindow)
+		}
+	}
+	return DefaultPairWindow
+}
+
+// findPair checks whether the two newest history entries (newest first) are an
+// image and a text copied within window of each other, in either order.
+func findPair(entries []historyEntry, window time.Duration) (image, text historyEntry, ok bool) {
+	if len(entries) < 2 {
+		return historyEntry{}, historyEntry{}, false
+	}
+	newer, older := entries[0], entries[1]
+	if newer.Time.Sub(older.Time) > window {
+		return historyEntry{}, historyEntry{}, false
+	}
+	switch {
+	case newer.Kind == "image" && older.Kind == "text":
+		return newer, older, true
+	case newer.Kind == "text" && older.Kind == "image":
+		return older, newer, true
+	}
+	return historyEntry{}, historyEntry{}, false
+}
+
+func (cs *ClipboardServer) readClipboardPairHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	window := getPairWindow()
+	if seconds := request.GetInt("window_seconds", 0); seconds > 0 {
+		window = time.Duration(seconds) * time.Second
+	}
+
+	recent := cs.history.recent(2)
+	image, text, ok := findPair(recent, window)
+	if !ok {
+		if len(recent) < 2 {
+			return mcp.NewToolResultText("No screenshot+text pair found: fewer than two clipboard changes recorded"), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("No screenshot+text pair found: the last two clipboard changes are %s and %s, %s apart (window %v)",
+			entryLabel(recent[1]), entryLabel(recent[0]), recent[0].Time.Sub(recent[1].Time).Round(time.Second), window)), nil
+	}
+
+	if image.redacted() || text.redacted() {
+		return mcp.NewToolResultError(fmt.Sprintf("Screenshot and text copied together (#%d and #%d), but privacy mode keeps only hashes; read the clipboard when it holds each item instead", image.ID, text.ID)), nil
+	}
+	imageResult, err := handleBinaryContent(ctx, []byte(image.Content), cs)
+	if err != nil || imageResult.IsError {
+		return imageResult, err
+	}
+	textResult, err := cs.contentResult(ctx, text.Content, "text")
+	if err != nil || textResult.IsError {
+		return textResult, err
+	}
+
+	order := "image then text"
+	if text.ID < image.ID {
+		order = "text then image"
+	}
+	header := mcp.NewTextContent(fmt.Sprintf("Screenshot and text copied together (%s, %s apart): #%d and #%d",
+		order, absDuration(image.Time.Sub(text.Time)).Round(time.Second), image.ID, text.ID))
+
+	result := &mcp.CallToolResult{Content: []mcp.Content{header}}
+	result.Content = append(result.Content, imageResult.Content...)
+	result.Content = append(result.Content, textResult.Content...)
+	result.Meta = mcp.NewMetaFromMap(map[string]any{"imageSequence": image.ID, "textSequence": text.ID})
+	return result, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Test screenshot+text pair detection in both orders and outside the window
+func TestFindPair(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	start := time.Now()
+
+	h := newClipboardHistory(10)
+	h.add(png, start)
+	h.add("docker compose up", start.Add(5*time.Second))
+	image, text, ok := findPair(h.recent(2), 30*time.Second)
+	if !ok || image.Kind != "image" || text.Content != "docker compose up" {
+		t.Errorf("Expected image then text pair, got %v %+v %+v", ok, image, text)
+	}
+
+	h = newClipboardHistory(10)
+	h.add("caption", start)
+	h.add(png, start.Add(10*time.Second))
+	if _, text, ok := findPair(h.recent(2), 30*time.Second); !ok || text.Content != "caption" {
+		t.Errorf("Expected text then image pair, got %v", ok)
+	}
+	if _, _, ok := findPair(h.recent(2), 5*time.Second); ok {
+		t.Errorf("Expected no pair outside the window")
+	}
+
+	h = newClipboardHistory(10)
+	h.add("one", start)
+	h.add("two", start.Add(time.Second))
+	if _, _, ok := findPair(h.recent(2), 30*time.Second); ok {
+		t.Errorf("Expected no pair for two text copies")
+	}
+	if _, _, ok := findPair(nil, 30*time.Second); ok {
+		t.Errorf("Expected no pair for empty history")
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// macPasteScript presses Cmd+V in the frontmost application.
+	macPasteScript = `tell application "System Events" to keystroke "v" using command down`
+
+	// sendKeysPasteScript presses Ctrl+V in the foreground window. SendKeys
+	// synthesizes the keystrokes with SendInput.
+	sendKeysPasteScript = `Add-Type -AssemblyName System.Windows.Forms; [System.Windows.Forms.SendKeys]::SendWait('^v')`
+
+	defaultPasteDelay = 100 * time.Millisecond
+	maxPasteDelay     = 10 * time.Second
+)
+
+// pasteKeystrokeCandidates lists the Linux commands that can press Ctrl+V,
+// in order of preference. xdotool only reaches X11 (and XWayland) windows,
+// so the Wayland tools come first when a Wayland session is running.
+func pasteKeystrokeCandidates() [][]string {
+	candidates := [][]string{
+		{"wtype", "-M", "ctrl", "v", "-m", "ctrl"},
+		{"ydotool", "key", "29:1", "47:1", "47:0", "29:0"}, // KEY_LEFTCTRL, KEY_V
+		{"xdotool", "key", "--clearmodifiers", "ctrl+v"},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		candidates = candidates[2:]
+	}
+	return candidates
+}
+
+// pasteKeystroke sends the platform paste shortcut to the application that
+// has keyboard focus: Cmd+V through System Events on macOS, Ctrl+V through
+// SendKeys on Windows and WSL2, and wtype, ydotool or xdotool on Linux.
+func pasteKeystroke(ctx context.Context) (string, error) {
+	ctx, cancel := withReadTimeout(ctx)
+	defer cancel()
+	var cmd *exec.Cmd
+	shortcut := "Ctrl+V"
+	switch {
+	case isWSL2() || runtime.GOOS == "windows":
+		powershellPath := findPowerShell()
+		if powershellPath == "" {
+			return "", fmt.Errorf("PowerShell not found - required to send keystrokes")
+		}
+		cmd = powershellCommand(ctx, powershellPath, "-NoProfile", "-Command", sendKeysPasteScript)
+	case runtime.GOOS == "darwin":
+		cmd, shortcut = exec.CommandContext(ctx, "osascript", "-e", macPasteScript), "Cmd+V"
+	default:
+		for _, args := range pasteKeystrokeCandidates() {
+			if _, err := exec.LookPath(args[0]); err == nil {
+				cmd = exec.CommandContext(ctx, args[0], args[1:]...)
+				break
+			}
+		}
+		if cmd == nil {
+			return "", fmt.Errorf("no keystroke utility found (install wtype or ydotool on Wayland, xdotool on X11)")
+		}
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return "", timeoutError(ctx, ctx.Err())
+		}
+		return "", fmt.Errorf("%s failed (is accessibility access granted?): %v %s", cmd.Args[0], err, strings.TrimSpace(string(output)))
+	}
+	return shortcut, nil
+}
+
+// pasteIntoActiveAppHandler places content on the clipboard, unless only the
+// current clipboard should be pasted, and then presses the paste shortcut so
+// the content lands in whatever application has focus.
+func (cs *ClipboardServer) pasteIntoActiveAppHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if _, ok := selectBackend().(bridgeBackend); ok {
+		return mcp.NewToolResultError("Pasting isn't available through a clipboard bridge: keystrokes would go to this machine, not the one whose clipboard is used"), nil
+	}
+	content := request.GetString("content", "")
+	delay := time.Duration(request.GetFloat("delay_ms", float64(defaultPasteDelay/time.Millisecond))) * time.Millisecond
+	if delay < 0 || delay > maxPasteDelay {
+		return mcp.NewToolResultError(fmt.Sprintf("delay_ms must be between 0 and %d", maxPasteDelay/time.Millisecond)), nil
+	}
+
+	described := "the current clipboard content"
+	if content != "" {
+		if limit := getMaxClipboardBytes(); limit > 0 && len(content) > limit {
+			return tooLargeResult(&oversizeError{size: len(content), limit: limit, exact: true}), nil
+		}
+		if denied := confirmOverwrite(ctx, fmt.Sprintf("write %d bytes of text to the clipboard and paste them", len(content)), content); denied != nil {
+			return denied, nil
+		}
+		if err := writeClipboard(ctx, content); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to write clipboard: %v", err)), nil
+		}
+		cs.recordChange(content)
+		described = fmt.Sprintf("%d bytes", len(content))
+	}
+
+	// Give the clipboard owner a moment to serve the new content before the
+	// target application asks for it
+	select {
+	case <-ctx.Done():
+		return mcp.NewToolResultError("Paste cancelled"), nil
+	case <-time.After(delay):
+	}
+
+	shortcut, err := pasteKeystroke(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to paste %s: %v", described, err)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Pasted %s into the active application with %s", described, shortcut)), nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Test that paste_into_active_app is only offered with --allow-paste
+func TestPasteToolOptIn(t *testing.T) {
+	t.Setenv("MCP_CLIP_ALLOW_PASTE", "")
+	if opts, _ := parseServerOptions(nil); opts.paste {
+		t.Error("Expected pasting to be disabled by default")
+	}
+	if opts, _ := parseServerOptions([]string{"--allow-paste"}); !opts.paste {
+		t.Error("Expected --allow-paste to enable pasting")
+	}
+
+	cs := NewClipboardServer()
+	s := server.NewMCPServer("test", "1.0.0")
+	cs.registerTools(s)
+	if s.GetTool("paste_into_active_app") != nil {
+		t.Error("Expected paste_into_active_app not to be registered by default")
+	}
+
+	cs.allowPaste = true
+	s = server.NewMCPServer("test", "1.0.0")
+	cs.registerTools(s)
+	if s.GetTool("paste_into_active_app") == nil {
+		t.Error("Expected paste_into_active_app to be registered with --allow-paste")
+	}
+}
+
+// Test that the content is written before xdotool presses Ctrl+V
+func TestPasteIntoActiveApp(t *testing.T) {
+	file := fakeClipboard(t, "old")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	dir := t.TempDir()
+	keys := filepath.Join(dir, "keys")
+	script := "#!/bin/sh\necho \"$* $(cat " + file + ")\" > \"" + keys + "\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "xdotool"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"content": "pasted text", "delay_ms": float64(0)}
+	result, err := NewClipboardServer().pasteIntoActiveAppHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Paste failed: %v %v", err, result.Content)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Ctrl+V") {
+		t.Errorf("Expected the shortcut to be reported, got %q", text)
+	}
+	if data, _ := os.ReadFile(keys); string(data) != "key --clearmodifiers ctrl+v pasted text\n" {
+		t.Errorf("Expected Ctrl+V after the write, got %q", data)
+	}
+
+	request.Params.Arguments = map[string]any{"delay_ms": float64(60000)}
+	if result, _ := NewClipboardServer().pasteIntoActiveAppHandler(context.Background(), request); !result.IsError {
+		t.Error("Expected an out-of-range delay to be rejected")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// jxaChangeCountScript prints NSPasteboard's changeCount at startup and then
+// whenever it changes. Checking the count is a cheap integer read, unlike
+// pbpaste, which copies the whole clipboard on every poll.
+const jxaChangeCountScript = `ObjC.import('AppKit');
+var pasteboard = $.NSPasteboard.generalPasteboard;
+var stdout = $.NSFileHandle.fileHandleWithStandardOutput;
+var last = -1;
+while (true) {
+	var count = pasteboard.changeCount;
+	if (count !== last) {
+		last = count;
+		stdout.writeData($(count + '\n').dataUsingEncoding($.NSUTF8StringEncoding));
+	}
+	delay(0.5);
+}`
+
+// watchPasteboard runs a long-lived osascript helper that reports
+// changeCount increments, and calls changed for each one (and once at start,
+// so the current contents are recorded). The clipboard is only read when it
+// has actually changed. It returns when ctx is done or the helper exits.
+func watchPasteboard(ctx context.Context, changed func()) error {
+	cmd := exec.CommandContext(ctx, "osascript", "-l", "JavaScript", "-e", jxaChangeCountScript)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start changeCount helper: %v", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if _, err := strconv.Atoi(strings.TrimSpace(scanner.Text())); err == nil {
+			changed()
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("changeCount helper failed: %v", err)
+	}
+	return fmt.Errorf("changeCount helper exited")
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// Test that each changeCount the helper prints triggers a change
+func TestWatchPasteboard(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts not available")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho 41\necho 42\necho 'osascript noise'\n"
+	if err := os.WriteFile(filepath.Join(dir, "osascript"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	changes := 0
+	err := watchPasteboard(context.Background(), func() { changes++ })
+	if changes != 2 {
+		t.Errorf("Expected 2 changes, got %d", changes)
+	}
+	if err == nil {
+		t.Error("Expected an error once the helper exits")
+	}
+}
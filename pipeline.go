@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// transformStep is a named text transformation pipelines are built from.
+type transformStep struct {
+	name  string
+	apply func(string) string
+}
+
+var (
+	// ansiEscapePattern matches CSI sequences (colors, cursor movement), OSC
+	// sequences (window titles, hyperlinks) and other two-byte escapes.
+	ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+	horizontalSpace   = regexp.MustCompile(`[ \t]+`)
+	blankLineRuns     = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+)
+
+// transformSteps are the steps pipelines may use, by name.
+var transformSteps = map[string]transformStep{
+	"strip-ansi":           {"strip-ansi", stripANSI},
+	"normalize-newlines":   {"normalize-newlines", normalizeNewlines},
+	"trim":                 {"trim", strings.TrimSpace},
+	"trim-lines":           {"trim-lines", trimLines},
+	"collapse-whitespace":  {"collapse-whitespace", collapseWhitespace},
+	"collapse-blank-lines": {"collapse-blank-lines", func(s string) string { return blankLineRuns.ReplaceAllString(s, "\n\n") }},
+	"dedent":               {"dedent", dedent},
+}
+
+func stripANSI(s string) string {
+	return ansiEscapePattern.ReplaceAllString(s, "")
+}
+
+// normalizeNewlines turns CRLF into LF and resolves carriage returns used
+// to redraw a line (progress bars), keeping what was drawn last.
+func normalizeNewlines(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if cr := strings.LastIndex(line, "\r"); cr >= 0 {
+			line = line[cr+1:]
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func trimLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collapseWhitespace turns runs of spaces and tabs into single spaces,
+// keeping each line's indentation.
+func collapseWhitespace(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		rest := strings.TrimLeft(line, " \t")
+		lines[i] = line[:len(line)-len(rest)] + horizontalSpace.ReplaceAllString(rest, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// dedent removes the indentation all non-blank lines share.
+func dedent(s string) string {
+	lines := strings.Split(s, "\n")
+	prefix := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, prefix)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// transformPipelines are the named pipelines configured for read_clipboard's
+// pipeline parameter.
+type transformPipelines map[string][]transformStep
+
+// loadPipelines reads pipeline definitions from MCP_CLIP_PIPELINES_FILE, one
+// per line, and from MCP_CLIP_PIPELINES, separated by semicolons. It returns
+// nil when none are configured.
+func loadPipelines() (transformPipelines, error) {
+	pipelines := transformPipelines{}
+	if path := os.Getenv("MCP_CLIP_PIPELINES_FILE"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open pipelines file: %v", err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for lineNo := 1; scanner.Scan(); lineNo++ {
+			if err := pipelines.parse(scanner.Text()); err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", f.Name(), lineNo, err)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	for _, definition := range strings.Split(os.Getenv("MCP_CLIP_PIPELINES"), ";") {
+		if err := pipelines.parse(definition); err != nil {
+			return nil, fmt.Errorf("MCP_CLIP_PIPELINES: %v", err)
+		}
+	}
+
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+	return pipelines, nil
+}
+
+// parse adds a definition of the form "name: step, step, ...", where steps
+// may also be separated by "|", "->" or "→". Blank lines
+// and lines starting with # are ignored.
+func (p transformPipelines) parse(definition string) error {
+	definition = strings.TrimSpace(definition)
+	if definition == "" || strings.HasPrefix(definition, "#") {
+		return nil
+	}
+	name, steps, ok := strings.Cut(definition, ":")
+	if name = strings.TrimSpace(name); !ok || name == "" {
+		return fmt.Errorf("expected 'name: step, step, ...'")
+	}
+	var pipeline []transformStep
+	steps = strings.ReplaceAll(steps, "->", ",")
+	for _, stepName := range strings.FieldsFunc(steps, func(r rune) bool { return r == ',' || r == '|' || r == '→' }) {
+		stepName = strings.TrimSpace(stepName)
+		step, ok := transformSteps[stepName]
+		if !ok {
+			return fmt.Errorf("unknown step %q in pipeline %s (available: %s)", stepName, name, strings.Join(transformStepNames(), ", "))
+		}
+		pipeline = append(pipeline, step)
+	}
+	if len(pipeline) == 0 {
+		return fmt.Errorf("pipeline %s has no steps", name)
+	}
+	p[name] = pipeline
+	return nil
+}
+
+// lookup returns the named pipeline. A step name works as a pipeline of
+// that single step, so simple cleanups need no configuration.
+func (p transformPipelines) lookup(name string) ([]transformStep, error) {
+	if pipeline, ok := p[name]; ok {
+		return pipeline, nil
+	}
+	if step, ok := transformSteps[name]; ok {
+		return []transformStep{step}, nil
+	}
+	available := append(p.names(), transformStepNames()...)
+	return nil, fmt.Errorf("unknown pipeline %q (available: %s)", name, strings.Join(available, ", "))
+}
+
+func (p transformPipelines) names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func transformStepNames() []string {
+	names := make([]string, 0, len(transformSteps))
+	for name := range transformSteps {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// runPipeline applies the steps of a pipeline in order.
+func runPipeline(text string, pipeline []transformStep) (string, []string) {
+	names := make([]string, 0, len(pipeline))
+	for _, step := range pipeline {
+		text = step.apply(text)
+		names = append(names, step.name)
+	}
+	return text, names
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test each transform step on its own
+func TestTransformSteps(t *testing.T) {
+	tests := []struct {
+		step, input, want string
+	}{
+		{"strip-ansi", "\x1b[1;31merror\x1b[0m: \x1b]0;title\x07failed", "error: failed"},
+		{"normalize-newlines", "a\r\nb\r\n 10%\r 50%\r100%\ndone\r", "a\nb\n100%\ndone"},
+		{"trim", "\n  text  \n\n", "text"},
+		{"trim-lines", "a  \nb\t\n", "a\nb\n"},
+		{"collapse-whitespace", "    if  x   {\n\treturn\t\ty", "    if x {\n\treturn y"},
+		{"collapse-blank-lines", "a\n\n\n  \n\nb\n\nc", "a\n\nb\n\nc"},
+		{"dedent", "    a\n\n      b\n    c", "a\n\n  b\nc"},
+		{"dedent", "  a\n\tb", "  a\n\tb"},
+	}
+	for _, tt := range tests {
+		if got := transformSteps[tt.step].apply(tt.input); got != tt.want {
+			t.Errorf("%s(%q): expected %q, got %q", tt.step, tt.input, tt.want, got)
+		}
+	}
+}
+
+// Test loading pipelines from MCP_CLIP_PIPELINES and a pipelines file
+func TestLoadPipelines(t *testing.T) {
+	t.Setenv("MCP_CLIP_PIPELINES_FILE", "")
+	t.Setenv("MCP_CLIP_PIPELINES", "")
+	if pipelines, err := loadPipelines(); err != nil || pipelines != nil {
+		t.Errorf("Expected no pipelines, got %v, %v", pipelines, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "pipelines")
+	if err := os.WriteFile(path, []byte("# terminal output\nterminal: strip-ansi -> normalize-newlines | trim\n\ncode: dedent, trim-lines\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MCP_CLIP_PIPELINES_FILE", path)
+	t.Setenv("MCP_CLIP_PIPELINES", "prose: trim → collapse-whitespace; code: trim")
+	pipelines, err := loadPipelines()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if names := strings.Join(pipelines.names(), ","); names != "code,prose,terminal" {
+		t.Errorf("Expected code,prose,terminal, got %s", names)
+	}
+	if _, steps := runPipeline("", pipelines["terminal"]); strings.Join(steps, ",") != "strip-ansi,normalize-newlines,trim" {
+		t.Errorf("Unexpected terminal steps: %v", steps)
+	}
+	if _, steps := runPipeline("", pipelines["code"]); strings.Join(steps, ",") != "trim" {
+		t.Errorf("Expected MCP_CLIP_PIPELINES to override the file, got %v", steps)
+	}
+
+	for _, definition := range []string{"broken", ": trim", "empty:", "bad: trim, shout"} {
+		t.Setenv("MCP_CLIP_PIPELINES", definition)
+		if _, err := loadPipelines(); err == nil {
+			t.Errorf("%q: expected an error", definition)
+		}
+	}
+}
+
+// Test looking up pipelines and single steps
+func TestPipelineLookup(t *testing.T) {
+	var unconfigured transformPipelines
+	if pipeline, err := unconfigured.lookup("trim"); err != nil || len(pipeline) != 1 {
+		t.Errorf("Expected a step to work as a pipeline, got %v, %v", pipeline, err)
+	}
+	_, err := unconfigured.lookup("terminal")
+	if err == nil || !strings.Contains(err.Error(), "strip-ansi") {
+		t.Errorf("Expected an error listing the steps, got %v", err)
+	}
+}
+
+// Test read_clipboard's pipeline parameter
+func TestReadClipboardPipeline(t *testing.T) {
+	fakeClipboard(t, "\x1b[32mok\x1b[0m   build\r\n\x1b[31mFAIL\x1b[0m  test\r\n\r\n")
+	cs := NewClipboardServer()
+	cs.pipelines = transformPipelines{}
+	if err := cs.pipelines.parse("terminal: strip-ansi, normalize-newlines, collapse-whitespace, trim"); err != nil {
+		t.Fatal(err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"pipeline": "terminal", "format": "text", "end_line": float64(1)}
+	result, err := cs.readClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "ok build\n" {
+		t.Errorf("Expected the cleaned first line, got %q", text)
+	}
+	info := result.Meta.AdditionalFields["pipeline"].(map[string]any)
+	if info["name"] != "terminal" || len(info["steps"].([]string)) != 4 || info["after"].(int) >= info["before"].(int) {
+		t.Errorf("Unexpected pipeline metadata: %v", info)
+	}
+
+	for _, args := range []map[string]any{
+		{"pipeline": "shout"},
+		{"pipeline": "trim", "since_length": float64(0)},
+	} {
+		request.Params.Arguments = args
+		if result, _ := cs.readClipboardHandler(context.Background(), request); !result.IsError {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
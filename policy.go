@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const StatusPolicyDenied = "policy_denied"
+
+// contentPolicy decides which clipboard text may be returned to clients.
+// Content matching a deny rule is withheld unless an allow rule also matches;
+// with "default: deny", only content matching an allow rule is returned.
+type contentPolicy struct {
+	deny        []policyRule
+	allow       []policyRule
+	defaultDeny bool
+}
+
+type policyRule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// loadContentPolicy reads rules from MCP_CLIP_POLICY_FILE and the single-regex
+// shortcuts MCP_CLIP_POLICY_DENY and MCP_CLIP_POLICY_ALLOW. It returns nil when
+// no policy is configured.
+func loadContentPolicy() (*contentPolicy, error) {
+	policy := &contentPolicy{}
+	if path := os.Getenv("MCP_CLIP_POLICY_FILE"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open policy file: %v", err)
+		}
+		defer f.Close()
+		if err := policy.parse(f.Name(), bufio.NewScanner(f)); err != nil {
+			return nil, err
+		}
+	}
+	for _, env := range []string{"MCP_CLIP_POLICY_DENY", "MCP_CLIP_POLICY_ALLOW"} {
+		if expr := os.Getenv(env); expr != "" {
+			directive := strings.ToLower(strings.TrimPrefix(env, "MCP_CLIP_POLICY_"))
+			if err := policy.addRule(directive, env, expr); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(policy.deny) == 0 && len(policy.allow) == 0 && !policy.defaultDeny {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+// parse reads policy lines of the form "deny: regex", "allow name: regex" or
+// "default: deny". Blank lines and lines starting with # are ignored.
+func (p *contentPolicy) parse(source string, scanner *bufio.Scanner) error {
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		head, expr, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected 'deny: <regex>', 'allow: <regex>' or 'default: deny'", source, lineNo)
+		}
+		directive, name, _ := strings.Cut(strings.TrimSpace(head), " ")
+		if name = strings.TrimSpace(name); name == "" {
+			name = fmt.Sprintf("%s:%d", source, lineNo)
+		}
+		if err := p.addRule(strings.ToLower(directive), name, strings.TrimSpace(expr)); err != nil {
+			return fmt.Errorf("%s:%d: %v", source, lineNo, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func (p *contentPolicy) addRule(directive, name, expr string) error {
+	if directive == "default" {
+		switch strings.ToLower(expr) {
+		case "deny":
+			p.defaultDeny = true
+		case "allow":
+			p.defaultDeny = false
+		default:
+			return fmt.Errorf("default must be 'allow' or 'deny', got %q", expr)
+		}
+		return nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern for rule %s: %v", name, err)
+	}
+	switch directive {
+	case "deny":
+		p.deny = append(p.deny, policyRule{name, re})
+	case "allow":
+		p.allow = append(p.allow, policyRule{name, re})
+	default:
+		return fmt.Errorf("unknown directive %q", directive)
+	}
+	return nil
+}
+
+// check reports whether content is denied and by which rule.
+func (p *contentPolicy) check(content string) (bool, string) {
+	if p == nil {
+		return false, ""
+	}
+	for _, rule := range p.allow {
+		if rule.pattern.MatchString(content) {
+			return false, ""
+		}
+	}
+	for _, rule := range p.deny {
+		if rule.pattern.MatchString(content) {
+			return true, rule.name
+		}
+	}
+	if p.defaultDeny {
+		return true, "default"
+	}
+	return false, ""
+}
+
+func policyDeniedResult(rule string) *mcp.CallToolResult {
+	result := mcp.NewToolResultError(fmt.Sprintf("Clipboard content withheld by content policy (rule %s)", rule))
+	result.Meta = mcp.NewMetaFromMap(map[string]any{"status": StatusPolicyDenied, "rule": rule})
+	return result
+}
+
+// policyResult returns a policy error if content may not be returned, or nil.
+func (cs *ClipboardServer) policyResult(content string) *mcp.CallToolResult {
+	if cs == nil {
+		return nil
+	}
+	if denied, rule := cs.policy.check(content); denied {
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Withheld %d bytes of clipboard content (policy rule %s)\n", len(content), rule)
+		}
+		return policyDeniedResult(rule)
+	}
+	return nil
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	portalService       = "org.freedesktop.portal.Desktop"
+	portalPath          = dbus.ObjectPath("/org/freedesktop/portal/desktop")
+	portalRemoteDesktop = "org.freedesktop.portal.RemoteDesktop"
+	portalClipboard     = "org.freedesktop.portal.Clipboard"
+	portalRequest       = "org.freedesktop.portal.Request"
+
+	// portalPersistUntilRevoked keeps the user's approval across restarts
+	portalPersistUntilRevoked = uint32(2)
+	// portalDeviceKeyboard is the smallest device type SelectDevices takes.
+	// Without types the portal offers keyboard and pointer control; the
+	// session only needs to exist for clipboard access.
+	portalDeviceKeyboard = uint32(1)
+	// portalApprovalTimeout leaves the user time to answer the permission dialog
+	portalApprovalTimeout = 2 * time.Minute
+	// portalRetryBackoff is how long a failed session start is reported
+	// again before the portal is asked anew
+	portalRetryBackoff = 30 * time.Second
+)
+
+// errPortalDenied is returned once the user has declined the permission
+// dialog. It is remembered until the server restarts, so the user isn't
+// asked again every time the clipboard is read.
+var errPortalDenied = errors.New("clipboard access was denied in the portal permission dialog; restart mcp-clip to be asked again")
+
+// portalBackend reads and writes the clipboard through xdg-desktop-portal,
+// for sandboxes (Flatpak) and compositors that deny direct X11/Wayland
+// clipboard access. The Clipboard portal only works within a RemoteDesktop
+// session, so the first access asks the user for permission; the approval
+// is remembered in the data dir where the portal supports it.
+type portalBackend struct{}
+
+func (portalBackend) Name() string { return "portal" }
+
+func (b portalBackend) Read(ctx context.Context) (string, error) {
+	return b.ReadLimited(ctx, 0)
+}
+
+func (portalBackend) ReadLimited(ctx context.Context, limit int) (string, error) {
+	session, err := portal.open(ctx)
+	if err != nil {
+		return "", err
+	}
+	return session.read(ctx, limit)
+}
+
+func (portalBackend) Write(ctx context.Context, content string) error {
+	session, err := portal.open(ctx)
+	if err != nil {
+		return err
+	}
+	return session.write(ctx, content)
+}
+
+// WatchChanges calls changed whenever the portal reports a new selection owner.
+func (portalBackend) WatchChanges(ctx context.Context, changed func()) error {
+	session, err := portal.open(ctx)
+	if err != nil {
+		return err
+	}
+	notify := session.subscribe()
+	defer session.unsubscribe(notify)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-notify:
+			if !ok {
+				return fmt.Errorf("portal session closed")
+			}
+			changed()
+		}
+	}
+}
+
+// isFlatpak reports whether the server runs inside a Flatpak sandbox, where
+// the clipboard is only reachable through the portal.
+func isFlatpak() bool {
+	if os.Getenv("FLATPAK_ID") != "" {
+		return true
+	}
+	_, err := os.Stat("/.flatpak-info")
+	return err == nil
+}
+
+// portal is the process-wide portal session, opened on first use.
+var portal = &portalClient{}
+
+type portalClient struct {
+	mu       sync.Mutex
+	session  *portalSession
+	starting *portalStart // in-progress session start, if any
+	failed   *portalStart // last failed start, reported instead of re-prompting
+	failedAt time.Time
+
+	// connect creates a session; nil means newPortalSession
+	connect func(ctx context.Context) (*portalSession, error)
+}
+
+type portalStart struct {
+	done    chan struct{}
+	session *portalSession
+	err     error
+}
+
+// portalSession is an approved RemoteDesktop session with clipboard access.
+type portalSession struct {
+	conn   *dbus.Conn
+	handle dbus.ObjectPath
+
+	mu        sync.Mutex
+	requests  map[dbus.ObjectPath]chan portalResponse
+	mimeTypes []string // offered by the current selection owner
+	owned     *string  // content this session has put on the clipboard
+	watchers  map[chan struct{}]struct{}
+}
+
+type portalResponse struct {
+	code    uint32 // 0 success, 1 cancelled by the user, 2 other error
+	results map[string]dbus.Variant
+}
+
+// open returns the shared session, creating it if needed. Creating it shows
+// the user a permission dialog, so it runs in the background with its own
+// timeout: a caller that gives up waiting doesn't abort the dialog. A failed
+// start is returned again for portalRetryBackoff, and a denial for good,
+// rather than showing the dialog on every read.
+func (p *portalClient) open(ctx context.Context) (*portalSession, error) {
+	p.mu.Lock()
+	if session := p.session; session != nil {
+		p.mu.Unlock()
+		return session, nil
+	}
+	if failed := p.failed; failed != nil && (errors.Is(failed.err, errPortalDenied) || time.Since(p.failedAt) < portalRetryBackoff) {
+		p.mu.Unlock()
+		return nil, failed.err
+	}
+	attempt := p.starting
+	if attempt == nil {
+		attempt = &portalStart{done: make(chan struct{})}
+		p.starting = attempt
+		go p.start(attempt)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-attempt.done:
+		return attempt.session, attempt.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("still waiting for clipboard permission from the portal: %v", ctx.Err())
+	}
+}
+
+func (p *portalClient) start(attempt *portalStart) {
+	ctx, cancel := context.WithTimeout(context.Background(), portalApprovalTimeout)
+	defer cancel()
+	connect := p.connect
+	if connect == nil {
+		connect = newPortalSession
+	}
+	attempt.session, attempt.err = connect(ctx)
+
+	p.mu.Lock()
+	p.session, p.starting = attempt.session, nil
+	p.failed = nil
+	if attempt.err != nil {
+		p.failed, p.failedAt = attempt, time.Now()
+	}
+	p.mu.Unlock()
+	close(attempt.done)
+}
+
+func newPortalSession(ctx context.Context) (*portalSession, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the D-Bus session bus: %v", err)
+	}
+	session := &portalSession{
+		conn:     conn,
+		requests: make(map[dbus.ObjectPath]chan portalResponse),
+		watchers: make(map[chan struct{}]struct{}),
+	}
+	if err := session.start(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return session, nil
+}
+
+// start creates the RemoteDesktop session, enables the clipboard on it and
+// waits for the user's approval.
+func (s *portalSession) start(ctx context.Context) error {
+	if err := s.conn.AddMatchSignal(dbus.WithMatchInterface(portalRequest), dbus.WithMatchMember("Response")); err != nil {
+		return err
+	}
+	if err := s.conn.AddMatchSignal(dbus.WithMatchInterface(portalClipboard)); err != nil {
+		return err
+	}
+	signals := make(chan *dbus.Signal, 16)
+	s.conn.Signal(signals)
+	go s.dispatch(signals)
+
+	desktop := s.conn.Object(portalService, portalPath)
+	response, err := s.request(ctx, func(token string) *dbus.Call {
+		return desktop.CallWithContext(ctx, portalRemoteDesktop+".CreateSession", 0, map[string]dbus.Variant{
+			"handle_token":         dbus.MakeVariant(token),
+			"session_handle_token": dbus.MakeVariant(newPortalToken()),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create portal session: %w", err)
+	}
+	switch handle := response.results["session_handle"].Value().(type) {
+	case string:
+		s.handle = dbus.ObjectPath(handle)
+	case dbus.ObjectPath:
+		s.handle = handle
+	default:
+		return fmt.Errorf("portal returned no session handle")
+	}
+
+	if err := desktop.CallWithContext(ctx, portalClipboard+".RequestClipboard", 0, s.handle, map[string]dbus.Variant{}).Err; err != nil {
+		return fmt.Errorf("the portal has no clipboard support: %v", err)
+	}
+
+	devices := portalDeviceOptions(loadPortalRestoreToken())
+	if _, err := s.request(ctx, func(token string) *dbus.Call {
+		devices["handle_token"] = dbus.MakeVariant(token)
+		return desktop.CallWithContext(ctx, portalRemoteDesktop+".SelectDevices", 0, s.handle, devices)
+	}); err != nil {
+		return fmt.Errorf("portal device selection failed: %w", err)
+	}
+
+	response, err = s.request(ctx, func(token string) *dbus.Call {
+		return desktop.CallWithContext(ctx, portalRemoteDesktop+".Start", 0, s.handle, "", map[string]dbus.Variant{
+			"handle_token": dbus.MakeVariant(token),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("portal session was not approved: %w", err)
+	}
+	if enabled, _ := response.results["clipboard_enabled"].Value().(bool); !enabled {
+		return fmt.Errorf("the portal session was approved without clipboard access")
+	}
+	if token, ok := response.results["restore_token"].Value().(string); ok {
+		savePortalRestoreToken(token)
+	}
+	return nil
+}
+
+// portalDeviceOptions are the SelectDevices options: the fewest devices the
+// portal accepts, remembered until revoked, and the approval to restore.
+func portalDeviceOptions(restoreToken string) map[string]dbus.Variant {
+	options := map[string]dbus.Variant{
+		"types":        dbus.MakeVariant(portalDeviceKeyboard),
+		"persist_mode": dbus.MakeVariant(portalPersistUntilRevoked),
+	}
+	if restoreToken != "" {
+		options["restore_token"] = dbus.MakeVariant(restoreToken)
+	}
+	return options
+}
+
+// request calls a portal method that answers with a Request object, and
+// waits for that request's Response signal.
+func (s *portalSession) request(ctx context.Context, call func(token string) *dbus.Call) (portalResponse, error) {
+	token := newPortalToken()
+	path := portalRequestPath(s.conn.Names()[0], token)
+	responses := make(chan portalResponse, 1)
+	s.mu.Lock()
+	s.requests[path] = responses
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.requests, path)
+		s.mu.Unlock()
+	}()
+
+	if err := call(token).Err; err != nil {
+		return portalResponse{}, err
+	}
+	select {
+	case response := <-responses:
+		switch response.code {
+		case 0:
+			return response, nil
+		case 1:
+			return response, errPortalDenied
+		default:
+			return response, fmt.Errorf("portal request failed")
+		}
+	case <-ctx.Done():
+		return portalResponse{}, ctx.Err()
+	}
+}
+
+// portalRequestPath predicts the Request object a portal call will create,
+// so its Response can't be missed: the sender's unique name without the
+// colon and with dots replaced, followed by the handle token.
+func portalRequestPath(uniqueName, token string) dbus.ObjectPath {
+	sender := strings.ReplaceAll(strings.TrimPrefix(uniqueName, ":"), ".", "_")
+	return dbus.ObjectPath("/org/freedesktop/portal/desktop/request/" + sender + "/" + token)
+}
+
+func newPortalToken() string {
+	return fmt.Sprintf("mcp_clip_%d", rand.Uint32())
+}
+
+// dispatch routes portal signals until the connection closes.
+func (s *portalSession) dispatch(signals <-chan *dbus.Signal) {
+	for signal := range signals {
+		switch signal.Name {
+		case portalRequest + ".Response":
+			var response portalResponse
+			if err := dbus.Store(signal.Body, &response.code, &response.results); err != nil {
+				continue
+			}
+			s.mu.Lock()
+			if responses, ok := s.requests[signal.Path]; ok {
+				responses <- response
+			}
+			s.mu.Unlock()
+		case portalClipboard + ".SelectionOwnerChanged":
+			var session dbus.ObjectPath
+			var options map[string]dbus.Variant
+			if err := dbus.Store(signal.Body, &session, &options); err != nil || session != s.handle {
+				continue
+			}
+			s.ownerChanged(options)
+		case portalClipboard + ".SelectionTransfer":
+			var session dbus.ObjectPath
+			var mimeType string
+			var serial uint32
+			if err := dbus.Store(signal.Body, &session, &mimeType, &serial); err != nil || session != s.handle {
+				continue
+			}
+			go s.transfer(serial)
+		}
+	}
+
+	s.mu.Lock()
+	for notify := range s.watchers {
+		close(notify)
+	}
+	s.watchers = nil
+	s.mu.Unlock()
+	portal.mu.Lock()
+	if portal.session == s {
+		portal.session = nil
+	}
+	portal.mu.Unlock()
+}
+
+func (s *portalSession) ownerChanged(options map[string]dbus.Variant) {
+	mimeTypes, _ := options["mime_types"].Value().([]string)
+	isOwner, _ := options["session_is_owner"].Value().(bool)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mimeTypes = mimeTypes
+	if !isOwner {
+		s.owned = nil
+	}
+	for notify := range s.watchers {
+		select {
+		case notify <- struct{}{}:
+		default: // a change is already pending
+		}
+	}
+}
+
+func (s *portalSession) subscribe() chan struct{} {
+	notify := make(chan struct{}, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.watchers == nil {
+		close(notify)
+	} else {
+		s.watchers[notify] = struct{}{}
+	}
+	return notify
+}
+
+func (s *portalSession) unsubscribe(notify chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.watchers, notify)
+}
+
+// read fetches the current selection, preferring text over other formats.
+func (s *portalSession) read(ctx context.Context, limit int) (string, error) {
+	s.mu.Lock()
+	owned, mimeTypes := s.owned, s.mimeTypes
+	s.mu.Unlock()
+	if owned != nil {
+		return *owned, nil
+	}
+	mimeType := preferredMIMEType(mimeTypes)
+	if mimeType == "" {
+		return "", nil // nothing on the clipboard
+	}
+
+	var fd dbus.UnixFD
+	err := s.conn.Object(portalService, portalPath).CallWithContext(ctx, portalClipboard+".SelectionRead", 0, s.handle, mimeType).Store(&fd)
+	if err != nil {
+		return "", timeoutError(ctx, fmt.Errorf("portal SelectionRead failed: %v", err))
+	}
+	file := os.NewFile(uintptr(fd), "portal-selection")
+	defer file.Close()
+	stop := context.AfterFunc(ctx, func() { file.Close() })
+	defer stop()
+
+	reader := io.Reader(file)
+	if limit > 0 {
+		reader = io.LimitReader(file, int64(limit)+1)
+	}
+	data, err := io.ReadAll(reader)
+	if ctx.Err() != nil {
+		return "", timeoutError(ctx, ctx.Err())
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the portal selection: %v", err)
+	}
+	if limit > 0 && len(data) > limit {
+		return "", &oversizeError{size: len(data), limit: limit}
+	}
+	return string(data), nil
+}
+
+// write takes ownership of the selection; the content is served to other
+// applications on request through SelectionTransfer.
+func (s *portalSession) write(ctx context.Context, content string) error {
+	s.mu.Lock()
+	s.owned = &content
+	s.mu.Unlock()
+	options := map[string]dbus.Variant{"mime_types": dbus.MakeVariant(textMIMETypes)}
+	if err := s.conn.Object(portalService, portalPath).CallWithContext(ctx, portalClipboard+".SetSelection", 0, s.handle, options).Err; err != nil {
+		return timeoutError(ctx, fmt.Errorf("portal SetSelection failed: %v", err))
+	}
+	return nil
+}
+
+// transfer hands the owned content to an application pasting it.
+func (s *portalSession) transfer(serial uint32) {
+	s.mu.Lock()
+	owned := s.owned
+	s.mu.Unlock()
+
+	desktop := s.conn.Object(portalService, portalPath)
+	success := false
+	if owned != nil {
+		var fd dbus.UnixFD
+		if err := desktop.Call(portalClipboard+".SelectionWrite", 0, s.handle, serial).Store(&fd); err == nil {
+			file := os.NewFile(uintptr(fd), "portal-transfer")
+			_, err = io.WriteString(file, *owned)
+			success = file.Close() == nil && err == nil
+		}
+	}
+	desktop.Call(portalClipboard+".SelectionWriteDone", 0, s.handle, serial, success)
+}
+
+func portalRestoreTokenPath() string {
+	if dir := dataDir(); dir != "" {
+		return filepath.Join(dir, "portal-restore-token")
+	}
+	return ""
+}
+
+func loadPortalRestoreToken() string {
+	path := portalRestoreTokenPath()
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// savePortalRestoreToken remembers the user's approval; restore tokens are
+// single-use, so every session start replaces it.
+func savePortalRestoreToken(token string) {
+	path := portalRestoreTokenPath()
+	if path == "" {
+		return
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err == nil {
+		err = os.WriteFile(path, []byte(token), 0600)
+	}
+	if err != nil && os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Failed to save portal restore token: %v\n", err)
+	}
+}
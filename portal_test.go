@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Test that request paths follow the portal's sender naming rules
+func TestPortalRequestPath(t *testing.T) {
+	if got := portalRequestPath(":1.42", "mcp_clip_7"); got != "/org/freedesktop/portal/desktop/request/1_42/mcp_clip_7" {
+		t.Errorf("Unexpected request path %s", got)
+	}
+}
+
+// Test that a failed session start is remembered instead of prompting the
+// user again on every read
+func TestPortalStartFailureCached(t *testing.T) {
+	var starts int
+	failure := errPortalDenied
+	client := &portalClient{connect: func(ctx context.Context) (*portalSession, error) {
+		starts++
+		return nil, failure
+	}}
+
+	for range 3 {
+		if _, err := client.open(context.Background()); !errors.Is(err, errPortalDenied) {
+			t.Errorf("Expected the denial, got %v", err)
+		}
+	}
+	client.failedAt = time.Now().Add(-2 * portalRetryBackoff)
+	if _, err := client.open(context.Background()); !errors.Is(err, errPortalDenied) {
+		t.Errorf("Expected the denial, got %v", err)
+	}
+	if starts != 1 {
+		t.Errorf("Expected a denial to be asked once, got %d starts", starts)
+	}
+
+	failure = errors.New("no portal")
+	client = &portalClient{connect: func(ctx context.Context) (*portalSession, error) {
+		starts++
+		return nil, failure
+	}}
+	starts = 0
+	client.open(context.Background())
+	client.open(context.Background())
+	if starts != 1 {
+		t.Errorf("Expected a failure to be cached during the backoff, got %d starts", starts)
+	}
+	client.failedAt = time.Now().Add(-2 * portalRetryBackoff)
+	client.open(context.Background())
+	if starts != 2 {
+		t.Errorf("Expected a retry after the backoff, got %d starts", starts)
+	}
+}
+
+// Test that only a keyboard is requested along with the clipboard
+func TestPortalDeviceOptions(t *testing.T) {
+	options := portalDeviceOptions("")
+	if types, ok := options["types"].Value().(uint32); !ok || types != portalDeviceKeyboard {
+		t.Errorf("Expected only the keyboard device type, got %v", options["types"])
+	}
+	if _, ok := options["restore_token"]; ok {
+		t.Errorf("Expected no restore token, got %v", options)
+	}
+	if token := portalDeviceOptions("saved")["restore_token"]; token != dbus.MakeVariant("saved") {
+		t.Errorf("Expected the restore token, got %v", token)
+	}
+}
+
+// Test that text formats are preferred over images and other formats
+func TestPreferredMIMEType(t *testing.T) {
+	cases := []struct {
+		offered  []string
+		expected string
+	}{
+		{[]string{"image/png", "text/plain", "text/plain;charset=utf-8"}, "text/plain;charset=utf-8"},
+		{[]string{"application/x-foo", "image/png"}, "image/png"},
+		{[]string{"application/x-foo"}, "application/x-foo"},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if got := preferredMIMEType(c.offered); got != c.expected {
+			t.Errorf("Expected %q for %v, got %q", c.expected, c.offered, got)
+		}
+	}
+}
+
+// Test that restore tokens round-trip through the data dir
+func TestPortalRestoreToken(t *testing.T) {
+	t.Setenv("MCP_CLIP_DATA_DIR", t.TempDir())
+	if token := loadPortalRestoreToken(); token != "" {
+		t.Errorf("Expected no token yet, got %q", token)
+	}
+	savePortalRestoreToken("abc-123")
+	if token := loadPortalRestoreToken(); token != "abc-123" {
+		t.Errorf("Expected saved token, got %q", token)
+	}
+}
+
+// Test that Flatpak sandboxes use the portal backend
+func TestSelectBackendInFlatpak(t *testing.T) {
+	t.Setenv("MCP_CLIP_BACKEND", "")
+	t.Setenv("FLATPAK_ID", "com.example.Client")
+	t.Setenv("TERMUX_VERSION", "")
+	if name := selectBackend().Name(); name != "portal" {
+		t.Errorf("Expected portal backend in Flatpak, got %s", name)
+	}
+}
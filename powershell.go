@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// powershellWaitDelay bounds how long a killed PowerShell may keep its output
+// pipes open through leftover child processes before they are closed anyway.
+const powershellWaitDelay = time.Second
+
+// powershellCommand prepares a PowerShell invocation bounded by ctx, which
+// should carry the read timeout. When ctx ends, the whole process tree is
+// killed: under WSL2 powershell.exe runs behind an interop process, and a
+// plain kill of the direct child can leave the rest hanging on to stdout.
+func powershellCommand(ctx context.Context, powershellPath string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, powershellPath, args...)
+	killTreeOnCancel(cmd)
+	cmd.WaitDelay = powershellWaitDelay
+	return cmd
+}
+
+// foundPowerShell caches lookupPowerShell, which searches every mounted
+// Windows drive under WSL2 and would otherwise run on every clipboard read.
+var foundPowerShell = sync.OnceValue(lookupPowerShell)
+
+// findPowerShell returns the PowerShell to run clipboard scripts with,
+// preferring PowerShell 7 (pwsh.exe), which starts faster, over Windows
+// PowerShell 5.1. MCP_CLIP_POWERSHELL names one explicitly; otherwise the
+// one lookupPowerShell found first is used for the rest of the process.
+func findPowerShell() string {
+	if configured := os.Getenv("MCP_CLIP_POWERSHELL"); configured != "" {
+		return configured
+	}
+	return foundPowerShell()
+}
+
+// lookupPowerShell looks for pwsh.exe, then powershell.exe, on PATH and,
+// under WSL2, on every mounted Windows drive.
+func lookupPowerShell() string {
+	for _, name := range []string{"pwsh.exe", "powershell.exe"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+		if runtime.GOOS == "windows" {
+			continue
+		}
+		if path := findOnWindowsDrives(name); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// windowsPowerShellPaths are where the PowerShells install, relative to a
+// Windows drive. PowerShell 7 installs into a directory per major version.
+var windowsPowerShellPaths = map[string]string{
+	"pwsh.exe":       "Program Files/PowerShell/*/pwsh.exe",
+	"powershell.exe": "Windows/System32/WindowsPowerShell/v1.0/powershell.exe",
+}
+
+// findOnWindowsDrives looks for a PowerShell executable on the Windows drives
+// WSL mounts (/mnt/c, /mnt/d, ...), taking the newest version if several are
+// installed. drvfs is case-insensitive, so the paths match any capitalization.
+func findOnWindowsDrives(name string) string {
+	pattern := filepath.Join(wslMountRoot(), "[a-z]", windowsPowerShellPaths[name])
+	matches, _ := filepath.Glob(pattern)
+	return newestPowerShell(matches)
+}
+
+// newestPowerShell picks the install with the highest major version from
+// paths, comparing version directories as numbers so 10 beats 7. Of equal
+// versions the one on the first drive wins, as Windows itself installs to C:.
+func newestPowerShell(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	paths = append([]string(nil), paths...)
+	sort.SliceStable(paths, func(i, j int) bool {
+		if vi, vj := powershellVersion(paths[i]), powershellVersion(paths[j]); vi != vj {
+			return vi > vj
+		}
+		return paths[i] < paths[j]
+	})
+	return paths[0]
+}
+
+// powershellVersion returns the major version named by the directory holding
+// a PowerShell 7 executable ("7", "7-preview"), or 0 if it names none.
+func powershellVersion(path string) int {
+	dir := filepath.Base(filepath.Dir(path))
+	version, _ := strconv.Atoi(dir[:len(dir)-len(strings.TrimLeft(dir, "0123456789"))])
+	return version
+}
+
+// wslMountRoot returns where WSL mounts Windows drives: /mnt/ unless
+// /etc/wsl.conf sets another [automount] root.
+func wslMountRoot() string {
+	root := "/mnt/"
+	file, err := os.Open("/etc/wsl.conf")
+	if err != nil {
+		return root
+	}
+	defer file.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if ok && section == "automount" && strings.TrimSpace(key) == "root" {
+			root = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return root
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// Test that a hung PowerShell, including a child holding its output open,
+// is killed when the timeout expires
+func TestPowerShellCommandTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts not available")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\nsleep 30 &\nsleep 30\n"
+	powershellPath := filepath.Join(dir, "powershell.exe")
+	if err := os.WriteFile(powershellPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := runLimited(ctx, powershellCommand(ctx, powershellPath, "-Command", "Get-Clipboard -Raw"), 0)
+	if err == nil {
+		t.Error("Expected the hung command to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the process tree to be killed promptly, took %v", elapsed)
+	}
+	if err := timeoutError(ctx, err); err == nil || err.Error() != "clipboard utility timed out after 10s" {
+		t.Errorf("Expected a clear timeout error, got %v", err)
+	}
+}
+
+// Test that pwsh.exe is preferred over powershell.exe on PATH, and that
+// MCP_CLIP_POWERSHELL overrides both
+func TestFindPowerShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("PATH lookup of .exe names differs on Windows")
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"pwsh.exe", "powershell.exe"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	if got := lookupPowerShell(); got != filepath.Join(dir, "pwsh.exe") {
+		t.Errorf("Expected pwsh.exe to be preferred, got %s", got)
+	}
+	os.Remove(filepath.Join(dir, "pwsh.exe"))
+	if got := lookupPowerShell(); got != filepath.Join(dir, "powershell.exe") && findOnWindowsDrives("pwsh.exe") == "" {
+		t.Errorf("Expected powershell.exe without pwsh.exe, got %s", got)
+	}
+	t.Setenv("MCP_CLIP_POWERSHELL", "/opt/pwsh")
+	if got := findPowerShell(); got != "/opt/pwsh" {
+		t.Errorf("Expected the configured PowerShell, got %s", got)
+	}
+}
+
+// Test that the highest PowerShell version wins by number, then the first drive
+func TestNewestPowerShell(t *testing.T) {
+	paths := []string{
+		"/mnt/d/Program Files/PowerShell/7/pwsh.exe",
+		"/mnt/c/Program Files/PowerShell/10/pwsh.exe",
+		"/mnt/c/Program Files/PowerShell/7-preview/pwsh.exe",
+		"/mnt/e/Program Files/PowerShell/10/pwsh.exe",
+	}
+	if got := newestPowerShell(paths); got != "/mnt/c/Program Files/PowerShell/10/pwsh.exe" {
+		t.Errorf("Expected version 10 on drive C, got %s", got)
+	}
+	if got := newestPowerShell(paths[:1]); got != paths[0] {
+		t.Errorf("Expected the only install, got %s", got)
+	}
+	if got := newestPowerShell(nil); got != "" {
+		t.Errorf("Expected no install, got %s", got)
+	}
+}
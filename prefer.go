@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// preferredFlavor walks read_clipboard's prefer list and returns the first
+// representation the clipboard can provide. content is the clipboard as
+// read; it satisfies preferences matching its own MIME type (image/png,
+// application/json, image/*, */*...), and text/plain whenever it is text.
+// HTML, Markdown (converted from HTML) and RTF are only read when one of them
+// comes before a match.
+func preferredFlavor(ctx context.Context, content string, prefer []string) (clipboardFlavor, bool) {
+	contentType := contentMIMEType(content)
+	for _, preference := range prefer {
+		mimeType, _, err := mime.ParseMediaType(preference)
+		if err != nil {
+			continue
+		}
+		switch {
+		case mimeTypeMatches(mimeType, contentType):
+			return clipboardFlavor{mimeType: contentType, content: content}, true
+		case mimeType == "text/plain" && isProbablyText(content):
+			return clipboardFlavor{mimeType: mimeType, ext: "txt", content: content}, true
+		case mimeType == "text/html" || mimeType == "text/markdown":
+			source, err := readClipboardHTML(ctx)
+			if err != nil || strings.TrimSpace(source) == "" {
+				debugFlavorUnavailable(mimeType, err)
+				continue
+			}
+			if mimeType == "text/html" {
+				return clipboardFlavor{mimeType: mimeType, ext: "html", content: source}, true
+			}
+			if markdown, err := htmlToMarkdown(source); err == nil {
+				return clipboardFlavor{mimeType: mimeType, ext: "md", content: markdown}, true
+			}
+		case mimeType == "text/rtf" || mimeType == "application/rtf" || mimeType == "text/richtext":
+			source, err := readClipboardRTF(ctx)
+			if source = strings.TrimRight(source, "\x00"); err != nil || strings.TrimSpace(source) == "" {
+				debugFlavorUnavailable(mimeType, err)
+				continue
+			}
+			return clipboardFlavor{mimeType: "text/rtf", ext: "rtf", content: source}, true
+		}
+	}
+	return clipboardFlavor{}, false
+}
+
+// mimeTypeMatches reports whether a preference such as image/png, image/*
+// or */* covers mimeType.
+func mimeTypeMatches(pattern, mimeType string) bool {
+	if pattern == "*/*" || pattern == mimeType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pattern, "/*")
+	return ok && strings.HasPrefix(mimeType, prefix+"/")
+}
+
+func debugFlavorUnavailable(mimeType string, err error) {
+	if err != nil && os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Clipboard %s flavor unavailable: %v\n", mimeType, err)
+	}
+}
+
+// isDerivedFlavor reports whether a preferred flavor was read separately
+// from the clipboard content, rather than being that content itself.
+func isDerivedFlavor(flavor clipboardFlavor) bool {
+	switch flavor.mimeType {
+	case "text/html", "text/markdown", "text/rtf":
+		return true
+	}
+	return false
+}
+
+// flavorResult returns an HTML, Markdown or RTF flavor chosen by prefer as
+// text, spilling it to a temp file like any large text.
+func (cs *ClipboardServer) flavorResult(ctx context.Context, flavor clipboardFlavor) (*mcp.CallToolResult, error) {
+	if denied := cs.policyResult(flavor.content); denied != nil {
+		return denied, nil
+	}
+	var result *mcp.CallToolResult
+	if len(flavor.content) > getInlineThresholds().text {
+		spill, err := cs.spillToFile(ctx, textSource(flavor.content), flavor.ext)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to save large %s content to temp file: %v", flavor.mimeType, err)), nil
+		}
+		result = withSpill(mcp.NewToolResultText(fmt.Sprintf("Clipboard %s content too large (%d bytes). Saved to: %s", flavor.mimeType, len(flavor.content), spill)), spill)
+	} else {
+		result = withOutputContent(mcp.NewToolResultText(flavor.content), flavor.content)
+	}
+	meta := resultMeta(result)
+	meta["mimeType"] = flavor.mimeType
+	if flavor.mimeType == "text/markdown" {
+		meta["sourceMimeType"] = "text/html"
+	}
+	return result, nil
+}
+
+// annotatePreference records which flavor the prefer list resolved to, or
+// that none was available and the default representation was returned.
+func annotatePreference(result *mcp.CallToolResult, flavor clipboardFlavor, matched bool) {
+	meta := resultMeta(result)
+	meta["preferMatched"] = matched
+	if matched {
+		meta["flavor"] = flavor.mimeType
+	}
+}
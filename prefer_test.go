@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test MIME type patterns of the prefer parameter
+func TestMimeTypeMatches(t *testing.T) {
+	tests := []struct {
+		pattern, mimeType string
+		want              bool
+	}{
+		{"image/png", "image/png", true},
+		{"image/*", "image/jpeg", true},
+		{"*/*", "application/json", true},
+		{"image/png", "image/jpeg", false},
+		{"text/*", "textual/plain", false},
+	}
+	for _, tt := range tests {
+		if got := mimeTypeMatches(tt.pattern, tt.mimeType); got != tt.want {
+			t.Errorf("mimeTypeMatches(%s, %s): expected %v, got %v", tt.pattern, tt.mimeType, tt.want, got)
+		}
+	}
+}
+
+// Test that the first available preferred flavor is returned
+func TestReadClipboardPrefer(t *testing.T) {
+	fakeClipboard(t, "Hello world")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncase \"$*\" in\n*text/html*) printf '<p>Hello <b>world</b></p>' ;;\n*) exit 1 ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		prefer        []any
+		flavor, text  string
+		preferMatched bool
+	}{
+		{[]any{"image/png", "text/html", "text/plain"}, "text/html", "<p>Hello <b>world</b></p>", true},
+		{[]any{"text/markdown"}, "text/markdown", "Hello **world**", true},
+		{[]any{"image/*", "text/rtf", "text/plain"}, "text/plain", "Hello world", true},
+		{[]any{"image/png"}, "", "Clipboard text content:\nHello world", false},
+	}
+	for _, tt := range tests {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"prefer": tt.prefer}
+		result, err := NewClipboardServer().readClipboardHandler(context.Background(), request)
+		if err != nil || result.IsError {
+			t.Fatalf("%v: read failed: %v %v", tt.prefer, err, result.Content)
+		}
+		if text := result.Content[0].(mcp.TextContent).Text; text != tt.text {
+			t.Errorf("%v: expected %q, got %q", tt.prefer, tt.text, text)
+		}
+		meta := result.Meta.AdditionalFields
+		if meta["preferMatched"] != tt.preferMatched || (tt.preferMatched && meta["flavor"] != tt.flavor) {
+			t.Errorf("%v: expected flavor %q, got %v", tt.prefer, tt.flavor, meta)
+		}
+	}
+}
+
+// Test that image content satisfies image preferences before text ones
+func TestPreferredFlavorImage(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	flavor, ok := preferredFlavor(context.Background(), png, []string{"text/plain", "image/*"})
+	if !ok || flavor.mimeType != "image/png" || flavor.content != png {
+		t.Errorf("Expected the PNG to match image/*, got %q (%v)", flavor.mimeType, ok)
+	}
+	if _, ok := preferredFlavor(context.Background(), png, []string{"text/plain", "not a type"}); ok {
+		t.Error("Expected no match for an image when only text is preferred")
+	}
+}
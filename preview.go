@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultPreviewBytes is how much text read_clipboard's preview mode returns
+// unless preview_bytes says otherwise.
+const DefaultPreviewBytes = 512
+
+// previewResult serves read_clipboard's preview mode: the head of the text
+// plus the size, MIME type and sha256 of the whole content. It never spills
+// to disk; previews are capped at the inline text limit, and binary content
+// is only described.
+func (cs *ClipboardServer) previewResult(content, raw string, previewBytes int) *mcp.CallToolResult {
+	if previewBytes <= 0 {
+		return mcp.NewToolResultError("preview_bytes must be positive")
+	}
+	if denied := cs.policyResult(content); denied != nil {
+		return denied
+	}
+	previewBytes = min(previewBytes, getInlineThresholds().text)
+
+	mimeType, hash := contentMIMEType(raw), contentHash(raw)
+	var result *mcp.CallToolResult
+	var head string
+	if isProbablyText(content) {
+		head = truncateBytes(content, previewBytes)
+		result = mcp.NewToolResultText(fmt.Sprintf("Clipboard preview (first %d of %d bytes, %s, sha256: %s):\n%s", len(head), len(raw), mimeType, hash, head))
+		outputOf(result).Content = head
+	} else {
+		result = mcp.NewToolResultText(fmt.Sprintf("Clipboard holds %s (%d bytes, sha256: %s); binary content has no text preview", mimeType, len(raw), hash))
+	}
+
+	truncated := len(head) < len(content)
+	meta := resultMeta(result)
+	meta["mimeType"], meta["size"], meta["sha256"] = mimeType, len(raw), hash
+	meta["preview"] = map[string]any{"bytes": len(head), "truncated": truncated}
+	outputOf(result).Truncated = truncated
+	return result
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test that preview mode returns the head of large text without spilling
+func TestReadClipboardPreview(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	content := strings.Repeat("é", 20000) // 40000 bytes, above the inline limit
+	fakeClipboard(t, content)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"preview": true, "preview_bytes": float64(11)}
+	result, err := NewClipboardServer().readClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Preview failed: %v %v", err, result.Content)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.HasSuffix(text, ":\nééééé") || !strings.Contains(text, "first 10 of 40000 bytes") || !strings.Contains(text, contentHash(content)) {
+		t.Errorf("Unexpected preview: %q", text)
+	}
+	meta := result.Meta.AdditionalFields
+	if meta["size"] != 40000 || meta["sha256"] != contentHash(content) || meta["preview"].(map[string]any)["truncated"] != true {
+		t.Errorf("Unexpected metadata: %v", meta)
+	}
+	if entries, _ := os.ReadDir(os.Getenv("TMPDIR")); len(entries) != 0 {
+		t.Errorf("Expected nothing saved to disk, got %d entries", len(entries))
+	}
+
+	request.Params.Arguments = map[string]any{"preview": true, "preview_bytes": float64(0)}
+	if result, _ := NewClipboardServer().readClipboardHandler(context.Background(), request); !result.IsError {
+		t.Error("Expected preview_bytes 0 to be rejected")
+	}
+}
+
+// Test that binary content is described but not previewed
+func TestPreviewBinary(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	result := NewClipboardServer().previewResult(png, png, DefaultPreviewBytes)
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "image/png") || !strings.Contains(text, "no text preview") {
+		t.Errorf("Unexpected preview: %q", text)
+	}
+	if output := result.StructuredContent.(*toolOutput); output.Content != "" || !output.Truncated {
+		t.Errorf("Unexpected output: %+v", output)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// errPrivacySpill is returned instead of saving clipboard content to a temp
+// file in privacy mode.
+var errPrivacySpill = errors.New("privacy mode (--privacy) never saves clipboard content to files; raise MCP_CLIP_MAX_INLINE_TEXT, MCP_CLIP_MAX_INLINE_BASE64 or MCP_CLIP_MAX_INLINE_IMAGE to read it inline")
+
+// errPrivacyQR is returned by decode_qr in privacy mode: zbarimg only reads
+// images from files, which privacy mode never writes clipboard content to.
+var errPrivacyQR = errors.New("privacy mode (--privacy) never saves clipboard content to files, and zbarimg can only decode QR codes from a file")
+
+// setPrivacy switches the server to privacy mode: the monitor, history and
+// wait_for_clipboard_change only keep and report hashes, sizes and types,
+// and content is transferred only when read_clipboard (or another read
+// tool) is called explicitly, never stored in history or temp files.
+func (cs *ClipboardServer) setPrivacy(privacy bool) {
+	cs.privacy = privacy
+	cs.history.redact = privacy
+}
+
+// redacted reports whether the content of entry was not retained because
+// it was recorded in privacy mode.
+func (e historyEntry) redacted() bool {
+	return e.Content == "" && e.Size > 0
+}
+
+// redactedResult reports a clipboard change without its content.
+func redactedResult(header string, seq uint64, content string) *mcp.CallToolResult {
+	kind, _ := classifyContent(content)
+	hash := contentHash(content)
+	result := mcp.NewToolResultText(fmt.Sprintf("%s (sequence: %d, sha256: %s): %s (%s), %d bytes. Privacy mode is on; call read_clipboard to get the content.", header, seq, hash, kind, contentMIMEType(content), len(content)))
+	meta := resultMeta(result)
+	meta["sequence"] = seq
+	meta["sha256"] = hash
+	meta["kind"] = kind
+	meta["size"] = len(content)
+	return result
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test that privacy mode keeps only hashes, sizes and types in the history
+func TestPrivacyHistory(t *testing.T) {
+	cs := NewClipboardServer()
+	cs.setPrivacy(true)
+	cs.updateClipboard("secret token")
+
+	entries := cs.history.recent(0)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 history entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if !entry.redacted() || entry.Hash != contentHash("secret token") || entry.Size != 12 || entry.Kind != "text" {
+		t.Errorf("Expected a redacted text entry of 12 bytes, got %+v", entry)
+	}
+	if rendered := renderChanges(entries, 0, 1, 10); strings.Contains(rendered, "secret") || !strings.Contains(rendered, "privacy mode") {
+		t.Errorf("Expected the change without content, got %q", rendered)
+	}
+}
+
+// Test that privacy mode never saves content to temp files
+func TestPrivacySpill(t *testing.T) {
+	cs := NewClipboardServer()
+	cs.setPrivacy(true)
+	if _, err := cs.spillToFile(context.Background(), textSource("secret"), "txt"); !errors.Is(err, errPrivacySpill) {
+		t.Errorf("Expected the spill to be refused, got %v", err)
+	}
+}
+
+// Test that wait_for_clipboard_change reports a change without content
+func TestPrivacyWait(t *testing.T) {
+	cs := NewClipboardServer()
+	cs.setPrivacy(true)
+	cs.updateClipboard("first")
+	cs.updateClipboard("secret token")
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"hash": contentHash("first")}
+	result, err := cs.waitForClipboardChangeHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Expected a change, got %v (%v)", result, err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if strings.Contains(text, "secret") || result.Meta.AdditionalFields["sha256"] != contentHash("secret token") || result.Meta.AdditionalFields["size"] != 12 {
+		t.Errorf("Expected only the hash and size of the change, got %q %v", text, result.Meta)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// ProgressThreshold is the smallest transfer that reports progress;
+	// anything smaller finishes before a progress bar would be drawn.
+	ProgressThreshold = 1 << 20
+	progressChunkSize = 256 << 10
+	progressInterval  = 100 * time.Millisecond
+)
+
+type progressKey struct{}
+
+// progressReporter sends MCP progress notifications for one tool call. Work
+// is counted in bytes and accumulates across stages (encoding, then saving),
+// so the reported progress only ever increases.
+type progressReporter struct {
+	ctx      context.Context
+	srv      *server.MCPServer
+	token    mcp.ProgressToken
+	mu       sync.Mutex
+	finished int64     // bytes processed by completed stages
+	lastSent time.Time // throttles notifications within a stage
+}
+
+// progressMiddleware attaches a reporter to calls that carry a progress
+// token, for the transfer helpers to pick up via progressFromContext.
+func progressMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.Params.Meta != nil && request.Params.Meta.ProgressToken != nil {
+			if srv := server.ServerFromContext(ctx); srv != nil {
+				ctx = context.WithValue(ctx, progressKey{}, &progressReporter{ctx: ctx, srv: srv, token: request.Params.Meta.ProgressToken})
+			}
+		}
+		return next(ctx, request)
+	}
+}
+
+// progressFromContext returns the call's reporter, or nil if the client did
+// not ask for progress. A nil reporter ignores all reports.
+func progressFromContext(ctx context.Context) *progressReporter {
+	p, _ := ctx.Value(progressKey{}).(*progressReporter)
+	return p
+}
+
+// stage returns a callback that reports how many of a stage's size bytes are
+// done. The stage completes when the callback reaches size.
+func (p *progressReporter) stage(message string, size int) func(done int) {
+	if p == nil || size < ProgressThreshold {
+		return func(int) {}
+	}
+	return func(done int) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		now := time.Now()
+		if done < size && now.Sub(p.lastSent) < progressInterval {
+			return
+		}
+		p.lastSent = now
+
+		err := p.srv.SendNotificationToClient(p.ctx, "notifications/progress", map[string]any{
+			"progressToken": p.token,
+			"progress":      p.finished + int64(done),
+			"total":         p.finished + int64(size),
+			"message":       fmt.Sprintf("%s (%s of %s)", message, formatBytes(done), formatBytes(size)),
+		})
+		if err != nil && os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Progress notification failed: %v\n", err)
+		}
+		if done >= size {
+			p.finished += int64(size)
+		}
+	}
+}
+
+// writeWithProgress writes data in chunks, reporting each to track.
+func writeWithProgress(w io.Writer, data []byte, track func(done int)) error {
+	for written := 0; written < len(data); {
+		n, err := w.Write(data[written:min(written+progressChunkSize, len(data))])
+		written += n
+		if err != nil {
+			return err
+		}
+		track(written)
+	}
+	return nil
+}
+
+// encodeBase64 base64-encodes data, reporting progress for large content.
+func encodeBase64(ctx context.Context, data []byte) string {
+	track := progressFromContext(ctx).stage("Encoding clipboard content", len(data))
+	if len(data) < ProgressThreshold {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+
+	// Chunks are a multiple of 3 bytes, so they encode without padding
+	const chunk = progressChunkSize / 3 * 3
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
+	for offset := 0; offset < len(data); offset += chunk {
+		end := min(offset+chunk, len(data))
+		base64.StdEncoding.Encode(encoded[offset/3*4:], data[offset:end])
+		track(end)
+	}
+	return string(encoded)
+}
+
+// formatBytes renders a byte count for humans: "512 B", "3.2 MB".
+func formatBytes(n int) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+// progressWriter reports the running total of bytes written through it to
+// track.
+type progressWriter struct {
+	w       io.Writer
+	track   func(done int)
+	written int
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += n
+	p.track(p.written)
+	return n, err
+}
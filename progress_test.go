@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test that chunked base64 encoding matches the standard encoder
+func TestEncodeBase64(t *testing.T) {
+	for _, size := range []int{0, 5, ProgressThreshold + 1, ProgressThreshold + 2, 3*progressChunkSize + 7} {
+		data := bytes.Repeat([]byte{0x00, 0x7f, 0xff, 'a', 0x10}, size/5+1)[:size]
+		if got, expected := encodeBase64(context.Background(), data), base64.StdEncoding.EncodeToString(data); got != expected {
+			t.Errorf("Expected standard encoding for %d bytes, got %d chars instead of %d", size, len(got), len(expected))
+		}
+	}
+}
+
+// Test that writes are chunked and every chunk is reported
+func TestWriteWithProgress(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 2*progressChunkSize+10)
+	var buf bytes.Buffer
+	var reported []int
+	if err := writeWithProgress(&buf, data, func(done int) { reported = append(reported, done) }); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Error("Expected all data to be written")
+	}
+	if len(reported) != 3 || reported[2] != len(data) {
+		t.Errorf("Expected 3 reports ending at %d, got %v", len(data), reported)
+	}
+}
+
+// Test that calls without a progress token get no reporter, which ignores reports
+func TestProgressMiddlewareWithoutToken(t *testing.T) {
+	var reporter *progressReporter
+	handler := progressMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		reporter = progressFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+	if _, err := handler(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reporter != nil {
+		t.Error("Expected no reporter without a progress token")
+	}
+	reporter.stage("Saving", 10*ProgressThreshold)(ProgressThreshold)
+}
+
+// Test human-readable byte counts
+func TestFormatBytes(t *testing.T) {
+	cases := map[int]string{512: "512 B", 2048: "2.0 KB", 3*1024*1024 + 200*1024: "3.2 MB", 5 << 30: "5.0 GB"}
+	for n, expected := range cases {
+		if got := formatBytes(n); got != expected {
+			t.Errorf("Expected %q for %d, got %q", expected, n, got)
+		}
+	}
+}
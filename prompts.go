@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerPrompts adds built-in prompts that pre-fill the clipboard content,
+// so clipboard workflows can be started from the client's prompt picker.
+func (cs *ClipboardServer) registerPrompts(s *server.MCPServer) {
+	s.AddPrompt(mcp.NewPrompt("summarize_clipboard",
+		mcp.WithPromptDescription("Summarize whatever is on the clipboard"),
+	), cs.promptHandler(func(args map[string]string) string {
+		return "Summarize the following content I copied to my clipboard. Lead with the key points."
+	}))
+
+	s.AddPrompt(mcp.NewPrompt("explain_clipboard_error",
+		mcp.WithPromptDescription("Explain a copied error message or stack trace and suggest fixes"),
+		mcp.WithArgument("context",
+			mcp.ArgumentDescription("What you were doing when the error occurred"),
+		),
+	), cs.promptHandler(func(args map[string]string) string {
+		instruction := "I copied the following error to my clipboard. Explain what it means, the most likely cause, and how to fix it."
+		if situation := strings.TrimSpace(args["context"]); situation != "" {
+			instruction += "\n\nContext: " + situation
+		}
+		return instruction
+	}))
+
+	s.AddPrompt(mcp.NewPrompt("translate_clipboard",
+		mcp.WithPromptDescription("Translate the clipboard text into another language"),
+		mcp.WithArgument("language",
+			mcp.ArgumentDescription("Target language (default: English)"),
+		),
+	), cs.promptHandler(func(args map[string]string) string {
+		language := strings.TrimSpace(args["language"])
+		if language == "" {
+			language = "English"
+		}
+		return fmt.Sprintf("Translate the following clipboard text into %s. Preserve formatting, code and names; reply with the translation only.", language)
+	}))
+}
+
+// promptHandler builds a prompt from an instruction and the current clipboard.
+func (cs *ClipboardServer) promptHandler(instruction func(args map[string]string) string) server.PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		content, err := readClipboard(ctx)
+		if cs.syncer != nil {
+			content, err = cs.syncer.fallback(content, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read clipboard: %v", err)
+		}
+
+		if hint := cs.concealedHint(ctx, content); hint != "" {
+			return nil, fmt.Errorf("clipboard content withheld: a password manager marked it as concealed (%s)", hint)
+		}
+		if denied, rule := cs.policy.check(content); denied {
+			return nil, fmt.Errorf("clipboard content withheld by content policy (rule %s)", rule)
+		}
+		messages, err := buildPromptMessages(instruction(request.Params.Arguments), content, getInlineThresholds())
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewGetPromptResult(fmt.Sprintf("%s with the current clipboard content", request.Params.Name), messages), nil
+	}
+}
+
+// buildPromptMessages pairs instruction with the clipboard content: text is
+// embedded in a code fence (truncated to the inline text limit), images are
+// attached as image content.
+func buildPromptMessages(instruction, content string, limits inlineThresholds) ([]mcp.PromptMessage, error) {
+	if content == "" {
+		return nil, fmt.Errorf("the clipboard is empty; copy something first")
+	}
+
+	if !isProbablyText(content) {
+		isImage, imageType := detectImageType([]byte(content))
+		if !isImage {
+			return nil, fmt.Errorf("the clipboard holds binary content that cannot be used in a prompt")
+		}
+		if len(content) > limits.image {
+			return nil, fmt.Errorf("the clipboard image is too large for a prompt (%d bytes, limit %d)", len(content), limits.image)
+		}
+		return []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(instruction)),
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewImageContent(base64.StdEncoding.EncodeToString([]byte(content)), imageMIMEType(imageType))),
+		}, nil
+	}
+
+	text := normalizeText(content)
+	note := ""
+	if len(text) > limits.text {
+		truncated := truncateBytes(text, limits.text)
+		note = fmt.Sprintf("\n\n(The clipboard content was truncated from %d to %d bytes.)", len(text), len(truncated))
+		text = truncated
+	}
+
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	body := fmt.Sprintf("%s\n\n%s%s\n%s\n%s%s", instruction, fence, detectLanguage(text), strings.TrimRight(text, "\n"), fence, note)
+	return []mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(body))}, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test that prompts embed text, attach images and reject unusable content
+func TestBuildPromptMessages(t *testing.T) {
+	limits := inlineThresholds{text: 20, base64: 100, image: 100}
+
+	messages, err := buildPromptMessages("Explain:", "panic: oops", limits)
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("Expected one message, got %d (%v)", len(messages), err)
+	}
+	text := messages[0].Content.(mcp.TextContent).Text
+	if !strings.HasPrefix(text, "Explain:\n\n```") || !strings.Contains(text, "panic: oops\n```") {
+		t.Errorf("Unexpected prompt text: %q", text)
+	}
+
+	messages, _ = buildPromptMessages("Summarize:", strings.Repeat("a", 19)+"ébbbb", limits)
+	text = messages[0].Content.(mcp.TextContent).Text
+	if !strings.Contains(text, "truncated from 25 to 19 bytes") || !strings.Contains(text, strings.Repeat("a", 19)+"\n") {
+		t.Errorf("Expected rune-safe truncation, got %q", text)
+	}
+
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	messages, err = buildPromptMessages("Describe:", png, limits)
+	if err != nil || len(messages) != 2 {
+		t.Fatalf("Expected text and image messages, got %d (%v)", len(messages), err)
+	}
+	if image, ok := messages[1].Content.(mcp.ImageContent); !ok || image.MIMEType != "image/png" {
+		t.Errorf("Expected PNG image content, got %#v", messages[1].Content)
+	}
+
+	if _, err := buildPromptMessages("x", "", limits); err == nil {
+		t.Errorf("Expected an error for an empty clipboard")
+	}
+	if _, err := buildPromptMessages("x", "%PDF-1.7\x00\x01\x02", limits); err == nil {
+		t.Errorf("Expected an error for non-image binary content")
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// zbarNoSymbols is the exit status of zbarimg when an image holds no codes.
+const zbarNoSymbols = 4
+
+// zbarOutput is the part of zbarimg's --xml output decode_qr uses.
+type zbarOutput struct {
+	Symbols []struct {
+		Type string `xml:"type,attr"`
+		Data struct {
+			Format string `xml:"format,attr"`
+			Text   string `xml:",chardata"`
+		} `xml:"data"`
+	} `xml:"source>index>symbol"`
+}
+
+// decodeQRCodes returns the payloads of the QR codes in an image, using
+// zbarimg from the zbar tools. An image without codes yields no payloads.
+func decodeQRCodes(ctx context.Context, image []byte) ([]string, error) {
+	if _, err := exec.LookPath("zbarimg"); err != nil {
+		return nil, fmt.Errorf("zbarimg not found - required to decode QR codes (install zbar-tools, or zbar with Homebrew)")
+	}
+	file, err := os.CreateTemp("", FilenamePrefix+"qr-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage image: %v", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(image)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage image: %v", err)
+	}
+
+	ctx, cancel := withReadTimeout(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "zbarimg", "--quiet", "--xml", "-Sdisable", "-Sqrcode.enable", file.Name())
+	end := traceCommand(ctx, cmd)
+	output, err := cmd.Output()
+	end(err)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == zbarNoSymbols {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("zbarimg failed: %v", timeoutError(ctx, err))
+	}
+
+	var parsed zbarOutput
+	if err := xml.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse zbarimg output: %v", err)
+	}
+	var payloads []string
+	for _, symbol := range parsed.Symbols {
+		payload := symbol.Data.Text
+		if symbol.Data.Format == "base64" {
+			// Payloads that aren't valid text are reported base64-encoded
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode zbarimg output: %v", err)
+			}
+			payload = string(decoded)
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
+
+func (cs *ClipboardServer) decodeQRHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	data, err := readClipboardData(ctx)
+	if err != nil {
+		return backendErrorResult(ctx, err), nil
+	}
+	content := data.content
+	if content == "" {
+		return emptyClipboardResult(ctx), nil
+	}
+	if hint := cs.concealedHint(ctx, content); hint != "" {
+		return concealedResult(hint), nil
+	}
+	if kind, _ := classifyContent(content); kind != "image" {
+		return mcp.NewToolResultError("The clipboard holds " + contentMIMEType(content) + ", not an image; copy a screenshot of the QR code"), nil
+	}
+
+	if cs.privacy {
+		return mcp.NewToolResultError(errPrivacyQR.Error()), nil
+	}
+	payloads, err := decodeQRCodes(ctx, []byte(content))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to decode QR codes: %v", err)), nil
+	}
+	if len(payloads) == 0 {
+		return mcp.NewToolResultText("No QR code found in the clipboard image"), nil
+	}
+	if denied := cs.policyResult(strings.Join(payloads, "\n")); denied != nil {
+		return denied, nil
+	}
+
+	result := &mcp.CallToolResult{}
+	if len(payloads) > 1 {
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf("Found %d QR codes; their payloads follow", len(payloads))))
+	}
+	for _, payload := range payloads {
+		result.Content = append(result.Content, mcp.NewTextContent(payload))
+	}
+	meta := resultMeta(result)
+	meta["qrCodes"] = len(payloads)
+	meta["imageMimeType"] = contentMIMEType(content)
+	if urls := clipboardURLs(strings.Join(payloads, "\n")); urls != nil {
+		meta["urls"] = urls
+	}
+	outputOf(result).Content = strings.Join(payloads, "\n")
+	return result, nil
+}
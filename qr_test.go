@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fakeZbarimg puts a zbarimg in PATH that prints output and exits with
+// status.
+func fakeZbarimg(t *testing.T, output string, status int) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake zbarimg is a shell script")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat <<'XML'\n" + output + "\nXML\nexit " + strconv.Itoa(status) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "zbarimg"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// Test parsing zbarimg's output
+func TestDecodeQRCodes(t *testing.T) {
+	fakeZbarimg(t, `<barcodes xmlns='http://zbar.sourceforge.net/2008/barcode'>
+<source href='/tmp/qr.png'>
+<index num='0'>
+<symbol type='QR-Code' quality='1' orientation='UP'><data><![CDATA[WIFI:S:home;T:WPA;P:secret;;]]></data></symbol>
+<symbol type='QR-Code' quality='1' orientation='UP'><data format='base64' length='3'><![CDATA[AP8K]]></data></symbol>
+</index>
+</source>
+</barcodes>`, 0)
+
+	payloads, err := decodeQRCodes(context.Background(), testPNG)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(payloads) != 2 || payloads[0] != "WIFI:S:home;T:WPA;P:secret;;" || payloads[1] != "\x00\xff\n" {
+		t.Errorf("Unexpected payloads: %q", payloads)
+	}
+
+	fakeZbarimg(t, "", zbarNoSymbols)
+	if payloads, err := decodeQRCodes(context.Background(), testPNG); err != nil || payloads != nil {
+		t.Errorf("Expected no payloads, got %q, %v", payloads, err)
+	}
+}
+
+// Test the decode_qr tool
+func TestDecodeQRHandler(t *testing.T) {
+	fakeClipboard(t, string(testPNG))
+	fakeZbarimg(t, `<barcodes><source><index><symbol type='QR-Code'><data><![CDATA[https://example.com/pair?code=42]]></data></symbol></index></source></barcodes>`, 0)
+
+	result, err := NewClipboardServer().decodeQRHandler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("Decode failed: %v %v", err, result.Content)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "https://example.com/pair?code=42" {
+		t.Errorf("Unexpected payload: %q", text)
+	}
+	meta := result.Meta.AdditionalFields
+	if meta["qrCodes"] != 1 || meta["imageMimeType"] != "image/png" || len(meta["urls"].([]string)) != 1 {
+		t.Errorf("Unexpected metadata: %v", meta)
+	}
+
+	cs := NewClipboardServer()
+	cs.setPrivacy(true)
+	result, _ = cs.decodeQRHandler(context.Background(), mcp.CallToolRequest{})
+	if !result.IsError || result.Content[0].(mcp.TextContent).Text != errPrivacyQR.Error() {
+		t.Errorf("Expected privacy mode to refuse staging the image, got %v", result.Content)
+	}
+
+	fakeClipboard(t, "just text")
+	result, _ = NewClipboardServer().decodeQRHandler(context.Background(), mcp.CallToolRequest{})
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "not an image") {
+		t.Errorf("Expected text to be rejected, got %v", result.Content)
+	}
+}
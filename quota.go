@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const DefaultTempQuota = 1 << 30 // 1GB
+
+// getTempQuota returns the most bytes mcp-clip temp files may occupy, or 0
+// when MCP_CLIP_TEMP_QUOTA=0 disables the quota.
+func getTempQuota() int {
+	return getSizeEnv("MCP_CLIP_TEMP_QUOTA", DefaultTempQuota)
+}
+
+// isSessionFile reports whether this server instance created filePath.
+func (cs *ClipboardServer) isSessionFile(filePath string) bool {
+	if cs == nil {
+		return false
+	}
+	cs.filesMutex.Lock()
+	defer cs.filesMutex.Unlock()
+	return slices.Contains(cs.sessionFiles, filePath)
+}
+
+// enforceTempQuota makes room for incoming bytes under MCP_CLIP_TEMP_QUOTA by
+// removing the oldest mcp-clip temp files in tempDir. Files created by this
+// session are kept, since their paths were handed to the client, so the
+// write fails instead when they alone leave too little room.
+func enforceTempQuota(tempDir string, incoming int, cs *ClipboardServer) error {
+	quota := getTempQuota()
+	if quota <= 0 {
+		return nil
+	}
+	if incoming > quota {
+		return fmt.Errorf("%d bytes exceed the temp file quota of %d bytes (MCP_CLIP_TEMP_QUOTA)", incoming, quota)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(tempDir, FilenamePrefix+"*"))
+	if err != nil {
+		return fmt.Errorf("failed to list temp files: %v", err)
+	}
+	type tempFile struct {
+		path    string
+		size    int64
+		modTime int64
+	}
+	var files []tempFile
+	var used int64
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		used += info.Size()
+		files = append(files, tempFile{path, info.Size(), info.ModTime().UnixNano()})
+	}
+	if used+int64(incoming) <= int64(quota) {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime < files[j].modTime })
+	for _, file := range files {
+		if used+int64(incoming) <= int64(quota) {
+			break
+		}
+		if cs.isSessionFile(file.path) || strings.HasSuffix(file.path, PartialSuffix) {
+			continue // handed to the client, or still being written
+		}
+		if err := os.Remove(file.path); err != nil {
+			if os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "Failed to evict temp file %s: %v\n", file.path, err)
+			}
+			continue
+		}
+		used -= file.size
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Evicted temp file %s (%d bytes) to stay within the quota\n", file.path, file.size)
+		}
+	}
+	if used+int64(incoming) > int64(quota) {
+		return fmt.Errorf("temp file quota of %d bytes exceeded: %d bytes in use by this session's files, %d more needed (raise MCP_CLIP_TEMP_QUOTA)", quota, used, incoming)
+	}
+	return nil
+}
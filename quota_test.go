@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeAgedFile creates a temp file of size bytes, modified age ago.
+func writeAgedFile(t *testing.T, path string, size int, age time.Duration) {
+	t.Helper()
+	if err := os.WriteFile(path, make([]byte, size), 0600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Test that the oldest files are evicted first and session files are kept
+func TestEnforceTempQuota(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_CLIP_TEMP_QUOTA", "100")
+	cs := NewClipboardServer()
+	atomic.StoreInt32(&cs.running, 1)
+
+	oldest := filepath.Join(dir, FilenamePrefix+"1-a.txt")
+	session := filepath.Join(dir, FilenamePrefix+"2-b.txt")
+	newer := filepath.Join(dir, FilenamePrefix+"3-c.txt")
+	writeAgedFile(t, session, 40, 3*time.Minute)
+	writeAgedFile(t, oldest, 40, 2*time.Minute)
+	writeAgedFile(t, newer, 40, time.Minute)
+	cs.addSessionFile(session)
+
+	if err := enforceTempQuota(dir, 20, cs); err != nil {
+		t.Fatalf("Expected room to be made, got %v", err)
+	}
+	if _, err := os.Stat(oldest); !os.IsNotExist(err) {
+		t.Error("Expected the oldest non-session file to be evicted")
+	}
+	for _, kept := range []string{session, newer} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("Expected %s to be kept: %v", kept, err)
+		}
+	}
+
+	// Only the session file is left once newer goes, which is not enough
+	if err := enforceTempQuota(dir, 70, cs); err == nil || !strings.Contains(err.Error(), "quota") {
+		t.Errorf("Expected a quota error, got %v", err)
+	}
+	if _, err := os.Stat(session); err != nil {
+		t.Errorf("Expected the session file to survive: %v", err)
+	}
+}
+
+// Test that a zero quota disables enforcement
+func TestEnforceTempQuotaDisabled(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_CLIP_TEMP_QUOTA", "0")
+	writeAgedFile(t, filepath.Join(dir, FilenamePrefix+"1-a.txt"), 100, time.Minute)
+	if err := enforceTempQuota(dir, 1<<20, nil); err != nil {
+		t.Errorf("Expected no quota, got %v", err)
+	}
+}
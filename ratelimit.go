@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	DefaultRateLimit  = 10.0 // tool calls per second per client
+	DefaultRateBurst  = 20
+	rateBucketIdleTTL = 10 * time.Minute
+	rateBucketSweepAt = 256 // prune idle buckets once this many clients were seen
+)
+
+// tokenBucket allows bursts of up to burst calls, refilled at rate per second.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter keeps one token bucket per client session, so a misbehaving
+// agent loop can't hammer the clipboard backend (every read spawns xclip or
+// PowerShell) and one HTTP client can't starve the others.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*tokenBucket)}
+}
+
+// getRateLimiter configures rate limiting from MCP_CLIP_RATE_LIMIT (calls per
+// second, 0 disables) and MCP_CLIP_RATE_BURST. It returns nil when disabled.
+func getRateLimiter() *rateLimiter {
+	rate := DefaultRateLimit
+	if rateStr := os.Getenv("MCP_CLIP_RATE_LIMIT"); rateStr != "" {
+		if parsed, err := strconv.ParseFloat(rateStr, 64); err == nil && parsed >= 0 {
+			rate = parsed
+		} else if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Invalid MCP_CLIP_RATE_LIMIT '%s', using default: %v\n", rateStr, DefaultRateLimit)
+		}
+	}
+	if rate == 0 {
+		return nil
+	}
+	return newRateLimiter(rate, max(getSizeEnv("MCP_CLIP_RATE_BURST", DefaultRateBurst), 1))
+}
+
+// allow takes a token for client, or reports how long until one is available.
+func (rl *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	bucket, ok := rl.buckets[client]
+	if !ok {
+		if len(rl.buckets) >= rateBucketSweepAt {
+			rl.sweep(now)
+		}
+		bucket = &tokenBucket{tokens: rl.burst, last: now}
+		rl.buckets[client] = bucket
+	}
+
+	bucket.tokens = min(rl.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate)
+	bucket.last = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - bucket.tokens) / rl.rate * float64(time.Second))
+	return false, wait
+}
+
+func (rl *rateLimiter) sweep(now time.Time) {
+	for client, bucket := range rl.buckets {
+		if now.Sub(bucket.last) > rateBucketIdleTTL {
+			delete(rl.buckets, client)
+		}
+	}
+}
+
+// middleware rejects tool calls beyond the client's rate with a structured error.
+func (rl *rateLimiter) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := "stdio"
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			client = session.SessionID()
+		}
+
+		if ok, wait := rl.allow(client, time.Now()); !ok {
+			if os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "Rate limited %s call from %s\n", request.Params.Name, client)
+			}
+			result := mcp.NewToolResultError(fmt.Sprintf("Rate limit exceeded (%.4g calls/s, burst %.0f); retry in %v", rl.rate, rl.burst, wait.Round(time.Millisecond)))
+			result.Meta = mcp.NewMetaFromMap(map[string]any{"status": "rate_limited", "retryAfterMs": wait.Milliseconds()})
+			return result, nil
+		}
+		return next(ctx, request)
+	}
+}
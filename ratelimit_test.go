@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Test token bucket bursts, refill and per-client isolation
+func TestRateLimiter(t *testing.T) {
+	rl := newRateLimiter(2, 3)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := rl.allow("a", now); !ok {
+			t.Fatalf("Expected burst call %d to be allowed", i+1)
+		}
+	}
+	ok, wait := rl.allow("a", now)
+	if ok || wait != 500*time.Millisecond {
+		t.Errorf("Expected rejection with 500ms wait, got %v %v", ok, wait)
+	}
+
+	if ok, _ := rl.allow("b", now); !ok {
+		t.Errorf("Expected another client to have its own bucket")
+	}
+
+	if ok, _ := rl.allow("a", now.Add(500*time.Millisecond)); !ok {
+		t.Errorf("Expected a token to be refilled after 500ms")
+	}
+	if ok, _ := rl.allow("a", now.Add(500*time.Millisecond)); ok {
+		t.Errorf("Expected the refilled token to be used up")
+	}
+}
+
+// Test that rate limiting can be disabled
+func TestGetRateLimiter(t *testing.T) {
+	t.Setenv("MCP_CLIP_RATE_LIMIT", "0")
+	if getRateLimiter() != nil {
+		t.Errorf("Expected MCP_CLIP_RATE_LIMIT=0 to disable rate limiting")
+	}
+
+	t.Setenv("MCP_CLIP_RATE_LIMIT", "5")
+	t.Setenv("MCP_CLIP_RATE_BURST", "7")
+	if rl := getRateLimiter(); rl == nil || rl.rate != 5 || rl.burst != 7 {
+		t.Errorf("Expected 5/s with burst 7, got %+v", rl)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// clipboardPart is one representation returned by read_clipboard_all.
+type clipboardPart struct {
+	clipboardFlavor
+	kind string // "text", "image" or "binary"
+}
+
+// readClipboardParts collects every representation of the clipboard: the
+// content itself (text, image or other binary data), the files it lists,
+// and the HTML and RTF flavors when the backend can read them.
+func readClipboardParts(ctx context.Context, content string, caps backendCapabilities) []clipboardPart {
+	kind, format := classifyContent(content)
+	primary := clipboardPart{clipboardFlavor: clipboardFlavor{mimeType: contentMIMEType(content), ext: format, content: content}, kind: kind}
+	switch {
+	case kind == "text":
+		primary.ext = "txt"
+	case primary.ext == "":
+		primary.ext = "bin"
+	}
+	parts := []clipboardPart{primary}
+
+	if kind == "text" {
+		if paths := parseFileList(content); len(paths) > 0 {
+			parts = append(parts, clipboardPart{clipboardFlavor: clipboardFlavor{mimeType: "text/uri-list", ext: "txt", content: describeFileList(paths)}, kind: "text"})
+		}
+	}
+	if !caps.flavors {
+		return parts
+	}
+	for _, rich := range []struct {
+		mimeType, ext string
+		read          func(context.Context) (string, error)
+	}{
+		{"text/html", "html", readClipboardHTML},
+		{"text/rtf", "rtf", readClipboardRTF},
+	} {
+		flavor, err := rich.read(ctx)
+		if flavor = strings.TrimRight(flavor, "\x00"); err != nil || strings.TrimSpace(flavor) == "" {
+			debugFlavorUnavailable(rich.mimeType, err)
+			continue
+		}
+		parts = append(parts, clipboardPart{clipboardFlavor: clipboardFlavor{mimeType: rich.mimeType, ext: rich.ext, content: flavor}, kind: "text"})
+	}
+	return parts
+}
+
+// describeFileList lists copied files one per line with their size, so the
+// file list part says which of them still exist.
+func describeFileList(paths []string) string {
+	var b strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			fmt.Fprintf(&b, "%s (not found)\n", path)
+		case info.IsDir():
+			fmt.Fprintf(&b, "%s (directory)\n", path)
+		default:
+			fmt.Fprintf(&b, "%s (%d bytes)\n", path, info.Size())
+		}
+	}
+	return b.String()
+}
+
+// partCap is the largest part of a kind returned inline: max_part_bytes
+// when given, otherwise the usual inline threshold for the kind.
+func partCap(kind string, maxPartBytes int) int {
+	if maxPartBytes > 0 {
+		return maxPartBytes
+	}
+	limits := getInlineThresholds()
+	switch kind {
+	case "image":
+		return limits.image
+	case "binary":
+		return limits.base64
+	}
+	return limits.text
+}
+
+// readClipboardAllHandler returns every representation of the clipboard as
+// one multi-part result, for complex copies (a spreadsheet range, a web page
+// selection, files from a file manager) where one flavor loses information.
+// Parts above their size cap are saved to temp files instead of inlined.
+func (cs *ClipboardServer) readClipboardAllHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	maxPartBytes := request.GetInt("max_part_bytes", 0)
+	if maxPartBytes < 0 {
+		return mcp.NewToolResultError("max_part_bytes must not be negative"), nil
+	}
+
+	data, err := readClipboardData(ctx)
+	if err != nil {
+		return backendErrorResult(ctx, err), nil
+	}
+	if data.content == "" {
+		return emptyClipboardResult(ctx), nil
+	}
+	if hint := cs.concealedHint(ctx, data.content); hint != "" {
+		return concealedResult(hint), nil
+	}
+
+	parts := readClipboardParts(ctx, data.content, capabilitiesOf(selectBackend()))
+	names := make([]string, 0, len(parts))
+	summary := make([]map[string]any, 0, len(parts))
+	var contents []mcp.Content
+	for _, part := range parts {
+		if part.kind == "text" {
+			if denied := cs.policyResult(part.content); denied != nil {
+				return denied, nil
+			}
+		}
+		names = append(names, part.mimeType)
+		info := map[string]any{"mimeType": part.mimeType, "size": len(part.content)}
+		content, err := cs.partContent(ctx, part, partCap(part.kind, maxPartBytes), info)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to save large %s content to temp file: %v", part.mimeType, err)), nil
+		}
+		contents = append(contents, content...)
+		summary = append(summary, info)
+	}
+
+	header := mcp.NewTextContent(fmt.Sprintf("Clipboard offers %d representation(s): %s", len(parts), strings.Join(names, ", ")))
+	result := &mcp.CallToolResult{Content: append([]mcp.Content{header}, contents...)}
+	result.Meta = mcp.NewMetaFromMap(map[string]any{"parts": summary})
+	return result, nil
+}
+
+// partContent renders one part inline, or saves it to a temp file when it
+// exceeds limit and records where in info.
+func (cs *ClipboardServer) partContent(ctx context.Context, part clipboardPart, limit int, info map[string]any) ([]mcp.Content, error) {
+	size := len(part.content)
+	if part.kind == "binary" {
+		size = base64.StdEncoding.EncodedLen(size)
+	}
+	if size <= limit {
+		switch part.kind {
+		case "image":
+			return []mcp.Content{mcp.NewImageContent(encodeBase64(ctx, []byte(part.content)), part.mimeType)}, nil
+		case "binary":
+			return []mcp.Content{mcp.NewTextContent(fmt.Sprintf("%s (%d bytes, base64 encoded):\n%s", part.mimeType, len(part.content), encodeBase64(ctx, []byte(part.content))))}, nil
+		}
+		return []mcp.Content{mcp.NewTextContent(fmt.Sprintf("%s (%d bytes):\n%s", part.mimeType, len(part.content), part.content))}, nil
+	}
+
+	if part.kind == "text" {
+		spill, err := cs.spillToFile(ctx, textSource(part.content), part.ext)
+		if err != nil {
+			return nil, err
+		}
+		info["path"], info["sha256"] = spill.location, spill.sha256
+		return []mcp.Content{mcp.NewTextContent(fmt.Sprintf("%s too large (%d bytes). Saved to: %s", part.mimeType, len(part.content), spill))}, nil
+	}
+	spill, resource, err := cs.spillResource(ctx, []byte(part.content), part.ext, part.mimeType)
+	if err != nil {
+		return nil, err
+	}
+	info["path"], info["sha256"] = spill.location, spill.sha256
+	return []mcp.Content{mcp.NewTextContent(fmt.Sprintf("%s too large (%d bytes). Saved to: %s", part.mimeType, len(part.content), spill)), resource}, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test that read_clipboard_all returns the text, file list and HTML parts
+func TestReadClipboardAll(t *testing.T) {
+	dir := t.TempDir()
+	copied := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(copied, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fakeClipboard(t, copied)
+	t.Setenv("WAYLAND_DISPLAY", "")
+	script := "#!/bin/sh\ncase \"$*\" in\n*text/html*) printf '<a href=\"file:///notes.txt\">notes</a>' ;;\n*) exit 1 ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result, err := NewClipboardServer().readClipboardAllHandler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	if header := result.Content[0].(mcp.TextContent).Text; header != "Clipboard offers 3 representation(s): text/plain, text/uri-list, text/html" {
+		t.Errorf("Unexpected header: %q", header)
+	}
+	if files := result.Content[2].(mcp.TextContent).Text; !strings.Contains(files, copied+" (5 bytes)") {
+		t.Errorf("Expected the copied file with its size, got %q", files)
+	}
+	parts := result.Meta.AdditionalFields["parts"].([]map[string]any)
+	if len(parts) != 3 || parts[2]["mimeType"] != "text/html" {
+		t.Errorf("Unexpected parts: %v", parts)
+	}
+}
+
+// Test that parts above max_part_bytes are saved to temp files
+func TestReadClipboardAllPartCap(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	file := fakeClipboard(t, strings.Repeat("long text ", 10))
+	t.Setenv("WAYLAND_DISPLAY", "")
+	// The fake xsel offers no HTML or RTF: xclip -t finds no such type
+	if err := os.WriteFile(filepath.Join(filepath.Dir(file), "xclip"), []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cs := NewClipboardServer()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"max_part_bytes": float64(50)}
+	result, err := cs.readClipboardAllHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	parts := result.Meta.AdditionalFields["parts"].([]map[string]any)
+	path, ok := parts[0]["path"].(string)
+	if !ok {
+		t.Fatalf("Expected the text part to be saved to a file, got %v", parts)
+	}
+	if data, _ := os.ReadFile(path); string(data) != strings.Repeat("long text ", 10) {
+		t.Errorf("Unexpected saved content %q", data)
+	}
+
+	request.Params.Arguments = map[string]any{"max_part_bytes": float64(-1)}
+	if result, _ := cs.readClipboardAllHandler(context.Background(), request); !result.IsError {
+		t.Error("Expected a negative max_part_bytes to be rejected")
+	}
+}
+
+// Test that image content is returned as an image part
+func TestReadClipboardPartsImage(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	parts := readClipboardParts(context.Background(), png, backendCapabilities{readImages: true})
+	if len(parts) != 1 || parts[0].kind != "image" || parts[0].mimeType != "image/png" || parts[0].ext != "png" {
+		t.Fatalf("Unexpected parts: %+v", parts)
+	}
+	content, err := NewClipboardServer().partContent(context.Background(), parts[0], 1<<20, map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image, ok := content[0].(mcp.ImageContent); !ok || image.MIMEType != "image/png" {
+		t.Errorf("Expected inline image content, got %#v", content[0])
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// errReadOnly is returned by every clipboard write in read-only mode, as a
+// backstop should a code path that modifies the clipboard still be reachable.
+var errReadOnly = errors.New("mcp-clip runs in read-only mode (--read-only); the clipboard is never modified")
+
+// setReadOnly switches the server to read-only mode before its tools are
+// registered: tools that modify the clipboard are left out and clipboard
+// writes are refused.
+func (cs *ClipboardServer) setReadOnly(readOnly bool) {
+	cs.readOnly = readOnly
+	clipboardAccessor.readOnly.Store(readOnly)
+}
+
+// addWriteTool registers a tool that modifies the clipboard, unless the
+// server is read-only, in which case clients never see the tool at all.
+func (cs *ClipboardServer) addWriteTool(s *server.MCPServer, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if cs.readOnly {
+		return
+	}
+	s.AddTool(tool, handler)
+}
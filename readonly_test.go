@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Test that --read-only and MCP_CLIP_READ_ONLY enable read-only mode
+func TestParseReadOnlyOption(t *testing.T) {
+	t.Setenv("MCP_CLIP_READ_ONLY", "")
+	if opts, _ := parseServerOptions(nil); opts.readOnly {
+		t.Error("Expected writes to be allowed by default")
+	}
+	if opts, _ := parseServerOptions([]string{"--read-only"}); !opts.readOnly {
+		t.Error("Expected --read-only to enable read-only mode")
+	}
+	t.Setenv("MCP_CLIP_READ_ONLY", "1")
+	if opts, _ := parseServerOptions(nil); !opts.readOnly {
+		t.Error("Expected MCP_CLIP_READ_ONLY=1 to enable read-only mode")
+	}
+}
+
+// Test that read-only mode leaves out write tools and refuses writes
+func TestReadOnlyMode(t *testing.T) {
+	cs := NewClipboardServer()
+	cs.setReadOnly(true)
+	t.Cleanup(func() { cs.setReadOnly(false) })
+
+	s := server.NewMCPServer("test", "1.0.0")
+	cs.registerTools(s)
+	for _, name := range []string{"write_clipboard", "append_to_clipboard", "copy_snippet_to_clipboard", "schedule_clipboard_clear", "restore_clipboard"} {
+		if s.GetTool(name) != nil {
+			t.Errorf("Expected %s not to be registered in read-only mode", name)
+		}
+	}
+	for _, name := range []string{"read_clipboard", "clipboard_info", "list_snippets"} {
+		if s.GetTool(name) == nil {
+			t.Errorf("Expected %s to be registered in read-only mode", name)
+		}
+	}
+
+	if err := writeClipboard(context.Background(), "text"); !errors.Is(err, errReadOnly) {
+		t.Errorf("Expected the write to be refused, got %v", err)
+	}
+}
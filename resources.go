@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	timelineURI       = "clipboard://timeline"
+	historyURIPrefix  = "clipboard://history/"
+	timelineEntries   = 20
+	timelinePreviewSz = 80
+)
+
+// registerResources exposes the clipboard and its history as MCP resources.
+func (cs *ClipboardServer) registerResources(s *server.MCPServer) {
+	cs.subscriptions.register(s)
+	s.AddResource(mcp.NewResource(currentURI, "Current clipboard",
+		mcp.WithResourceDescription("Live clipboard content; subscribe to be notified when it changes"),
+	), cs.currentResourceHandler)
+
+	s.AddResource(mcp.NewResource(timelineURI, "Clipboard timeline",
+		mcp.WithResourceDescription("Recent clipboard history rendered as Markdown, newest first"),
+		mcp.WithMIMEType("text/markdown"),
+	), cs.timelineResourceHandler)
+
+	s.AddResourceTemplate(mcp.NewResourceTemplate(historyURIPrefix+"{id}", "Clipboard history entry",
+		mcp.WithTemplateDescription("Full content of a recorded clipboard history entry"),
+	), cs.historyEntryResourceHandler)
+
+	s.AddResourceTemplate(mcp.NewResourceTemplate(spillURIPrefix+"{name}", "Saved clipboard file",
+		mcp.WithTemplateDescription("Clipboard content too large to return inline, saved by a tool call"),
+	), cs.spilledFileResourceHandler)
+}
+
+func (cs *ClipboardServer) timelineResourceHandler(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      timelineURI,
+			MIMEType: "text/markdown",
+			Text:     renderTimeline(cs.history.recent(timelineEntries), time.Now()),
+		},
+	}, nil
+}
+
+func (cs *ClipboardServer) historyEntryResourceHandler(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uri := request.Params.URI
+	id, err := strconv.ParseUint(strings.TrimPrefix(uri, historyURIPrefix), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid history entry URI %s: %v", uri, err)
+	}
+	entry, ok := cs.history.get(id)
+	if !ok {
+		return nil, fmt.Errorf("history entry %d not found (it may have been evicted)", id)
+	}
+	if entry.redacted() {
+		return nil, fmt.Errorf("history entry %d has no content: privacy mode keeps only hashes", id)
+	}
+
+	if entry.Kind == "text" {
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: uri, MIMEType: "text/plain", Text: entry.Content},
+		}, nil
+	}
+	return []mcp.ResourceContents{
+		mcp.BlobResourceContents{
+			URI:      uri,
+			MIMEType: entryMIMEType(entry),
+			Blob:     base64.StdEncoding.EncodeToString([]byte(entry.Content)),
+		},
+	}, nil
+}
+
+func entryMIMEType(entry historyEntry) string {
+	switch entry.Kind {
+	case "text":
+		return "text/plain"
+	case "image":
+		return imageMIMEType(entry.Format)
+	default:
+		if mimeType, ok := binaryMIMETypes[entry.Format]; ok {
+			return mimeType
+		}
+		return "application/octet-stream"
+	}
+}
+
+func entryIcon(entry historyEntry) string {
+	switch entry.Kind {
+	case "text":
+		return "📝"
+	case "image":
+		return "🖼️"
+	default:
+		return "📦"
+	}
+}
+
+// renderTimeline renders history entries (newest first) as a Markdown list.
+func renderTimeline(entries []historyEntry, now time.Time) string {
+	var b strings.Builder
+	b.WriteString("# Clipboard Timeline\n\n")
+	if len(entries) == 0 {
+		b.WriteString("_No clipboard changes recorded yet._\n")
+		return b.String()
+	}
+
+	for _, entry := range entries {
+		uri := fmt.Sprintf("%s%d", historyURIPrefix, entry.ID)
+		fmt.Fprintf(&b, "- %s **#%d** · %s (%s) · %s · %d bytes",
+			entryIcon(entry), entry.ID, entry.Time.Format("2006-01-02 15:04:05"), formatAge(now.Sub(entry.Time)), entryLabel(entry), entry.Size)
+		if entry.Kind == "text" && !entry.redacted() {
+			preview := strings.ReplaceAll(previewText(entry.Content, timelinePreviewSz), "`", "'")
+			fmt.Fprintf(&b, "\n  `%s`", preview)
+		}
+		fmt.Fprintf(&b, "\n  [%s](%s)\n", uri, uri)
+	}
+	return b.String()
+}
+
+func entryLabel(entry historyEntry) string {
+	if entry.Format != "" {
+		return entry.Kind + "/" + entry.Format
+	}
+	return entry.Kind
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
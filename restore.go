@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const DefaultScratchTimeout = 60 * time.Second
+
+// scratchWrite is the clipboard content saved by a scratch write, to be put
+// back once the agent is done using the clipboard as a transfer channel.
+type scratchWrite struct {
+	mu       sync.Mutex
+	timer    *time.Timer
+	original string // clipboard content before the first scratch write
+	scratch  string // sha256 of the content the latest scratch write placed
+}
+
+// scratchDelay returns how long a scratch write keeps its content on the
+// clipboard, or 0 when the write is not a scratch write.
+func scratchDelay(request mcp.CallToolRequest) (time.Duration, error) {
+	if !request.GetBool("scratch", false) {
+		return 0, nil
+	}
+	if request.GetFloat("clear_after_seconds", 0) > 0 {
+		return 0, fmt.Errorf("pass either scratch or clear_after_seconds, not both")
+	}
+	delay, err := clearDelay(request, "restore_after_seconds")
+	if err != nil || delay > 0 {
+		return delay, err
+	}
+	return DefaultScratchTimeout, nil
+}
+
+// snapshotForScratch returns the content a scratch write should restore. A
+// scratch write on top of a pending one keeps the original snapshot, so the
+// user's content is what comes back in the end. Concealed content is not
+// snapshot: restoring it would put a password back without its marker, and
+// record it.
+func (cs *ClipboardServer) snapshotForScratch(ctx context.Context) (string, error) {
+	p := &cs.scratch
+	p.mu.Lock()
+	pending, original, scratch := p.timer != nil, p.original, p.scratch
+	p.mu.Unlock()
+
+	current, err := readClipboard(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot the clipboard: %v", err)
+	}
+	if pending && contentHash(current) == scratch {
+		return original, nil
+	}
+	if hint := cs.concealedHint(ctx, current); hint != "" {
+		return "", &concealedError{hint: hint}
+	}
+	if current != "" && !isProbablyText(current) {
+		if _, err := writableImageType([]byte(current)); err != nil {
+			return "", fmt.Errorf("the current clipboard content could not be restored afterwards: %v", err)
+		}
+	}
+	return current, nil
+}
+
+// scheduleRestore puts original back on the clipboard after delay, unless
+// restore_clipboard does it first.
+func (cs *ClipboardServer) scheduleRestore(delay time.Duration, original, scratch string) time.Time {
+	p := &cs.scratch
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.original, p.scratch = original, contentHash(scratch)
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		p.mu.Lock()
+		current := p.timer == timer
+		p.mu.Unlock()
+		if !current {
+			return // restored or replaced meanwhile
+		}
+		if _, err := cs.restoreClipboard(context.Background()); err != nil && os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Failed to restore the clipboard after a scratch write: %v\n", err)
+		}
+	})
+	p.timer = timer
+	return time.Now().Add(delay)
+}
+
+// restoreClipboard puts back the content saved by the pending scratch write
+// and describes the outcome. If the clipboard no longer holds the scratch
+// content, the user copied something since, and that is kept instead.
+func (cs *ClipboardServer) restoreClipboard(ctx context.Context) (string, error) {
+	p := &cs.scratch
+	p.mu.Lock()
+	if p.timer == nil {
+		p.mu.Unlock()
+		return "No scratch write is pending; nothing to restore", nil
+	}
+	p.timer.Stop()
+	p.timer = nil
+	original, scratch := p.original, p.scratch
+	p.original = ""
+	p.mu.Unlock()
+
+	current, err := readClipboard(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read clipboard: %v", err)
+	}
+	if contentHash(current) != scratch {
+		return "Clipboard changed since the scratch write; kept the new content instead of restoring", nil
+	}
+
+	if original != "" && !isProbablyText(original) {
+		mimeType, _ := writableImageType([]byte(original))
+		err = clipboardAccessor.writeImage(ctx, []byte(original), mimeType)
+	} else {
+		err = writeClipboard(ctx, original)
+	}
+	if err != nil {
+		return "", err
+	}
+	cs.recordChange(original)
+	if os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Restored %d bytes of clipboard content after a scratch write\n", len(original))
+	}
+	return fmt.Sprintf("Restored the previous clipboard content (%d bytes)", len(original)), nil
+}
+
+// runPendingRestore restores the clipboard right away if a scratch write is
+// pending, so the user's content comes back when the server shuts down first.
+func (cs *ClipboardServer) runPendingRestore() {
+	cs.scratch.mu.Lock()
+	pending := cs.scratch.timer != nil
+	cs.scratch.mu.Unlock()
+	if !pending {
+		return
+	}
+	if _, err := cs.restoreClipboard(context.Background()); err != nil && os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Failed to restore the clipboard after a scratch write: %v\n", err)
+	}
+}
+
+// withScratchRestore schedules restoring original after a scratch write, if
+// the write was one.
+func (cs *ClipboardServer) withScratchRestore(result *mcp.CallToolResult, delay time.Duration, original, scratch string) *mcp.CallToolResult {
+	if delay == 0 {
+		return result
+	}
+	at := cs.scheduleRestore(delay, original, scratch)
+	if text, ok := result.Content[0].(mcp.TextContent); ok {
+		text.Text += fmt.Sprintf("; the previous content will be restored in %v, or call restore_clipboard when done", delay)
+		result.Content[0] = text
+	}
+	resultMeta(result)["restoreAt"] = at.UTC().Format(time.RFC3339)
+	return result
+}
+
+func (cs *ClipboardServer) restoreClipboardHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	message, err := cs.restoreClipboard(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to restore clipboard: %v", err)), nil
+	}
+	return mcp.NewToolResultText(message), nil
+}
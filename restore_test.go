@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func scratchWriteRequest(content string) mcp.CallToolRequest {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"content": content, "scratch": true, "restore_after_seconds": 3600}
+	return request
+}
+
+// Test that restore_clipboard puts back the content saved by scratch writes
+func TestScratchWriteRestore(t *testing.T) {
+	file := fakeClipboard(t, "user content")
+	cs := NewClipboardServer()
+
+	for _, content := range []string{"transfer 1", "transfer 2"} {
+		result, err := cs.writeClipboardHandler(context.Background(), scratchWriteRequest(content))
+		if err != nil || result.IsError || result.Meta.AdditionalFields["restoreAt"] == nil {
+			t.Fatalf("Expected a scratch write, got %v (%v)", result, err)
+		}
+	}
+	if data, _ := os.ReadFile(file); string(data) != "transfer 2" {
+		t.Fatalf("Expected the scratch content, got %q", data)
+	}
+
+	result, _ := cs.restoreClipboardHandler(context.Background(), mcp.CallToolRequest{})
+	if result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "Restored") {
+		t.Errorf("Expected the content to be restored, got %v", result)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "user content" {
+		t.Errorf("Expected the original content back, got %q", data)
+	}
+	if message, _ := cs.restoreClipboard(context.Background()); !strings.Contains(message, "nothing to restore") {
+		t.Errorf("Expected nothing left to restore, got %q", message)
+	}
+}
+
+// Test that content copied after a scratch write is kept
+func TestScratchWriteKeepsNewCopy(t *testing.T) {
+	file := fakeClipboard(t, "user content")
+	cs := NewClipboardServer()
+
+	if result, _ := cs.writeClipboardHandler(context.Background(), scratchWriteRequest("transfer")); result.IsError {
+		t.Fatalf("Expected a scratch write, got %v", result)
+	}
+	if err := os.WriteFile(file, []byte("copied later"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cs.runPendingRestore()
+	if data, _ := os.ReadFile(file); string(data) != "copied later" {
+		t.Errorf("Expected the newer copy to be kept, got %q", data)
+	}
+}
+
+// Test that the scratch timeout defaults and conflicts with clear_after_seconds
+func TestScratchDelay(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"scratch": true}
+	if delay, err := scratchDelay(request); err != nil || delay != DefaultScratchTimeout {
+		t.Errorf("Expected the default timeout, got %v (%v)", delay, err)
+	}
+	request.Params.Arguments = map[string]any{"scratch": true, "clear_after_seconds": 5}
+	if _, err := scratchDelay(request); err == nil {
+		t.Error("Expected scratch and clear_after_seconds to conflict")
+	}
+	request.Params.Arguments = map[string]any{"restore_after_seconds": 5}
+	if delay, _ := scratchDelay(request); delay != 0 {
+		t.Errorf("Expected no restore without scratch, got %v", delay)
+	}
+}
+
+// Test that concealed content is neither appended to nor snapshot for a
+// scratch write, so it is never written back or recorded
+func TestConcealedWriteBack(t *testing.T) {
+	fakeConcealedCopyQ(t)
+	cs := NewClipboardServer()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"text": "more"}
+	result, err := cs.appendToClipboardHandler(context.Background(), request)
+	if err != nil || !result.IsError || result.Meta.AdditionalFields["status"] != StatusConcealed {
+		t.Errorf("Expected append_to_clipboard to refuse concealed content, got %v (%v)", result, err)
+	}
+
+	result, err = cs.writeClipboardHandler(context.Background(), scratchWriteRequest("transfer"))
+	if err != nil || !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "concealed") {
+		t.Errorf("Expected the scratch write to be refused, got %v (%v)", result, err)
+	}
+	if len(cs.history.recent(0)) != 0 {
+		t.Error("Expected nothing to be recorded in history")
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultReadRetries  = 2
+	DefaultRetryBackoff = 100 * time.Millisecond
+)
+
+// getReadRetries returns how many times a failed clipboard read is retried.
+func getReadRetries() int {
+	if retriesStr := os.Getenv("MCP_CLIP_READ_RETRIES"); retriesStr != "" {
+		if retries, err := strconv.Atoi(retriesStr); err == nil && retries >= 0 {
+			return retries
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Invalid MCP_CLIP_READ_RETRIES '%s', using default: %d\n", retriesStr, DefaultReadRetries)
+		}
+	}
+	return DefaultReadRetries
+}
+
+// getRetryBackoff returns the wait before the first retry; it doubles for
+// each further retry.
+func getRetryBackoff() time.Duration {
+	if backoffStr := os.Getenv("MCP_CLIP_RETRY_BACKOFF"); backoffStr != "" {
+		if backoff, err := time.ParseDuration(backoffStr); err == nil && backoff >= 0 {
+			return backoff
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Invalid MCP_CLIP_RETRY_BACKOFF format '%s', using default: %v\n", backoffStr, DefaultRetryBackoff)
+		}
+	}
+	return DefaultRetryBackoff
+}
+
+// isTransientReadError reports whether a failed read is worth retrying, such
+// as another application holding the clipboard open or a busy X server.
+// Missing utilities, missing displays, an empty clipboard, oversized content,
+// timeouts and cancellation fail the same way every time. Errors are matched
+// by type where the backend has one, by message otherwise.
+func isTransientReadError(err error) bool {
+	if _, ok := asOversize(err); ok {
+		return false
+	}
+	var empty *emptySelectionError
+	if errors.As(err, &empty) || errors.Is(err, exec.ErrNotFound) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if isEmptySelectionMessage(err.Error()) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, permanent := range []string{"timed out", "not found", "no clipboard utilities", "display", "not supported", "cannot"} {
+		if strings.Contains(msg, permanent) {
+			return false
+		}
+	}
+	return true
+}
+
+// readWithRetry calls read until it succeeds, fails permanently, or the
+// retries configured by MCP_CLIP_READ_RETRIES are used up, backing off
+// exponentially in between.
+func readWithRetry[T any](ctx context.Context, read func(context.Context) (T, error)) (T, error) {
+	backoff := getRetryBackoff()
+	retries := getReadRetries()
+	for attempt := 0; ; attempt++ {
+		value, err := read(ctx)
+		if err == nil || attempt >= retries || !isTransientReadError(err) {
+			return value, err
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Clipboard read failed (%v), retrying in %v\n", err, backoff)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return value, err
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+// Test that transient failures are retried and permanent ones are not
+func TestReadWithRetry(t *testing.T) {
+	t.Setenv("MCP_CLIP_RETRY_BACKOFF", "1ms")
+	t.Setenv("MCP_CLIP_READ_RETRIES", "2")
+
+	calls := 0
+	content, err := readWithRetry(context.Background(), func(context.Context) (string, error) {
+		if calls++; calls < 3 {
+			return "", errors.New("OpenClipboard: Access is denied.")
+		}
+		return "hello", nil
+	})
+	if err != nil || content != "hello" || calls != 3 {
+		t.Errorf("Expected success on the third attempt, got %q (%v) after %d calls", content, err, calls)
+	}
+
+	calls = 0
+	readWithRetry(context.Background(), func(context.Context) (string, error) {
+		calls++
+		return "", errors.New("every clipboard utility failed: xclip: exit status 1")
+	})
+	if calls != 3 {
+		t.Errorf("Expected 1 attempt and 2 retries, got %d calls", calls)
+	}
+
+	calls = 0
+	readWithRetry(context.Background(), func(context.Context) (string, error) {
+		calls++
+		return "", errors.New("no clipboard utilities available: install wl-clipboard, xclip or xsel")
+	})
+	if calls != 1 {
+		t.Errorf("Expected a permanent error not to be retried, got %d calls", calls)
+	}
+}
+
+// Test which errors count as transient
+func TestIsTransientReadError(t *testing.T) {
+	cases := []struct {
+		err       error
+		transient bool
+	}{
+		{errors.New("OpenClipboard: Access is denied."), true},
+		{errors.New("clipboard utility timed out after 10s"), false},
+		{errors.New("Error: Can't open display: (null)"), false},
+		{&oversizeError{size: 10, limit: 5}, false},
+		{context.Canceled, false},
+		{errors.New("exit status 1: Error: target STRING not available"), false},
+		{errors.New("exit status 1: No selection"), false},
+		{&emptySelectionError{utility: "xclip", err: errors.New("exit status 1")}, false},
+		{fmt.Errorf("every clipboard utility failed: %w", exec.ErrNotFound), false},
+	}
+	for _, c := range cases {
+		if got := isTransientReadError(c.err); got != c.transient {
+			t.Errorf("Expected transient=%v for %v, got %v", c.transient, c.err, got)
+		}
+	}
+}
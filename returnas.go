@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// resourceResult returns clipboard content as an embedded resource with its
+// URI and MIME type, for clients that want structured access rather than
+// prose. The URI is that of the history entry for raw, the content as read
+// before normalization; raw is "" when content is a transformed part or copy
+// of it, which the history entry would not match. Content that was not
+// recorded, or was transformed, is saved and named by its clipboard://files/
+// URI instead, and content too large to embed is only
+// linked. Text is returned as text unless format is "base64".
+func (cs *ClipboardServer) resourceResult(ctx context.Context, content, raw, format string) (*mcp.CallToolResult, error) {
+	if denied := cs.policyResult(content); denied != nil {
+		return denied, nil
+	}
+	kind, sniffed := classifyContent(content)
+	mimeType := contentMIMEType(content)
+	asText := kind == "text" && format != "base64"
+	embedSize := len(content)
+	if !asText {
+		embedSize = base64.StdEncoding.EncodedLen(len(content))
+	}
+	fits := embedSize <= getInlineThresholds().embedded
+
+	var uri, name string
+	var spill *spillInfo
+	if entry, ok := cs.history.findHash(contentHash(raw)); ok && fits && raw != "" {
+		uri = fmt.Sprintf("%s%d", historyURIPrefix, entry.ID)
+	} else {
+		src, ext := textSource(content), "txt"
+		switch {
+		case kind == "image" || (kind == "binary" && binaryMIMETypes[sniffed] != ""):
+			ext = sniffed
+		case kind == "binary":
+			src, ext = base64Source(src), "b64"
+		}
+		saved, filePath, err := cs.saveSpill(ctx, src, ext)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to save clipboard content as a resource: %v", err)), nil
+		}
+		name, spill = filepath.Base(filePath), &saved
+		uri = spillURIPrefix + name
+	}
+
+	description := fmt.Sprintf("Clipboard %s content (%s, %d bytes)", kind, mimeType, len(content))
+	var resource mcp.Content
+	switch {
+	case !fits:
+		resource = mcp.NewResourceLink(uri, name, description, mimeType)
+	case asText:
+		resource = mcp.NewEmbeddedResource(mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: content})
+	default:
+		resource = mcp.NewEmbeddedResource(mcp.BlobResourceContents{URI: uri, MIMEType: mimeType, Blob: encodeBase64(ctx, []byte(content))})
+	}
+	result := withResource(mcp.NewToolResultText(fmt.Sprintf("%s as resource %s", description, uri)), resource)
+	meta := resultMeta(result)
+	meta["uri"] = uri
+	meta["mimeType"] = mimeType
+	if spill != nil {
+		withSpill(result, *spill)
+	}
+	return result, nil
+}
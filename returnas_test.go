@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test that recorded text is returned as an embedded text resource named by
+// its history entry
+func TestResourceResultHistory(t *testing.T) {
+	cs := NewClipboardServer()
+	entry := cs.history.add(`{"key": "value"}`, time.Now())
+
+	result, err := cs.resourceResult(context.Background(), `{"key": "value"}`, `{"key": "value"}`, "auto")
+	if err != nil || result.IsError {
+		t.Fatalf("Expected a resource result, got %v (%v)", result, err)
+	}
+	embedded, ok := result.Content[1].(mcp.EmbeddedResource)
+	if !ok {
+		t.Fatalf("Expected an embedded resource, got %T", result.Content[1])
+	}
+	text, ok := embedded.Resource.(mcp.TextResourceContents)
+	expectedURI := fmt.Sprintf("%s%d", historyURIPrefix, entry.ID)
+	if !ok || text.URI != expectedURI || text.MIMEType != "application/json" || text.Text != `{"key": "value"}` {
+		t.Errorf("Expected the JSON text at %s, got %+v", expectedURI, embedded.Resource)
+	}
+	if result.Meta.AdditionalFields["uri"] != expectedURI {
+		t.Errorf("Expected uri %s in _meta, got %v", expectedURI, result.Meta.AdditionalFields["uri"])
+	}
+
+	// base64 returns the same text as a blob
+	result, _ = cs.resourceResult(context.Background(), `{"key": "value"}`, `{"key": "value"}`, "base64")
+	blob, ok := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents)
+	if decoded, _ := base64.StdEncoding.DecodeString(blob.Blob); !ok || string(decoded) != `{"key": "value"}` {
+		t.Errorf("Expected a base64 blob of the text, got %+v", result.Content[1])
+	}
+}
+
+// Test that unrecorded content is saved and named by its file resource, and
+// that content too large to embed is only linked
+func TestResourceResultSpill(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	cs := NewClipboardServer()
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+	result, err := cs.resourceResult(context.Background(), png, png, "auto")
+	if err != nil || result.IsError {
+		t.Fatalf("Expected a resource result, got %v (%v)", result, err)
+	}
+	blob, ok := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents)
+	if !ok || !strings.HasPrefix(blob.URI, spillURIPrefix) || !strings.HasSuffix(blob.URI, ".png") || blob.MIMEType != "image/png" {
+		t.Errorf("Expected a PNG blob named by its saved file, got %+v", result.Content[1])
+	}
+	if _, ok := result.Meta.AdditionalFields["spill"]; !ok {
+		t.Error("Expected the saved file in _meta.spill")
+	}
+
+	t.Setenv("MCP_CLIP_MAX_EMBEDDED", "10")
+	result, _ = cs.resourceResult(context.Background(), "a longer piece of text", "a longer piece of text", "auto")
+	link, ok := result.Content[1].(mcp.ResourceLink)
+	if !ok || !strings.HasSuffix(link.URI, ".txt") || link.MIMEType != "text/plain" {
+		t.Errorf("Expected a link to the saved text, got %+v", result.Content[1])
+	}
+}
+
+// Test that only untransformed reads are named by their history entry
+func TestReadClipboardResourceTransformed(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	fakeClipboard(t, "first\nsecond\nthird\n")
+	cs := NewClipboardServer()
+	cs.onDemand = true
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"return_as": "resource", "format": "text"}
+	result, err := cs.readClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	if uri := result.Meta.AdditionalFields["uri"].(string); !strings.HasPrefix(uri, historyURIPrefix) {
+		t.Errorf("Expected the whole content to be named by its history entry, got %s", uri)
+	}
+
+	request.Params.Arguments = map[string]any{"return_as": "resource", "format": "text", "start_line": float64(2), "end_line": float64(2)}
+	result, err = cs.readClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	text, ok := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.TextResourceContents)
+	if !ok || !strings.HasPrefix(text.URI, spillURIPrefix) || text.Text != "second\n" {
+		t.Errorf("Expected the selected line named by its saved file, got %+v", result.Content[1])
+	}
+}
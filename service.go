@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	serviceTaskName = "mcp-clip"
+
+	// DefaultServiceAddr is where the Windows service listens by default.
+	DefaultServiceAddr = "127.0.0.1:8765"
+)
+
+// The Windows "service" is a per-user scheduled task started at logon rather
+// than a service control manager service: services run in session 0, which
+// has no access to the user's clipboard. The task restarts the server when it
+// fails and runs it without a console window.
+const serviceTaskTemplate = `<?xml version="1.0" encoding="UTF-16"?>
+<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">
+  <RegistrationInfo>
+    <Description>MCP clipboard server (mcp-clip)</Description>
+  </RegistrationInfo>
+  <Triggers>
+    <LogonTrigger>
+      <Enabled>true</Enabled>
+      <UserId>%[1]s</UserId>
+    </LogonTrigger>
+  </Triggers>
+  <Principals>
+    <Principal id="Author">
+      <UserId>%[1]s</UserId>
+      <LogonType>InteractiveToken</LogonType>
+      <RunLevel>LeastPrivilege</RunLevel>
+    </Principal>
+  </Principals>
+  <Settings>
+    <MultipleInstancesPolicy>IgnoreNew</MultipleInstancesPolicy>
+    <DisallowStartIfOnBatteries>false</DisallowStartIfOnBatteries>
+    <StopIfGoingOnBatteries>false</StopIfGoingOnBatteries>
+    <ExecutionTimeLimit>PT0S</ExecutionTimeLimit>
+    <RestartOnFailure>
+      <Interval>PT1M</Interval>
+      <Count>999</Count>
+    </RestartOnFailure>
+    <Enabled>true</Enabled>
+  </Settings>
+  <Actions Context="Author">
+    <Exec>
+      <Command>%[2]s</Command>
+      <Arguments>%[3]s</Arguments>
+    </Exec>
+  </Actions>
+</Task>
+`
+
+// handleServiceCommand installs or removes the Windows background server.
+func handleServiceCommand(args []string) {
+	if len(args) == 0 || (args[0] != "install" && args[0] != "uninstall") {
+		fmt.Printf("Usage: %s service install [--http ADDR] | uninstall\n", os.Args[0])
+		os.Exit(1)
+	}
+	if runtime.GOOS != "windows" {
+		fmt.Fprintf(os.Stderr, "The service command is only available on Windows; on Linux use install --systemd-user\n")
+		os.Exit(1)
+	}
+
+	var err error
+	if args[0] == "install" {
+		fs := flag.NewFlagSet("service install", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		addr := fs.String("http", DefaultServiceAddr, "address the service listens on")
+		if err = fs.Parse(args[1:]); err != nil {
+			fmt.Printf("Invalid arguments: %v\n", err)
+			os.Exit(1)
+		}
+		err = installWindowsService(*addr, os.Stdout)
+	} else {
+		err = uninstallWindowsService(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Service %s failed: %v\n", args[0], err)
+		os.Exit(1)
+	}
+}
+
+// serviceTaskXML is the task definition that runs exe over HTTP at addr when
+// userID logs on.
+func serviceTaskXML(exe, addr, userID string) string {
+	arguments := "--background --http " + addr
+	return fmt.Sprintf(serviceTaskTemplate, xmlEscape(userID), xmlEscape(exe), xmlEscape(arguments))
+}
+
+func xmlEscape(s string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(s))
+	return escaped.String()
+}
+
+// utf16File encodes s as UTF-16LE with a byte order mark, the encoding
+// schtasks expects of task XML files.
+func utf16File(s string) []byte {
+	var encoded bytes.Buffer
+	encoded.Write([]byte{0xFF, 0xFE})
+	for _, unit := range utf16.Encode([]rune(s)) {
+		encoded.WriteByte(byte(unit))
+		encoded.WriteByte(byte(unit >> 8))
+	}
+	return encoded.Bytes()
+}
+
+// installWindowsService registers the logon task, replacing an earlier one,
+// and starts it right away.
+func installWindowsService(addr string, out io.Writer) error {
+	exe, err := installedExecutable()
+	if err != nil {
+		return err
+	}
+	current, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("failed to determine the current user: %v", err)
+	}
+
+	taskFile := filepath.Join(os.TempDir(), FilenamePrefix+"service.xml")
+	if err := os.WriteFile(taskFile, utf16File(serviceTaskXML(exe, addr, current.Username)), 0600); err != nil {
+		return fmt.Errorf("failed to write the task definition: %v", err)
+	}
+	defer os.Remove(taskFile)
+
+	if err := runSchtasks("/Create", "/TN", serviceTaskName, "/XML", taskFile, "/F"); err != nil {
+		return err
+	}
+	if err := runSchtasks("/Run", "/TN", serviceTaskName); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Installed and started the %s task; MCP is served at http://%s/mcp\n", serviceTaskName, addr)
+	return nil
+}
+
+// uninstallWindowsService stops the running server and removes the task.
+func uninstallWindowsService(out io.Writer) error {
+	runSchtasks("/End", "/TN", serviceTaskName)
+	if err := runSchtasks("/Delete", "/TN", serviceTaskName, "/F"); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Removed the %s task\n", serviceTaskName)
+	return nil
+}
+
+func runSchtasks(args ...string) error {
+	output, err := exec.Command("schtasks", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("schtasks %s failed: %v: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
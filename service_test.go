@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// Test that the task definition is valid XML running the server at logon
+func TestServiceTaskXML(t *testing.T) {
+	definition := serviceTaskXML(`C:\Tools & Apps\mcp-clip.exe`, "127.0.0.1:9000", `PC\alice`)
+
+	var task struct {
+		UserID  string `xml:"Principals>Principal>UserId"`
+		Trigger string `xml:"Triggers>LogonTrigger>UserId"`
+		Command string `xml:"Actions>Exec>Command"`
+		Args    string `xml:"Actions>Exec>Arguments"`
+	}
+	decoder := xml.NewDecoder(strings.NewReader(definition))
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := decoder.Decode(&task); err != nil {
+		t.Fatalf("Invalid task XML: %v", err)
+	}
+	if task.UserID != `PC\alice` || task.Trigger != `PC\alice` {
+		t.Errorf("Expected the task to run as the user at their logon, got %+v", task)
+	}
+	if task.Command != `C:\Tools & Apps\mcp-clip.exe` || task.Args != "--background --http 127.0.0.1:9000" {
+		t.Errorf("Unexpected action: %+v", task)
+	}
+}
+
+// Test that task files are written as UTF-16LE with a byte order mark
+func TestUTF16File(t *testing.T) {
+	encoded := utf16File("<é>")
+	if len(encoded) != 8 || encoded[0] != 0xFF || encoded[1] != 0xFE {
+		t.Fatalf("Expected a BOM and three code units, got %v", encoded)
+	}
+	units := make([]uint16, 0, 3)
+	for i := 2; i < len(encoded); i += 2 {
+		units = append(units, uint16(encoded[i])|uint16(encoded[i+1])<<8)
+	}
+	if got := string(utf16.Decode(units)); got != "<é>" {
+		t.Errorf("Expected <é>, got %q", got)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	snippetExt        = ".txt"
+	snippetPreviewLen = 60
+	maxSnippetSize    = 1 << 20
+)
+
+var snippetNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// snippetStore keeps named text snippets as one file each in a directory.
+type snippetStore struct {
+	dir string
+}
+
+type snippet struct {
+	Name     string
+	Content  string
+	Modified time.Time
+}
+
+// newSnippetStore keeps snippets in the "snippets" subdirectory of base. An
+// empty base yields a store whose operations all fail with a hint.
+func newSnippetStore(base string) *snippetStore {
+	if base == "" {
+		return &snippetStore{}
+	}
+	return &snippetStore{dir: filepath.Join(base, "snippets")}
+}
+
+func (st *snippetStore) path(name string) (string, error) {
+	if st.dir == "" {
+		return "", fmt.Errorf("no data directory available; set MCP_CLIP_DATA_DIR")
+	}
+	if !snippetNamePattern.MatchString(name) || strings.Contains(name, "..") {
+		return "", fmt.Errorf("invalid snippet name %q: use up to 64 letters, digits, '.', '_' or '-'", name)
+	}
+	return filepath.Join(st.dir, name+snippetExt), nil
+}
+
+// save stores content under name. Existing snippets are only replaced when
+// overwrite is set.
+func (st *snippetStore) save(name, content string, overwrite bool) error {
+	path, err := st.path(name)
+	if err != nil {
+		return err
+	}
+	if !overwrite {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("snippet %q already exists (pass overwrite to replace it)", name)
+		}
+	}
+	if err := os.MkdirAll(st.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create snippet directory: %v", err)
+	}
+
+	// Write to a temp file and rename so a crash never leaves a truncated snippet
+	tmp, err := os.CreateTemp(st.dir, ".snippet-*")
+	if err != nil {
+		return fmt.Errorf("failed to create snippet file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snippet: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snippet: %v", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (st *snippetStore) load(name string) (string, error) {
+	path, err := st.path(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("snippet %q not found", name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read snippet %q: %v", name, err)
+	}
+	return string(data), nil
+}
+
+// list returns all snippets sorted by name.
+func (st *snippetStore) list() ([]snippet, error) {
+	if st.dir == "" {
+		return nil, fmt.Errorf("no data directory available; set MCP_CLIP_DATA_DIR")
+	}
+	entries, err := os.ReadDir(st.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snippets: %v", err)
+	}
+
+	var snippets []snippet
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), snippetExt)
+		if !ok || entry.IsDir() || !snippetNamePattern.MatchString(name) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(st.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		snippets = append(snippets, snippet{Name: name, Content: string(content), Modified: info.ModTime()})
+	}
+	sort.Slice(snippets, func(i, j int) bool { return snippets[i].Name < snippets[j].Name })
+	return snippets, nil
+}
+
+func (cs *ClipboardServer) saveSnippetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	content := request.GetString("content", "")
+	source := "provided text"
+	if content == "" {
+		if content, err = readClipboard(ctx); err != nil {
+			return backendErrorResult(ctx, err), nil
+		}
+		if content == "" {
+			return mcp.NewToolResultError("No content given and the clipboard is empty"), nil
+		}
+		if hint := cs.concealedHint(ctx, content); hint != "" {
+			return concealedResult(hint), nil
+		}
+		if !isProbablyText(content) {
+			return mcp.NewToolResultError("The clipboard holds binary content; only text can be saved as a snippet"), nil
+		}
+		if denied := cs.policyResult(content); denied != nil {
+			return denied, nil
+		}
+		source = "clipboard"
+	}
+	if len(content) > maxSnippetSize {
+		return mcp.NewToolResultError(fmt.Sprintf("Snippet too large (%d bytes, limit %d)", len(content), maxSnippetSize)), nil
+	}
+
+	if err := cs.snippets.save(name, content, request.GetBool("overwrite", false)); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to save snippet: %v", err)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Saved snippet %q from %s (%d bytes)", name, source, len(content))), nil
+}
+
+func (cs *ClipboardServer) listSnippetsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	snippets, err := cs.snippets.list()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to list snippets: %v", err)), nil
+	}
+	return mcp.NewToolResultText(renderSnippetList(snippets)), nil
+}
+
+func renderSnippetList(snippets []snippet) string {
+	if len(snippets) == 0 {
+		return "No snippets saved yet. Use save_snippet to add one."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d snippet(s):\n", len(snippets))
+	for _, s := range snippets {
+		fmt.Fprintf(&b, "- %s (%d bytes, %s): %s\n", s.Name, len(s.Content), s.Modified.Format("2006-01-02"), previewText(s.Content, snippetPreviewLen))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (cs *ClipboardServer) copySnippetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	content, err := cs.snippets.load(name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if content == "" {
+		return mcp.NewToolResultError(fmt.Sprintf("Snippet %q is empty", name)), nil
+	}
+
+	if denied := confirmOverwrite(ctx, fmt.Sprintf("copy snippet %q to the clipboard", name), content); denied != nil {
+		return denied, nil
+	}
+	if err := writeClipboard(ctx, content); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to write clipboard: %v", err)), nil
+	}
+	cs.recordChange(content)
+	return mcp.NewToolResultText(fmt.Sprintf("Copied snippet %q to the clipboard (%d bytes)", name, len(content))), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test saving, listing and loading snippets
+func TestSnippetStore(t *testing.T) {
+	store := newSnippetStore(t.TempDir())
+
+	if snippets, err := store.list(); err != nil || len(snippets) != 0 {
+		t.Errorf("Expected empty store, got %v (%v)", snippets, err)
+	}
+
+	if err := store.save("sig", "Best regards,\nMe", false); err != nil {
+		t.Fatalf("Failed to save snippet: %v", err)
+	}
+	if err := store.save("license-header", "// SPDX-License-Identifier: MIT", false); err != nil {
+		t.Fatalf("Failed to save snippet: %v", err)
+	}
+	if err := store.save("sig", "other", false); err == nil {
+		t.Errorf("Expected saving over an existing snippet to fail without overwrite")
+	}
+	if err := store.save("sig", "Cheers", true); err != nil {
+		t.Errorf("Expected overwrite to succeed, got %v", err)
+	}
+
+	content, err := store.load("sig")
+	if err != nil || content != "Cheers" {
+		t.Errorf("Expected overwritten content, got %q (%v)", content, err)
+	}
+	if _, err := store.load("missing"); err == nil {
+		t.Errorf("Expected error for a missing snippet")
+	}
+
+	snippets, err := store.list()
+	if err != nil || len(snippets) != 2 || snippets[0].Name != "license-header" || snippets[1].Name != "sig" {
+		t.Errorf("Expected 2 snippets sorted by name, got %+v (%v)", snippets, err)
+	}
+	if rendered := renderSnippetList(snippets); !strings.Contains(rendered, "- sig (6 bytes") {
+		t.Errorf("Unexpected listing: %s", rendered)
+	}
+}
+
+// Test that snippet names cannot escape the store directory
+func TestSnippetNameValidation(t *testing.T) {
+	store := newSnippetStore(t.TempDir())
+	for _, name := range []string{"", "../etc/passwd", "a/b", ".hidden", "a..b", strings.Repeat("x", 65)} {
+		if err := store.save(name, "x", false); err == nil {
+			t.Errorf("Expected name %q to be rejected", name)
+		}
+	}
+
+	if err := newSnippetStore("").save("ok", "x", false); err == nil {
+		t.Errorf("Expected a store without data directory to fail")
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/base64"
+	"io"
+)
+
+// spillSource is content to save to a temp file. Its write streams the
+// content in chunks, so a large clipboard is never copied or encoded in full
+// in memory on its way to disk.
+type spillSource struct {
+	size  int // bytes write produces
+	write func(w io.Writer) error
+}
+
+// bytesSource saves data as is.
+func bytesSource(data []byte) spillSource {
+	return spillSource{size: len(data), write: func(w io.Writer) error {
+		return writeWithProgress(w, data, func(int) {})
+	}}
+}
+
+// textSource saves content as is, without converting it to a []byte copy.
+func textSource(content string) spillSource {
+	return spillSource{size: len(content), write: func(w io.Writer) error {
+		for offset := 0; offset < len(content); offset += progressChunkSize {
+			if _, err := io.WriteString(w, content[offset:min(offset+progressChunkSize, len(content))]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+}
+
+// base64Source saves src base64-encoded, encoding it as it is written.
+func base64Source(src spillSource) spillSource {
+	return spillSource{size: base64.StdEncoding.EncodedLen(src.size), write: func(w io.Writer) error {
+		encoder := base64.NewEncoder(base64.StdEncoding, w)
+		if err := src.write(encoder); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test that each source writes exactly size bytes of the expected content
+func TestSpillSources(t *testing.T) {
+	data := bytes.Repeat([]byte{0x00, 0xff, 'a', 0x10, 0x7f}, progressChunkSize/2)
+	text := strings.Repeat("clipboard текст ", progressChunkSize/8)
+	for _, tc := range []struct {
+		name     string
+		src      spillSource
+		expected string
+	}{
+		{"bytes", bytesSource(data), string(data)},
+		{"text", textSource(text), text},
+		{"base64", base64Source(bytesSource(data)), base64.StdEncoding.EncodeToString(data)},
+		{"base64 text", base64Source(textSource(text)), base64.StdEncoding.EncodeToString([]byte(text))},
+		{"empty", base64Source(textSource("")), ""},
+	} {
+		var buf bytes.Buffer
+		if err := tc.src.write(&buf); err != nil {
+			t.Fatalf("%s: Expected no error, got %v", tc.name, err)
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("%s: Expected %d bytes of the content, got %d different bytes", tc.name, len(tc.expected), buf.Len())
+		}
+		if tc.src.size != buf.Len() {
+			t.Errorf("%s: Expected size %d, got %d", tc.name, buf.Len(), tc.src.size)
+		}
+	}
+}
+
+// Test that base64 spills are encoded into the file with the size and
+// sha256 of the encoded content
+func TestSaveSpillBase64(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	data := bytes.Repeat([]byte{0x00, 0x01, 0xfe}, 100000)
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	spill, filePath, err := NewClipboardServer().saveSpill(context.Background(), base64Source(bytesSource(data)), "b64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ := os.ReadFile(filePath); string(saved) != encoded {
+		t.Errorf("Expected the file to hold the base64 content, got %d bytes", len(saved))
+	}
+	if spill.size != len(encoded) || spill.sha256 != contentHash(encoded) {
+		t.Errorf("Expected size %d and sha256 %s, got %d and %s", len(encoded), contentHash(encoded), spill.size, spill.sha256)
+	}
+}
+
+// Test that a source that fails midway leaves no file behind
+func TestSaveSourceToTempFileFailure(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	failing := spillSource{size: 10, write: func(w io.Writer) error {
+		io.WriteString(w, "half")
+		return errors.New("read failed")
+	}}
+	if _, _, err := saveSourceToTempFile(failing, "txt", false, nil, func(int) {}); err == nil {
+		t.Error("Expected the failed write to be reported")
+	}
+	short := spillSource{size: 10, write: func(w io.Writer) error {
+		_, err := io.WriteString(w, "half")
+		return err
+	}}
+	if _, _, err := saveSourceToTempFile(short, "txt", false, nil, func(int) {}); err == nil {
+		t.Error("Expected a short write to be reported")
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("Expected no files left behind, got %v", files)
+	}
+}
+
+// Test that large text spills are gzipped above the threshold, reported with
+// the decompressed size and sha256, and served decompressed
+func TestCompressedSpill(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	t.Setenv("MCP_CLIP_COMPRESS_SPILLS_ABOVE", "1000")
+	cs := NewClipboardServer()
+	content := strings.Repeat("2024-01-01 INFO request handled\n", 1000)
+
+	spill, filePath, err := cs.saveSpill(context.Background(), textSource(content), "txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(filePath, ".txt.gz") || !spill.compressed {
+		t.Fatalf("Expected a compressed .txt.gz file, got %s", filePath)
+	}
+	if info, _ := os.Stat(filePath); info.Size() >= int64(len(content)) {
+		t.Errorf("Expected the file to be smaller than %d bytes, got %d", len(content), info.Size())
+	}
+	if spill.size != len(content) || spill.sha256 != contentHash(content) {
+		t.Errorf("Expected the decompressed size and sha256, got %d and %s", spill.size, spill.sha256)
+	}
+	if !strings.Contains(spill.String(), "gzip") {
+		t.Errorf("Expected the spill to mention gzip, got %s", spill)
+	}
+
+	again, _, err := cs.saveSpill(context.Background(), textSource(content), "txt")
+	if err != nil || again.location != spill.location {
+		t.Errorf("Expected identical content to reuse %s, got %s (%v)", spill.location, again.location, err)
+	}
+
+	var request mcp.ReadResourceRequest
+	request.Params.URI = spillURIPrefix + filepath.Base(filePath)
+	contents, err := cs.spilledFileResourceHandler(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := contents[0].(mcp.BlobResourceContents)
+	if decoded, _ := base64.StdEncoding.DecodeString(blob.Blob); string(decoded) != content || blob.MIMEType != "text/plain" {
+		t.Errorf("Expected the decompressed text as text/plain, got %d bytes of %s", len(decoded), blob.MIMEType)
+	}
+
+	// Small text, images and gzip clipboard content are saved as is
+	if small, _, _ := cs.saveSpill(context.Background(), textSource("short"), "txt"); small.compressed {
+		t.Error("Expected content below the threshold to be saved uncompressed")
+	}
+	if compressSpill("png", 1<<20) || compressSpill("gz", 1<<20) {
+		t.Error("Expected images and compressed formats never to be gzipped")
+	}
+	if isCompressedSpill(FilenamePrefix+"1-abc.gz") || !isCompressedSpill(FilenamePrefix+"1-abc.b64.gz") {
+		t.Error("Expected only double .gz extensions to mark compressed spills")
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Read outcomes reported as "status" in the result metadata, so clients can
+// tell an empty clipboard from one they cannot read.
+const (
+	StatusEmpty             = "empty"
+	StatusUnsupportedFormat = "unsupported_format"
+	StatusError             = "error"
+)
+
+// psClipboardFormatsScript lists the formats on the Windows clipboard.
+const psClipboardFormatsScript = `Add-Type -AssemblyName System.Windows.Forms; $data = [System.Windows.Forms.Clipboard]::GetDataObject(); if ($data) { $data.GetFormats() }`
+
+// listClipboardFormats returns the raw format names the clipboard currently
+// offers (MIME types, X11 targets, Windows or macOS clipboard types).
+func listClipboardFormats(ctx context.Context) ([]string, error) {
+	if reader, ok := selectBackend().(formatReader); ok {
+		return reader.Formats(ctx)
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case isTermux():
+		return nil, fmt.Errorf("the Termux clipboard cannot list formats")
+	case isWSL2() || runtime.GOOS == "windows":
+		powershellPath := findPowerShell()
+		if powershellPath == "" {
+			return nil, fmt.Errorf("PowerShell not found")
+		}
+		cmd = powershellCommand(ctx, powershellPath, "-NoProfile", "-STA", "-Command", psClipboardFormatsScript)
+	case runtime.GOOS == "darwin":
+		output, err := exec.CommandContext(ctx, "osascript", "-e", "clipboard info").Output()
+		if err != nil {
+			return nil, err
+		}
+		return parseAppleScriptClipboardInfo(string(output)), nil
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		args := withWaylandSeat([]string{"wl-paste", "--list-types"})
+		cmd = exec.CommandContext(ctx, args[0], args[1:]...)
+	default:
+		cmd = exec.CommandContext(ctx, "xclip", "-o", "-selection", "clipboard", "-t", "TARGETS")
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var formats []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			formats = append(formats, line)
+		}
+	}
+	return formats, nil
+}
+
+// parseAppleScriptClipboardInfo extracts the types from "clipboard info"
+// output, which alternates types and sizes: "«class PNGf», 2048, TIFF picture, 9000".
+func parseAppleScriptClipboardInfo(output string) []string {
+	var formats []string
+	fields := strings.Split(strings.TrimSpace(output), ", ")
+	for i := 0; i < len(fields); i += 2 {
+		if format := strings.TrimSpace(fields[i]); format != "" {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// unreadableFormats filters formats down to meaningful content types. It
+// returns nil if any text flavor is present, since text is always readable.
+func unreadableFormats(formats []string) []string {
+	var content []string
+	for _, format := range formats {
+		lower := strings.ToLower(format)
+		switch {
+		case lower == "text/uri-list":
+			// Copied files, which have no text flavor of their own
+		case strings.Contains(lower, "text") || strings.Contains(lower, "string") || strings.Contains(lower, "utf8"):
+			return nil
+		case lower == "targets" || lower == "timestamp" || lower == "multiple" || lower == "save_targets" ||
+			strings.HasPrefix(lower, "chromium/") || strings.HasPrefix(lower, "application/x-qt"):
+			continue
+		}
+		content = append(content, format)
+	}
+	return content
+}
+
+// remediationHint suggests a fix for a clipboard backend error.
+func remediationHint(err error) string {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "timed out"):
+		return "The clipboard utility did not respond in time. Retry, or raise MCP_CLIP_READ_TIMEOUT if large content takes longer to read."
+	case strings.Contains(msg, "powershell not found"):
+		return "Enable Windows interop in WSL ([interop] enabled=true in /etc/wsl.conf) so powershell.exe can be reached."
+	case strings.Contains(msg, "termux"):
+		return "Install the Termux:API app and run `pkg install termux-api`."
+	case isContainer() && os.Getenv("MCP_CLIP_BRIDGE") == "" && (strings.Contains(msg, "display") || strings.Contains(msg, "not found") || strings.Contains(msg, "no clipboard utilities")):
+		return "Containers can't reach the desktop clipboard: run `mcp-clip bridge --listen unix:/path/mcp-clip-bridge.sock` on the host, mount the socket and set MCP_CLIP_BRIDGE=unix:/path/mcp-clip-bridge.sock."
+	case strings.Contains(msg, "display"):
+		return "No graphical session is reachable: set DISPLAY or WAYLAND_DISPLAY, or use clipboard sync or HTTP mode on headless machines."
+	case strings.Contains(msg, "not found") || strings.Contains(msg, "no clipboard utilities"):
+		return "Install a clipboard utility: wl-clipboard on Wayland, or xclip or xsel on X11."
+	default:
+		return "Run `mcp-clip test` for diagnostics, or set MCP_DEBUG=1 for detailed logs."
+	}
+}
+
+// resultMeta returns the _meta fields of a result, creating them if needed.
+func resultMeta(result *mcp.CallToolResult) map[string]any {
+	if result.Meta == nil {
+		result.Meta = &mcp.Meta{}
+	}
+	if result.Meta.AdditionalFields == nil {
+		result.Meta.AdditionalFields = make(map[string]any)
+	}
+	return result.Meta.AdditionalFields
+}
+
+func withStatus(result *mcp.CallToolResult, status, hint string) *mcp.CallToolResult {
+	meta := resultMeta(result)
+	meta["status"] = status
+	if hint != "" {
+		meta["hint"] = hint
+	}
+	return result
+}
+
+// unsupportedFormatResult reports content the backend cannot read, if the
+// clipboard offers any; ok is false when nothing but text (or nothing at
+// all) is on the clipboard.
+func unsupportedFormatResult(ctx context.Context) (*mcp.CallToolResult, bool) {
+	ctx, cancel := withReadTimeout(ctx)
+	defer cancel()
+	formats, err := listClipboardFormats(ctx)
+	if err != nil {
+		return nil, false
+	}
+	unreadable := unreadableFormats(formats)
+	if len(unreadable) == 0 {
+		return nil, false
+	}
+
+	hint := fmt.Sprintf("The %s backend can only read text here. Copy the content as text, or save it to a file and share the path.", selectBackend().Name())
+	result := mcp.NewToolResultText(fmt.Sprintf("Clipboard holds content in a format that cannot be read (%s). %s", strings.Join(unreadable, ", "), hint))
+	result.Meta = mcp.NewMetaFromMap(map[string]any{"formats": unreadable})
+	return withStatus(result, StatusUnsupportedFormat, hint), true
+}
+
+// emptyClipboardResult distinguishes a truly empty clipboard from one holding
+// only formats the backend cannot read.
+func emptyClipboardResult(ctx context.Context) *mcp.CallToolResult {
+	if result, ok := unsupportedFormatResult(ctx); ok {
+		return result
+	}
+	hint := "Copy something and try again, or pass selection_fallback to read the selected text instead."
+	return withStatus(mcp.NewToolResultText("Clipboard is empty"), StatusEmpty, hint)
+}
+
+// backendErrorResult reports a failed clipboard read. Some backends fail
+// rather than return nothing when only non-text formats are present, so that
+// case is reported as unsupported content instead of an error.
+func backendErrorResult(ctx context.Context, err error) *mcp.CallToolResult {
+	if oversize, ok := asOversize(err); ok {
+		return tooLargeResult(oversize)
+	}
+	var empty *emptySelectionError
+	if errors.As(err, &empty) {
+		return emptyClipboardResult(ctx)
+	}
+	if result, ok := unsupportedFormatResult(ctx); ok {
+		return result
+	}
+	hint := remediationHint(err)
+	result := withStatus(mcp.NewToolResultError(fmt.Sprintf("Failed to read clipboard: %v. %s", err, hint)), StatusError, hint)
+	resultMeta(result)["environment"] = environmentMeta()
+	return result
+}
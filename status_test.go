@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test parsing of AppleScript "clipboard info" output
+func TestParseAppleScriptClipboardInfo(t *testing.T) {
+	got := parseAppleScriptClipboardInfo("«class PNGf», 2048, «class 8BPS», 9000, TIFF picture, 12000\n")
+	want := []string{"«class PNGf»", "«class 8BPS»", "TIFF picture"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// Test that only clipboards without any text flavor are reported as unreadable
+func TestUnreadableFormats(t *testing.T) {
+	tests := []struct {
+		formats []string
+		want    []string
+	}{
+		{[]string{"TARGETS", "TIMESTAMP", "image/png"}, []string{"image/png"}},
+		{[]string{"image/png", "UTF8_STRING"}, nil},
+		{[]string{"Bitmap", "DeviceIndependentBitmap"}, []string{"Bitmap", "DeviceIndependentBitmap"}},
+		{[]string{"UnicodeText", "Text"}, nil},
+		{[]string{"text/uri-list", "x-special/gnome-copied-files"}, []string{"text/uri-list", "x-special/gnome-copied-files"}},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		if got := unreadableFormats(tt.formats); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: expected %v, got %v", tt.formats, tt.want, got)
+		}
+	}
+}
+
+// Test remediation hints for common backend errors
+func TestRemediationHint(t *testing.T) {
+	markers := containerMarkerPaths
+	t.Cleanup(func() { containerMarkerPaths = markers })
+	containerMarkerPaths = nil
+	t.Setenv("container", "")
+	t.Setenv("MCP_CLIP_BRIDGE", "")
+
+	tests := []struct {
+		err, want string
+	}{
+		{"PowerShell not found - required for WSL2 clipboard access", "interop"},
+		{"exec: \"xclip\": executable file not found in $PATH", "xclip or xsel"},
+		{"No clipboard utilities available. Please install xsel, xclip, wl-clipboard or Termux:API add-on for termux-clipboard-get/set.", "Termux"},
+		{"Error: Can't open display: (null)", "DISPLAY"},
+		{"exit status 1", "mcp-clip test"},
+	}
+	for _, tt := range tests {
+		if got := remediationHint(errors.New(tt.err)); !strings.Contains(got, tt.want) {
+			t.Errorf("%q: expected hint mentioning %q, got %q", tt.err, tt.want, got)
+		}
+	}
+
+	t.Setenv("container", "podman")
+	if got := remediationHint(errors.New("Error: Can't open display: (null)")); !strings.Contains(got, "MCP_CLIP_BRIDGE") {
+		t.Errorf("Expected a bridge hint inside a container, got %q", got)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	currentURI = "clipboard://current"
+
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+
+	// maxSubscribeMessage bounds the messages checked for a subscription, so
+	// large tool calls aren't parsed twice.
+	maxSubscribeMessage = 4 << 10
+
+	// stdioSessionID is the session ID mcp-go gives the stdio client.
+	stdioSessionID = "stdio"
+)
+
+// subscribableURIs are the resources whose content changes with the clipboard.
+var subscribableURIs = map[string]bool{currentURI: true, timelineURI: true}
+
+// resourceSubscriptions answers resources/subscribe and
+// resources/unsubscribe, which mcp-go doesn't handle, and sends
+// notifications/resources/updated to the subscribed sessions when the
+// clipboard changes. The stdio client is recorded under mcp-go's stdio
+// session ID, so it and HTTP sessions can be served at the same time.
+type resourceSubscriptions struct {
+	mu       sync.Mutex
+	srv      *server.MCPServer
+	sessions map[string]map[string]bool // session ID -> subscribed URIs
+}
+
+// register sets the server notifications are sent through.
+func (rs *resourceSubscriptions) register(s *server.MCPServer) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.srv = s
+}
+
+// handle answers message if it is a subscription request from sessionID,
+// returning the JSON-RPC response. Any other message returns nil and is left
+// to the MCP server.
+func (rs *resourceSubscriptions) handle(sessionID string, message []byte) []byte {
+	var request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if len(message) > maxSubscribeMessage {
+		return nil
+	}
+	if err := json.Unmarshal(message, &request); err != nil || request.ID == nil {
+		return nil
+	}
+	subscribe := request.Method == methodResourcesSubscribe
+	if !subscribe && request.Method != methodResourcesUnsubscribe {
+		return nil
+	}
+
+	response := map[string]any{"jsonrpc": mcp.JSONRPC_VERSION, "id": request.ID}
+	if !subscribableURIs[request.Params.URI] {
+		response["error"] = map[string]any{
+			"code":    mcp.INVALID_PARAMS,
+			"message": fmt.Sprintf("resource %q does not change; subscribe to %s or %s", request.Params.URI, currentURI, timelineURI),
+		}
+	} else {
+		rs.mu.Lock()
+		if rs.sessions == nil {
+			rs.sessions = make(map[string]map[string]bool)
+		}
+		uris := rs.sessions[sessionID]
+		if subscribe {
+			if uris == nil {
+				uris = make(map[string]bool)
+				rs.sessions[sessionID] = uris
+			}
+			uris[request.Params.URI] = true
+		} else {
+			delete(uris, request.Params.URI)
+		}
+		rs.mu.Unlock()
+		response["result"] = map[string]any{}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Session %q %s %s\n", sessionID, request.Method, request.Params.URI)
+		}
+	}
+	encoded, _ := json.Marshal(response)
+	return encoded
+}
+
+// notify tells every subscribed session that the clipboard resources changed.
+// Sessions that can no longer be reached are forgotten.
+func (rs *resourceSubscriptions) notify() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.srv == nil {
+		return
+	}
+	for sessionID, uris := range rs.sessions {
+		for uri := range uris {
+			params := map[string]any{"uri": uri}
+			var err error
+			if sessionID == "" {
+				rs.srv.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, params)
+			} else {
+				err = rs.srv.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, params)
+			}
+			if err != nil {
+				if os.Getenv("MCP_DEBUG") == "1" {
+					fmt.Fprintf(os.Stderr, "Dropping subscriptions of session %q: %v\n", sessionID, err)
+				}
+				delete(rs.sessions, sessionID)
+				break
+			}
+		}
+	}
+}
+
+// serveStdio serves MCP over stdin and stdout like server.ServeStdio, until
+// ctx is cancelled, answering subscription requests before the rest reach
+// the MCP server.
+func (cs *ClipboardServer) serveStdio(ctx context.Context, s *server.MCPServer) error {
+	out := &lockedWriter{w: os.Stdout}
+	in, pipe := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if response := cs.subscriptions.handle(stdioSessionID, line); response != nil {
+				out.Write(append(response, '\n'))
+			} else if _, writeErr := pipe.Write(line); writeErr != nil {
+				return
+			}
+			if err != nil {
+				pipe.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return server.NewStdioServer(s).Listen(ctx, in, out)
+}
+
+// subscriptionMiddleware answers subscription requests posted to the
+// streamable HTTP endpoint by an established session.
+func (rs *resourceSubscriptions) subscriptionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Header.Get(server.HeaderKeySessionID)
+		if r.Method != http.MethodPost || sessionID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		head, err := io.ReadAll(io.LimitReader(r.Body, maxSubscribeMessage+1))
+		if response := rs.handle(sessionID, head); err == nil && response != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(response)
+			return
+		}
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// lockedWriter serializes writes from the stdio server and from subscription
+// responses, each of which is a single complete message.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// currentResourceHandler serves the live clipboard content, text as text and
+// anything else as a base64 blob.
+func (cs *ClipboardServer) currentResourceHandler(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	content, err := readClipboard(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if hint := cs.concealedHint(ctx, content); hint != "" {
+		return nil, fmt.Errorf("the clipboard holds content a password manager marked as concealed (%s)", hint)
+	}
+	if denied, rule := cs.policy.check(content); denied {
+		return nil, fmt.Errorf("clipboard content withheld by policy rule %s", rule)
+	}
+
+	mimeType := contentMIMEType(content)
+	if kind, _ := classifyContent(content); kind == "text" {
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: currentURI, MIMEType: mimeType, Text: content},
+		}, nil
+	}
+	return []mcp.ResourceContents{
+		mcp.BlobResourceContents{URI: currentURI, MIMEType: mimeType, Blob: base64.StdEncoding.EncodeToString([]byte(content))},
+	}, nil
+}
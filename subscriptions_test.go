@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// subscriberSession is a client session that collects its notifications.
+type subscriberSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *subscriberSession) Initialize()       {}
+func (s *subscriberSession) Initialized() bool { return true }
+func (s *subscriberSession) SessionID() string { return s.id }
+func (s *subscriberSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// Test that subscription requests are answered and other messages passed on
+func TestSubscriptionHandle(t *testing.T) {
+	var rs resourceSubscriptions
+	if rs.handle("", []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)) != nil {
+		t.Error("Expected other methods to be left to the MCP server")
+	}
+
+	var response struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	json.Unmarshal(rs.handle("a", []byte(`{"jsonrpc":"2.0","id":7,"method":"resources/subscribe","params":{"uri":"clipboard://current"}}`)), &response)
+	if response.ID != 7 || response.Error != nil || !rs.sessions["a"][currentURI] {
+		t.Errorf("Expected the subscription to be recorded, got %+v", response)
+	}
+
+	json.Unmarshal(rs.handle("a", []byte(`{"jsonrpc":"2.0","id":8,"method":"resources/subscribe","params":{"uri":"clipboard://history/1"}}`)), &response)
+	if response.Error == nil || response.Error.Code != mcp.INVALID_PARAMS {
+		t.Errorf("Expected unchanging resources to be rejected, got %+v", response)
+	}
+
+	rs.handle("a", []byte(`{"jsonrpc":"2.0","id":9,"method":"resources/unsubscribe","params":{"uri":"clipboard://current"}}`))
+	if rs.sessions["a"][currentURI] {
+		t.Error("Expected the subscription to be removed")
+	}
+}
+
+// Test that a clipboard change notifies subscribed sessions only
+func TestSubscriptionNotify(t *testing.T) {
+	cs := NewClipboardServer()
+	s := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(true, false))
+	cs.registerResources(s)
+	subscribed := &subscriberSession{id: "subscribed", notifications: make(chan mcp.JSONRPCNotification, 10)}
+	other := &subscriberSession{id: "other", notifications: make(chan mcp.JSONRPCNotification, 10)}
+	for _, session := range []*subscriberSession{subscribed, other} {
+		if err := s.RegisterSession(t.Context(), session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cs.subscriptions.handle("subscribed", []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"clipboard://timeline"}}`))
+
+	cs.updateClipboard("new content")
+	select {
+	case notification := <-subscribed.notifications:
+		if notification.Method != mcp.MethodNotificationResourceUpdated || notification.Params.AdditionalFields["uri"] != timelineURI {
+			t.Errorf("Expected an update for %s, got %+v", timelineURI, notification)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a resource update notification")
+	}
+	if len(other.notifications) != 0 {
+		t.Error("Expected sessions without subscriptions not to be notified")
+	}
+}
+
+// Test that the HTTP middleware answers subscriptions of established sessions
+// and passes everything else through intact
+func TestSubscriptionMiddleware(t *testing.T) {
+	var rs resourceSubscriptions
+	var passed string
+	handler := rs.subscriptionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		passed = string(body)
+	}))
+
+	post := func(body, sessionID string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		if sessionID != "" {
+			request.Header.Set(server.HeaderKeySessionID, sessionID)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	subscribe := `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"clipboard://current"}}`
+	if recorder := post(subscribe, "s1"); !strings.Contains(recorder.Body.String(), `"result":{}`) || passed != "" {
+		t.Errorf("Expected the subscription to be answered, got %q", recorder.Body.String())
+	}
+	if post(subscribe, ""); passed != subscribe {
+		t.Errorf("Expected requests without a session to be passed on, got %q", passed)
+	}
+	large := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"content":"` + strings.Repeat("x", 2*maxSubscribeMessage) + `"}}`
+	if post(large, "s1"); passed != large {
+		t.Errorf("Expected large bodies to be passed on intact, got %d bytes", len(passed))
+	}
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	syncNonceSize      = 32
+	syncMaxFrameSize   = 64 << 20 // 64MB, generous for images
+	syncHandshakeLimit = 10 * time.Second
+	syncRedialDelay    = 5 * time.Second
+	syncAuthMagic      = "mcp-clip-sync-v2"
+	syncSeqSize        = 8
+
+	// Roles of the two ends of a connection; the dialing peer is the client
+	syncRoleClient = "client"
+	syncRoleServer = "server"
+
+	syncFrameClip byte = 'C'
+)
+
+// syncConfig holds the opt-in clipboard sync settings read from the environment.
+type syncConfig struct {
+	listen string
+	peers  []string
+	token  string
+}
+
+func loadSyncConfig() syncConfig {
+	cfg := syncConfig{
+		listen: os.Getenv("MCP_CLIP_SYNC_LISTEN"),
+		token:  os.Getenv("MCP_CLIP_SYNC_TOKEN"),
+	}
+	for _, peer := range strings.Split(os.Getenv("MCP_CLIP_SYNC_PEERS"), ",") {
+		if peer = strings.TrimSpace(peer); peer != "" {
+			cfg.peers = append(cfg.peers, peer)
+		}
+	}
+	return cfg
+}
+
+func (c syncConfig) requested() bool {
+	return c.listen != "" || len(c.peers) > 0
+}
+
+// syncManager mirrors clipboard changes between mcp-clip instances. Peers
+// authenticate with a shared token via a nonce handshake, and every frame is
+// sealed with AES-GCM under a key derived from that token, the handshake and
+// the direction it travels in, with a sequence number as its nonce.
+type syncManager struct {
+	cfg    syncConfig
+	cs     *ClipboardServer
+	write  func(content string) error // applies remote content to the local clipboard
+	remote atomic.Value               // string: last content received from a peer
+
+	mu    sync.Mutex
+	conns map[*syncConn]struct{}
+}
+
+type syncConn struct {
+	conn    net.Conn
+	role    string      // syncRoleClient or syncRoleServer
+	auth    []byte      // key for the handshake's auth MACs
+	send    cipher.AEAD // seals frames to the peer
+	receive cipher.AEAD // opens frames from the peer
+	writeMu sync.Mutex  // protects sendSeq and frame writes
+	sendSeq uint64
+	recvSeq uint64 // only used by the connection's reader
+}
+
+func newSyncManager(cfg syncConfig, cs *ClipboardServer) *syncManager {
+	sm := &syncManager{
+		cfg: cfg,
+		cs:  cs,
+		write: func(content string) error {
+			ctx, cancel := withReadTimeout(context.Background())
+			defer cancel()
+			return selectBackend().Write(ctx, content)
+		},
+		conns: make(map[*syncConn]struct{}),
+	}
+	sm.remote.Store("")
+	return sm
+}
+
+// run starts the listener and peer dialers and blocks until ctx is cancelled.
+func (sm *syncManager) run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	if sm.cfg.listen != "" {
+		ln, err := net.Listen("tcp", sm.cfg.listen)
+		if err != nil {
+			return fmt.Errorf("failed to listen for sync peers on %s: %v", sm.cfg.listen, err)
+		}
+		go func() {
+			<-ctx.Done()
+			ln.Close()
+		}()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sm.acceptLoop(ctx, ln)
+		}()
+	}
+
+	for _, peer := range sm.cfg.peers {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			sm.dialLoop(ctx, addr)
+		}(peer)
+	}
+
+	wg.Wait()
+	return nil
+}
+
+func (sm *syncManager) acceptLoop(ctx context.Context, ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "Sync accept error: %v\n", err)
+			}
+			continue
+		}
+		go sm.serveConn(ctx, conn, syncRoleServer)
+	}
+}
+
+func (sm *syncManager) dialLoop(ctx context.Context, addr string) {
+	var dialer net.Dialer
+	for {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err == nil {
+			sm.serveConn(ctx, conn, syncRoleClient)
+		} else if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Sync dial %s failed: %v\n", addr, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(syncRedialDelay):
+		}
+	}
+}
+
+// serveConn authenticates a peer connection and relays frames until it closes.
+func (sm *syncManager) serveConn(ctx context.Context, conn net.Conn, role string) {
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	sc, reader, err := sm.handshake(conn, role)
+	if err != nil {
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Sync handshake with %s failed: %v\n", conn.RemoteAddr(), err)
+		}
+		return
+	}
+
+	sm.mu.Lock()
+	sm.conns[sc] = struct{}{}
+	sm.mu.Unlock()
+	defer func() {
+		sm.mu.Lock()
+		delete(sm.conns, sc)
+		sm.mu.Unlock()
+	}()
+
+	if os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Sync peer connected: %s\n", conn.RemoteAddr())
+	}
+
+	for {
+		kind, payload, err := sc.readFrame(reader)
+		if err != nil {
+			if os.Getenv("MCP_DEBUG") == "1" && ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "Sync peer %s disconnected: %v\n", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		if kind == syncFrameClip {
+			sm.applyRemote(string(payload), sc)
+		}
+	}
+}
+
+// handshake exchanges nonces, derives the connection keys and proves both
+// sides hold the shared token by exchanging MACs over their role and the
+// handshake. A peer can't pass by reflecting a MAC back, since it covers the
+// sender's role.
+func (sm *syncManager) handshake(conn net.Conn, role string) (*syncConn, *bufio.Reader, error) {
+	conn.SetDeadline(time.Now().Add(syncHandshakeLimit))
+	defer conn.SetDeadline(time.Time{})
+
+	localNonce := make([]byte, syncNonceSize)
+	if _, err := rand.Read(localNonce); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	if _, err := conn.Write(localNonce); err != nil {
+		return nil, nil, fmt.Errorf("failed to send nonce: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	remoteNonce := make([]byte, syncNonceSize)
+	if _, err := io.ReadFull(reader, remoteNonce); err != nil {
+		return nil, nil, fmt.Errorf("failed to read peer nonce: %v", err)
+	}
+
+	clientNonce, serverNonce := localNonce, remoteNonce
+	if role == syncRoleServer {
+		clientNonce, serverNonce = remoteNonce, localNonce
+	}
+	sc, err := newSyncConn(conn, sm.cfg.token, clientNonce, serverNonce, role)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := conn.Write(sc.authMAC(role)); err != nil {
+		return nil, nil, fmt.Errorf("failed to send auth MAC: %v", err)
+	}
+	remoteMAC := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(reader, remoteMAC); err != nil {
+		return nil, nil, fmt.Errorf("peer authentication failed: %v", err)
+	}
+	if !hmac.Equal(remoteMAC, sc.authMAC(sc.peerRole())) {
+		return nil, nil, fmt.Errorf("peer authentication failed (token mismatch?)")
+	}
+	return sc, reader, nil
+}
+
+// newSyncConn derives the keys of a connection from the shared token and the
+// handshake transcript: one auth key, and a separate frame key per direction,
+// so a frame can't be sent back to the peer that sealed it.
+func newSyncConn(conn net.Conn, token string, clientNonce, serverNonce []byte, role string) (*syncConn, error) {
+	transcript := sha256.New()
+	transcript.Write([]byte(syncAuthMagic))
+	transcript.Write(clientNonce)
+	transcript.Write(serverNonce)
+	salt := transcript.Sum(nil)
+
+	key := func(label string) ([]byte, error) {
+		return hkdf.Key(sha256.New, []byte(token), salt, syncAuthMagic+" "+label, 32)
+	}
+	aead := func(label string) (cipher.AEAD, error) {
+		k, err := key(label)
+		if err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	}
+
+	sc := &syncConn{conn: conn, role: role}
+	var err error
+	if sc.auth, err = key("auth"); err != nil {
+		return nil, fmt.Errorf("failed to derive sync keys: %v", err)
+	}
+	toServer, err := aead("client->server")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync cipher: %v", err)
+	}
+	toClient, err := aead("server->client")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync cipher: %v", err)
+	}
+	sc.send, sc.receive = toServer, toClient
+	if role == syncRoleServer {
+		sc.send, sc.receive = toClient, toServer
+	}
+	return sc, nil
+}
+
+func (sc *syncConn) peerRole() string {
+	if sc.role == syncRoleClient {
+		return syncRoleServer
+	}
+	return syncRoleClient
+}
+
+// authMAC is the handshake proof sent by the peer in role.
+func (sc *syncConn) authMAC(role string) []byte {
+	mac := hmac.New(sha256.New, sc.auth)
+	mac.Write([]byte(role))
+	return mac.Sum(nil)
+}
+
+// seqNonce turns a frame's sequence number into its GCM nonce.
+func seqNonce(aead cipher.AEAD, seq []byte) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce[len(nonce)-len(seq):], seq)
+	return nonce
+}
+
+// sealFrame encodes a frame as its length, sequence number and ciphertext.
+// The caller holds writeMu.
+func (sc *syncConn) sealFrame(kind byte, payload []byte) []byte {
+	frame := make([]byte, 4+syncSeqSize)
+	seq := frame[4:]
+	binary.BigEndian.PutUint64(seq, sc.sendSeq)
+	sc.sendSeq++
+	plaintext := append([]byte{kind}, payload...)
+	frame = sc.send.Seal(frame, seqNonce(sc.send, seq), plaintext, seq)
+	binary.BigEndian.PutUint32(frame, uint32(len(frame)-4))
+	return frame
+}
+
+func (sc *syncConn) writeFrame(kind byte, payload []byte) error {
+	sc.writeMu.Lock()
+	defer sc.writeMu.Unlock()
+	_, err := sc.conn.Write(sc.sealFrame(kind, payload))
+	return err
+}
+
+// readFrame reads the next frame, rejecting any whose sequence number is not
+// the one expected next, so frames can't be replayed or reordered.
+func (sc *syncConn) readFrame(reader *bufio.Reader) (byte, []byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header)
+	if size > syncMaxFrameSize || int(size) < syncSeqSize+sc.receive.Overhead()+1 {
+		return 0, nil, fmt.Errorf("invalid frame size %d", size)
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(reader, frame); err != nil {
+		return 0, nil, err
+	}
+	seq := frame[:syncSeqSize]
+	if got := binary.BigEndian.Uint64(seq); got != sc.recvSeq {
+		return 0, nil, fmt.Errorf("frame %d out of order, expected %d", got, sc.recvSeq)
+	}
+	plaintext, err := sc.receive.Open(nil, seqNonce(sc.receive, seq), frame[syncSeqSize:], seq)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to authenticate frame (token mismatch?)")
+	}
+	sc.recvSeq++
+	return plaintext[0], plaintext[1:], nil
+}
+
+// broadcast sends content to every connected peer except the one it came from.
+func (sm *syncManager) broadcast(content string, except *syncConn) {
+	sm.mu.Lock()
+	conns := make([]*syncConn, 0, len(sm.conns))
+	for sc := range sm.conns {
+		if sc != except {
+			conns = append(conns, sc)
+		}
+	}
+	sm.mu.Unlock()
+
+	for _, sc := range conns {
+		if err := sc.writeFrame(syncFrameClip, []byte(content)); err != nil {
+			if os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "Sync send to %s failed: %v\n", sc.conn.RemoteAddr(), err)
+			}
+			sc.conn.Close()
+		}
+	}
+}
+
+// applyRemote records content received from a peer. The local clipboard state
+// is updated first so the monitor doesn't echo the change back, then the
+// content is written to the local clipboard on a best-effort basis (headless
+// servers typically have no clipboard) and relayed to the remaining peers.
+func (sm *syncManager) applyRemote(content string, from *syncConn) {
+	if !sm.cs.updateClipboard(content) {
+		return
+	}
+	sm.remote.Store(content)
+
+	if err := sm.write(content); err != nil && os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "Sync could not write to local clipboard, serving synced content only: %v\n", err)
+	}
+
+	sm.broadcast(content, from)
+}
+
+// fallback substitutes the most recently synced content when the local
+// clipboard is unreadable or empty, e.g. on a headless dev server.
+func (sm *syncManager) fallback(content string, err error) (string, error) {
+	if err == nil && content != "" {
+		return content, nil
+	}
+	if _, ok := asOversize(err); ok {
+		// The local clipboard has content, it is just too large to return
+		return content, err
+	}
+	if remote, _ := sm.remote.Load().(string); remote != "" {
+		return remote, nil
+	}
+	return content, err
+}
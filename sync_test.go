@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tcpPair returns both ends of a loopback TCP connection.
+func tcpPair(t *testing.T) (net.Conn, net.Conn) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := ln.Accept()
+		accepted <- conn
+	}()
+
+	dialed, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	return <-accepted, dialed
+}
+
+func newTestSyncManager(token string) (*syncManager, chan string) {
+	written := make(chan string, 1)
+	sm := newSyncManager(syncConfig{token: token}, NewClipboardServer())
+	sm.write = func(content string) error {
+		written <- content
+		return nil
+	}
+	return sm, written
+}
+
+// Test that authenticated peers mirror clipboard content
+func TestSyncMirrorsContent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, _ := newTestSyncManager("secret")
+	client, written := newTestSyncManager("secret")
+
+	a, b := tcpPair(t)
+	go server.serveConn(ctx, a, syncRoleServer)
+	go client.serveConn(ctx, b, syncRoleClient)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		server.mu.Lock()
+		connected := len(server.conns) == 1
+		server.mu.Unlock()
+		if connected {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Peers never completed handshake")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	server.broadcast("copied on laptop", nil)
+
+	select {
+	case got := <-written:
+		if got != "copied on laptop" {
+			t.Errorf("Expected 'copied on laptop', got '%s'", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for synced content")
+	}
+
+	if content, _ := client.fallback("", nil); content != "copied on laptop" {
+		t.Errorf("Expected fallback to synced content, got '%s'", content)
+	}
+}
+
+// Test that peers with different tokens are rejected
+func TestSyncRejectsWrongToken(t *testing.T) {
+	server, _ := newTestSyncManager("secret")
+	client, _ := newTestSyncManager("wrong")
+
+	a, b := tcpPair(t)
+	errs := make(chan error, 2)
+	go func() {
+		_, _, err := server.handshake(a, syncRoleServer)
+		a.Close()
+		errs <- err
+	}()
+	go func() {
+		_, _, err := client.handshake(b, syncRoleClient)
+		b.Close()
+		errs <- err
+	}()
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err == nil {
+			t.Error("Expected handshake to fail with mismatched tokens")
+		}
+	}
+}
+
+// Test that a peer's own nonce and auth MAC reflected back are rejected
+func TestSyncRejectsReflectedHandshake(t *testing.T) {
+	server, _ := newTestSyncManager("secret")
+	a, b := tcpPair(t)
+	defer a.Close()
+	defer b.Close()
+	go io.Copy(b, b) // the "peer" echoes everything
+
+	if _, _, err := server.handshake(a, syncRoleServer); err == nil {
+		t.Error("Expected a reflected handshake to fail")
+	}
+}
+
+// Test that frames can't be replayed, reordered or sent back to their sender
+func TestSyncFrameSequence(t *testing.T) {
+	clientNonce, serverNonce := bytes.Repeat([]byte{1}, syncNonceSize), bytes.Repeat([]byte{2}, syncNonceSize)
+	client, err := newSyncConn(nil, "secret", clientNonce, serverNonce, syncRoleClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, _ := newSyncConn(nil, "secret", clientNonce, serverNonce, syncRoleServer)
+
+	first := client.sealFrame(syncFrameClip, []byte("one"))
+	second := client.sealFrame(syncFrameClip, []byte("two"))
+	read := func(sc *syncConn, frames ...[]byte) (string, error) {
+		var payload []byte
+		var err error
+		reader := bufio.NewReader(bytes.NewReader(bytes.Join(frames, nil)))
+		for range frames {
+			if _, payload, err = sc.readFrame(reader); err != nil {
+				return "", err
+			}
+		}
+		return string(payload), nil
+	}
+
+	if got, err := read(server, first, second); err != nil || got != "two" {
+		t.Fatalf("Expected frames in order to be accepted, got %q: %v", got, err)
+	}
+	if _, err := read(server, first); err == nil || !strings.Contains(err.Error(), "out of order") {
+		t.Errorf("Expected a replayed frame to be rejected, got %v", err)
+	}
+
+	server.recvSeq = 0
+	if _, err := read(server, second); err == nil {
+		t.Error("Expected a reordered frame to be rejected")
+	}
+	reflected := server.sealFrame(syncFrameClip, []byte("back"))
+	server.recvSeq = 0
+	if _, err := read(server, reflected); err == nil {
+		t.Error("Expected a frame sent back to its sender to be rejected")
+	}
+}
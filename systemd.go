@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	systemdServiceName = "mcp-clip.service"
+	systemdSocketName  = "mcp-clip.socket"
+
+	// DefaultSystemdAddr is a socket in the user's runtime directory; %t is
+	// expanded by systemd.
+	DefaultSystemdAddr = unixAddrPrefix + "%t/mcp-clip.sock"
+)
+
+// systemdUserDir returns where systemd looks for the user's own units.
+func systemdUserDir() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "systemd", "user"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the systemd user unit directory: %v", err)
+	}
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+// systemdServiceUnit runs the server over HTTP at addr, restarting it when it
+// fails. With socket activation the socket unit owns the listener and the
+// server adopts it; otherwise the service starts with the session.
+func systemdServiceUnit(exe, addr string, socket bool) string {
+	var unit strings.Builder
+	unit.WriteString("[Unit]\n")
+	unit.WriteString("Description=MCP clipboard server\n")
+	unit.WriteString("Documentation=https://github.com/standardbeagle/mcp-clip\n")
+	if socket {
+		fmt.Fprintf(&unit, "Requires=%s\nAfter=%s\n", systemdSocketName, systemdSocketName)
+	}
+	unit.WriteString("\n[Service]\n")
+	fmt.Fprintf(&unit, "ExecStart=%s --http %s\n", systemdQuote(exe), systemdQuote(addr))
+	unit.WriteString("Restart=on-failure\n")
+	unit.WriteString("RestartSec=2\n")
+	if !socket {
+		unit.WriteString("\n[Install]\nWantedBy=default.target\n")
+	}
+	return unit.String()
+}
+
+// systemdSocketUnit listens at addr on the server's behalf and starts it on
+// the first connection.
+func systemdSocketUnit(addr string) string {
+	var unit strings.Builder
+	unit.WriteString("[Unit]\n")
+	unit.WriteString("Description=MCP clipboard server socket\n")
+	unit.WriteString("\n[Socket]\n")
+	fmt.Fprintf(&unit, "ListenStream=%s\n", strings.TrimPrefix(addr, unixAddrPrefix))
+	if strings.HasPrefix(addr, unixAddrPrefix) {
+		unit.WriteString("SocketMode=0600\n")
+	}
+	unit.WriteString("\n[Install]\nWantedBy=sockets.target\n")
+	return unit.String()
+}
+
+// systemdQuote quotes a unit file argument that contains whitespace, quotes
+// or backslashes.
+func systemdQuote(arg string) string {
+	if !strings.ContainsAny(arg, " \t\"'\\") {
+		return arg
+	}
+	arg = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg)
+	return `"` + arg + `"`
+}
+
+// installSystemdUser writes the user units and, unless disabled, enables and
+// starts them with systemctl --user.
+func installSystemdUser(opts installOptions, out io.Writer) error {
+	exe, err := installedExecutable()
+	if err != nil {
+		return err
+	}
+	dir, err := systemdUserDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", dir, err)
+	}
+	addr := opts.httpAddr
+	if addr == "" {
+		addr = DefaultSystemdAddr
+	}
+
+	units := map[string]string{systemdServiceName: systemdServiceUnit(strings.ReplaceAll(exe, "%", "%%"), addr, opts.socket)}
+	enable := systemdServiceName
+	if opts.socket {
+		units[systemdSocketName] = systemdSocketUnit(addr)
+		enable = systemdSocketName
+	} else if err := os.Remove(filepath.Join(dir, systemdSocketName)); err == nil {
+		fmt.Fprintf(out, "Removed %s from an earlier socket-activated install\n", systemdSocketName)
+	}
+	for name, unit := range units {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(unit), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+		fmt.Fprintf(out, "Wrote %s\n", path)
+	}
+
+	if opts.noEnable {
+		fmt.Fprintf(out, "Enable it with: systemctl --user daemon-reload && systemctl --user enable --now %s\n", enable)
+		return nil
+	}
+	for _, args := range [][]string{{"daemon-reload"}, {"enable", "--now", enable}} {
+		cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("systemctl --user %s failed: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+		}
+	}
+	fmt.Fprintf(out, "Enabled and started %s\n", enable)
+	return nil
+}
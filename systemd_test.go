@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test the generated service and socket units
+func TestSystemdUnits(t *testing.T) {
+	service := systemdServiceUnit("/opt/my tools/mcp-clip", DefaultSystemdAddr, false)
+	for _, want := range []string{
+		`ExecStart="/opt/my tools/mcp-clip" --http unix:%t/mcp-clip.sock`,
+		"Restart=on-failure",
+		"WantedBy=default.target",
+	} {
+		if !strings.Contains(service, want) {
+			t.Errorf("Expected the service unit to contain %q, got:\n%s", want, service)
+		}
+	}
+
+	service = systemdServiceUnit("/usr/bin/mcp-clip", "127.0.0.1:8765", true)
+	if !strings.Contains(service, "Requires=mcp-clip.socket") || strings.Contains(service, "[Install]") {
+		t.Errorf("Expected a socket-activated service without an install section, got:\n%s", service)
+	}
+	socket := systemdSocketUnit("127.0.0.1:8765")
+	if !strings.Contains(socket, "ListenStream=127.0.0.1:8765\n") || strings.Contains(socket, "SocketMode") {
+		t.Errorf("Unexpected TCP socket unit:\n%s", socket)
+	}
+	socket = systemdSocketUnit(DefaultSystemdAddr)
+	if !strings.Contains(socket, "ListenStream=%t/mcp-clip.sock\n") || !strings.Contains(socket, "SocketMode=0600") {
+		t.Errorf("Unexpected Unix socket unit:\n%s", socket)
+	}
+}
+
+// Test that --no-enable writes the units into the user unit directory and
+// switching away from socket activation removes the socket unit
+func TestInstallSystemdUserNoEnable(t *testing.T) {
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	dir := filepath.Join(config, "systemd", "user")
+
+	var out bytes.Buffer
+	if err := installSystemdUser(installOptions{systemdUser: true, socket: true, noEnable: true}, &out); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	for _, name := range []string{systemdServiceName, systemdSocketName} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+	if !strings.Contains(out.String(), "enable --now mcp-clip.socket") {
+		t.Errorf("Expected instructions to enable the socket, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := installSystemdUser(installOptions{systemdUser: true, noEnable: true}, &out); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, systemdSocketName)); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket unit to be removed, got %v", err)
+	}
+}
+
+// Test that install requires exactly one target
+func TestParseInstallOptions(t *testing.T) {
+	for _, args := range [][]string{nil, {"--systemd-user", "--launchd"}, {"--launchd", "--socket"}} {
+		if _, err := parseInstallOptions(args); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+	opts, err := parseInstallOptions([]string{"--systemd-user", "--socket", "--http", "127.0.0.1:9000"})
+	if err != nil || !opts.systemdUser || !opts.socket || opts.httpAddr != "127.0.0.1:9000" {
+		t.Errorf("Unexpected options %+v: %v", opts, err)
+	}
+}
+
+// Test that processes not started by systemd don't adopt a socket
+func TestActivatedListenerNotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+	if listener, err := activatedListener(); listener != nil || err != nil {
+		t.Errorf("Expected no listener for another process, got %v %v", listener, err)
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// tableDelimiters are tried in order; the first that splits every row into
+// the same number of columns wins.
+var tableDelimiters = []struct {
+	delimiter rune
+	name      string
+	mimeType  string
+}{
+	{'\t', "tab", "text/tab-separated-values"},
+	{',', "comma", "text/csv"},
+	{';', "semicolon", "text/csv"},
+}
+
+// clipboardTable is tabular clipboard content: text parsed as CSV or TSV, or
+// the HTML table spreadsheets put on the clipboard. The first record is taken
+// as the header; all records have the same number of fields.
+type clipboardTable struct {
+	delimiter string // "" for HTML tables
+	mimeType  string // of the content the table was read from
+	records   [][]string
+}
+
+// readClipboardTable returns the table on the clipboard. Spreadsheets publish
+// an HTML table alongside the tab-separated text, which keeps cells holding
+// tabs or line breaks intact, so that is preferred over parsing text.
+func readClipboardTable(ctx context.Context, text string) *clipboardTable {
+	source, err := readClipboardHTML(ctx)
+	if err != nil && os.Getenv("MCP_DEBUG") == "1" {
+		fmt.Fprintf(os.Stderr, "HTML clipboard unavailable, parsing text as a table: %v\n", err)
+	}
+	if table := htmlTable(source); table != nil {
+		return table
+	}
+	return parseDelimited(text)
+}
+
+// htmlTable extracts the first table of an HTML document, spreading cells
+// that span columns over empty cells. It returns nil unless the table has
+// more than one cell.
+func htmlTable(source string) *clipboardTable {
+	if !strings.Contains(strings.ToLower(source), "<table") {
+		return nil
+	}
+	doc, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return nil
+	}
+	var find func(*html.Node) *html.Node
+	find = func(n *html.Node) *html.Node {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Table {
+			return n
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if table := find(child); table != nil {
+				return table
+			}
+		}
+		return nil
+	}
+	table := find(doc)
+	if table == nil {
+		return nil
+	}
+
+	var records [][]string
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			switch {
+			case child.Type != html.ElementNode, child.DataAtom == atom.Table:
+				// Nested tables belong to a cell
+			case child.DataAtom == atom.Tr:
+				var record []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type != html.ElementNode || (cell.DataAtom != atom.Td && cell.DataAtom != atom.Th) {
+						continue
+					}
+					record = append(record, strings.TrimSpace(whitespacePattern.ReplaceAllString(textContent(cell), " ")))
+					if span, err := strconv.Atoi(attr(cell, "colspan")); err == nil && span > 1 {
+						record = append(record, make([]string, min(span, 1000)-1)...)
+					}
+				}
+				records = append(records, record)
+			default:
+				walk(child)
+			}
+		}
+	}
+	walk(table)
+
+	columns := 0
+	for _, record := range records {
+		columns = max(columns, len(record))
+	}
+	if columns == 0 || len(records)*columns < 2 {
+		return nil
+	}
+	for i := range records {
+		for len(records[i]) < columns {
+			records[i] = append(records[i], "")
+		}
+	}
+	return &clipboardTable{mimeType: "text/html", records: records}
+}
+
+// parseDelimited parses text as delimiter-separated values. It returns nil
+// unless the text has at least two rows of at least two columns each, all
+// rows having the same number of columns.
+func parseDelimited(text string) *clipboardTable {
+	for _, candidate := range tableDelimiters {
+		if !strings.ContainsRune(text, candidate.delimiter) {
+			continue
+		}
+		reader := csv.NewReader(strings.NewReader(text))
+		reader.Comma = candidate.delimiter
+		reader.LazyQuotes = true
+		reader.TrimLeadingSpace = candidate.delimiter != '\t'
+		records, err := reader.ReadAll()
+		if err != nil || len(records) < 2 || len(records[0]) < 2 {
+			continue
+		}
+		return &clipboardTable{delimiter: candidate.name, mimeType: candidate.mimeType, records: records}
+	}
+	return nil
+}
+
+// detectDelimited reports the MIME type of CSV or TSV text, judging by its
+// head so large content isn't parsed in full.
+func detectDelimited(text string) (string, bool) {
+	if len(text) > languageScanLimit {
+		text = text[:languageScanLimit]
+		if cut := strings.LastIndexByte(text, '\n'); cut > 0 {
+			text = text[:cut]
+		}
+	}
+	if table := parseDelimited(text); table != nil {
+		return table.mimeType, true
+	}
+	return "", false
+}
+
+// markdown renders the table as a Markdown table with padded columns.
+// Columns holding only numbers are right-aligned.
+func (t *clipboardTable) markdown() string {
+	columns := len(t.records[0])
+	widths := make([]int, columns)
+	numbers := make([]int, columns)
+	texts := make([]int, columns)
+	cells := make([][]string, len(t.records))
+	for r, record := range t.records {
+		cells[r] = make([]string, columns)
+		for c, field := range record {
+			cell := strings.ReplaceAll(strings.ReplaceAll(strings.TrimSpace(field), "|", `\|`), "\n", "<br>")
+			cells[r][c] = strings.ReplaceAll(cell, "\r", "")
+			widths[c] = max(widths[c], 3, utf8.RuneCountInString(cells[r][c]))
+			if r > 0 && cells[r][c] != "" {
+				if _, err := strconv.ParseFloat(strings.ReplaceAll(cells[r][c], ",", ""), 64); err == nil {
+					numbers[c]++
+				} else {
+					texts[c]++
+				}
+			}
+		}
+	}
+	numeric := make([]bool, columns)
+	for c := range numeric {
+		numeric[c] = numbers[c] > 0 && texts[c] == 0
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		b.WriteString("|")
+		for c, cell := range row {
+			padding := strings.Repeat(" ", widths[c]-utf8.RuneCountInString(cell))
+			if numeric[c] {
+				b.WriteString(" " + padding + cell + " |")
+			} else {
+				b.WriteString(" " + cell + padding + " |")
+			}
+		}
+		b.WriteString("\n")
+	}
+	writeRow(cells[0])
+	b.WriteString("|")
+	for c, width := range widths {
+		if numeric[c] {
+			b.WriteString(" " + strings.Repeat("-", width-1) + ": |")
+		} else {
+			b.WriteString(" " + strings.Repeat("-", width) + " |")
+		}
+	}
+	b.WriteString("\n")
+	for _, row := range cells[1:] {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// json renders the table as a JSON array with an object per data row, keyed
+// by the header in column order. Cells stay strings, as the spreadsheet
+// displayed them. Empty or repeated header cells are named "column N".
+func (t *clipboardTable) json() string {
+	keys := make([]string, len(t.records[0]))
+	seen := map[string]bool{}
+	for c, cell := range t.records[0] {
+		key := strings.TrimSpace(cell)
+		if key == "" || seen[key] {
+			key = fmt.Sprintf("column %d", c+1)
+		}
+		seen[key] = true
+		keys[c] = key
+	}
+
+	var b strings.Builder
+	b.WriteString("[")
+	for r, record := range t.records[1:] {
+		if r > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n" + jsonIndent + "{")
+		for c, cell := range record {
+			if c > 0 {
+				b.WriteString(", ")
+			}
+			key, _ := json.Marshal(keys[c])
+			value, _ := json.Marshal(cell)
+			b.WriteString(string(key) + ": " + string(value))
+		}
+		b.WriteString("}")
+	}
+	if len(t.records) > 1 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// render renders the table as a Markdown table or as JSON rows.
+func (t *clipboardTable) render(as string) string {
+	if as == "json" {
+		return t.json()
+	}
+	return t.markdown()
+}
+
+// annotateTable describes a rendered table in a result's metadata. rows
+// counts the data rows, not the header.
+func annotateTable(result *mcp.CallToolResult, table *clipboardTable, as string) {
+	meta := resultMeta(result)
+	meta["mimeType"], meta["sourceMimeType"] = "text/markdown", table.mimeType
+	if as == "json" {
+		meta["mimeType"] = "application/json"
+	}
+	info := map[string]any{"rows": len(table.records) - 1, "columns": len(table.records[0])}
+	if table.delimiter != "" {
+		info["delimiter"] = table.delimiter
+	}
+	meta["table"] = info
+}
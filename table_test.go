@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test detecting CSV and TSV text
+func TestParseDelimited(t *testing.T) {
+	tests := []struct {
+		text      string
+		delimiter string
+		rows      int
+	}{
+		{"name\tqty\nfoo\t1\nbar\t2\n", "tab", 3},
+		{"name,qty\n\"foo, inc\",1\n", "comma", 2},
+		{"a;b;c\n1;2;3", "semicolon", 2},
+		{"name\tnote\nfoo\tsays \"hi\", twice\n", "tab", 2},
+	}
+	for _, tt := range tests {
+		table := parseDelimited(tt.text)
+		if table == nil || table.delimiter != tt.delimiter || len(table.records) != tt.rows {
+			t.Errorf("%q: expected %d rows delimited by %s, got %+v", tt.text, tt.rows, tt.delimiter, table)
+		}
+	}
+
+	for _, text := range []string{
+		"just some text",
+		"one,row,only",
+		"a,b\nc,d,e",
+		"first\nsecond",
+	} {
+		if table := parseDelimited(text); table != nil {
+			t.Errorf("%q: expected no table, got %+v", text, table)
+		}
+	}
+}
+
+// Test rendering a table as aligned Markdown
+func TestDelimitedTableMarkdown(t *testing.T) {
+	table := parseDelimited("item\tprice\tnote\nwidget\t1.50\tsmall | cheap\ngadget\t12\t\n")
+	want := "" +
+		"| item   | price | note           |\n" +
+		"| ------ | ----: | -------------- |\n" +
+		"| widget |  1.50 | small \\| cheap |\n" +
+		"| gadget |    12 |                |"
+	if got := table.markdown(); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+// Test read_clipboard's table format
+func TestReadClipboardTable(t *testing.T) {
+	fakeClipboard(t, "city,population\nOslo,709037\nBergen,289330")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"format": "table"}
+	result, err := NewClipboardServer().readClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	want := "| city   | population |\n| ------ | ---------: |\n| Oslo   |     709037 |\n| Bergen |     289330 |"
+	if text := result.Content[0].(mcp.TextContent).Text; text != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, text)
+	}
+	meta := result.Meta.AdditionalFields
+	info := meta["table"].(map[string]any)
+	if info["rows"] != 2 || info["columns"] != 2 || info["delimiter"] != "comma" || meta["sourceMimeType"] != "text/csv" {
+		t.Errorf("Unexpected table metadata: %v", meta)
+	}
+
+	request.Params.Arguments = map[string]any{"format": "text"}
+	result, _ = NewClipboardServer().readClipboardHandler(context.Background(), request)
+	if result.Meta.AdditionalFields["delimited"] != "text/csv" {
+		t.Errorf("Expected the CSV to be detected, got %v", result.Meta.AdditionalFields)
+	}
+
+	fakeClipboard(t, "not a table")
+	request.Params.Arguments = map[string]any{"format": "table"}
+	result, _ = NewClipboardServer().readClipboardHandler(context.Background(), request)
+	if result.IsError || result.Content[0].(mcp.TextContent).Text != "not a table" {
+		t.Errorf("Expected plain text back, got %v", result.Content)
+	}
+}
+
+// Test extracting the table from spreadsheet HTML
+func TestHTMLTable(t *testing.T) {
+	excel := `<html><body><!--StartFragment--><table><col width=64><tr><td>Region</td><td colspan=2>Sales</td></tr>` +
+		`<tr><td>North</td><td>1,200</td><td class=xl65>Q1<br>final</td></tr>` +
+		`<tr><td>South <table><tr><td>nested</td></tr></table></td><td>900</td></tr></table><!--EndFragment--></body></html>`
+	table := htmlTable(excel)
+	if table == nil {
+		t.Fatal("Expected a table")
+	}
+	want := [][]string{{"Region", "Sales", ""}, {"North", "1,200", "Q1 final"}, {"South nested", "900", ""}}
+	for r, record := range table.records {
+		for c, cell := range record {
+			if cell != want[r][c] {
+				t.Errorf("Cell %d,%d: expected %q, got %q", r, c, want[r][c], cell)
+			}
+		}
+	}
+	if len(table.records) != len(want) || table.mimeType != "text/html" {
+		t.Errorf("Unexpected table: %+v", table)
+	}
+
+	for _, source := range []string{"", "<p>no table</p>", "<table><tr><td>one cell</td></tr></table>"} {
+		if table := htmlTable(source); table != nil {
+			t.Errorf("%q: expected no table, got %+v", source, table)
+		}
+	}
+}
+
+// Test rendering a table as JSON rows
+func TestClipboardTableJSON(t *testing.T) {
+	table := parseDelimited("name,,name\nfoo,\"say \"\"hi\"\"\",1")
+	want := "[\n  {\"name\": \"foo\", \"column 2\": \"say \\\"hi\\\"\", \"column 3\": \"1\"}\n]"
+	got := table.json()
+	if got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+	var rows []map[string]string
+	if err := json.Unmarshal([]byte(got), &rows); err != nil || len(rows) != 1 {
+		t.Errorf("Expected valid JSON rows, got %v, %v", rows, err)
+	}
+}
+
+// Test that read_clipboard's table format prefers a spreadsheet's HTML table
+func TestReadClipboardSpreadsheetTable(t *testing.T) {
+	fakeClipboard(t, "item\tnote\nwidget\t\"two\nlines\"")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncase \"$*\" in\n*text/html*) printf '<table><tr><th>item</th><th>note</th></tr><tr><td>widget</td><td>two<br>lines</td></tr></table>' ;;\n*) exit 1 ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"format": "table", "table_as": "json"}
+	result, err := NewClipboardServer().readClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "[\n  {\"item\": \"widget\", \"note\": \"two lines\"}\n]" {
+		t.Errorf("Unexpected JSON rows: %s", text)
+	}
+	meta := result.Meta.AdditionalFields
+	if meta["mimeType"] != "application/json" || meta["sourceMimeType"] != "text/html" || meta["table"].(map[string]any)["rows"] != 1 {
+		t.Errorf("Unexpected metadata: %v", meta)
+	}
+
+	request.Params.Arguments = map[string]any{"format": "table", "table_as": "xml"}
+	if result, _ = NewClipboardServer().readClipboardHandler(context.Background(), request); !result.IsError {
+		t.Errorf("Expected an unknown table_as to be rejected")
+	}
+}
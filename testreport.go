@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// testReport is the machine-readable result of `mcp-clip test --json`, for
+// setup scripts and CI that verify clipboard access. Content is described
+// but never included.
+type testReport struct {
+	OK          bool              `json:"ok"`
+	Backend     string            `json:"backend"`
+	WSL         bool              `json:"wsl"`
+	WSLReason   string            `json:"wslReason"`
+	Utilities   string            `json:"utilities,omitempty"`
+	PowerShell  string            `json:"powershell,omitempty"`
+	Experiments []string          `json:"experiments,omitempty"`
+	Read        testReadReport    `json:"read"`
+	Cleanup     testCleanupReport `json:"cleanup"`
+	DurationMs  float64           `json:"durationMs"`
+}
+
+type testReadReport struct {
+	OK         bool    `json:"ok"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+	Empty      bool    `json:"empty"`
+	Type       string  `json:"type,omitempty"` // "text", "image" or "binary"
+	MIMEType   string  `json:"mimeType,omitempty"`
+	Size       int     `json:"size"`
+	SHA256     string  `json:"sha256,omitempty"`
+}
+
+type testCleanupReport struct {
+	OK         bool    `json:"ok"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+	Removed    int     `json:"removed"`
+	Bytes      int64   `json:"bytes"`
+	Errors     int     `json:"errors"`
+}
+
+// runTestReport reads the clipboard and removes expired temp files, timing
+// both, as the test subcommand does.
+func runTestReport(ctx context.Context) testReport {
+	start := time.Now()
+	backend := selectBackend()
+	wsl := detectWSL()
+	report := testReport{Backend: backend.Name(), WSL: wsl.detected, WSLReason: wsl.reason, Experiments: enabledExperiments()}
+	if _, ok := backend.(nativeBackend); ok && usesLinuxUtilities() {
+		report.Utilities = utilityNames(getLinuxUtilityChain())
+	}
+	if _, ok := backend.(wsl2Backend); ok {
+		report.PowerShell = findPowerShell()
+	}
+
+	readStart := time.Now()
+	content, err := readClipboard(ctx)
+	report.Read.DurationMs = milliseconds(time.Since(readStart))
+	if err != nil {
+		report.Read.Error = err.Error()
+	} else {
+		report.Read.OK = true
+		report.Read.Empty = content == ""
+		report.Read.Size = len(content)
+		if content != "" {
+			report.Read.Type, _ = classifyContent(content)
+			report.Read.MIMEType = contentMIMEType(content)
+			report.Read.SHA256 = contentHash(content)
+		}
+	}
+
+	cleanupStart := time.Now()
+	cutoffTime := time.Now().Add(-getCleanupTTL())
+	cleanup, err := removeTempFiles(func(filePath string) bool { return shouldRemoveFile(filePath, cutoffTime) })
+	report.Cleanup = testCleanupReport{
+		OK:         err == nil && cleanup.errors == 0,
+		DurationMs: milliseconds(time.Since(cleanupStart)),
+		Removed:    cleanup.removed,
+		Bytes:      cleanup.bytes,
+		Errors:     cleanup.errors,
+	}
+	if err != nil {
+		report.Cleanup.Error = err.Error()
+	}
+
+	report.OK = report.Read.OK && report.Cleanup.OK
+	report.DurationMs = milliseconds(time.Since(start))
+	return report
+}
+
+// printTestReport writes the report as JSON to stdout and exits with status
+// 1 if any check failed.
+func printTestReport() {
+	report := runTestReport(context.Background())
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+	if !report.OK {
+		os.Exit(1)
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test that the report describes the clipboard without its content and
+// counts removed temp files
+func TestRunTestReport(t *testing.T) {
+	fakeClipboard(t, "secret text")
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	expired := filepath.Join(dir, FilenamePrefix+"1000-abc.txt")
+	os.WriteFile(expired, []byte("old"), 0600)
+
+	report := runTestReport(context.Background())
+	if !report.OK || report.Backend != "native" || report.Utilities != "xsel" {
+		t.Fatalf("Expected a passing native report, got %+v", report)
+	}
+	if report.Read.Type != "text" || report.Read.Size != len("secret text") || report.Read.SHA256 != contentHash("secret text") {
+		t.Errorf("Unexpected read report: %+v", report.Read)
+	}
+	if report.Cleanup.Removed != 1 || report.Cleanup.Bytes != 3 {
+		t.Errorf("Expected the expired file to be removed, got %+v", report.Cleanup)
+	}
+
+	encoded, _ := json.Marshal(report)
+	if strings.Contains(string(encoded), "secret") {
+		t.Errorf("Expected no clipboard content in the report, got %s", encoded)
+	}
+}
+
+// Test that a failed read fails the report
+func TestRunTestReportReadFailure(t *testing.T) {
+	fakeClipboard(t, "")
+	t.Setenv("MCP_CLIP_LINUX_UTILITIES", "xclip")
+	t.Setenv("PATH", t.TempDir())
+
+	report := runTestReport(context.Background())
+	if report.OK || report.Read.OK || report.Read.Error == "" {
+		t.Errorf("Expected the missing utility to fail the report, got %+v", report)
+	}
+	if report.Read.DurationMs < 0 || report.DurationMs > float64(time.Minute.Milliseconds()) {
+		t.Errorf("Unexpected timings: %+v", report)
+	}
+}
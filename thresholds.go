@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	DefaultMaxInlineText   = 25000
+	DefaultMaxInlineBase64 = 25000
+	DefaultMaxInlineImage  = 1 << 20 // 1MB, returned as MCP image content
+	DefaultMaxEmbedded     = 8 << 20 // 8MB, base64 embedded as a blob resource
+)
+
+// inlineThresholds are the largest sizes, in bytes, returned directly in a
+// tool result; anything larger is saved to a temp file instead.
+type inlineThresholds struct {
+	text   int // raw text
+	base64 int // base64-encoded binary data
+	image  int // raw image bytes, inlined as ImageContent
+
+	// embedded is the largest base64 payload of a saved file that is also
+	// embedded in the result; larger files are linked as resources instead.
+	embedded int
+}
+
+func getInlineThresholds() inlineThresholds {
+	return inlineThresholds{
+		text:   getSizeEnv("MCP_CLIP_MAX_INLINE_TEXT", DefaultMaxInlineText),
+		base64: getSizeEnv("MCP_CLIP_MAX_INLINE_BASE64", DefaultMaxInlineBase64),
+		image:  getSizeEnv("MCP_CLIP_MAX_INLINE_IMAGE", DefaultMaxInlineImage),
+
+		embedded: getSizeEnv("MCP_CLIP_MAX_EMBEDDED", DefaultMaxEmbedded),
+	}
+}
+
+// getSizeEnv reads a non-negative byte count from the environment.
+func getSizeEnv(name string, defaultValue int) int {
+	if sizeStr := os.Getenv(name); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size >= 0 {
+			return size
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Invalid %s '%s', using default: %d\n", name, sizeStr, defaultValue)
+		}
+	}
+	return defaultValue
+}
+
+func imageMIMEType(imageType string) string {
+	if imageType == "jpg" {
+		return "image/jpeg"
+	}
+	return "image/" + imageType
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+// Test per-format inline thresholds from the environment
+func TestGetInlineThresholds(t *testing.T) {
+	t.Setenv("MCP_CLIP_MAX_INLINE_TEXT", "100")
+	t.Setenv("MCP_CLIP_MAX_INLINE_BASE64", "invalid")
+	t.Setenv("MCP_CLIP_MAX_INLINE_IMAGE", "0")
+
+	limits := getInlineThresholds()
+	if limits.text != 100 {
+		t.Errorf("Expected text threshold 100, got %d", limits.text)
+	}
+	if limits.base64 != DefaultMaxInlineBase64 {
+		t.Errorf("Expected default base64 threshold for invalid value, got %d", limits.base64)
+	}
+	if limits.image != 0 {
+		t.Errorf("Expected image threshold 0, got %d", limits.image)
+	}
+}
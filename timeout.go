@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultReadTimeout bounds each clipboard utility call. PowerShell can take
+// a few seconds to start under WSL2, so this leaves plenty of headroom.
+const DefaultReadTimeout = 10 * time.Second
+
+// getReadTimeout returns how long a clipboard read (or write) may take before
+// the utility serving it is killed.
+func getReadTimeout() time.Duration {
+	if timeoutStr := os.Getenv("MCP_CLIP_READ_TIMEOUT"); timeoutStr != "" {
+		if timeout, err := time.ParseDuration(timeoutStr); err == nil && timeout > 0 {
+			return timeout
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Invalid MCP_CLIP_READ_TIMEOUT format '%s', using default: %v\n", timeoutStr, DefaultReadTimeout)
+		}
+	}
+	return DefaultReadTimeout
+}
+
+// withReadTimeout bounds a clipboard call made on behalf of ctx.
+func withReadTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, getReadTimeout())
+}
+
+// timeoutError replaces the "signal: killed" of a utility that ran out of time.
+func timeoutError(ctx context.Context, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("clipboard utility timed out after %v", getReadTimeout())
+	}
+	return err
+}
+
+// runWithContext runs fn, which cannot be interrupted, but stops waiting for
+// it once ctx is done. fn keeps running in the background until it returns.
+func runWithContext[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	type outcome struct {
+		value T
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := fn()
+		done <- outcome{value, err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.value, result.err
+	case <-ctx.Done():
+		var zero T
+		return zero, timeoutError(ctx, ctx.Err())
+	}
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerTools adds the clipboard tools to the MCP server, leaving out or
+// describing as limited what the clipboard backend can't do.
+func (cs *ClipboardServer) registerTools(s *server.MCPServer) {
+	caps := capabilitiesOf(selectBackend())
+
+	readDescription := "Read the current clipboard content, supporting text and images"
+	formatDescription := "Format to return clipboard content in: 'text', 'base64', 'markdown' (HTML copied from web pages converted to Markdown), 'table' (cells copied from a spreadsheet, or CSV/TSV text, as an aligned Markdown table), or 'auto' (default)"
+	if !caps.readImages {
+		readDescription = "Read the current clipboard text" + caps.limitation("can only read text")
+	}
+	if !caps.flavors {
+		formatDescription = "Format to return clipboard content in: 'text', 'base64', 'table' (CSV or TSV as an aligned Markdown table) or 'auto' (default)"
+	}
+	pipelineDescription := "Clean up text before it is returned (and before start_line, end_line and extract apply) with a named pipeline or a single step. Steps: " + strings.Join(transformStepNames(), ", ")
+	if names := cs.pipelines.names(); len(names) > 0 {
+		pipelineDescription += ". Configured pipelines: " + strings.Join(names, ", ")
+	}
+	readClipboardTool := mcp.NewTool("read_clipboard",
+		mcp.WithDescription(readDescription),
+		withSchemaVersion(),
+		mcp.WithString("format",
+			mcp.Description(formatDescription),
+		),
+		mcp.WithString("table_as",
+			mcp.Description("With format 'table': 'markdown' (default) or 'json' for an array with an object per row, keyed by the header row"),
+		),
+		mcp.WithNumber("since_length",
+			mcp.Description("Delta read: byte length of previously read text. Only text appended after this offset is returned when the clipboard still starts with the previous content. Use 0 on the first read to obtain the content hash."),
+		),
+		mcp.WithString("since_hash",
+			mcp.Description("Delta read: sha256 reported by the previous read, covering the first since_length bytes"),
+		),
+		mcp.WithBoolean("normalize",
+			mcp.Description("Strip byte order marks and apply Unicode NFC normalization to text (default true). Set false to get the exact code points."),
+		),
+		mcp.WithNumber("start_line",
+			mcp.Description("Only return text from this line on (1-based), e.g. to pull part of a large copied log"),
+		),
+		mcp.WithNumber("end_line",
+			mcp.Description("Only return text up to and including this line (default: the last line)"),
+		),
+		mcp.WithString("extract",
+			mcp.Description("Regular expression (RE2 syntax); only the matching substrings are returned, one per line, e.g. https?://\\S+ for all URLs. Use (?s) to let . span lines and (?m) for ^ and $ per line."),
+		),
+		mcp.WithString("pipeline",
+			mcp.Description(pipelineDescription),
+		),
+		mcp.WithNumber("extract_group",
+			mcp.Description("With extract, return this capture group of each match instead of the whole match (default 0)"),
+		),
+		mcp.WithBoolean("preview",
+			mcp.Description("Only return the head of the text plus the total size, MIME type and sha256, never saving to a temp file, for a quick look at what's on the clipboard"),
+		),
+		mcp.WithNumber("preview_bytes",
+			mcp.Description("With preview, how many bytes of text to return (default 512, at most the inline text limit)"),
+		),
+		mcp.WithBoolean("selection_fallback",
+			mcp.Description("When the clipboard is empty, return the text selected in the focused application via the accessibility API (requires MCP_CLIP_ACCESSIBILITY=1)"),
+		),
+		mcp.WithBoolean("fetch_url_metadata",
+			mcp.Description("When the clipboard holds URLs (always listed in _meta.urls), also fetch the status, content type and page title of the first 10 to confirm what they point to (requires MCP_CLIP_FETCH_URLS=1)"),
+		),
+		mcp.WithBoolean("pretty",
+			mcp.Description("When the clipboard holds JSON, return it indented for readability (JSON is always flagged as application/json in the result metadata)"),
+		),
+		mcp.WithBoolean("include_history_context",
+			mcp.Description("Also return type, preview and age of the previous 2-3 clipboard entries, e.g. to see that an error and a file path were copied just before this"),
+		),
+		mcp.WithBoolean("include_metadata",
+			mcp.Description("Attach mimeType, size, sha256 and change sequence to _meta, alongside the detected encoding and language"),
+		),
+		mcp.WithBoolean("allow_concealed",
+			mcp.Description("Return content a password manager marked as concealed (default false). Only set this when the user explicitly asked for it; concealed content is never recorded in history."),
+		),
+		mcp.WithBoolean("include_file_contents",
+			mcp.Description("When the clipboard holds copied files, also return the content of the small text files among them (size-capped; others are listed in _meta.files with the reason)"),
+		),
+		mcp.WithArray("prefer",
+			mcp.Description("Flavors you want, best first, e.g. [\"image/png\", \"text/html\", \"text/plain\"]. The first one on the clipboard is returned (text/markdown converts HTML; wildcards like image/* work) and named in _meta.flavor; if none is, the default representation is returned with _meta.preferMatched false. Overrides format."),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString("return_as",
+			mcp.Description("'content' (default) returns text or image content; 'resource' returns the content as an embedded resource with its URI and MIME type (a clipboard://history/ entry, or a clipboard://files/ link when too large to embed)"),
+			mcp.Enum("content", "resource"),
+		),
+	)
+
+	s.AddTool(readClipboardTool, cs.readClipboardHandler)
+
+	infoTool := mcp.NewTool("clipboard_info",
+		mcp.WithDescription("Describe the clipboard without returning its content: type, MIME type, size in bytes, line count, sha256 and when it last changed. Cheap way to decide whether a full read_clipboard is worth it."),
+		withSchemaVersion(),
+	)
+
+	s.AddTool(infoTool, cs.clipboardInfoHandler)
+
+	writeOptions := []mcp.ToolOption{
+		mcp.WithDescription("Write text or an image to the clipboard, e.g. to place a generated diagram where it can be pasted into other apps"),
+		withSchemaVersion(),
+		mcp.WithString("content",
+			mcp.Description("Text to place on the clipboard"),
+		),
+	}
+	if caps.writeImages {
+		writeOptions = append(writeOptions,
+			mcp.WithString("image",
+				mcp.Description("Base64 PNG or JPEG image (or a data: URL) to place on the clipboard instead of text"),
+			),
+			mcp.WithString("image_path",
+				mcp.Description("Path of a PNG or JPEG file to place on the clipboard as an image instead of text"),
+			),
+		)
+	} else {
+		writeOptions[0] = mcp.WithDescription("Write text to the clipboard" + caps.limitation("cannot write images"))
+	}
+	writeClipboardTool := mcp.NewTool("write_clipboard", append(writeOptions,
+		mcp.WithBoolean("skip_if_present",
+			mcp.Description("Return 'already present' instead of rewriting when the clipboard already holds identical content (default true)"),
+		),
+		mcp.WithNumber("clear_after_seconds",
+			mcp.Description("Clear the clipboard after this many seconds, as password managers do for secrets, unless it holds other content by then"),
+		),
+		mcp.WithBoolean("scratch",
+			mcp.Description("Scratch write: save the current clipboard and restore it after restore_after_seconds or when restore_clipboard is called, so the clipboard can be used as a transfer channel without losing what the user copied"),
+		),
+		mcp.WithNumber("restore_after_seconds",
+			mcp.Description("With scratch, restore the previous content after this many seconds (default 60)"),
+		),
+	)...)
+
+	cs.addWriteTool(s, writeClipboardTool, cs.writeClipboardHandler)
+
+	appendTool := mcp.NewTool("append_to_clipboard",
+		mcp.WithDescription("Append text to the current clipboard content, e.g. to collect several snippets, without a separate read and write that could race with the user copying"),
+		withSchemaVersion(),
+		mcp.WithString("text",
+			mcp.Required(),
+			mcp.Description("Text to append"),
+		),
+		mcp.WithString("separator",
+			mcp.Description("Inserted between the existing content and the new text unless the content already ends with it (default: newline)"),
+		),
+		mcp.WithString("expected_hash",
+			mcp.Description("Only append if the clipboard still has this sha256, as reported by a previous read or append"),
+		),
+	)
+
+	cs.addWriteTool(s, appendTool, cs.appendToClipboardHandler)
+
+	pasteTool := mcp.NewTool("paste_into_active_app",
+		mcp.WithDescription("Write text to the clipboard and press the paste shortcut (Cmd+V or Ctrl+V), delivering it straight into the application that has keyboard focus. Only use this when the user asked for content to be typed or pasted somewhere."),
+		withSchemaVersion(),
+		mcp.WithString("content",
+			mcp.Description("Text to paste (default: paste the current clipboard content)"),
+		),
+		mcp.WithNumber("delay_ms",
+			mcp.Description("Wait between writing the clipboard and pressing the shortcut, in milliseconds (default 100, max 10000)"),
+		),
+	)
+
+	// Typing into other applications is opt-in, see --allow-paste
+	if cs.allowPaste {
+		cs.addWriteTool(s, pasteTool, cs.pasteIntoActiveAppHandler)
+	}
+
+	digestTool := mcp.NewTool("clipboard_digest",
+		mcp.WithDescription("Generate a Markdown digest of recorded clipboard activity for a period: counts by class, activity by day, top domains and notable code snippets"),
+		withSchemaVersion(),
+		mcp.WithString("period",
+			mcp.Description("Period to cover, as a duration ('24h') or day count ('7d'). Default: 7d"),
+		),
+		mcp.WithBoolean("summarize",
+			mcp.Description("Ask the client's model (via MCP sampling) to add a natural-language summary"),
+		),
+	)
+
+	s.AddTool(digestTool, cs.clipboardDigestHandler)
+
+	waitTool := mcp.NewTool("wait_for_clipboard_change",
+		mcp.WithDescription("Block until the clipboard content changes, then return the new content. Useful for 'copy something and I'll process it' workflows."),
+		withSchemaVersion(),
+		mcp.WithString("hash",
+			mcp.Description("sha256 of the content already seen; returns as soon as the clipboard differs from it"),
+		),
+		mcp.WithNumber("sequence",
+			mcp.Description("Change sequence already seen; returns once a newer change is recorded"),
+		),
+		mcp.WithNumber("timeout_seconds",
+			mcp.Description("Maximum time to wait (default 60, max 600)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Format for the new content: 'text', 'base64', or 'auto' (default)"),
+		),
+	)
+
+	s.AddTool(waitTool, cs.waitForClipboardChangeHandler)
+
+	changesTool := mcp.NewTool("get_clipboard_changes",
+		mcp.WithDescription("List every clipboard change recorded after a sequence number, oldest first, so you can catch up on everything copied while you were busy"),
+		withSchemaVersion(),
+		mcp.WithNumber("since_sequence",
+			mcp.Description("Return changes newer than this sequence (default 0: all retained history)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of changes to return (default 20)"),
+		),
+	)
+
+	s.AddTool(changesTool, cs.getClipboardChangesHandler)
+
+	diffTool := mcp.NewTool("diff_clipboard",
+		mcp.WithDescription("Produce a unified diff between two clipboard history entries, or between a history entry and the current clipboard"),
+		withSchemaVersion(),
+		mcp.WithNumber("from_sequence",
+			mcp.Required(),
+			mcp.Description("Sequence of the older history entry"),
+		),
+		mcp.WithNumber("to_sequence",
+			mcp.Description("Sequence of the newer history entry (default: current clipboard)"),
+		),
+	)
+
+	s.AddTool(diffTool, cs.diffClipboardHandler)
+
+	pairTool := mcp.NewTool("read_clipboard_pair",
+		mcp.WithDescription("Return a screenshot and the text copied right before or after it as one result, for the common 'copy a screenshot, then its caption or command' pattern"),
+		withSchemaVersion(),
+		mcp.WithNumber("window_seconds",
+			mcp.Description("Maximum time between the two copies (default 30, or MCP_CLIP_PAIR_WINDOW)"),
+		),
+	)
+
+	// Screenshots never reach the history of a text-only backend
+	if caps.readImages {
+		s.AddTool(pairTool, cs.readClipboardPairHandler)
+	}
+
+	qrTool := mcp.NewTool("decode_qr",
+		mcp.WithDescription("Decode the QR codes in the image on the clipboard, e.g. a screenshot of a QR code, and return their payloads (requires zbarimg from the zbar tools)"),
+		withSchemaVersion(),
+	)
+
+	if caps.readImages {
+		s.AddTool(qrTool, cs.decodeQRHandler)
+	}
+
+	flavorsTool := mcp.NewTool("read_clipboard_flavors",
+		mcp.WithDescription("Return every text flavor on the clipboard (plain text, HTML, RTF) in one result, e.g. for content copied from Word, Excel or Outlook, so you can pick the richest usable one"),
+		withSchemaVersion(),
+	)
+
+	if caps.flavors {
+		s.AddTool(flavorsTool, cs.readClipboardFlavorsHandler)
+	}
+
+	allDescription := "Return every representation of the clipboard in one multi-part result: the text, image or binary content, the list of copied files, and the HTML and RTF flavors. For complex copies (spreadsheet ranges, web page selections, files from a file manager) where one flavor loses information."
+	if !caps.flavors {
+		allDescription += caps.limitation("cannot read HTML or RTF")
+	}
+	allTool := mcp.NewTool("read_clipboard_all",
+		mcp.WithDescription(allDescription),
+		withSchemaVersion(),
+		mcp.WithNumber("max_part_bytes",
+			mcp.Description("Largest part returned inline; larger parts are saved to temp files and listed in _meta.parts with their path (default: the inline limits for text, images and binary data)"),
+		),
+	)
+
+	s.AddTool(allTool, cs.readClipboardAllHandler)
+
+	saveSnippetTool := mcp.NewTool("save_snippet",
+		mcp.WithDescription("Save a named text snippet (boilerplate, signatures, commands) for later use with copy_snippet_to_clipboard"),
+		withSchemaVersion(),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Snippet name: letters, digits, '.', '_' or '-' (max 64)"),
+		),
+		mcp.WithString("content",
+			mcp.Description("Snippet text (default: the current clipboard text)"),
+		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("Replace an existing snippet with the same name (default false)"),
+		),
+	)
+
+	s.AddTool(saveSnippetTool, cs.saveSnippetHandler)
+
+	listSnippetsTool := mcp.NewTool("list_snippets",
+		mcp.WithDescription("List saved snippets with their size and a preview"),
+		withSchemaVersion(),
+	)
+
+	s.AddTool(listSnippetsTool, cs.listSnippetsHandler)
+
+	copySnippetTool := mcp.NewTool("copy_snippet_to_clipboard",
+		mcp.WithDescription("Place a saved snippet on the clipboard by name"),
+		withSchemaVersion(),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the snippet to copy"),
+		),
+	)
+
+	cs.addWriteTool(s, copySnippetTool, cs.copySnippetHandler)
+
+	clearTool := mcp.NewTool("schedule_clipboard_clear",
+		mcp.WithDescription("Clear the clipboard after a delay, e.g. once a secret placed there has been pasted. Only one clear is pending at a time; scheduling again replaces it."),
+		withSchemaVersion(),
+		mcp.WithNumber("seconds",
+			mcp.Required(),
+			mcp.Description("Delay before clearing, in seconds (max 86400); 0 cancels the pending clear"),
+		),
+		mcp.WithBoolean("only_if_unchanged",
+			mcp.Description("Only clear if the clipboard still holds its current content, so something the user copies meanwhile is kept (default true)"),
+		),
+	)
+
+	cs.addWriteTool(s, clearTool, cs.scheduleClipboardClearHandler)
+
+	restoreTool := mcp.NewTool("restore_clipboard",
+		mcp.WithDescription("Restore the clipboard content saved by a scratch write_clipboard now, instead of waiting for restore_after_seconds. Content the user copied since the scratch write is kept."),
+		withSchemaVersion(),
+	)
+
+	cs.addWriteTool(s, restoreTool, cs.restoreClipboardHandler)
+
+	cleanupTool := mcp.NewTool("cleanup_temp_files",
+		mcp.WithDescription("Delete temp files saved for clipboard content too large to return inline, to reclaim disk space or remove sensitive content. By default only files past the cleanup TTL are removed."),
+		withSchemaVersion(),
+		mcp.WithBoolean("all",
+			mcp.Description("Remove every saved clipboard file regardless of age, including ones returned earlier in this session (default false)"),
+		),
+	)
+
+	s.AddTool(cleanupTool, cs.cleanupTempFilesHandler)
+
+	purgeTool := mcp.NewTool("purge_session_files",
+		mcp.WithDescription("Delete every temp file saved during this session and list what was removed, e.g. at the end of a workflow that handled sensitive clipboard content"),
+		withSchemaVersion(),
+	)
+
+	s.AddTool(purgeTool, cs.purgeSessionFilesHandler)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the spans around tool calls and clipboard utilities. It is
+// a no-op until initTracing installs an exporter.
+var tracer = otel.Tracer("github.com/standardbeagle/mcp-clip")
+
+// tracingEnabled reports whether an OTLP endpoint is configured through the
+// standard OpenTelemetry environment variables.
+func tracingEnabled() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// initTracing exports spans over OTLP/HTTP when an endpoint is configured.
+// The returned func flushes pending spans and must be called before exiting.
+func initTracing(ctx context.Context) (func(context.Context) error, error) {
+	if !tracingEnabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %v", err)
+	}
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "mcp-clip"
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// tracingMiddleware wraps every tool call in a span.
+func tracingMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, span := tracer.Start(ctx, "tools/call "+request.Params.Name,
+			trace.WithAttributes(attribute.String("mcp.tool", request.Params.Name)))
+		defer span.End()
+
+		result, err := next(ctx, request)
+		switch {
+		case err != nil:
+			span.SetStatus(codes.Error, err.Error())
+		case result != nil && result.IsError:
+			span.SetStatus(codes.Error, "tool returned an error result")
+		}
+		return result, err
+	}
+}
+
+// startChildSpan starts a span only within a traced tool call, so the
+// monitor's background polling doesn't flood the exporter with root spans.
+func startChildSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func(error)) {
+	if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return ctx, func(error) {}
+	}
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, func(err error) {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// traceCommand starts a span for running a clipboard utility such as xclip
+// or PowerShell. Call the returned func with the command's outcome.
+func traceCommand(ctx context.Context, cmd *exec.Cmd) func(error) {
+	name := strings.TrimSuffix(filepath.Base(cmd.Path), ".exe")
+	_, end := startChildSpan(ctx, "exec "+name, attribute.String("process.executable.name", name))
+	return end
+}
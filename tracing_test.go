@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Test that tool calls are traced with their utilities as child spans, and
+// that utilities run outside a tool call (the monitor) are not
+func TestTracingSpans(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs the true command")
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	defer provider.Shutdown(context.Background())
+
+	handler := tracingMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		runLimited(ctx, exec.CommandContext(ctx, "true"), 100)
+		return mcp.NewToolResultError("nothing there"), nil
+	})
+	var request mcp.CallToolRequest
+	request.Params.Name = "read_clipboard"
+	handler(context.Background(), request)
+	runLimited(context.Background(), exec.Command("true"), 0)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	child, parent := spans[0], spans[1]
+	if child.Name != "exec true" || parent.Name != "tools/call read_clipboard" {
+		t.Errorf("Unexpected span names %q and %q", child.Name, parent.Name)
+	}
+	if child.Parent.SpanID() != parent.SpanContext.SpanID() {
+		t.Error("Expected the utility span to be a child of the tool call span")
+	}
+	if parent.Status.Description != "tool returned an error result" {
+		t.Errorf("Expected the error result to mark the span, got %q", parent.Status.Description)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// graphemeExtends reports whether r attaches to the character before it:
+// combining marks, variation selectors, emoji skin tones and tag
+// characters, and the zero width joiner.
+func graphemeExtends(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me) ||
+		r == '\u200d' ||
+		(r >= 0xfe00 && r <= 0xfe0f) ||
+		(r >= 0xe0100 && r <= 0xe01ef) ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) ||
+		(r >= 0xe0020 && r <= 0xe007f)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// isGraphemeBoundary reports whether s can be cut at byte offset i without
+// splitting a rune or a user-perceived character: an accented letter, an
+// emoji sequence joined with ZWJ, a flag, or CRLF. It covers the cases that
+// show up in copied text rather than all of UAX #29.
+func isGraphemeBoundary(s string, i int) bool {
+	if i <= 0 || i >= len(s) {
+		return true
+	}
+	if !utf8.RuneStart(s[i]) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	prev, _ := utf8.DecodeLastRuneInString(s[:i])
+	switch {
+	case graphemeExtends(r) || prev == '\u200d':
+		return false
+	case prev == '\r' && r == '\n':
+		return false
+	case isRegionalIndicator(r) && isRegionalIndicator(prev):
+		// Flags are pairs of regional indicators
+		n := 0
+		for j := i; j > 0; {
+			p, size := utf8.DecodeLastRuneInString(s[:j])
+			if !isRegionalIndicator(p) {
+				break
+			}
+			n++
+			j -= size
+		}
+		return n%2 == 0
+	}
+	return true
+}
+
+// truncateBytes cuts s to at most maxBytes bytes, backing off to the
+// previous character boundary so the result is valid UTF-8 and no
+// character loses its accents or emoji modifiers.
+func truncateBytes(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := max(maxBytes, 0)
+	for cut > 0 && !isGraphemeBoundary(s, cut) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// truncateRunes cuts s to at most maxRunes runes at a character boundary,
+// reporting whether anything was cut.
+func truncateRunes(s string, maxRunes int) (string, bool) {
+	i := 0
+	for n := 0; i < len(s) && n < maxRunes; n++ {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	if i >= len(s) {
+		return s, false
+	}
+	return truncateBytes(s, i), true
+}
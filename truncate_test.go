@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Test that truncation never splits a rune or a multi-rune character
+func TestTruncateBytes(t *testing.T) {
+	tests := []struct {
+		input    string
+		maxBytes int
+		expected string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"},           // é is two bytes
+		{"héllo", 3, "h"},          // e + combining acute
+		{"a👍🏽b", 5, "a"},            // thumbs up + skin tone
+		{"a👩‍💻b", 6, "a"},           // ZWJ sequence
+		{"🇩🇪🇫🇷", 12, "🇩🇪"},          // two flags
+		{"line\r\nnext", 5, "line"}, // CRLF
+		{"日本語", 7, "日本"},
+	}
+	for _, tt := range tests {
+		got := truncateBytes(tt.input, tt.maxBytes)
+		if got != tt.expected || !utf8.ValidString(got) {
+			t.Errorf("truncateBytes(%q, %d): expected %q, got %q", tt.input, tt.maxBytes, tt.expected, got)
+		}
+	}
+}
+
+// Test rune-based truncation used by previews
+func TestTruncateRunes(t *testing.T) {
+	if got, cut := truncateRunes("日本語テキスト", 3); got != "日本語" || !cut {
+		t.Errorf("Expected 日本語 and a cut, got %q %v", got, cut)
+	}
+	if got, cut := truncateRunes("short", 10); got != "short" || cut {
+		t.Errorf("Expected no cut, got %q %v", got, cut)
+	}
+	if got := previewText("aéi", 2); got != "a…" {
+		t.Errorf("Expected the accent to stay with its letter, got %q", got)
+	}
+	if got := previewText(strings.Repeat("é", 100), 10); !utf8.ValidString(got) {
+		t.Errorf("Expected a valid UTF-8 preview, got %q", got)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// DefaultURLFetchTimeout bounds each URL metadata fetch, redirects included.
+	DefaultURLFetchTimeout = 3 * time.Second
+	maxReportedURLs        = 100
+	maxFetchedURLs         = 10
+	maxTitleScanBytes      = 64 * 1024 // the <title> is in the head of a page
+	maxURLRedirects        = 5
+)
+
+// clipboardURLs returns the URLs on the clipboard when the text is a URL or a
+// list of URLs, one per line, and nil when it is anything else.
+func clipboardURLs(text string) []string {
+	var urls []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		u, err := url.Parse(line)
+		if err != nil || u.Scheme == "" || u.Host == "" || strings.ContainsAny(line, " \t") {
+			return nil
+		}
+		urls = append(urls, line)
+	}
+	return urls
+}
+
+// urlFetchEnabled reports whether the user opted into fetching metadata of
+// copied URLs. Fetching makes requests on the user's behalf, so it is never
+// done without explicit configuration.
+func urlFetchEnabled() bool {
+	return os.Getenv("MCP_CLIP_FETCH_URLS") == "1"
+}
+
+// privateURLFetchAllowed reports whether the user allowed fetching URLs that
+// resolve to loopback, private or link-local addresses, such as intranet pages.
+func privateURLFetchAllowed() bool {
+	return os.Getenv("MCP_CLIP_FETCH_PRIVATE_URLS") == "1"
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address, as
+// opposed to one on this machine or its networks (cloud metadata endpoints,
+// routers, internal services).
+func isPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !carrierGradeNAT.Contains(ip)
+}
+
+// carrierGradeNAT is the shared address space of RFC 6598, which isn't
+// reachable from the internet either.
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// publicAddressOnly is a net.Dialer Control function refusing connections to
+// non-public addresses. It sees the address after name resolution, for every
+// redirect, so neither DNS names nor redirects get a fetch past it.
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("refusing to fetch from non-public address %s (set MCP_CLIP_FETCH_PRIVATE_URLS=1 to allow it)", host)
+	}
+	return nil
+}
+
+// getURLFetchTimeout returns how long fetching one URL's metadata may take.
+func getURLFetchTimeout() time.Duration {
+	if timeoutStr := os.Getenv("MCP_CLIP_URL_FETCH_TIMEOUT"); timeoutStr != "" {
+		if timeout, err := time.ParseDuration(timeoutStr); err == nil && timeout > 0 {
+			return timeout
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Invalid MCP_CLIP_URL_FETCH_TIMEOUT format '%s', using default: %v\n", timeoutStr, DefaultURLFetchTimeout)
+		}
+	}
+	return DefaultURLFetchTimeout
+}
+
+// urlMetadata is what a fetch learned about a copied URL.
+type urlMetadata struct {
+	URL         string `json:"url"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Title       string `json:"title,omitempty"`
+	FinalURL    string `json:"finalUrl,omitempty"` // set when redirected
+	Error       string `json:"error,omitempty"`
+}
+
+// fetchURLMetadata fetches the status, content type and, for HTML pages,
+// title of each URL concurrently. Only the head of a page is downloaded.
+// Addresses that aren't public are refused unless allowed, and proxies are
+// not used, since they would connect on the fetch's behalf unchecked.
+func fetchURLMetadata(ctx context.Context, urls []string) []urlMetadata {
+	dialer := &net.Dialer{Timeout: getURLFetchTimeout()}
+	if !privateURLFetchAllowed() {
+		dialer.Control = publicAddressOnly
+	}
+	client := &http.Client{
+		Transport: &http.Transport{DialContext: dialer.DialContext, ForceAttemptHTTP2: true},
+		Timeout:   getURLFetchTimeout(),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxURLRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxURLRedirects)
+			}
+			return nil
+		},
+	}
+	results := make([]urlMetadata, len(urls))
+	var wg sync.WaitGroup
+	for i, link := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = fetchOneURL(ctx, client, link)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func fetchOneURL(ctx context.Context, client *http.Client, link string) urlMetadata {
+	info := urlMetadata{URL: link}
+	if u, _ := url.Parse(link); u.Scheme != "http" && u.Scheme != "https" {
+		info.Error = "only http and https URLs are fetched"
+		return info
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	req.Header.Set("User-Agent", "mcp-clip")
+	resp, err := client.Do(req)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	defer resp.Body.Close()
+
+	info.Status = resp.StatusCode
+	info.ContentType = resp.Header.Get("Content-Type")
+	if final := resp.Request.URL.String(); final != link {
+		info.FinalURL = final
+	}
+	if mediaType, _, _ := mime.ParseMediaType(info.ContentType); mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		info.Title = htmlTitle(io.LimitReader(resp.Body, maxTitleScanBytes))
+	}
+	return info
+}
+
+// htmlTitle returns the text of a page's <title>, or "" when there is none.
+func htmlTitle(r io.Reader) string {
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken:
+			if name, _ := tokenizer.TagName(); atom.Lookup(name) != atom.Title {
+				continue
+			}
+			if tokenizer.Next() != html.TextToken {
+				return ""
+			}
+			return strings.TrimSpace(whitespacePattern.ReplaceAllString(string(tokenizer.Text()), " "))
+		}
+	}
+}
+
+// annotateURLs reports the URLs on the clipboard in a result's metadata and,
+// when asked for and enabled, what they point to.
+func annotateURLs(ctx context.Context, result *mcp.CallToolResult, urls []string, fetch bool) {
+	meta := resultMeta(result)
+	meta["urls"] = urls[:min(len(urls), maxReportedURLs)]
+	meta["urlCount"] = len(urls)
+	if !fetch {
+		return
+	}
+	if !urlFetchEnabled() {
+		result.Content = append(result.Content, mcp.NewTextContent("URL metadata fetching is disabled; set MCP_CLIP_FETCH_URLS=1 to enable it"))
+		return
+	}
+	meta["urlMetadata"] = fetchURLMetadata(ctx, urls[:min(len(urls), maxFetchedURLs)])
+}
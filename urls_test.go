@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test recognizing URLs and lists of URLs
+func TestClipboardURLs(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"https://example.com/a?b=c", 1},
+		{"  https://example.com\n\nftp://files.example.org/x\r\nhttp://localhost:8080/\n", 3},
+		{"see https://example.com", 0},
+		{"https://example.com\nnot a url", 0},
+		{"localhost:8080", 0},
+		{"mailto:someone@example.com", 0},
+		{"C:\\Users\\me", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := clipboardURLs(tt.text); len(got) != tt.want {
+			t.Errorf("%q: expected %d URLs, got %q", tt.text, tt.want, got)
+		}
+	}
+}
+
+// Test fetching the status, content type and title of URLs
+func TestFetchURLMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html><head><title>\n  Release &amp; Notes\n</title></head><body>" + strings.Repeat("x", 1<<20) + "</body></html>"))
+		case "/moved":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		case "/data":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"title": "not a page"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("MCP_CLIP_FETCH_PRIVATE_URLS", "1")
+
+	results := fetchURLMetadata(context.Background(), []string{server.URL + "/page", server.URL + "/moved", server.URL + "/data", server.URL + "/gone", "ftp://example.com/x"})
+	if page := results[0]; page.Status != 200 || page.Title != "Release & Notes" || page.FinalURL != "" {
+		t.Errorf("Unexpected page metadata: %+v", page)
+	}
+	if moved := results[1]; moved.FinalURL != server.URL+"/page" || moved.Title != "Release & Notes" {
+		t.Errorf("Expected the redirect to be followed, got %+v", moved)
+	}
+	if data := results[2]; data.ContentType != "application/json" || data.Title != "" {
+		t.Errorf("Unexpected JSON metadata: %+v", data)
+	}
+	if gone := results[3]; gone.Status != http.StatusNotFound {
+		t.Errorf("Expected 404, got %+v", gone)
+	}
+	if ftp := results[4]; ftp.Error == "" || ftp.Status != 0 {
+		t.Errorf("Expected ftp to be skipped, got %+v", ftp)
+	}
+}
+
+// Test that fetches give up after the timeout
+func TestFetchURLMetadataTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	t.Setenv("MCP_CLIP_URL_FETCH_TIMEOUT", "50ms")
+	t.Setenv("MCP_CLIP_FETCH_PRIVATE_URLS", "1")
+
+	if result := fetchURLMetadata(context.Background(), []string{server.URL})[0]; result.Error == "" {
+		t.Errorf("Expected a timeout error, got %+v", result)
+	}
+}
+
+// Test that URLs on non-public addresses aren't fetched unless allowed
+func TestFetchURLMetadataPrivate(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	for _, link := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		if result := fetchURLMetadata(context.Background(), []string{link})[0]; !strings.Contains(result.Error, "non-public address") || result.Status != 0 {
+			t.Errorf("%s: expected the fetch to be refused, got %+v", link, result)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests to reach the server, got %d", requests)
+	}
+
+	for ip, public := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"192.168.0.1":     false,
+		"172.16.0.1":      false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::ffff:10.0.0.1": false,
+	} {
+		if got := isPublicIP(net.ParseIP(ip)); got != public {
+			t.Errorf("%s: expected public %v, got %v", ip, public, got)
+		}
+	}
+}
+
+// Test read_clipboard's URL metadata and its opt-in
+func TestReadClipboardURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<title>Dashboard</title>"))
+	}))
+	defer server.Close()
+	fakeClipboard(t, server.URL+"/dashboard")
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"fetch_url_metadata": true}
+	t.Setenv("MCP_CLIP_FETCH_URLS", "")
+	result, err := NewClipboardServer().readClipboardHandler(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("Read failed: %v %v", err, result.Content)
+	}
+	meta := result.Meta.AdditionalFields
+	if urls := meta["urls"].([]string); len(urls) != 1 || meta["urlMetadata"] != nil {
+		t.Errorf("Expected the URL without metadata, got %v", meta)
+	}
+	if note := result.Content[len(result.Content)-1].(mcp.TextContent).Text; !strings.Contains(note, "MCP_CLIP_FETCH_URLS") {
+		t.Errorf("Expected a note on enabling fetches, got %q", note)
+	}
+
+	t.Setenv("MCP_CLIP_FETCH_URLS", "1")
+	t.Setenv("MCP_CLIP_FETCH_PRIVATE_URLS", "1")
+	result, _ = NewClipboardServer().readClipboardHandler(context.Background(), request)
+	metadata, _ := result.Meta.AdditionalFields["urlMetadata"].([]urlMetadata)
+	if len(metadata) != 1 || metadata[0].Title != "Dashboard" {
+		t.Errorf("Expected the page title, got %v", result.Meta.AdditionalFields)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Tool result schema versions. Bump CurrentSchemaVersion whenever the shape of
+// tool results changes in a way existing prompts or automations could notice,
+// and teach adaptResult how to produce the older shape.
+const (
+	// SchemaVersion1 is the original format: content blocks only.
+	SchemaVersion1 = 1
+	// SchemaVersion2 adds result metadata in _meta (encoding, mimeType,
+	// language, history, ...), including schemaVersion itself.
+	SchemaVersion2 = 2
+	// SchemaVersion3 adds structuredContent (see toolOutput), declared as
+	// every tool's output schema.
+	SchemaVersion3 = 3
+
+	MinSchemaVersion     = SchemaVersion1
+	CurrentSchemaVersion = SchemaVersion3
+)
+
+// withSchemaVersion adds the optional schema_version parameter every tool
+// accepts and, unless MCP_CLIP_SCHEMA_VERSION pins an older format, declares
+// the structured output every tool returns.
+func withSchemaVersion() mcp.ToolOption {
+	parameter := mcp.WithNumber("schema_version",
+		mcp.Description(fmt.Sprintf("Result format version to use (%d-%d, default %d). Pin this to keep automations working when the response format evolves; structured content is returned with every version.",
+			MinSchemaVersion, CurrentSchemaVersion, CurrentSchemaVersion)),
+	)
+	if defaultSchemaVersion() < SchemaVersion3 {
+		return parameter
+	}
+	outputSchema := mcp.WithOutputSchema[toolOutput]()
+	return func(t *mcp.Tool) {
+		parameter(t)
+		outputSchema(t)
+	}
+}
+
+// defaultSchemaVersion is CurrentSchemaVersion unless MCP_CLIP_SCHEMA_VERSION
+// pins an older one for every call.
+func defaultSchemaVersion() int {
+	if versionStr := os.Getenv("MCP_CLIP_SCHEMA_VERSION"); versionStr != "" {
+		if version, err := strconv.Atoi(versionStr); err == nil && version >= MinSchemaVersion && version <= CurrentSchemaVersion {
+			return version
+		}
+		if os.Getenv("MCP_DEBUG") == "1" {
+			fmt.Fprintf(os.Stderr, "Invalid MCP_CLIP_SCHEMA_VERSION '%s', using default: %d\n", versionStr, CurrentSchemaVersion)
+		}
+	}
+	return CurrentSchemaVersion
+}
+
+func requestedSchemaVersion(request mcp.CallToolRequest) (int, error) {
+	version := request.GetInt("schema_version", defaultSchemaVersion())
+	if version < MinSchemaVersion || version > CurrentSchemaVersion {
+		return 0, fmt.Errorf("unsupported schema_version %d: this server supports versions %d-%d", version, MinSchemaVersion, CurrentSchemaVersion)
+	}
+	return version, nil
+}
+
+// schemaVersionMiddleware negotiates the result format of every tool call.
+func schemaVersionMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		version, err := requestedSchemaVersion(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		result, err := next(ctx, request)
+		if err != nil || result == nil {
+			return result, err
+		}
+		return adaptResult(result, version), nil
+	}
+}
+
+// adaptResult converts a current-format result to the requested version.
+// Structured content is kept whenever tools declare their output schema (see
+// withSchemaVersion), since clients reject results that lack it then.
+func adaptResult(result *mcp.CallToolResult, version int) *mcp.CallToolResult {
+	if defaultSchemaVersion() < SchemaVersion3 {
+		result.StructuredContent = nil
+	} else {
+		completeOutput(result)
+	}
+	if version < SchemaVersion2 {
+		result.Meta = nil
+		return result
+	}
+	meta := resultMeta(result)
+	meta["schemaVersion"] = version
+	return result
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test schema version negotiation and downgrading of results
+func TestSchemaVersionMiddleware(t *testing.T) {
+	handler := schemaVersionMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := mcp.NewToolResultText("content")
+		result.Meta = mcp.NewMetaFromMap(map[string]any{"encoding": EncodingUTF8})
+		return result, nil
+	})
+	call := func(args map[string]any) *mcp.CallToolResult {
+		var request mcp.CallToolRequest
+		request.Params.Arguments = args
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result
+	}
+
+	result := call(nil)
+	if result.Meta.AdditionalFields["schemaVersion"] != CurrentSchemaVersion || result.Meta.AdditionalFields["encoding"] != EncodingUTF8 {
+		t.Errorf("Expected current version metadata, got %v", result.Meta)
+	}
+
+	if output, ok := result.StructuredContent.(*toolOutput); !ok || output.Status != "ok" || output.Message != "content" {
+		t.Errorf("Expected structured content, got %#v", result.StructuredContent)
+	}
+
+	result = call(map[string]any{"schema_version": 2})
+	if result.StructuredContent == nil || result.Meta.AdditionalFields["schemaVersion"] != 2 {
+		t.Errorf("Expected version 2 result with the declared structured content, got %#v", result.StructuredContent)
+	}
+
+	result = call(map[string]any{"schema_version": 1})
+	if result.Meta != nil || len(result.Content) != 1 || result.StructuredContent == nil {
+		t.Errorf("Expected version 1 result without _meta, got %v", result.Meta)
+	}
+
+	result = call(map[string]any{"schema_version": CurrentSchemaVersion + 1})
+	if !result.IsError {
+		t.Errorf("Expected unsupported version to be rejected")
+	}
+
+	t.Setenv("MCP_CLIP_SCHEMA_VERSION", "1")
+	if result = call(nil); result.Meta != nil || result.StructuredContent != nil {
+		t.Errorf("Expected MCP_CLIP_SCHEMA_VERSION to pin version 1, got %v %#v", result.Meta, result.StructuredContent)
+	}
+}
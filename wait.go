@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	DefaultWaitTimeout = 60 * time.Second
+	MaxWaitTimeout     = 10 * time.Minute
+)
+
+// changeSignal returns a channel that is closed on the next clipboard change.
+func (cs *ClipboardServer) changeSignal() <-chan struct{} {
+	return *cs.changed.Load()
+}
+
+// signalChange wakes all waiters by closing the current channel and installing a fresh one.
+func (cs *ClipboardServer) signalChange() {
+	next := make(chan struct{})
+	prev := cs.changed.Swap(&next)
+	close(*prev)
+}
+
+// waitForChange blocks until the monitored clipboard differs from the supplied
+// hash and/or advances past the supplied sequence, the timeout elapses or ctx
+// is cancelled. Without either baseline it waits for the next change.
+func (cs *ClipboardServer) waitForChange(ctx context.Context, hash string, sequence uint64, hasSequence bool, timeout time.Duration) (bool, error) {
+	if cs.onDemand {
+		defer cs.watchOnDemand(ctx)()
+	}
+	if hash == "" && !hasSequence {
+		sequence, hasSequence = cs.history.latestID(), true
+	}
+
+	changed := func() bool {
+		if hasSequence && cs.history.latestID() > sequence {
+			return true
+		}
+		if hash != "" {
+			if content, _ := cs.getLastClipboard(); content != "" && contentHash(content) != hash {
+				return true
+			}
+		}
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		signal := cs.changeSignal()
+		if changed() {
+			return true, nil
+		}
+		select {
+		case <-signal:
+		case <-timer.C:
+			return false, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+func (cs *ClipboardServer) waitForClipboardChangeHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	timeout := DefaultWaitTimeout
+	if seconds := request.GetFloat("timeout_seconds", 0); seconds > 0 {
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+	if timeout > MaxWaitTimeout {
+		timeout = MaxWaitTimeout
+	}
+
+	_, hasSequence := request.GetArguments()["sequence"]
+	sequence := request.GetInt("sequence", 0)
+	if sequence < 0 {
+		return mcp.NewToolResultError("sequence must not be negative"), nil
+	}
+
+	changed, err := cs.waitForChange(ctx, request.GetString("hash", ""), uint64(sequence), hasSequence, timeout)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Wait cancelled: %v", err)), nil
+	}
+
+	content, _ := cs.getLastClipboard()
+	seq, hash := cs.history.latestID(), contentHash(content)
+	if !changed {
+		return mcp.NewToolResultText(fmt.Sprintf("No clipboard change within %v (sequence: %d, sha256: %s)", timeout, seq, hash)), nil
+	}
+
+	if cs.privacy {
+		return redactedResult("Clipboard changed", seq, content), nil
+	}
+	result, err := cs.contentResult(ctx, content, request.GetString("format", "auto"))
+	if err != nil || result.IsError {
+		return result, err
+	}
+	header := mcp.NewTextContent(fmt.Sprintf("Clipboard changed (sequence: %d, sha256: %s)", seq, hash))
+	result.Content = append([]mcp.Content{header}, result.Content...)
+	return result, nil
+}
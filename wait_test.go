@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Test that waiters wake on clipboard changes
+func TestWaitForChangeWakesOnUpdate(t *testing.T) {
+	cs := NewClipboardServer()
+	cs.updateClipboard("before")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cs.updateClipboard("after")
+	}()
+
+	changed, err := cs.waitForChange(context.Background(), "", 0, false, time.Second)
+	if err != nil || !changed {
+		t.Fatalf("Expected change to be observed, got changed=%v err=%v", changed, err)
+	}
+	if content, _ := cs.getLastClipboard(); content != "after" {
+		t.Errorf("Expected 'after', got '%s'", content)
+	}
+}
+
+// Test that stale hashes and sequences return immediately, and timeouts report no change
+func TestWaitForChangeBaselines(t *testing.T) {
+	cs := NewClipboardServer()
+	cs.updateClipboard("first")
+	cs.updateClipboard("second")
+
+	if changed, _ := cs.waitForChange(context.Background(), contentHash("first"), 0, false, time.Second); !changed {
+		t.Error("Expected immediate change for stale hash")
+	}
+	if changed, _ := cs.waitForChange(context.Background(), "", 1, true, time.Second); !changed {
+		t.Error("Expected immediate change for stale sequence")
+	}
+	if changed, _ := cs.waitForChange(context.Background(), contentHash("second"), 0, false, 20*time.Millisecond); changed {
+		t.Error("Expected timeout without change")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func writeClipboard(ctx context.Context, content string) error {
+	return clipboardAccessor.write(ctx, content)
+}
+
+// recordChange updates the monitored clipboard state and mirrors the change
+// to sync peers. It returns false if the content was already current.
+func (cs *ClipboardServer) recordChange(content string) bool {
+	if !cs.updateClipboard(content) {
+		return false
+	}
+	if cs.syncer != nil && !cs.privacy {
+		cs.syncer.broadcast(content, nil)
+	}
+	return true
+}
+
+func (cs *ClipboardServer) writeClipboardHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if request.GetString("image", "") != "" || request.GetString("image_path", "") != "" {
+		return cs.writeImageHandler(ctx, request)
+	}
+	content, err := request.RequireString("content")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if content == "" {
+		return mcp.NewToolResultError("content must not be empty"), nil
+	}
+	clearAfter, err := clearDelay(request, "clear_after_seconds")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	scratchAfter, err := scratchDelay(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if limit := getMaxClipboardBytes(); limit > 0 && len(content) > limit {
+		return tooLargeResult(&oversizeError{size: len(content), limit: limit, exact: true}), nil
+	}
+
+	// Rewriting identical content still fires clipboard-change events that
+	// wake other clipboard managers, so skip it unless explicitly forced.
+	if request.GetBool("skip_if_present", true) {
+		if current, err := readClipboard(ctx); err == nil && current == content {
+			return cs.withScheduledClear(mcp.NewToolResultText(fmt.Sprintf("Clipboard already contains this content (%d bytes); not rewritten", len(content))), clearAfter, content), nil
+		}
+	}
+
+	if denied := confirmOverwrite(ctx, fmt.Sprintf("write %d bytes of text to the clipboard", len(content)), content); denied != nil {
+		return denied, nil
+	}
+	var original string
+	if scratchAfter > 0 {
+		if original, err = cs.snapshotForScratch(ctx); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Scratch write refused: %v", err)), nil
+		}
+	}
+	if err := writeClipboard(ctx, content); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to write clipboard: %v", err)), nil
+	}
+	cs.recordChange(content)
+
+	result := cs.withScheduledClear(mcp.NewToolResultText(fmt.Sprintf("Wrote %d bytes to the clipboard", len(content))), clearAfter, content)
+	return cs.withScratchRestore(result, scratchAfter, original, content), nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// wslDetection is whether the server runs under WSL, and the signal that
+// decided it, for diagnostics.
+type wslDetection struct {
+	detected bool
+	reason   string
+}
+
+// wslMarkerPaths exist only under WSL: the runtime directory of the WSL init
+// and the binfmt handler that runs Windows executables.
+var wslMarkerPaths = []string{"/run/WSL", "/proc/sys/fs/binfmt_misc/WSLInterop"}
+
+var procVersionPath = "/proc/version"
+
+// detectWSL checks the environment WSL sets up for its shells, then marker
+// paths, then the kernel version string. The kernel string alone misses
+// custom kernels, and the variables are missing in services and containers,
+// so any one signal is enough.
+func detectWSL() wslDetection {
+	if runtime.GOOS != "linux" {
+		return wslDetection{reason: "not Linux"}
+	}
+	for _, name := range []string{"WSL_DISTRO_NAME", "WSL_INTEROP"} {
+		if os.Getenv(name) != "" {
+			return wslDetection{detected: true, reason: name + " is set"}
+		}
+	}
+	for _, path := range wslMarkerPaths {
+		if _, err := os.Stat(path); err == nil {
+			return wslDetection{detected: true, reason: path + " exists"}
+		}
+	}
+	if content, err := os.ReadFile(procVersionPath); err == nil {
+		version := strings.ToLower(string(content))
+		if strings.Contains(version, "microsoft") || strings.Contains(version, "wsl") {
+			return wslDetection{detected: true, reason: procVersionPath + " names a WSL kernel"}
+		}
+	}
+	return wslDetection{reason: "no WSL variables, marker paths or kernel"}
+}
+
+func isWSL2() bool {
+	return detectWSL().detected
+}
+
+// environmentMeta describes the backend choice and the WSL detection behind
+// it, for clipboard_info and error results.
+func environmentMeta() map[string]any {
+	wsl := detectWSL()
+	return map[string]any{
+		"backend":   selectBackend().Name(),
+		"wsl":       wsl.detected,
+		"wslReason": wsl.reason,
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeWSLPaths points the marker and kernel version checks at temp files.
+func fakeWSLPaths(t *testing.T, kernel string) string {
+	dir := t.TempDir()
+	markers, version := wslMarkerPaths, procVersionPath
+	t.Cleanup(func() { wslMarkerPaths, procVersionPath = markers, version })
+	wslMarkerPaths = []string{filepath.Join(dir, "run-WSL")}
+	procVersionPath = filepath.Join(dir, "version")
+	os.WriteFile(procVersionPath, []byte(kernel), 0644)
+	t.Setenv("WSL_DISTRO_NAME", "")
+	t.Setenv("WSL_INTEROP", "")
+	return dir
+}
+
+// Test each WSL signal and the reason reported for it
+func TestDetectWSL(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("WSL detection only applies on Linux")
+	}
+	dir := fakeWSLPaths(t, "Linux version 6.6.0-custom (gcc 13)")
+	if wsl := detectWSL(); wsl.detected {
+		t.Errorf("Expected no WSL on a plain kernel, got %+v", wsl)
+	}
+
+	os.WriteFile(procVersionPath, []byte("Linux version 5.15.153.1-microsoft-standard-WSL2"), 0644)
+	if wsl := detectWSL(); !wsl.detected || !strings.Contains(wsl.reason, "kernel") {
+		t.Errorf("Expected the kernel string to be recognized, got %+v", wsl)
+	}
+
+	os.WriteFile(procVersionPath, []byte("Linux version 6.6.0-custom"), 0644)
+	os.Mkdir(filepath.Join(dir, "run-WSL"), 0755)
+	if wsl := detectWSL(); !wsl.detected || !strings.Contains(wsl.reason, "run-WSL exists") {
+		t.Errorf("Expected the marker path to be recognized, got %+v", wsl)
+	}
+
+	t.Setenv("WSL_INTEROP", "/run/WSL/1_interop")
+	if wsl := detectWSL(); !wsl.detected || wsl.reason != "WSL_INTEROP is set" {
+		t.Errorf("Expected WSL_INTEROP to be recognized first, got %+v", wsl)
+	}
+}
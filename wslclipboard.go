@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// psReadClipboardScript prints the kinds of content on the Windows clipboard
+// (Text, Image, FileDropList, HTML, in the order the server prefers them) on
+// the first line, then the content of the first kind. Enumerating first picks
+// the right extraction without trying each one, and doing both in one script
+// costs a single PowerShell start-up: text is printed as UTF-8, images as
+// base64 PNG, file lists one path per line and HTML as CF_HTML.
+const psReadClipboardScript = `[Console]::OutputEncoding = [System.Text.Encoding]::UTF8
+Add-Type -AssemblyName System.Windows.Forms
+$kinds = @()
+if ([System.Windows.Forms.Clipboard]::ContainsText()) { $kinds += 'Text' }
+if ([System.Windows.Forms.Clipboard]::ContainsImage()) { $kinds += 'Image' }
+if ([System.Windows.Forms.Clipboard]::ContainsFileDropList()) { $kinds += 'FileDropList' }
+if ([System.Windows.Forms.Clipboard]::ContainsText([System.Windows.Forms.TextDataFormat]::Html)) { $kinds += 'HTML' }
+[Console]::Out.Write(($kinds -join ',') + [char]10)
+switch ($kinds | Select-Object -First 1) {
+	'Text' { [Console]::Out.Write([System.Windows.Forms.Clipboard]::GetText()) }
+	'Image' {
+		Add-Type -AssemblyName System.Drawing
+		$ms = New-Object System.IO.MemoryStream
+		[System.Windows.Forms.Clipboard]::GetImage().Save($ms, [System.Drawing.Imaging.ImageFormat]::Png)
+		[Console]::Out.Write([Convert]::ToBase64String($ms.ToArray()))
+	}
+	'FileDropList' { [Console]::Out.Write(([System.Windows.Forms.Clipboard]::GetFileDropList() -join [char]10)) }
+	'HTML' { [Console]::Out.Write([System.Windows.Forms.Clipboard]::GetText([System.Windows.Forms.TextDataFormat]::Html)) }
+}
+`
+
+// wslKindsHeaderMax bounds the kinds line, on top of the content limit.
+const wslKindsHeaderMax = 64
+
+// readClipboardDataWSL2 reads the Windows clipboard through PowerShell. The
+// script reports which kinds of content are offered (Text, Image,
+// FileDropList, HTML) and returns the preferred one, which is decoded here.
+// Output beyond limit bytes (if positive) is not read.
+func readClipboardDataWSL2(ctx context.Context, limit int) ([]byte, error) {
+	powershellPath := findPowerShell()
+	if powershellPath == "" {
+		return nil, fmt.Errorf("PowerShell not found - required for WSL2 clipboard access")
+	}
+
+	outputLimit := 0
+	if limit > 0 {
+		// Images arrive base64-encoded, so allow for the expansion
+		outputLimit = wslKindsHeaderMax + base64.StdEncoding.EncodedLen(limit)
+	}
+	cmd := powershellCommand(ctx, powershellPath, "-NoProfile", "-STA", "-Command", psReadClipboardScript)
+	output, err := runLimited(ctx, cmd, outputLimit)
+	if _, ok := asOversize(err); ok {
+		return nil, &oversizeError{size: limit + 1, limit: limit}
+	}
+	if ctx.Err() != nil {
+		return nil, timeoutError(ctx, ctx.Err())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("PowerShell clipboard read failed: %v", err)
+	}
+
+	kinds, payload := parseWSLClipboardOutput(string(output))
+	data, err := decodeWSLClipboard(kinds, payload)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(data) > limit {
+		return nil, &oversizeError{size: len(data), limit: limit, exact: true}
+	}
+	return data, nil
+}
+
+// psWriteTextScript sets the Windows clipboard to the UTF-8 text read from
+// stdin as base64, so no console code page can mangle it. Empty text clears
+// the clipboard, which Set-Clipboard otherwise refuses.
+const psWriteTextScript = `$text = [System.Text.Encoding]::UTF8.GetString([Convert]::FromBase64String([Console]::In.ReadToEnd()))
+if ($text.Length -eq 0) { Set-Clipboard -Value $null } else { Set-Clipboard -Value $text }
+`
+
+// writeWindowsText sets the Windows clipboard to text through PowerShell's
+// Set-Clipboard, from WSL2.
+func writeWindowsText(ctx context.Context, text string) error {
+	powershellPath := findPowerShell()
+	if powershellPath == "" {
+		return fmt.Errorf("PowerShell not found - required for WSL2 clipboard access")
+	}
+	cmd := powershellCommand(ctx, powershellPath, "-NoProfile", "-STA", "-Command", psWriteTextScript)
+	cmd.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString([]byte(text)))
+	end := traceCommand(ctx, cmd)
+	output, err := cmd.CombinedOutput()
+	end(err)
+	if err != nil {
+		if ctx.Err() != nil {
+			return timeoutError(ctx, ctx.Err())
+		}
+		return fmt.Errorf("PowerShell clipboard write failed: %v (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// parseWSLClipboardOutput splits the script output into the offered kinds
+// and the content of the first one.
+func parseWSLClipboardOutput(output string) ([]string, string) {
+	header, payload, _ := strings.Cut(output, "\n")
+	var kinds []string
+	for _, kind := range strings.Split(strings.TrimSpace(header), ",") {
+		if kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, payload
+}
+
+// decodeWSLClipboard converts the payload of the preferred kind to clipboard
+// data: images are decoded to PNG bytes, file lists become WSL paths and
+// HTML is reduced to its fragment.
+func decodeWSLClipboard(kinds []string, payload string) ([]byte, error) {
+	if len(kinds) == 0 {
+		return []byte{}, nil
+	}
+	switch kinds[0] {
+	case "Image":
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 image data: %v", err)
+		}
+		return data, nil
+	case "FileDropList":
+		var paths []string
+		for _, line := range strings.Split(payload, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				paths = append(paths, windowsToWSLPath(line))
+			}
+		}
+		return []byte(strings.Join(paths, "\n")), nil
+	case "HTML":
+		return []byte(extractCFHTMLFragment(payload)), nil
+	default:
+		return []byte(payload), nil
+	}
+}
+
+// windowsToWSLPath maps a drive path such as C:\Users\me\a.txt to its WSL
+// mount (/mnt/c/Users/me/a.txt). Other paths, such as UNC shares, are kept.
+func windowsToWSLPath(windowsPath string) string {
+	if len(windowsPath) < 3 || windowsPath[1] != ':' || (windowsPath[2] != '\\' && windowsPath[2] != '/') {
+		return windowsPath
+	}
+	drive := strings.ToLower(windowsPath[:1])
+	if drive < "a" || drive > "z" {
+		return windowsPath
+	}
+	return path.Join(wslMountRoot(), drive, strings.ReplaceAll(windowsPath[3:], `\`, "/"))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test that the script output is decoded according to the preferred kind
+func TestDecodeWSLClipboard(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nrest")
+	cases := []struct {
+		output   string
+		expected string
+	}{
+		{"Text,HTML\nhello\r\nworld", "hello\r\nworld"},
+		{"Image\n" + base64.StdEncoding.EncodeToString(png) + "\r\n", string(png)},
+		{"FileDropList\nC:\\Users\\me\\a.txt\n\\\\server\\share\\b.txt", "/mnt/c/Users/me/a.txt\n\\\\server\\share\\b.txt"},
+		{"\n", ""},
+	}
+	for _, c := range cases {
+		kinds, payload := parseWSLClipboardOutput(c.output)
+		data, err := decodeWSLClipboard(kinds, payload)
+		if err != nil || string(data) != c.expected {
+			t.Errorf("Expected %q for %q, got %q (%v)", c.expected, c.output, data, err)
+		}
+	}
+
+	if _, err := decodeWSLClipboard([]string{"Image"}, "not base64!"); err == nil {
+		t.Error("Expected invalid image data to be reported")
+	}
+}
+
+// Test that text is handed to PowerShell as base64 of its UTF-8 bytes
+func TestWriteWindowsText(t *testing.T) {
+	dir := t.TempDir()
+	received := filepath.Join(dir, "stdin")
+	script := filepath.Join(dir, "pwsh.exe")
+	os.WriteFile(script, []byte("#!/bin/sh\ncat > "+received+"\n"), 0755)
+	t.Setenv("MCP_CLIP_POWERSHELL", script)
+
+	if err := (wsl2Backend{}).Write(context.Background(), "héllo ✓"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	encoded, _ := os.ReadFile(received)
+	if decoded, _ := base64.StdEncoding.DecodeString(string(encoded)); string(decoded) != "héllo ✓" {
+		t.Errorf("Expected the UTF-8 text, got %q", decoded)
+	}
+
+	os.WriteFile(script, []byte("#!/bin/sh\necho 'Set-Clipboard: access denied'\nexit 1\n"), 0755)
+	if err := (wsl2Backend{}).Write(context.Background(), "x"); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Errorf("Expected the PowerShell error, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xfixes"
+	"github.com/jezek/xgb/xproto"
+)
+
+// watchXFixes subscribes to XFixes selection-owner notifications for the
+// CLIPBOARD and PRIMARY selections, so an X11 clipboard is only read after an
+// application has claimed a selection. changed is called once at start, so the
+// current contents are recorded, and then for every notification. It returns
+// when ctx is done or the X connection fails.
+func watchXFixes(ctx context.Context, changed func()) error {
+	if os.Getenv("DISPLAY") == "" {
+		return fmt.Errorf("DISPLAY is not set")
+	}
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the X server: %v", err)
+	}
+	stop := context.AfterFunc(ctx, conn.Close)
+	defer stop()
+	defer conn.Close()
+
+	if err := xfixes.Init(conn); err != nil {
+		return fmt.Errorf("XFixes extension unavailable: %v", err)
+	}
+	// The extension must be told which version we speak before it sends events
+	if _, err := xfixes.QueryVersion(conn, 5, 0).Reply(); err != nil {
+		return fmt.Errorf("XFixes version query failed: %v", err)
+	}
+
+	clipboard, err := xproto.InternAtom(conn, false, uint16(len("CLIPBOARD")), "CLIPBOARD").Reply()
+	if err != nil {
+		return fmt.Errorf("failed to look up the CLIPBOARD atom: %v", err)
+	}
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+	mask := uint32(xfixes.SelectionEventMaskSetSelectionOwner |
+		xfixes.SelectionEventMaskSelectionWindowDestroy |
+		xfixes.SelectionEventMaskSelectionClientClose)
+	for _, selection := range []xproto.Atom{clipboard.Atom, xproto.AtomPrimary} {
+		if err := xfixes.SelectSelectionInputChecked(conn, root, selection, mask).Check(); err != nil {
+			return fmt.Errorf("failed to subscribe to selection changes: %v", err)
+		}
+	}
+
+	changed()
+	for {
+		event, xerr := conn.WaitForEvent()
+		switch {
+		case event == nil && xerr == nil:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("X server connection closed")
+		case xerr != nil:
+			if os.Getenv("MCP_DEBUG") == "1" {
+				fmt.Fprintf(os.Stderr, "X11 error while watching selections: %v\n", xerr)
+			}
+		default:
+			if _, ok := event.(xfixes.SelectionNotifyEvent); ok {
+				changed()
+			}
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// Test that XFixes watching fails fast without a reachable X server, so the
+// monitor falls back to polling
+func TestWatchXFixesWithoutDisplay(t *testing.T) {
+	for _, display := range []string{"", ":4242"} {
+		t.Setenv("DISPLAY", display)
+		called := false
+		if err := watchXFixes(context.Background(), func() { called = true }); err == nil {
+			t.Errorf("Expected an error for DISPLAY=%q", display)
+		}
+		if called {
+			t.Errorf("Expected no change callback for DISPLAY=%q", display)
+		}
+	}
+}